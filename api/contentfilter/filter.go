@@ -0,0 +1,149 @@
+// Package contentfilter evalúa el contenido que publican los usuarios (posts, comentarios y
+// mensajes) con una cadena de reglas configurables. Cada regla permite, marca para revisión
+// o rechaza el contenido; el resultado final es el más severo de todos.
+package contentfilter
+
+import (
+	"log"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+// Tipos de contenido evaluados (coinciden con los de search y moderación)
+const (
+	TypePost    = "post"
+	TypeComment = "comment"
+	TypeMessage = "message"
+)
+
+// Verdict es la decisión de una regla, ordenada de menor a mayor severidad
+type Verdict int
+
+const (
+	Allow Verdict = iota
+	Flag
+	Reject
+)
+
+func (v Verdict) String() string {
+	switch v {
+	case Flag:
+		return "flag"
+	case Reject:
+		return "reject"
+	default:
+		return "allow"
+	}
+}
+
+// Content es el texto a evaluar junto con su contexto
+type Content struct {
+	Type     string
+	ID       uint // 0 al crear; en ediciones se excluye de las comparaciones con el historial
+	AuthorID uint
+	Text     string
+}
+
+// Result es la respuesta de una regla
+type Result struct {
+	Verdict Verdict `json:"verdict"`
+	Rule    string  `json:"rule"`
+	Reason  string  `json:"reason"`
+}
+
+// Rule es una regla del filtro
+type Rule interface {
+	Name() string
+	Check(content Content) (Result, error)
+}
+
+// Decision agrupa el veredicto final y los resultados de las reglas que no permitieron el contenido
+type Decision struct {
+	Verdict Verdict
+	Results []Result
+}
+
+// Reasons devuelve los motivos de los resultados con el veredicto indicado
+func (d Decision) Reasons(verdict Verdict) []string {
+	var reasons []string
+	for _, result := range d.Results {
+		if result.Verdict == verdict {
+			reasons = append(reasons, result.Reason)
+		}
+	}
+	return reasons
+}
+
+// Rules devuelve los nombres de las reglas que marcaron o rechazaron el contenido
+func (d Decision) Rules() []string {
+	var names []string
+	for _, result := range d.Results {
+		names = append(names, result.Rule)
+	}
+	return names
+}
+
+// Summary describe la decisión en una sola línea
+func (d Decision) Summary() string {
+	parts := make([]string, 0, len(d.Results))
+	for _, result := range d.Results {
+		parts = append(parts, result.Rule+": "+result.Reason)
+	}
+	return strings.Join(parts, "; ")
+}
+
+// Pipeline ejecuta las reglas en orden
+type Pipeline struct {
+	rules []Rule
+}
+
+// NewPipeline crea una cadena con las reglas indicadas
+func NewPipeline(rules ...Rule) *Pipeline {
+	return &Pipeline{rules: rules}
+}
+
+// NewDefaultPipeline crea la cadena estándar configurada con variables de entorno
+func NewDefaultPipeline(db *gorm.DB) *Pipeline {
+	history := NewSQLHistory(db)
+	return NewPipeline(
+		NewWordListRule(LoadWordLists()),
+		NewLinkRule(LoadLinkConfig()),
+		NewContactInfoRule(),
+		NewDuplicateRule(history),
+		NewVelocityRule(history, LoadVelocityConfig()),
+	)
+}
+
+// Add agrega una regla al final de la cadena
+func (p *Pipeline) Add(rule Rule) {
+	p.rules = append(p.rules, rule)
+}
+
+// Evaluate ejecuta todas las reglas y devuelve el veredicto más severo. Un error en una regla
+// se registra y se ignora para no bloquear publicaciones por fallos internos.
+func (p *Pipeline) Evaluate(content Content) Decision {
+	decision := Decision{Verdict: Allow}
+	if p == nil {
+		return decision
+	}
+
+	for _, rule := range p.rules {
+		result, err := rule.Check(content)
+		if err != nil {
+			log.Printf("Error en la regla de contenido %s: %v", rule.Name(), err)
+			continue
+		}
+		if result.Verdict == Allow {
+			continue
+		}
+		if result.Rule == "" {
+			result.Rule = rule.Name()
+		}
+		decision.Results = append(decision.Results, result)
+		if result.Verdict > decision.Verdict {
+			decision.Verdict = result.Verdict
+		}
+	}
+	return decision
+}
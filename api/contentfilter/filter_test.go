@@ -0,0 +1,140 @@
+package contentfilter
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+// fakeHistory devuelve siempre los mismos textos recientes
+type fakeHistory struct {
+	texts []string
+	err   error
+}
+
+func (h fakeHistory) RecentTexts(contentType string, authorID uint, since time.Time, excludeID uint, limit int) ([]string, error) {
+	if len(h.texts) > limit {
+		return h.texts[:limit], h.err
+	}
+	return h.texts, h.err
+}
+
+// failingRule siempre devuelve un error
+type failingRule struct{}
+
+func (failingRule) Name() string { return "failing" }
+
+func (failingRule) Check(Content) (Result, error) {
+	return Result{}, errors.New("fallo interno")
+}
+
+func testLinkConfig() LinkConfig {
+	return LinkConfig{
+		MaxLinks:      2,
+		RejectLinks:   4,
+		FlagDomains:   toDomainSet([]string{"bit.ly"}),
+		RejectDomains: toDomainSet([]string{"malware.example"}),
+		AllowDomains:  toDomainSet([]string{"github.com"}),
+	}
+}
+
+func TestRules(t *testing.T) {
+	words := NewWordListRule(WordLists{
+		Flag:   toSet([]string{"idiota"}),
+		Reject: toSet([]string{"prohibida"}),
+	})
+	links := NewLinkRule(testLinkConfig())
+	contact := NewContactInfoRule()
+	repeated := "Ofrezco clases de guitarra los fines de semana"
+	duplicate := NewDuplicateRule(fakeHistory{texts: []string{repeated}})
+	velocity := NewVelocityRule(fakeHistory{texts: []string{"a", "b", "c"}}, VelocityConfig{
+		Window: time.Minute,
+		Limits: map[string]int{TypePost: 3, TypeComment: 10},
+	})
+
+	tests := []struct {
+		name    string
+		rule    Rule
+		content Content
+		want    Verdict
+	}{
+		{"texto limpio", words, Content{Type: TypePost, Text: "Enseño Go y SQL"}, Allow},
+		{"palabra marcada", words, Content{Type: TypePost, Text: "Eres un idiota"}, Flag},
+		{"palabra marcada con acentos y mayúsculas", words, Content{Type: TypePost, Text: "IDIÓTA"}, Flag},
+		{"palabra marcada con sustituciones", words, Content{Type: TypePost, Text: "1d10t4"}, Flag},
+		{"palabra rechazada", words, Content{Type: TypePost, Text: "una palabra prohibida"}, Reject},
+		{"rechazo sobre marca", words, Content{Type: TypePost, Text: "idiota prohibida"}, Reject},
+
+		{"sin enlaces", links, Content{Type: TypePost, Text: "Sin enlaces"}, Allow},
+		{"enlaces dentro del límite", links, Content{Type: TypePost, Text: "https://a.example https://b.example"}, Allow},
+		{"demasiados enlaces", links, Content{Type: TypePost, Text: "https://a.example https://b.example https://c.example"}, Flag},
+		{"enlaces en cantidad de rechazo", links, Content{Type: TypePost, Text: "https://a.example https://b.example https://c.example https://d.example"}, Reject},
+		{"dominios de confianza no cuentan", links, Content{Type: TypePost, Text: "https://github.com/a https://github.com/b https://github.com/c"}, Allow},
+		{"acortador", links, Content{Type: TypePost, Text: "mira https://bit.ly/abc"}, Flag},
+		{"subdominio de dominio bloqueado", links, Content{Type: TypePost, Text: "www.cdn.malware.example/x"}, Reject},
+
+		{"correo en un post", contact, Content{Type: TypePost, Text: "escríbeme a juan@example.com"}, Flag},
+		{"correo ofuscado", contact, Content{Type: TypeComment, Text: "juan (at) example (dot) com"}, Flag},
+		{"teléfono en un comentario", contact, Content{Type: TypeComment, Text: "llámame al +34 612 345 678"}, Flag},
+		{"números cortos", contact, Content{Type: TypePost, Text: "nivel 3, 2 horas por semana"}, Allow},
+		{"correo en un mensaje privado", contact, Content{Type: TypeMessage, Text: "mi correo es juan@example.com"}, Allow},
+
+		{"texto repetido", duplicate, Content{Type: TypePost, Text: "ofrezco CLASES de guitarra, los fines de semana!"}, Flag},
+		{"texto distinto", duplicate, Content{Type: TypePost, Text: "Busco clases de cocina italiana los sábados"}, Allow},
+		{"texto corto repetido", NewDuplicateRule(fakeHistory{texts: []string{"gracias"}}), Content{Type: TypeComment, Text: "gracias"}, Allow},
+
+		{"límite de publicaciones alcanzado", velocity, Content{Type: TypePost, Text: "nuevo"}, Reject},
+		{"debajo del límite", velocity, Content{Type: TypeComment, Text: "nuevo"}, Allow},
+		{"las ediciones no cuentan", velocity, Content{Type: TypePost, ID: 7, Text: "editado"}, Allow},
+		{"tipo sin límite", velocity, Content{Type: TypeMessage, Text: "hola"}, Allow},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := tt.rule.Check(tt.content)
+			if err != nil {
+				t.Fatalf("Check devolvió un error: %v", err)
+			}
+			if result.Verdict != tt.want {
+				t.Errorf("veredicto = %s, se esperaba %s (motivo: %q)", result.Verdict, tt.want, result.Reason)
+			}
+			if result.Verdict != Allow && result.Reason == "" {
+				t.Error("un veredicto distinto de allow debe indicar el motivo")
+			}
+		})
+	}
+}
+
+func TestPipelineEvaluate(t *testing.T) {
+	pipeline := NewPipeline(
+		NewWordListRule(WordLists{Flag: toSet([]string{"idiota"}), Reject: toSet(nil)}),
+		NewLinkRule(testLinkConfig()),
+		failingRule{},
+		NewContactInfoRule(),
+	)
+
+	tests := []struct {
+		name      string
+		pipeline  *Pipeline
+		text      string
+		want      Verdict
+		wantRules []string
+	}{
+		{"sin coincidencias", pipeline, "Enseño guitarra", Allow, nil},
+		{"una regla marca", pipeline, "idiota", Flag, []string{"profanity"}},
+		{"gana el veredicto más severo", pipeline, "idiota www.malware.example", Reject, []string{"profanity", "spam_links"}},
+		{"varias reglas marcan", pipeline, "idiota, escribe a ana@example.com", Flag, []string{"profanity", "contact_info"}},
+		{"cadena nula", nil, "idiota", Allow, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			decision := tt.pipeline.Evaluate(Content{Type: TypePost, AuthorID: 1, Text: tt.text})
+			if decision.Verdict != tt.want {
+				t.Errorf("veredicto = %s, se esperaba %s", decision.Verdict, tt.want)
+			}
+			if got := strings.Join(decision.Rules(), ","); got != strings.Join(tt.wantRules, ",") {
+				t.Errorf("reglas = %q, se esperaba %q", got, strings.Join(tt.wantRules, ","))
+			}
+		})
+	}
+}
@@ -0,0 +1,49 @@
+package contentfilter
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// History da acceso al contenido publicado recientemente por un usuario
+type History interface {
+	// RecentTexts devuelve hasta limit textos del autor publicados desde since, excluyendo excludeID
+	RecentTexts(contentType string, authorID uint, since time.Time, excludeID uint, limit int) ([]string, error)
+}
+
+// SQLHistory consulta el historial en las tablas de la aplicación
+type SQLHistory struct {
+	DB *gorm.DB
+}
+
+// NewSQLHistory crea un historial respaldado por la base de datos
+func NewSQLHistory(db *gorm.DB) *SQLHistory {
+	return &SQLHistory{DB: db}
+}
+
+// historySources indica tabla, columnas de ID, autor y texto por tipo de contenido
+var historySources = map[string]struct {
+	table, id, author, text string
+}{
+	TypePost:    {"Posts", "PostID", "UsuarioID", "Descripcion"},
+	TypeComment: {"Comentarios", "ComentarioID", "UsuarioID", "Contenido"},
+	// Content es NTEXT, por lo que se convierte para poder leerlo como texto normal
+	TypeMessage: {"Messages", "ID", "SenderID", "CAST(Content AS NVARCHAR(MAX))"},
+}
+
+func (h *SQLHistory) RecentTexts(contentType string, authorID uint, since time.Time, excludeID uint, limit int) ([]string, error) {
+	source, ok := historySources[contentType]
+	if !ok {
+		return nil, nil
+	}
+
+	var texts []string
+	err := h.DB.Table(source.table).
+		Select(source.text).
+		Where(source.author+" = ? AND CreatedAt >= ? AND "+source.id+" <> ?", authorID, since, excludeID).
+		Order("CreatedAt DESC").
+		Limit(limit).
+		Pluck(source.text, &texts).Error
+	return texts, err
+}
@@ -0,0 +1,385 @@
+package contentfilter
+
+import (
+	"bufio"
+	"fmt"
+	"log"
+	"net/url"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	"golang.org/x/text/runes"
+	"golang.org/x/text/transform"
+	"golang.org/x/text/unicode/norm"
+)
+
+// Palabras marcadas por defecto (español e inglés). Se pueden reemplazar con
+// CONTENT_FILTER_FLAG_WORDS / CONTENT_FILTER_REJECT_WORDS (separadas por comas) o con
+// CONTENT_FILTER_WORDLIST_FILE, un archivo con una palabra por línea y el prefijo "!" para rechazar.
+var defaultFlagWords = []string{
+	"mierda", "puta", "puto", "pendejo", "cabron", "gilipollas", "imbecil", "estupido", "idiota", "joder", "carajo", "verga", "chinga",
+	"fuck", "fucking", "shit", "bitch", "asshole", "bastard", "dick", "cunt", "motherfucker",
+}
+
+// Dominios de acortadores que ocultan el destino real del enlace
+var defaultFlagDomains = []string{"bit.ly", "tinyurl.com", "goo.gl", "t.co", "ow.ly", "is.gd", "cutt.ly", "rebrand.ly"}
+
+// WordLists son las palabras que marcan o rechazan el contenido, ya normalizadas
+type WordLists struct {
+	Flag   map[string]bool
+	Reject map[string]bool
+}
+
+// LoadWordLists lee las listas de palabras de la configuración
+func LoadWordLists() WordLists {
+	lists := WordLists{
+		Flag:   toSet(envList("CONTENT_FILTER_FLAG_WORDS", defaultFlagWords)),
+		Reject: toSet(envList("CONTENT_FILTER_REJECT_WORDS", nil)),
+	}
+
+	if path := os.Getenv("CONTENT_FILTER_WORDLIST_FILE"); path != "" {
+		file, err := os.Open(path)
+		if err != nil {
+			log.Printf("⚠️  No se pudo abrir la lista de palabras %s: %v", path, err)
+			return lists
+		}
+		defer file.Close()
+
+		scanner := bufio.NewScanner(file)
+		for scanner.Scan() {
+			word := strings.TrimSpace(scanner.Text())
+			if word == "" || strings.HasPrefix(word, "#") {
+				continue
+			}
+			if strings.HasPrefix(word, "!") {
+				lists.Reject[normalize(word[1:])] = true
+			} else {
+				lists.Flag[normalize(word)] = true
+			}
+		}
+	}
+	return lists
+}
+
+// WordListRule marca o rechaza el contenido que contiene palabras de las listas
+type WordListRule struct {
+	lists WordLists
+}
+
+func NewWordListRule(lists WordLists) *WordListRule {
+	return &WordListRule{lists: lists}
+}
+
+func (r *WordListRule) Name() string { return "profanity" }
+
+func (r *WordListRule) Check(content Content) (Result, error) {
+	verdict := Allow
+	var found []string
+	for _, word := range words(content.Text) {
+		switch {
+		case r.lists.Reject[word]:
+			verdict = Reject
+			found = append(found, word)
+		case r.lists.Flag[word]:
+			if verdict < Flag {
+				verdict = Flag
+			}
+			found = append(found, word)
+		}
+	}
+	if verdict == Allow {
+		return Result{Verdict: Allow}, nil
+	}
+	return Result{Verdict: verdict, Reason: "lenguaje inapropiado (" + strings.Join(unique(found), ", ") + ")"}, nil
+}
+
+// LinkConfig define los límites de enlaces y la reputación de dominios
+type LinkConfig struct {
+	MaxLinks      int             // A partir de aquí se marca el contenido
+	RejectLinks   int             // A partir de aquí se rechaza
+	FlagDomains   map[string]bool // Acortadores u otros dominios dudosos
+	RejectDomains map[string]bool // Dominios con mala reputación conocida
+	AllowDomains  map[string]bool // Dominios de confianza que no cuentan para el límite
+}
+
+// LoadLinkConfig lee la configuración de enlaces
+func LoadLinkConfig() LinkConfig {
+	config := LinkConfig{
+		MaxLinks:      envInt("CONTENT_FILTER_MAX_LINKS", 3),
+		FlagDomains:   toDomainSet(envList("CONTENT_FILTER_FLAG_DOMAINS", defaultFlagDomains)),
+		RejectDomains: toDomainSet(envList("CONTENT_FILTER_REJECT_DOMAINS", nil)),
+		AllowDomains:  toDomainSet(envList("CONTENT_FILTER_ALLOW_DOMAINS", []string{"github.com", "linkedin.com", "youtube.com", "wikipedia.org"})),
+	}
+	config.RejectLinks = envInt("CONTENT_FILTER_REJECT_LINKS", config.MaxLinks*3)
+	return config
+}
+
+var linkPattern = regexp.MustCompile(`(?i)\b(?:https?://|www\.)[^\s<>()\[\]"']+`)
+
+// LinkRule limita la cantidad de enlaces y revisa la reputación de sus dominios
+type LinkRule struct {
+	config LinkConfig
+}
+
+func NewLinkRule(config LinkConfig) *LinkRule {
+	return &LinkRule{config: config}
+}
+
+func (r *LinkRule) Name() string { return "spam_links" }
+
+func (r *LinkRule) Check(content Content) (Result, error) {
+	counted := 0
+	var flagged []string
+	for _, link := range linkPattern.FindAllString(content.Text, -1) {
+		domain := linkDomain(link)
+		if matchesDomain(r.config.RejectDomains, domain) {
+			return Result{Verdict: Reject, Reason: "enlace a un dominio bloqueado (" + domain + ")"}, nil
+		}
+		if matchesDomain(r.config.FlagDomains, domain) {
+			flagged = append(flagged, domain)
+		}
+		if !matchesDomain(r.config.AllowDomains, domain) {
+			counted++
+		}
+	}
+
+	switch {
+	case r.config.RejectLinks > 0 && counted >= r.config.RejectLinks:
+		return Result{Verdict: Reject, Reason: fmt.Sprintf("demasiados enlaces (%d)", counted)}, nil
+	case r.config.MaxLinks > 0 && counted > r.config.MaxLinks:
+		return Result{Verdict: Flag, Reason: fmt.Sprintf("demasiados enlaces (%d)", counted)}, nil
+	case len(flagged) > 0:
+		return Result{Verdict: Flag, Reason: "enlaces acortados o de dominios dudosos (" + strings.Join(unique(flagged), ", ") + ")"}, nil
+	}
+	return Result{Verdict: Allow}, nil
+}
+
+var (
+	emailPattern = regexp.MustCompile(`(?i)[a-z0-9._%+\-]+\s*(?:@|\(at\)|\[at\]|\sarroba\s)\s*[a-z0-9.\-]+\s*(?:\.|\(dot\)|\[dot\]|\spunto\s)\s*[a-z]{2,}`)
+	phonePattern = regexp.MustCompile(`\+?\d[\d\s().\-]{7,}\d`)
+)
+
+// ContactInfoRule marca posts y comentarios públicos que exponen correos o teléfonos.
+// Los mensajes privados no se revisan porque compartir datos de contacto ahí es legítimo.
+type ContactInfoRule struct{}
+
+func NewContactInfoRule() *ContactInfoRule {
+	return &ContactInfoRule{}
+}
+
+func (r *ContactInfoRule) Name() string { return "contact_info" }
+
+func (r *ContactInfoRule) Check(content Content) (Result, error) {
+	if content.Type == TypeMessage {
+		return Result{Verdict: Allow}, nil
+	}
+	if emailPattern.MatchString(content.Text) {
+		return Result{Verdict: Flag, Reason: "contiene un correo electrónico"}, nil
+	}
+	for _, match := range phonePattern.FindAllString(content.Text, -1) {
+		if digits := countDigits(match); digits >= 9 && digits <= 15 {
+			return Result{Verdict: Flag, Reason: "contiene un número de teléfono"}, nil
+		}
+	}
+	return Result{Verdict: Allow}, nil
+}
+
+// DuplicateRule detecta contenido repetido por el mismo autor en las últimas 24 horas
+type DuplicateRule struct {
+	history History
+	window  time.Duration
+}
+
+func NewDuplicateRule(history History) *DuplicateRule {
+	return &DuplicateRule{history: history, window: 24 * time.Hour}
+}
+
+func (r *DuplicateRule) Name() string { return "duplicate" }
+
+func (r *DuplicateRule) Check(content Content) (Result, error) {
+	fingerprint := strings.Join(words(content.Text), " ")
+	// Los textos muy cortos ("gracias", "ok") se repiten de forma legítima
+	if len([]rune(fingerprint)) < 20 {
+		return Result{Verdict: Allow}, nil
+	}
+
+	texts, err := r.history.RecentTexts(content.Type, content.AuthorID, time.Now().Add(-r.window), content.ID, 50)
+	if err != nil {
+		return Result{}, err
+	}
+	for _, text := range texts {
+		if strings.Join(words(text), " ") == fingerprint {
+			return Result{Verdict: Flag, Reason: "contenido duplicado publicado recientemente"}, nil
+		}
+	}
+	return Result{Verdict: Allow}, nil
+}
+
+// VelocityConfig define cuántas publicaciones se permiten por ventana de tiempo
+type VelocityConfig struct {
+	Window time.Duration
+	Limits map[string]int
+}
+
+// LoadVelocityConfig lee los límites de publicación por minuto
+func LoadVelocityConfig() VelocityConfig {
+	return VelocityConfig{
+		Window: time.Minute,
+		Limits: map[string]int{
+			TypePost:    envInt("CONTENT_FILTER_POSTS_PER_MINUTE", 3),
+			TypeComment: envInt("CONTENT_FILTER_COMMENTS_PER_MINUTE", 10),
+			TypeMessage: envInt("CONTENT_FILTER_MESSAGES_PER_MINUTE", 30),
+		},
+	}
+}
+
+// VelocityRule rechaza publicaciones nuevas cuando el usuario supera el ritmo permitido
+type VelocityRule struct {
+	history History
+	config  VelocityConfig
+}
+
+func NewVelocityRule(history History, config VelocityConfig) *VelocityRule {
+	return &VelocityRule{history: history, config: config}
+}
+
+func (r *VelocityRule) Name() string { return "velocity" }
+
+func (r *VelocityRule) Check(content Content) (Result, error) {
+	limit := r.config.Limits[content.Type]
+	// Las ediciones no cuentan como publicaciones nuevas
+	if content.ID != 0 || limit <= 0 {
+		return Result{Verdict: Allow}, nil
+	}
+
+	texts, err := r.history.RecentTexts(content.Type, content.AuthorID, time.Now().Add(-r.config.Window), 0, limit)
+	if err != nil {
+		return Result{}, err
+	}
+	if len(texts) >= limit {
+		return Result{Verdict: Reject, Reason: "estás publicando demasiado rápido, espera un momento"}, nil
+	}
+	return Result{Verdict: Allow}, nil
+}
+
+// normalize pasa el texto a minúsculas, elimina acentos y revierte sustituciones comunes (p0t4 -> pota)
+func normalize(s string) string {
+	t := transform.Chain(norm.NFD, runes.Remove(runes.In(unicode.Mn)), norm.NFC)
+	folded, _, err := transform.String(t, s)
+	if err != nil {
+		folded = s
+	}
+	return strings.Map(func(r rune) rune {
+		switch r {
+		case '0':
+			return 'o'
+		case '1':
+			return 'i'
+		case '3':
+			return 'e'
+		case '4', '@':
+			return 'a'
+		case '5', '$':
+			return 's'
+		case '7':
+			return 't'
+		}
+		return r
+	}, strings.ToLower(strings.TrimSpace(folded)))
+}
+
+// words divide el texto en palabras normalizadas
+func words(text string) []string {
+	return strings.FieldsFunc(normalize(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
+
+func linkDomain(link string) string {
+	if !strings.Contains(link, "://") {
+		link = "http://" + link
+	}
+	parsed, err := url.Parse(link)
+	if err != nil {
+		return ""
+	}
+	return strings.TrimPrefix(strings.ToLower(parsed.Hostname()), "www.")
+}
+
+// matchesDomain indica si el dominio o alguno de sus dominios padre está en la lista
+func matchesDomain(domains map[string]bool, domain string) bool {
+	for domain != "" {
+		if domains[domain] {
+			return true
+		}
+		dot := strings.Index(domain, ".")
+		if dot < 0 {
+			return false
+		}
+		domain = domain[dot+1:]
+	}
+	return false
+}
+
+func countDigits(s string) int {
+	count := 0
+	for _, r := range s {
+		if unicode.IsDigit(r) {
+			count++
+		}
+	}
+	return count
+}
+
+func envList(name string, defaults []string) []string {
+	configured := os.Getenv(name)
+	if configured == "" {
+		return defaults
+	}
+	var values []string
+	for _, value := range strings.Split(configured, ",") {
+		if value = strings.TrimSpace(value); value != "" {
+			values = append(values, value)
+		}
+	}
+	return values
+}
+
+func envInt(name string, defaultValue int) int {
+	if value, err := strconv.Atoi(os.Getenv(name)); err == nil && value >= 0 {
+		return value
+	}
+	return defaultValue
+}
+
+func toSet(values []string) map[string]bool {
+	set := make(map[string]bool, len(values))
+	for _, value := range values {
+		set[normalize(value)] = true
+	}
+	return set
+}
+
+func toDomainSet(domains []string) map[string]bool {
+	set := make(map[string]bool, len(domains))
+	for _, domain := range domains {
+		set[strings.TrimPrefix(strings.ToLower(domain), "www.")] = true
+	}
+	return set
+}
+
+func unique(values []string) []string {
+	seen := make(map[string]bool, len(values))
+	var result []string
+	for _, value := range values {
+		if !seen[value] {
+			seen[value] = true
+			result = append(result, value)
+		}
+	}
+	return result
+}
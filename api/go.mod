@@ -20,5 +20,5 @@ require (
 	github.com/jinzhu/now v1.1.5 // indirect
 	github.com/microsoft/go-mssqldb v1.7.2 // indirect
 	golang.org/x/crypto v0.38.0
-	golang.org/x/text v0.25.0 // indirect
+	golang.org/x/text v0.25.0
 )
@@ -2,6 +2,7 @@ package handlers
 
 import (
 	"encoding/json"
+	"fmt"
 	"net/http"
 	"skillswap/api/models"
 	"strconv"
@@ -10,11 +11,17 @@ import (
 )
 
 type abilityHandler struct {
-	DB *gorm.DB
+	DB           *gorm.DB
+	SuggestIndex *SkillSuggestIndex
 }
 
 func NewAbilityHandler(db *gorm.DB) *abilityHandler {
-	return &abilityHandler{DB: db}
+	return &abilityHandler{DB: db, SuggestIndex: NewSkillSuggestIndex(db)}
+}
+
+// SetSuggestIndex configura el índice de autocompletado que se invalida al cambiar las habilidades
+func (h *abilityHandler) SetSuggestIndex(index *SkillSuggestIndex) {
+	h.SuggestIndex = index
 }
 
 func (h *abilityHandler) GetAbilities(w http.ResponseWriter, r *http.Request) {
@@ -108,12 +115,27 @@ func (h *abilityHandler) CreateAbility(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if _, ok := authorizeAdmin(h.DB, w, r); !ok {
+		return
+	}
+
 	var ability models.CreateAbilityRequest
 	if err := json.NewDecoder(r.Body).Decode(&ability); err != nil {
 		http.Error(w, "Error al decodificar la solicitud", http.StatusBadRequest)
 		return
 	}
 
+	// Evitar duplicados que solo difieren en mayúsculas, acentos o espacios, o que ya son alias
+	existing, err := findSkillByName(h.DB, ability.Name)
+	if err != nil {
+		http.Error(w, "Error al buscar habilidades: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if existing != nil {
+		http.Error(w, fmt.Sprintf("La habilidad ya existe como «%s» (ID %d)", existing.Name, existing.ID), http.StatusConflict)
+		return
+	}
+
 	newAbility := models.Ability{
 		Name:        ability.Name,
 		Category:    ability.Category,
@@ -124,6 +146,7 @@ func (h *abilityHandler) CreateAbility(w http.ResponseWriter, r *http.Request) {
 		http.Error(w, result.Error.Error(), http.StatusInternalServerError)
 		return
 	}
+	h.SuggestIndex.Invalidate()
 
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(newAbility)
@@ -135,6 +158,10 @@ func (h *abilityHandler) UpdateAbility(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if _, ok := authorizeAdmin(h.DB, w, r); !ok {
+		return
+	}
+
 	id, err := strconv.Atoi(r.PathValue("id"))
 	if err != nil {
 		http.Error(w, "ID inválido", http.StatusBadRequest)
@@ -154,6 +181,15 @@ func (h *abilityHandler) UpdateAbility(w http.ResponseWriter, r *http.Request) {
 	}
 
 	if ability.Name != nil {
+		duplicate, err := findSkillByName(h.DB, *ability.Name)
+		if err != nil {
+			http.Error(w, "Error al buscar habilidades: "+err.Error(), http.StatusInternalServerError)
+			return
+		}
+		if duplicate != nil && duplicate.ID != existingAbility.ID {
+			http.Error(w, fmt.Sprintf("El nombre ya corresponde a la habilidad «%s» (ID %d): fusiónalas si son la misma", duplicate.Name, duplicate.ID), http.StatusConflict)
+			return
+		}
 		existingAbility.Name = *ability.Name
 	}
 	if ability.Category != nil {
@@ -167,6 +203,7 @@ func (h *abilityHandler) UpdateAbility(w http.ResponseWriter, r *http.Request) {
 		http.Error(w, result.Error.Error(), http.StatusInternalServerError)
 		return
 	}
+	h.SuggestIndex.Invalidate()
 
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(existingAbility)
@@ -177,6 +214,9 @@ func (h *abilityHandler) DeleteAbility(w http.ResponseWriter, r *http.Request) {
 		http.Error(w, "Método no permitido", http.StatusMethodNotAllowed)
 		return
 	}
+	if _, ok := authorizeAdmin(h.DB, w, r); !ok {
+		return
+	}
 
 	id, err := strconv.Atoi(r.PathValue("id"))
 	if err != nil {
@@ -188,6 +228,7 @@ func (h *abilityHandler) DeleteAbility(w http.ResponseWriter, r *http.Request) {
 		http.Error(w, result.Error.Error(), http.StatusInternalServerError)
 		return
 	}
+	h.SuggestIndex.Invalidate()
 
 	w.WriteHeader(http.StatusNoContent)
 }
@@ -0,0 +1,345 @@
+package handlers
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/xml"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"skillswap/api/models"
+
+	"gorm.io/gorm"
+)
+
+const (
+	atomFeedMaxEntries = 50
+	// atomTagPrefix identifica de forma estable los feeds y las entradas (RFC 4151), sin depender del dominio
+	atomTagPrefix = "tag:skillswap,2025:"
+)
+
+type atomFeedHandler struct {
+	DB *gorm.DB
+}
+
+func NewAtomFeedHandler(db *gorm.DB) *atomFeedHandler {
+	return &atomFeedHandler{DB: db}
+}
+
+// Estructuras de Atom 1.0 (RFC 4287)
+type atomFeed struct {
+	XMLName xml.Name    `xml:"http://www.w3.org/2005/Atom feed"`
+	ID      string      `xml:"id"`
+	Title   string      `xml:"title"`
+	Updated string      `xml:"updated"`
+	Links   []atomLink  `xml:"link"`
+	Author  atomPerson  `xml:"author"`
+	Entries []atomEntry `xml:"entry"`
+}
+
+type atomLink struct {
+	Rel  string `xml:"rel,attr,omitempty"`
+	Type string `xml:"type,attr,omitempty"`
+	Href string `xml:"href,attr"`
+}
+
+type atomPerson struct {
+	Name string `xml:"name"`
+	URI  string `xml:"uri,omitempty"`
+}
+
+type atomCategory struct {
+	Term string `xml:"term,attr"`
+}
+
+type atomText struct {
+	Type string `xml:"type,attr"`
+	Body string `xml:",chardata"`
+}
+
+type atomEntry struct {
+	ID         string         `xml:"id"`
+	Title      string         `xml:"title"`
+	Updated    string         `xml:"updated"`
+	Published  string         `xml:"published"`
+	Author     atomPerson     `xml:"author"`
+	Links      []atomLink     `xml:"link"`
+	Categories []atomCategory `xml:"category"`
+	Content    atomText       `xml:"content"`
+}
+
+// GetPostsAtomFeed publica los posts abiertos más recientes como feed Atom 1.0. Acepta skill (ID o
+// nombre de la habilidad), category y tipo, y responde 304 a las solicitudes condicionales
+// (If-None-Match / If-Modified-Since) cuando el feed no cambió.
+func (h *atomFeedHandler) GetPostsAtomFeed(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		http.Error(w, "Método no permitido", http.StatusMethodNotAllowed)
+		return
+	}
+
+	params := r.URL.Query()
+	skill := strings.TrimSpace(params.Get("skill"))
+	category := strings.TrimSpace(params.Get("category"))
+	tipo := strings.ToUpper(strings.TrimSpace(params.Get("tipo")))
+
+	query := postsWithStatus(visiblePosts(h.DB.Model(&models.PostFullInfo{})), []string{models.PostAbierto})
+	var titleParts []string
+	if skill != "" {
+		if id, err := strconv.ParseUint(skill, 10, 64); err == nil {
+			query = query.Where("PostID IN (SELECT PostID FROM Posts WHERE HabilidadID = ?)", id)
+		} else {
+			query = query.Where("NombreHabilidad = ?", skill)
+		}
+		titleParts = append(titleParts, skill)
+	}
+	if category != "" {
+		query = query.Where("PostID IN (SELECT p.PostID FROM Posts p INNER JOIN Habilidades h ON h.HabilidadID = p.HabilidadID WHERE h.Categoria = ?)", category)
+		titleParts = append(titleParts, category)
+	}
+	if tipo != "" {
+		query = query.Where("TipoPost = ?", tipo)
+		titleParts = append(titleParts, tipo)
+	}
+
+	var posts []models.PostFullInfo
+	if err := query.Order("CreatedAt DESC, PostID DESC").Limit(atomFeedMaxEntries).Find(&posts).Error; err != nil {
+		http.Error(w, "Error al obtener posts: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if err := attachPostDetails(h.DB, posts); err != nil {
+		http.Error(w, "Error al obtener posts: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+	categories, err := loadPostCategories(h.DB, posts)
+	if err != nil {
+		http.Error(w, "Error al obtener categorías: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	// Parámetros en forma canónica para que el ID del feed no dependa de su orden en la URL
+	canonical := url.Values{}
+	for name, value := range map[string]string{"skill": skill, "category": category, "tipo": tipo} {
+		if value != "" {
+			canonical.Set(name, value)
+		}
+	}
+	feedID := atomTagPrefix + "feeds/posts"
+	if encoded := canonical.Encode(); encoded != "" {
+		feedID += "?" + encoded
+	}
+
+	title := "SkillSwap - Posts"
+	if len(titleParts) > 0 {
+		title += " (" + strings.Join(titleParts, ", ") + ")"
+	}
+
+	frontendURL := strings.TrimRight(os.Getenv("FRONTEND_URL"), "/")
+	if frontendURL == "" {
+		frontendURL = "http://localhost:3000"
+	}
+
+	// La fecha del feed considera también los posts que salieron de él (cerrados, ocultos, vencidos o
+	// eliminados), así una solicitud con If-Modified-Since no recibe un 304 desactualizado. Sin cambios
+	// registrados se usa el inicio de la hora actual, así el ETag del feed vacío es estable.
+	changedAt, err := feedLastModified(h.DB, skill, category, tipo)
+	if err != nil {
+		http.Error(w, "Error al obtener posts: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+	updated := time.Now().UTC().Truncate(time.Hour)
+	if changedAt != nil {
+		updated = *changedAt
+	}
+	entries := make([]atomEntry, 0, len(posts))
+	for _, post := range posts {
+		entryUpdated := postLastModified(post)
+		if entryUpdated.After(updated) {
+			updated = entryUpdated
+		}
+
+		entry := atomEntry{
+			ID:        fmt.Sprintf("%spost/%d", atomTagPrefix, post.PostID),
+			Title:     postEntryTitle(post),
+			Updated:   entryUpdated.UTC().Format(time.RFC3339),
+			Published: post.CreatedAt.UTC().Format(time.RFC3339),
+			Author: atomPerson{
+				Name: post.NombreUsuario,
+				URI:  fmt.Sprintf("%s/profiles/%d", frontendURL, post.IDUsuario),
+			},
+			Links:   []atomLink{{Rel: "alternate", Type: "text/html", Href: fmt.Sprintf("%s/posts/%d", frontendURL, post.PostID)}},
+			Content: atomText{Type: "html", Body: post.DescripcionHTML},
+		}
+		if categoria := categories[post.PostID]; categoria != "" {
+			entry.Categories = append(entry.Categories, atomCategory{Term: categoria})
+		}
+		entries = append(entries, entry)
+	}
+
+	feed := atomFeed{
+		ID:      feedID,
+		Title:   title,
+		Updated: updated.UTC().Format(time.RFC3339),
+		Links: []atomLink{
+			{Rel: "self", Type: "application/atom+xml", Href: requestAbsoluteURL(r)},
+			{Rel: "alternate", Type: "text/html", Href: frontendURL},
+		},
+		Author:  atomPerson{Name: "SkillSwap", URI: frontendURL},
+		Entries: entries,
+	}
+
+	body, err := xml.MarshalIndent(feed, "", "  ")
+	if err != nil {
+		http.Error(w, "Error al generar el feed: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+	body = append([]byte(xml.Header), body...)
+
+	sum := sha256.Sum256(body)
+	etag := `"` + hex.EncodeToString(sum[:16]) + `"`
+	lastModified := updated.UTC().Truncate(time.Second)
+
+	w.Header().Set("ETag", etag)
+	w.Header().Set("Last-Modified", lastModified.Format(http.TimeFormat))
+	w.Header().Set("Cache-Control", "public, max-age=300")
+	if notModified(r, etag, lastModified) {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/atom+xml; charset=utf-8")
+	if r.Method == http.MethodHead {
+		return
+	}
+	w.Write(body)
+}
+
+// notModified evalúa las cabeceras condicionales; If-None-Match tiene prioridad sobre If-Modified-Since
+func notModified(r *http.Request, etag string, lastModified time.Time) bool {
+	if match := r.Header.Get("If-None-Match"); match != "" {
+		for _, candidate := range strings.Split(match, ",") {
+			candidate = strings.TrimPrefix(strings.TrimSpace(candidate), "W/")
+			if candidate == etag || candidate == "*" {
+				return true
+			}
+		}
+		return false
+	}
+	if since := r.Header.Get("If-Modified-Since"); since != "" {
+		if t, err := http.ParseTime(since); err == nil {
+			return !lastModified.After(t)
+		}
+	}
+	return false
+}
+
+// feedLastModified devuelve el último cambio de los posts que coinciden con los filtros del feed en
+// cualquier estado: creación, edición, cambio de estado o moderación (UpdatedAt), cumplimiento,
+// vencimiento y eliminación. nil si no hay ninguno.
+func feedLastModified(db *gorm.DB, skill, category, tipo string) (*time.Time, error) {
+	filter := func(query *gorm.DB) *gorm.DB {
+		query = query.Joins("INNER JOIN Habilidades h ON h.HabilidadID = p.HabilidadID")
+		if skill != "" {
+			if id, err := strconv.ParseUint(skill, 10, 64); err == nil {
+				query = query.Where("p.HabilidadID = ?", id)
+			} else {
+				query = query.Where("h.NombreHabilidad = ?", skill)
+			}
+		}
+		if category != "" {
+			query = query.Where("h.Categoria = ?", category)
+		}
+		if tipo != "" {
+			query = query.Where("p.TipoPost = ?", tipo)
+		}
+		return query
+	}
+
+	var posts, deleted struct {
+		Fecha *time.Time `gorm:"column:Fecha"`
+	}
+	if err := filter(db.Table("Posts p")).
+		Joins("CROSS APPLY (VALUES (p.CreatedAt), (p.UpdatedAt), (p.EditadoEn), (p.CumplidoEn), " +
+			"(CASE WHEN p.ExpiresAt <= GETDATE() THEN p.ExpiresAt END)) AS v(Fecha)").
+		Select("MAX(v.Fecha) AS Fecha").
+		Scan(&posts).Error; err != nil {
+		return nil, err
+	}
+	if err := filter(db.Table("PostsEliminados p")).
+		Select("MAX(p.FechaEliminacion) AS Fecha").
+		Scan(&deleted).Error; err != nil {
+		return nil, err
+	}
+
+	if posts.Fecha == nil || (deleted.Fecha != nil && deleted.Fecha.After(*posts.Fecha)) {
+		return deleted.Fecha, nil
+	}
+	return posts.Fecha, nil
+}
+
+// postLastModified devuelve la última modificación visible de un post
+func postLastModified(post models.PostFullInfo) time.Time {
+	updated := post.CreatedAt
+	if post.UpdatedAt.After(updated) {
+		updated = post.UpdatedAt
+	}
+	if post.EditadoEn != nil && post.EditadoEn.After(updated) {
+		updated = *post.EditadoEn
+	}
+	return updated
+}
+
+// postEntryTitle arma el título de la entrada con el tipo de post y la habilidad
+func postEntryTitle(post models.PostFullInfo) string {
+	switch strings.ToUpper(post.TipoPost) {
+	case "OFREZCO":
+		return "Ofrezco: " + post.NombreHabilidad
+	case "BUSCO":
+		return "Busco: " + post.NombreHabilidad
+	}
+	return post.NombreHabilidad
+}
+
+// loadPostCategories devuelve la categoría de la habilidad de cada post
+func loadPostCategories(db *gorm.DB, posts []models.PostFullInfo) (map[uint]string, error) {
+	categories := make(map[uint]string, len(posts))
+	if len(posts) == 0 {
+		return categories, nil
+	}
+	ids := make([]uint, 0, len(posts))
+	for _, post := range posts {
+		ids = append(ids, post.PostID)
+	}
+
+	var rows []struct {
+		PostID    uint   `gorm:"column:PostID"`
+		Categoria string `gorm:"column:Categoria"`
+	}
+	if err := db.Table("Posts p").
+		Select("p.PostID, ISNULL(h.Categoria, '') AS Categoria").
+		Joins("INNER JOIN Habilidades h ON h.HabilidadID = p.HabilidadID").
+		Where("p.PostID IN ?", ids).
+		Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+	for _, row := range rows {
+		categories[row.PostID] = row.Categoria
+	}
+	return categories, nil
+}
+
+// requestAbsoluteURL reconstruye la URL pública de la solicitud, respetando X-Forwarded-Proto
+func requestAbsoluteURL(r *http.Request) string {
+	scheme := "http"
+	if r.TLS != nil {
+		scheme = "https"
+	}
+	if proto := r.Header.Get("X-Forwarded-Proto"); proto != "" {
+		scheme = strings.TrimSpace(strings.Split(proto, ",")[0])
+	}
+	return scheme + "://" + r.Host + r.URL.RequestURI()
+}
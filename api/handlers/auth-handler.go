@@ -2,9 +2,11 @@ package handlers
 
 import (
 	"encoding/json"
+	"errors"
 	"log"
 	"net/http"
 	"os"
+	"skillswap/api/middleware"
 	"skillswap/api/models"
 	"time"
 
@@ -79,6 +81,12 @@ func (h *authHandler) Login(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// Las cuentas baneadas o suspendidas por moderación no pueden iniciar sesión
+	if reason := accountRestriction(user); reason != "" {
+		http.Error(w, reason, http.StatusForbidden)
+		return
+	}
+
 	// Generar token JWT
 	expiresAt := time.Now().Add(h.JWTExpires)
 	claims := jwt.MapClaims{
@@ -168,3 +176,30 @@ func (h *authHandler) ValidateToken(w http.ResponseWriter, r *http.Request) {
 	w.WriteHeader(http.StatusOK)
 	json.NewEncoder(w).Encode(user)
 }
+
+// accountRestriction devuelve el motivo por el que la cuenta no puede usarse, o "" si está habilitada
+func accountRestriction(user models.User) string {
+	if user.IsBanned {
+		return "Tu cuenta ha sido bloqueada por moderación"
+	}
+	if user.SuspendidoHasta != nil && user.SuspendidoHasta.After(time.Now()) {
+		return "Tu cuenta está suspendida hasta el " + user.SuspendidoHasta.Format("02/01/2006 15:04")
+	}
+	return ""
+}
+
+// AccountStatusChecker verifica en cada petición autenticada que la cuenta no haya sido baneada
+// o suspendida después de emitir su token
+func AccountStatusChecker(db *gorm.DB) middleware.AccountStatusChecker {
+	return func(userID uint) (bool, string, error) {
+		var user models.User
+		if err := db.Select("UsuarioID", "IsBanned", "SuspendidoHasta").First(&user, userID).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return false, "La cuenta ya no existe", nil
+			}
+			return false, "", err
+		}
+		reason := accountRestriction(user)
+		return reason == "", reason, nil
+	}
+}
@@ -0,0 +1,208 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"skillswap/api/middleware"
+	"skillswap/api/models"
+
+	"gorm.io/gorm"
+)
+
+type blocksHandler struct {
+	DB *gorm.DB
+}
+
+func NewBlocksHandler(db *gorm.DB) *blocksHandler {
+	return &blocksHandler{DB: db}
+}
+
+// GetUserBlocks obtiene la lista de usuarios bloqueados por un usuario
+func (h *blocksHandler) GetUserBlocks(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Método no permitido", http.StatusMethodNotAllowed)
+		return
+	}
+
+	userID, ok := h.authorizeBlockOwner(w, r)
+	if !ok {
+		return
+	}
+
+	var blocks []models.UserBlock
+	if result := h.DB.Preload("Bloqueado").Where("UsuarioID = ?", userID).Order("FechaCreacion DESC").Find(&blocks); result.Error != nil {
+		http.Error(w, "Error al obtener bloqueos: "+result.Error.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	if blocks == nil {
+		blocks = []models.UserBlock{}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(blocks)
+}
+
+// BlockUser agrega un usuario a la lista de bloqueos
+func (h *blocksHandler) BlockUser(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Método no permitido", http.StatusMethodNotAllowed)
+		return
+	}
+
+	userID, ok := h.authorizeBlockOwner(w, r)
+	if !ok {
+		return
+	}
+
+	var req models.BlockUserRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Error al decodificar la solicitud: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if req.BloqueadoID == 0 {
+		http.Error(w, "Se requiere el ID del usuario a bloquear", http.StatusBadRequest)
+		return
+	}
+	if req.BloqueadoID == userID {
+		http.Error(w, "Un usuario no puede bloquearse a sí mismo", http.StatusBadRequest)
+		return
+	}
+
+	var blockedUser models.User
+	if result := h.DB.First(&blockedUser, req.BloqueadoID); result.Error != nil {
+		http.Error(w, "Usuario a bloquear no encontrado", http.StatusNotFound)
+		return
+	}
+
+	// Si el bloqueo ya existe, devolverlo sin crear uno nuevo
+	var block models.UserBlock
+	result := h.DB.Where("UsuarioID = ? AND BloqueadoID = ?", userID, req.BloqueadoID).First(&block)
+	if result.Error == nil {
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(block)
+		return
+	}
+	if !errors.Is(result.Error, gorm.ErrRecordNotFound) {
+		http.Error(w, "Error al verificar bloqueo: "+result.Error.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	block = models.UserBlock{
+		UsuarioID:   userID,
+		BloqueadoID: req.BloqueadoID,
+	}
+	if result := h.DB.Create(&block); result.Error != nil {
+		http.Error(w, "Error al bloquear usuario: "+result.Error.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(block)
+}
+
+// UnblockUser elimina un usuario de la lista de bloqueos
+func (h *blocksHandler) UnblockUser(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodDelete {
+		http.Error(w, "Método no permitido", http.StatusMethodNotAllowed)
+		return
+	}
+
+	userID, ok := h.authorizeBlockOwner(w, r)
+	if !ok {
+		return
+	}
+
+	var req models.BlockUserRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Error al decodificar la solicitud: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if req.BloqueadoID == 0 {
+		http.Error(w, "Se requiere el ID del usuario a desbloquear", http.StatusBadRequest)
+		return
+	}
+
+	result := h.DB.Where("UsuarioID = ? AND BloqueadoID = ?", userID, req.BloqueadoID).Delete(&models.UserBlock{})
+	if result.Error != nil {
+		http.Error(w, "Error al desbloquear usuario: "+result.Error.Error(), http.StatusInternalServerError)
+		return
+	}
+	if result.RowsAffected == 0 {
+		http.Error(w, "Bloqueo no encontrado", http.StatusNotFound)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// authorizeBlockOwner valida que el usuario autenticado sea el dueño de la lista de bloqueos
+func (h *blocksHandler) authorizeBlockOwner(w http.ResponseWriter, r *http.Request) (uint, bool) {
+	userID, err := parseIDFromPath(r, "id")
+	if err != nil {
+		http.Error(w, "ID de usuario inválido", http.StatusBadRequest)
+		return 0, false
+	}
+
+	user, authenticated := middleware.GetUserFromContext(r)
+	if !authenticated {
+		http.Error(w, "Autenticación requerida para gestionar bloqueos", http.StatusUnauthorized)
+		return 0, false
+	}
+	if user.UserID != userID {
+		http.Error(w, "No tienes permiso para gestionar los bloqueos de este usuario", http.StatusForbidden)
+		return 0, false
+	}
+
+	return userID, true
+}
+
+// blockedUserIDs devuelve los IDs de los usuarios bloqueados por blockerID
+func blockedUserIDs(db *gorm.DB, blockerID uint) ([]uint, error) {
+	var ids []uint
+	err := db.Model(&models.UserBlock{}).
+		Where("UsuarioID = ?", blockerID).
+		Pluck("BloqueadoID", &ids).Error
+	return ids, err
+}
+
+// blockRelatedUserIDs devuelve los IDs de los usuarios con un bloqueo en cualquier dirección con userID
+func blockRelatedUserIDs(db *gorm.DB, userID uint) ([]uint, error) {
+	var blocks []models.UserBlock
+	if err := db.Where("UsuarioID = ? OR BloqueadoID = ?", userID, userID).Find(&blocks).Error; err != nil {
+		return nil, err
+	}
+
+	ids := make([]uint, 0, len(blocks))
+	for _, block := range blocks {
+		if block.UsuarioID == userID {
+			ids = append(ids, block.BloqueadoID)
+		} else {
+			ids = append(ids, block.UsuarioID)
+		}
+	}
+	return ids, nil
+}
+
+// isBlockedBetween indica si alguno de los dos usuarios bloqueó al otro
+func isBlockedBetween(db *gorm.DB, userA, userB uint) (bool, error) {
+	var count int64
+	err := db.Model(&models.UserBlock{}).
+		Where("(UsuarioID = ? AND BloqueadoID = ?) OR (UsuarioID = ? AND BloqueadoID = ?)", userA, userB, userB, userA).
+		Count(&count).Error
+	return count > 0, err
+}
+
+// viewerBlockedUserIDs devuelve los usuarios bloqueados por el usuario autenticado, si lo hay
+func viewerBlockedUserIDs(db *gorm.DB, r *http.Request) ([]uint, error) {
+	user, authenticated := middleware.GetUserFromContext(r)
+	if !authenticated {
+		return nil, nil
+	}
+	return blockedUserIDs(db, user.UserID)
+}
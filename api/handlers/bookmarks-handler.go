@@ -0,0 +1,380 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"math"
+	"net/http"
+	"strings"
+
+	"skillswap/api/middleware"
+	"skillswap/api/models"
+
+	"gorm.io/gorm"
+)
+
+const (
+	bookmarksDefaultPageSize = 20
+	bookmarksMaxPageSize     = 100
+)
+
+type bookmarksHandler struct {
+	DB *gorm.DB
+}
+
+func NewBookmarksHandler(db *gorm.DB) *bookmarksHandler {
+	return &bookmarksHandler{DB: db}
+}
+
+// CreateBookmark guarda un post o un perfil en los marcadores del usuario
+func (h *bookmarksHandler) CreateBookmark(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Método no permitido", http.StatusMethodNotAllowed)
+		return
+	}
+
+	userID, ok := authorizeBookmarksOwner(w, r)
+	if !ok {
+		return
+	}
+
+	var req models.MarcadorRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Error al decodificar la solicitud: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	req.Tipo = strings.ToLower(strings.TrimSpace(req.Tipo))
+	if !containsString(models.TiposMarcador, req.Tipo) {
+		http.Error(w, "Tipo inválido: use "+strings.Join(models.TiposMarcador, " o "), http.StatusBadRequest)
+		return
+	}
+	if req.ElementoID == 0 {
+		http.Error(w, "elemento_id es obligatorio", http.StatusBadRequest)
+		return
+	}
+
+	// El elemento debe existir y ser visible; no se guardan perfiles propios ni de usuarios bloqueados
+	var ownerID uint
+	switch req.Tipo {
+	case models.MarcadorPost:
+		var post models.Post
+		if result := visiblePosts(h.DB).First(&post, req.ElementoID); result.Error != nil {
+			if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+				http.Error(w, "Post no encontrado", http.StatusNotFound)
+			} else {
+				http.Error(w, "Error al buscar post: "+result.Error.Error(), http.StatusInternalServerError)
+			}
+			return
+		}
+		ownerID = post.UsuarioID
+	case models.MarcadorUsuario:
+		if req.ElementoID == userID {
+			http.Error(w, "No puedes guardar tu propio perfil", http.StatusBadRequest)
+			return
+		}
+		var usuario models.User
+		if result := h.DB.Select("UsuarioID").First(&usuario, req.ElementoID); result.Error != nil {
+			if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+				http.Error(w, "Usuario no encontrado", http.StatusNotFound)
+			} else {
+				http.Error(w, "Error al buscar usuario: "+result.Error.Error(), http.StatusInternalServerError)
+			}
+			return
+		}
+		ownerID = usuario.ID
+	}
+	if ownerID != userID {
+		blocked, err := isBlockedBetween(h.DB, userID, ownerID)
+		if err != nil {
+			http.Error(w, "Error al verificar bloqueos: "+err.Error(), http.StatusInternalServerError)
+			return
+		}
+		if blocked {
+			http.Error(w, "No puedes guardar contenido de este usuario", http.StatusForbidden)
+			return
+		}
+	}
+
+	// Guardar dos veces el mismo elemento devuelve el marcador existente
+	var marcador models.Marcador
+	result := h.DB.Where("UsuarioID = ? AND TipoElemento = ? AND ElementoID = ?", userID, req.Tipo, req.ElementoID).First(&marcador)
+	if result.Error == nil {
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(marcador)
+		return
+	}
+	if !errors.Is(result.Error, gorm.ErrRecordNotFound) {
+		http.Error(w, "Error al buscar el marcador: "+result.Error.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	marcador = models.Marcador{UsuarioID: userID, TipoElemento: req.Tipo, ElementoID: req.ElementoID}
+	if err := h.DB.Create(&marcador).Error; err != nil {
+		http.Error(w, "Error al guardar el marcador: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(marcador)
+}
+
+// DeleteBookmark quita un post o un perfil de los marcadores del usuario
+func (h *bookmarksHandler) DeleteBookmark(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodDelete {
+		http.Error(w, "Método no permitido", http.StatusMethodNotAllowed)
+		return
+	}
+
+	userID, ok := authorizeBookmarksOwner(w, r)
+	if !ok {
+		return
+	}
+
+	var req models.MarcadorRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Error al decodificar la solicitud: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	req.Tipo = strings.ToLower(strings.TrimSpace(req.Tipo))
+	if !containsString(models.TiposMarcador, req.Tipo) {
+		http.Error(w, "Tipo inválido: use "+strings.Join(models.TiposMarcador, " o "), http.StatusBadRequest)
+		return
+	}
+	if req.ElementoID == 0 {
+		http.Error(w, "elemento_id es obligatorio", http.StatusBadRequest)
+		return
+	}
+
+	result := h.DB.Where("UsuarioID = ? AND TipoElemento = ? AND ElementoID = ?", userID, req.Tipo, req.ElementoID).
+		Delete(&models.Marcador{})
+	if result.Error != nil {
+		http.Error(w, "Error al eliminar el marcador: "+result.Error.Error(), http.StatusInternalServerError)
+		return
+	}
+	if result.RowsAffected == 0 {
+		http.Error(w, "Marcador no encontrado", http.StatusNotFound)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// GetBookmarks lista paginados los marcadores del usuario con el post o el perfil completo.
+// Acepta tipo=post|user para filtrar; los posts ocultos por moderación no se listan.
+func (h *bookmarksHandler) GetBookmarks(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Método no permitido", http.StatusMethodNotAllowed)
+		return
+	}
+
+	userID, ok := authorizeBookmarksOwner(w, r)
+	if !ok {
+		return
+	}
+
+	page := queryIntInRange(r, "page", 1, 1, math.MaxInt32)
+	pageSize := queryIntInRange(r, "pageSize", bookmarksDefaultPageSize, 1, bookmarksMaxPageSize)
+
+	query := h.DB.Model(&models.Marcador{}).
+		Where("UsuarioID = ?", userID).
+		Where("NOT (TipoElemento = ? AND ElementoID IN (SELECT PostID FROM Posts WHERE Oculto = 1))", models.MarcadorPost)
+	if tipo := strings.ToLower(strings.TrimSpace(r.URL.Query().Get("tipo"))); tipo != "" {
+		if !containsString(models.TiposMarcador, tipo) {
+			http.Error(w, "Tipo inválido: use "+strings.Join(models.TiposMarcador, " o "), http.StatusBadRequest)
+			return
+		}
+		query = query.Where("TipoElemento = ?", tipo)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		http.Error(w, "Error al contar marcadores: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	var marcadores []models.Marcador
+	if err := query.Order("FechaCreacion DESC, MarcadorID DESC").
+		Limit(pageSize).Offset((page - 1) * pageSize).
+		Find(&marcadores).Error; err != nil {
+		http.Error(w, "Error al obtener marcadores: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	completos, err := loadBookmarkItems(h.DB, userID, marcadores)
+	if err != nil {
+		http.Error(w, "Error al obtener marcadores: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(models.MarcadoresResponse{
+		Marcadores:      completos,
+		TotalMarcadores: total,
+		Page:            page,
+		PageSize:        pageSize,
+		TotalPages:      int(math.Ceil(float64(total) / float64(pageSize))),
+	})
+}
+
+// authorizeBookmarksOwner verifica que los marcadores solicitados sean los del usuario autenticado
+func authorizeBookmarksOwner(w http.ResponseWriter, r *http.Request) (uint, bool) {
+	userID, err := parseIDFromPath(r, "id")
+	if err != nil {
+		http.Error(w, "ID de usuario inválido", http.StatusBadRequest)
+		return 0, false
+	}
+
+	user, authenticated := middleware.GetUserFromContext(r)
+	if !authenticated {
+		http.Error(w, "Autenticación requerida", http.StatusUnauthorized)
+		return 0, false
+	}
+	if user.UserID != userID {
+		http.Error(w, "No tienes permiso para gestionar los marcadores de este usuario", http.StatusForbidden)
+		return 0, false
+	}
+	return userID, true
+}
+
+// loadBookmarkItems completa cada marcador con el post o el usuario guardado
+func loadBookmarkItems(db *gorm.DB, viewerID uint, marcadores []models.Marcador) ([]models.MarcadorCompleto, error) {
+	var postIDs, userIDs []uint
+	for _, marcador := range marcadores {
+		if marcador.TipoElemento == models.MarcadorPost {
+			postIDs = append(postIDs, marcador.ElementoID)
+		} else {
+			userIDs = append(userIDs, marcador.ElementoID)
+		}
+	}
+
+	posts := make(map[uint]models.PostFullInfo)
+	if len(postIDs) > 0 {
+		var rows []models.PostFullInfo
+		if err := db.Where("PostID IN ?", postIDs).Find(&rows).Error; err != nil {
+			return nil, err
+		}
+		if err := attachPostDetails(db, rows); err != nil {
+			return nil, err
+		}
+		if err := markBookmarkedPosts(db, viewerID, rows); err != nil {
+			return nil, err
+		}
+		for _, post := range rows {
+			posts[post.PostID] = post
+		}
+	}
+
+	users := make(map[uint]models.User)
+	if len(userIDs) > 0 {
+		var rows []models.User
+		if err := db.Where("UsuarioID IN ?", userIDs).Find(&rows).Error; err != nil {
+			return nil, err
+		}
+		for _, user := range rows {
+			user.Guardado = true
+			users[user.ID] = user
+		}
+	}
+
+	completos := make([]models.MarcadorCompleto, 0, len(marcadores))
+	for _, marcador := range marcadores {
+		completo := models.MarcadorCompleto{Marcador: marcador}
+		if post, ok := posts[marcador.ElementoID]; ok && marcador.TipoElemento == models.MarcadorPost {
+			completo.Post = &post
+		}
+		if user, ok := users[marcador.ElementoID]; ok && marcador.TipoElemento == models.MarcadorUsuario {
+			completo.Usuario = &user
+		}
+		completos = append(completos, completo)
+	}
+	return completos, nil
+}
+
+// markBookmarkedPosts marca los posts que el usuario guardó y, en los posts de los que es autor,
+// agrega cuántas personas los guardaron como métrica de interés
+func markBookmarkedPosts(db *gorm.DB, viewerID uint, posts []models.PostFullInfo) error {
+	if viewerID == 0 || len(posts) == 0 {
+		return nil
+	}
+
+	ids := make([]uint, 0, len(posts))
+	var ownIDs []uint
+	for _, post := range posts {
+		ids = append(ids, post.PostID)
+		if post.IDUsuario == viewerID {
+			ownIDs = append(ownIDs, post.PostID)
+		}
+	}
+
+	var saved []uint
+	if err := db.Model(&models.Marcador{}).
+		Where("UsuarioID = ? AND TipoElemento = ? AND ElementoID IN ?", viewerID, models.MarcadorPost, ids).
+		Pluck("ElementoID", &saved).Error; err != nil {
+		return err
+	}
+
+	counts := make(map[uint]int64)
+	if len(ownIDs) > 0 {
+		var rows []struct {
+			ElementoID uint  `gorm:"column:ElementoID"`
+			Total      int64 `gorm:"column:Total"`
+		}
+		if err := db.Model(&models.Marcador{}).
+			Select("ElementoID, COUNT(*) AS Total").
+			Where("TipoElemento = ? AND ElementoID IN ?", models.MarcadorPost, ownIDs).
+			Group("ElementoID").
+			Scan(&rows).Error; err != nil {
+			return err
+		}
+		for _, row := range rows {
+			counts[row.ElementoID] = row.Total
+		}
+	}
+
+	for i := range posts {
+		posts[i].Guardado = containsUint(saved, posts[i].PostID)
+		if posts[i].IDUsuario == viewerID {
+			total := counts[posts[i].PostID]
+			posts[i].TotalGuardados = &total
+		}
+	}
+	return nil
+}
+
+// markBookmarkedUsers marca los perfiles que el usuario autenticado guardó
+func markBookmarkedUsers(db *gorm.DB, viewerID uint, users []models.User) error {
+	if viewerID == 0 || len(users) == 0 {
+		return nil
+	}
+
+	ids := make([]uint, 0, len(users))
+	for _, user := range users {
+		ids = append(ids, user.ID)
+	}
+	var saved []uint
+	if err := db.Model(&models.Marcador{}).
+		Where("UsuarioID = ? AND TipoElemento = ? AND ElementoID IN ?", viewerID, models.MarcadorUsuario, ids).
+		Pluck("ElementoID", &saved).Error; err != nil {
+		return err
+	}
+	for i := range users {
+		users[i].Guardado = containsUint(saved, users[i].ID)
+	}
+	return nil
+}
+
+// deleteBookmarksOf elimina los marcadores que apuntan a un elemento eliminado
+func deleteBookmarksOf(db *gorm.DB, tipo string, elementoID uint) error {
+	return db.Where("TipoElemento = ? AND ElementoID = ?", tipo, elementoID).Delete(&models.Marcador{}).Error
+}
+
+// containsUint indica si el ID está en la lista
+func containsUint(values []uint, value uint) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,356 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+
+	"skillswap/api/middleware"
+	"skillswap/api/models"
+
+	"gorm.io/gorm"
+)
+
+// ToggleCommentReaction agrega la reacción del usuario autenticado a un comentario o la quita si ya existía.
+// Las reacciones (helpful, insightful, thanks) no afectan la puntuación, que sigue basada en likes/dislikes.
+func (h *commentsHandler) ToggleCommentReaction(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Método no permitido", http.StatusMethodNotAllowed)
+		return
+	}
+
+	comentarioID, err := extractIDFromPath(r.URL.Path, "comments")
+	if err != nil {
+		log.Printf("Error extrayendo comentarioID: %v", err)
+		http.Error(w, "ID de comentario inválido", http.StatusBadRequest)
+		return
+	}
+
+	user, authenticated := middleware.GetUserFromContext(r)
+	if !authenticated {
+		http.Error(w, "Autenticación requerida para reaccionar a comentarios", http.StatusUnauthorized)
+		return
+	}
+
+	var req models.ReaccionComentarioRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		log.Printf("Error decodificando JSON: %v", err)
+		http.Error(w, "Error decodificando JSON", http.StatusBadRequest)
+		return
+	}
+	req.Tipo = strings.ToLower(strings.TrimSpace(req.Tipo))
+	if !containsString(models.TiposReaccionComentario, req.Tipo) {
+		http.Error(w, "Tipo de reacción inválido: use "+strings.Join(models.TiposReaccionComentario, ", "), http.StatusBadRequest)
+		return
+	}
+
+	var comentario models.Comentario
+	if err := h.DB.Where("ComentarioID = ? AND Activo = 1 AND Oculto = 0", comentarioID).First(&comentario).Error; err != nil {
+		http.Error(w, "Comentario no encontrado", http.StatusNotFound)
+		return
+	}
+
+	// Alternar la reacción: si ya existe se elimina, si no se crea
+	accion := "added"
+	var existing models.ComentarioReaccion
+	result := h.DB.Where("ComentarioID = ? AND UsuarioID = ? AND Tipo = ?", comentarioID, int(user.UserID), req.Tipo).
+		First(&existing)
+	switch {
+	case result.Error == nil:
+		if err := h.DB.Delete(&existing).Error; err != nil {
+			log.Printf("Error eliminando reacción: %v", err)
+			http.Error(w, "Error interno del servidor", http.StatusInternalServerError)
+			return
+		}
+		accion = "removed"
+	case result.Error == gorm.ErrRecordNotFound:
+		reaccion := models.ComentarioReaccion{ComentarioID: comentarioID, UsuarioID: int(user.UserID), Tipo: req.Tipo}
+		if err := h.DB.Create(&reaccion).Error; err != nil {
+			log.Printf("Error creando reacción: %v", err)
+			http.Error(w, "Error interno del servidor", http.StatusInternalServerError)
+			return
+		}
+	default:
+		log.Printf("Error consultando reacción existente: %v", result.Error)
+		http.Error(w, "Error interno del servidor", http.StatusInternalServerError)
+		return
+	}
+
+	counts, mine, err := loadCommentReactions(h.DB, []int{comentarioID}, user.UserID)
+	if err != nil {
+		log.Printf("Error consultando reacciones: %v", err)
+		http.Error(w, "Error interno del servidor", http.StatusInternalServerError)
+		return
+	}
+	misReacciones := mine[comentarioID]
+	if misReacciones == nil {
+		misReacciones = []string{}
+	}
+
+	// Los totales se envían sin mis_reacciones, que depende de quién recibe el evento
+	h.broadcastCommentUpdate(uint(comentario.PostID), map[string]interface{}{
+		"evento":        "comment_reaction",
+		"comentario_id": comentarioID,
+		"post_id":       comentario.PostID,
+		"usuario_id":    user.UserID,
+		"tipo":          req.Tipo,
+		"accion":        accion,
+		"reacciones":    counts[comentarioID],
+	})
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(models.ReaccionesComentarioResponse{
+		ComentarioID:  comentarioID,
+		Tipo:          req.Tipo,
+		Accion:        accion,
+		Reacciones:    counts[comentarioID],
+		MisReacciones: misReacciones,
+	})
+}
+
+// MarkCommentAsAnswer permite al autor del post marcar el comentario que resolvió su solicitud.
+// Solo hay una respuesta por post: marcar otro comentario reemplaza la anterior.
+func (h *commentsHandler) MarkCommentAsAnswer(w http.ResponseWriter, r *http.Request) {
+	h.setAcceptedAnswer(w, r, true)
+}
+
+// UnmarkCommentAsAnswer quita la marca de respuesta de un comentario
+func (h *commentsHandler) UnmarkCommentAsAnswer(w http.ResponseWriter, r *http.Request) {
+	h.setAcceptedAnswer(w, r, false)
+}
+
+// setAcceptedAnswer marca o desmarca un comentario como respuesta del post al que pertenece
+func (h *commentsHandler) setAcceptedAnswer(w http.ResponseWriter, r *http.Request, mark bool) {
+	comentarioID, err := extractIDFromPath(r.URL.Path, "comments")
+	if err != nil {
+		log.Printf("Error extrayendo comentarioID: %v", err)
+		http.Error(w, "ID de comentario inválido", http.StatusBadRequest)
+		return
+	}
+
+	user, authenticated := middleware.GetUserFromContext(r)
+	if !authenticated {
+		http.Error(w, "Autenticación requerida para marcar respuestas", http.StatusUnauthorized)
+		return
+	}
+
+	var comentario models.Comentario
+	if err := h.DB.Where("ComentarioID = ? AND Activo = 1 AND Oculto = 0", comentarioID).First(&comentario).Error; err != nil {
+		http.Error(w, "Comentario no encontrado", http.StatusNotFound)
+		return
+	}
+
+	var post models.Post
+	if err := h.DB.First(&post, comentario.PostID).Error; err != nil {
+		http.Error(w, "Post no encontrado", http.StatusNotFound)
+		return
+	}
+	if post.UsuarioID != user.UserID {
+		http.Error(w, "Solo el autor del post puede marcar la respuesta", http.StatusForbidden)
+		return
+	}
+
+	var respuestaID *int
+	if mark {
+		respuestaID = &comentarioID
+	} else if post.RespuestaComentarioID == nil || *post.RespuestaComentarioID != comentarioID {
+		http.Error(w, "El comentario no está marcado como respuesta", http.StatusNotFound)
+		return
+	}
+
+	// UpdateColumn evita modificar UpdatedAt: marcar la respuesta no es una edición del post
+	if err := h.DB.Model(&models.Post{}).Where("PostID = ?", post.ID).
+		UpdateColumn("RespuestaComentarioID", respuestaID).Error; err != nil {
+		log.Printf("Error guardando respuesta aceptada: %v", err)
+		http.Error(w, "Error interno del servidor", http.StatusInternalServerError)
+		return
+	}
+
+	alreadyMarked := post.RespuestaComentarioID != nil && *post.RespuestaComentarioID == comentarioID
+	if mark && !alreadyMarked {
+		h.notifyAnswerAccepted(comentario, post)
+	}
+
+	evento := "answer_marked"
+	if !mark {
+		evento = "answer_unmarked"
+	}
+	h.broadcastCommentUpdate(post.ID, map[string]interface{}{
+		"evento":                  evento,
+		"comentario_id":           comentarioID,
+		"post_id":                 post.ID,
+		"respuesta_comentario_id": respuestaID,
+	})
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(models.RespuestaAceptadaResponse{
+		PostID:       post.ID,
+		ComentarioID: respuestaID,
+	})
+}
+
+// notifyAnswerAccepted avisa al autor del comentario que fue marcado como respuesta
+func (h *commentsHandler) notifyAnswerAccepted(comentario models.Comentario, post models.Post) {
+	autorID := uint(comentario.UsuarioID)
+	if autorID == post.UsuarioID || !notificationEnabled(h.DB, autorID, "comment_marked_answer") {
+		return
+	}
+
+	err := createNotification(h.DB, h.WSHandler, h.SocketIOBroadcaster, models.Notification{
+		UsuarioID:    autorID,
+		Tipo:         "comment_marked_answer",
+		Titulo:       "Tu comentario fue marcado como respuesta",
+		Contenido:    loadUserDisplayName(h.DB, post.UsuarioID) + " marcó tu comentario como la respuesta a su publicación",
+		ReferenciaID: uint(comentario.ComentarioID),
+	})
+	logNotificationError("comment_marked_answer", autorID, err)
+}
+
+// broadcastCommentUpdate envía a la sala del post un cambio en sus comentarios
+func (h *commentsHandler) broadcastCommentUpdate(postID uint, data map[string]interface{}) {
+	if h.SocketIOBroadcaster != nil {
+		if err := h.SocketIOBroadcaster.BroadcastMessage(fmt.Sprintf("post_%d", postID), "comment_update", data); err != nil {
+			log.Printf("Error enviando actualización de comentario via Socket.IO: %v", err)
+		}
+	}
+	if h.WSHandler != nil {
+		h.WSHandler.BroadcastCommentUpdate(postID, data)
+	}
+}
+
+// clearAcceptedAnswer quita la marca de respuesta del post si apuntaba al comentario indicado
+func clearAcceptedAnswer(db *gorm.DB, postID, comentarioID int) error {
+	return db.Model(&models.Post{}).
+		Where("PostID = ? AND RespuestaComentarioID = ?", postID, comentarioID).
+		UpdateColumn("RespuestaComentarioID", nil).Error
+}
+
+// loadCommentReactions obtiene los totales por tipo de reacción de cada comentario y los tipos
+// con los que reaccionó viewerID (0 si la petición es anónima)
+func loadCommentReactions(db *gorm.DB, comentarioIDs []int, viewerID uint) (map[int]map[string]int, map[int][]string, error) {
+	counts := make(map[int]map[string]int, len(comentarioIDs))
+	mine := make(map[int][]string)
+	for _, id := range comentarioIDs {
+		counts[id] = emptyCommentReactionCounts()
+	}
+	if len(comentarioIDs) == 0 {
+		return counts, mine, nil
+	}
+
+	var rows []struct {
+		ComentarioID int    `gorm:"column:ComentarioID"`
+		Tipo         string `gorm:"column:Tipo"`
+		Total        int    `gorm:"column:Total"`
+		Mia          int    `gorm:"column:Mia"`
+	}
+	if err := db.Model(&models.ComentarioReaccion{}).
+		Select("ComentarioID, Tipo, COUNT(*) AS Total, MAX(CASE WHEN UsuarioID = ? THEN 1 ELSE 0 END) AS Mia", int(viewerID)).
+		Where("ComentarioID IN ?", comentarioIDs).
+		Group("ComentarioID, Tipo").
+		Scan(&rows).Error; err != nil {
+		return nil, nil, err
+	}
+
+	for _, row := range rows {
+		counts[row.ComentarioID][row.Tipo] = row.Total
+		if row.Mia == 1 {
+			mine[row.ComentarioID] = append(mine[row.ComentarioID], row.Tipo)
+		}
+	}
+	return counts, mine, nil
+}
+
+// emptyCommentReactionCounts devuelve todos los tipos de reacción con total 0, para que el
+// cliente reciba siempre las mismas claves
+func emptyCommentReactionCounts() map[string]int {
+	counts := make(map[string]int, len(models.TiposReaccionComentario))
+	for _, tipo := range models.TiposReaccionComentario {
+		counts[tipo] = 0
+	}
+	return counts
+}
+
+// attachCommentReactions completa Reacciones y MisReacciones de cada comentario
+func attachCommentReactions(db *gorm.DB, comentarios []models.ComentarioCompleto, viewerID uint) error {
+	ids := make([]int, 0, len(comentarios))
+	for _, comentario := range comentarios {
+		ids = append(ids, comentario.ComentarioID)
+	}
+	counts, mine, err := loadCommentReactions(db, ids, viewerID)
+	if err != nil {
+		return err
+	}
+	for i := range comentarios {
+		comentarios[i].Reacciones = counts[comentarios[i].ComentarioID]
+		comentarios[i].MisReacciones = mine[comentarios[i].ComentarioID]
+	}
+	return nil
+}
+
+// markAcceptedAnswers marca EsRespuesta en los comentarios elegidos como respuesta de su post
+func markAcceptedAnswers(db *gorm.DB, comentarios []models.ComentarioCompleto) error {
+	if len(comentarios) == 0 {
+		return nil
+	}
+	postIDs := make([]int, 0, 1)
+	for _, comentario := range comentarios {
+		if !containsInt(postIDs, comentario.PostID) {
+			postIDs = append(postIDs, comentario.PostID)
+		}
+	}
+
+	var respuestaIDs []int
+	if err := db.Model(&models.Post{}).
+		Where("PostID IN ? AND RespuestaComentarioID IS NOT NULL", postIDs).
+		Pluck("RespuestaComentarioID", &respuestaIDs).Error; err != nil {
+		return err
+	}
+	for i := range comentarios {
+		comentarios[i].EsRespuesta = containsInt(respuestaIDs, comentarios[i].ComentarioID)
+	}
+	return nil
+}
+
+// loadAcceptedAnswer obtiene el comentario marcado como respuesta de un post, o nil si no hay
+// ninguno visible para quien consulta (eliminado, oculto o de un usuario bloqueado)
+func loadAcceptedAnswer(db *gorm.DB, postID int, blockedIDs []uint, viewerID uint) (*models.ComentarioCompleto, error) {
+	var post models.Post
+	if err := db.Select("PostID", "RespuestaComentarioID").First(&post, postID).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		return nil, err
+	}
+	if post.RespuestaComentarioID == nil {
+		return nil, nil
+	}
+
+	var comentarios []models.ComentarioCompleto
+	query := db.Table("vw_ComentariosCompletos").
+		Where("ComentarioID = ? AND PostID = ?", *post.RespuestaComentarioID, postID)
+	if len(blockedIDs) > 0 {
+		query = query.Where("UsuarioID NOT IN ?", blockedIDs)
+	}
+	if err := query.Limit(1).Find(&comentarios).Error; err != nil {
+		return nil, err
+	}
+	if len(comentarios) == 0 {
+		return nil, nil
+	}
+	if err := decorateComments(db, comentarios, viewerID); err != nil {
+		return nil, err
+	}
+	return &comentarios[0], nil
+}
+
+// containsInt indica si value está en values
+func containsInt(values []int, value int) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
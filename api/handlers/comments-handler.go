@@ -1,16 +1,22 @@
 package handlers
 
 import (
+	"encoding/base64"
 	"encoding/json"
 	"fmt"
 	"log"
+	"math"
 	"net/http"
+	"sort"
 	"strconv"
 	"strings"
 	"time"
 
+	"skillswap/api/contentfilter"
+	"skillswap/api/markdown"
 	"skillswap/api/middleware"
 	"skillswap/api/models"
+	"skillswap/api/search"
 
 	"gorm.io/gorm"
 )
@@ -19,6 +25,8 @@ type commentsHandler struct {
 	DB                  *gorm.DB
 	WSHandler           *WebSocketHandler
 	SocketIOBroadcaster *SocketIOBroadcaster
+	Searcher            search.Searcher
+	ContentFilter       *contentfilter.Pipeline
 }
 
 func NewCommentHandler(db *gorm.DB) *commentsHandler {
@@ -33,6 +41,16 @@ func (h *commentsHandler) SetWebSocketHandler(wsHandler *WebSocketHandler) {
 	h.WSHandler = wsHandler
 }
 
+// SetSearcher configura el índice de búsqueda que se actualiza con cada cambio
+func (h *commentsHandler) SetSearcher(searcher search.Searcher) {
+	h.Searcher = searcher
+}
+
+// SetContentFilter configura el filtro automático que revisa el contenido antes de publicarlo
+func (h *commentsHandler) SetContentFilter(filter *contentfilter.Pipeline) {
+	h.ContentFilter = filter
+}
+
 // GetPostComments obtiene los comentarios de un post
 func (h *commentsHandler) GetPostComments(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodGet {
@@ -66,10 +84,22 @@ func (h *commentsHandler) GetPostComments(w http.ResponseWriter, r *http.Request
 
 	offset := (page - 1) * pageSize
 
+	// Ocultar los comentarios de usuarios bloqueados por el usuario autenticado
+	blockedIDs, err := viewerBlockedUserIDs(h.DB, r)
+	if err != nil {
+		log.Printf("Error obteniendo bloqueos: %v", err)
+		http.Error(w, "Error interno del servidor", http.StatusInternalServerError)
+		return
+	}
+
 	// Consultar comentarios principales (sin padre)
 	var comentarios []models.ComentarioCompleto
-	result := h.DB.Table("vw_ComentariosCompletos").
-		Where("PostID = ? AND ComentarioPadreID IS NULL", postID).
+	query := h.DB.Table("vw_ComentariosCompletos").
+		Where("PostID = ? AND ComentarioPadreID IS NULL", postID)
+	if len(blockedIDs) > 0 {
+		query = query.Where("UsuarioID NOT IN ?", blockedIDs)
+	}
+	result := query.
 		Order("CreatedAt ASC").
 		Offset(offset).
 		Limit(pageSize).
@@ -81,11 +111,21 @@ func (h *commentsHandler) GetPostComments(w http.ResponseWriter, r *http.Request
 		return
 	}
 
+	viewerID := commentViewerID(r)
+	if err := decorateComments(h.DB, comentarios, viewerID); err != nil {
+		log.Printf("Error completando comentarios: %v", err)
+		http.Error(w, "Error interno del servidor", http.StatusInternalServerError)
+		return
+	}
+
 	// Contar total de comentarios
 	var total int64
-	h.DB.Model(&models.Comentario{}).
-		Where("PostID = ? AND ComentarioPadreID IS NULL AND Activo = 1", postID).
-		Count(&total)
+	countQuery := h.DB.Model(&models.Comentario{}).
+		Where("PostID = ? AND ComentarioPadreID IS NULL AND Activo = 1 AND Oculto = 0", postID)
+	if len(blockedIDs) > 0 {
+		countQuery = countQuery.Where("UsuarioID NOT IN ?", blockedIDs)
+	}
+	countQuery.Count(&total)
 
 	response := map[string]interface{}{
 		"comentarios": comentarios,
@@ -97,10 +137,341 @@ func (h *commentsHandler) GetPostComments(w http.ResponseWriter, r *http.Request
 		},
 	}
 
+	// La respuesta aceptada se fija arriba del hilo en la primera página
+	if page == 1 {
+		respuesta, err := loadAcceptedAnswer(h.DB, postID, blockedIDs, viewerID)
+		if err != nil {
+			log.Printf("Error consultando respuesta aceptada: %v", err)
+			http.Error(w, "Error interno del servidor", http.StatusInternalServerError)
+			return
+		}
+		if respuesta != nil {
+			response["respuesta_aceptada"] = respuesta
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
+
+// Límites del árbol de comentarios
+const (
+	commentTreeDefaultDepth   = 3
+	commentTreeMaxDepth       = 10
+	commentTreeDefaultLimit   = 20
+	commentTreeDefaultReplies = 5
+	commentTreeMaxLimit       = 100
+)
+
+// GetPostCommentTree obtiene los comentarios de un post como árbol anidado.
+// Parámetros: sort (top, new, old, controversial), depth, limit (comentarios del primer nivel),
+// replies_limit (respuestas por comentario) y cursor (para continuar una rama truncada).
+func (h *commentsHandler) GetPostCommentTree(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Método no permitido", http.StatusMethodNotAllowed)
+		return
+	}
+
+	// Extraer postId del path
+	postID, err := extractIDFromPath(r.URL.Path, "posts")
+	if err != nil {
+		log.Printf("Error extrayendo postID: %v", err)
+		http.Error(w, "ID de post inválido", http.StatusBadRequest)
+		return
+	}
+
+	sortMode := r.URL.Query().Get("sort")
+	if sortMode == "" {
+		sortMode = "top"
+	}
+	if sortMode != "top" && sortMode != "new" && sortMode != "old" && sortMode != "controversial" {
+		http.Error(w, "Orden inválido: use top, new, old o controversial", http.StatusBadRequest)
+		return
+	}
+
+	depth := queryIntInRange(r, "depth", commentTreeDefaultDepth, 1, commentTreeMaxDepth)
+	limit := queryIntInRange(r, "limit", commentTreeDefaultLimit, 1, commentTreeMaxLimit)
+	repliesLimit := queryIntInRange(r, "replies_limit", commentTreeDefaultReplies, 1, commentTreeMaxLimit)
+
+	// El cursor indica el comentario padre (0 = primer nivel) y el desplazamiento dentro de sus respuestas
+	parentID, offset := 0, 0
+	if cursor := r.URL.Query().Get("cursor"); cursor != "" {
+		if parentID, offset, err = decodeCommentCursor(cursor); err != nil {
+			http.Error(w, "Cursor inválido", http.StatusBadRequest)
+			return
+		}
+	}
+
+	// Ocultar los comentarios (y sus respuestas) de usuarios bloqueados por el usuario autenticado
+	blockedIDs, err := viewerBlockedUserIDs(h.DB, r)
+	if err != nil {
+		log.Printf("Error obteniendo bloqueos: %v", err)
+		http.Error(w, "Error interno del servidor", http.StatusInternalServerError)
+		return
+	}
+
+	comentarios, err := h.loadCommentTreeRows(postID)
+	if err != nil {
+		log.Printf("Error consultando árbol de comentarios: %v", err)
+		http.Error(w, "Error interno del servidor", http.StatusInternalServerError)
+		return
+	}
+	viewerID := commentViewerID(r)
+	if err := decorateComments(h.DB, comentarios, viewerID); err != nil {
+		log.Printf("Error completando comentarios: %v", err)
+		http.Error(w, "Error interno del servidor", http.StatusInternalServerError)
+		return
+	}
+
+	blocked := make(map[int]bool, len(blockedIDs))
+	for _, id := range blockedIDs {
+		blocked[int(id)] = true
+	}
+
+	// Agrupar las respuestas por comentario padre
+	children := make(map[int][]models.ComentarioCompleto)
+	exists := make(map[int]bool)
+	for _, comentario := range comentarios {
+		if blocked[comentario.UsuarioID] {
+			continue
+		}
+		padre := 0
+		if comentario.ComentarioPadreID != nil {
+			padre = *comentario.ComentarioPadreID
+		}
+		children[padre] = append(children[padre], comentario)
+		exists[comentario.ComentarioID] = true
+	}
+	if parentID != 0 && !exists[parentID] {
+		http.Error(w, "Cursor inválido", http.StatusBadRequest)
+		return
+	}
+	for padre := range children {
+		sortComments(children[padre], sortMode)
+	}
+
+	tree := commentTreeBuilder{children: children, repliesLimit: repliesLimit}
+	levelLimit := limit
+	if parentID != 0 {
+		levelLimit = repliesLimit
+	}
+	nodes, more := tree.build(parentID, offset, levelLimit, depth)
+
+	response := models.ArbolComentariosResponse{
+		PostID:           postID,
+		Orden:            sortMode,
+		Profundidad:      depth,
+		Comentarios:      nodes,
+		TotalComentarios: tree.countVisible(0),
+		MasComentarios:   more,
+	}
+
+	// La respuesta aceptada se fija arriba del hilo solo en la carga inicial
+	if parentID == 0 && offset == 0 {
+		if response.RespuestaAceptada, err = loadAcceptedAnswer(h.DB, postID, blockedIDs, viewerID); err != nil {
+			log.Printf("Error consultando respuesta aceptada: %v", err)
+			http.Error(w, "Error interno del servidor", http.StatusInternalServerError)
+			return
+		}
+	}
+
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(response)
 }
 
+// loadCommentTreeRows obtiene en una sola consulta los comentarios activos de un post
+// con su autor y sus totales de votos
+func (h *commentsHandler) loadCommentTreeRows(postID int) ([]models.ComentarioCompleto, error) {
+	var comentarios []models.ComentarioCompleto
+	err := h.DB.Raw(`
+		SELECT c.ComentarioID, c.PostID, c.UsuarioID, c.ComentarioPadreID, c.Contenido,
+			ISNULL(c.ContenidoHTML, '') AS ContenidoHTML, c.CreatedAt, c.UpdatedAt, c.Activo,
+			c.EditadoEn, c.TotalEdiciones,
+			u.NombreUsuario, u.PrimerNombre, u.PrimerApellido AS Apellido,
+			COALESCE(v.TotalLikes, 0) AS TotalLikes, COALESCE(v.TotalDislikes, 0) AS TotalDislikes
+		FROM Comentarios c
+		INNER JOIN Usuarios u ON u.UsuarioID = c.UsuarioID
+		LEFT JOIN (
+			SELECT ComentarioID,
+				SUM(CASE WHEN TipoVoto = 'like' THEN 1 ELSE 0 END) AS TotalLikes,
+				SUM(CASE WHEN TipoVoto = 'dislike' THEN 1 ELSE 0 END) AS TotalDislikes
+			FROM ComentarioLikes
+			GROUP BY ComentarioID
+		) v ON v.ComentarioID = c.ComentarioID
+		WHERE c.PostID = ? AND c.Activo = 1 AND c.Oculto = 0`, postID).
+		Scan(&comentarios).Error
+	return comentarios, err
+}
+
+// commentViewerID devuelve el usuario autenticado (0 si la petición es anónima)
+func commentViewerID(r *http.Request) uint {
+	if user, authenticated := middleware.GetUserFromContext(r); authenticated {
+		return user.UserID
+	}
+	return 0
+}
+
+// decorateComments completa los datos que no forman parte de la vista: menciones, reacciones,
+// la marca de respuesta aceptada y el HTML de los comentarios anteriores a ContenidoHTML
+func decorateComments(db *gorm.DB, comentarios []models.ComentarioCompleto, viewerID uint) error {
+	if err := attachCommentMentions(db, comentarios); err != nil {
+		return err
+	}
+	if err := attachCommentReactions(db, comentarios, viewerID); err != nil {
+		return err
+	}
+	if err := markAcceptedAnswers(db, comentarios); err != nil {
+		return err
+	}
+	renderMissingCommentHTML(comentarios)
+	return nil
+}
+
+// renderMissingCommentHTML renderiza al vuelo los comentarios guardados antes de existir ContenidoHTML
+func renderMissingCommentHTML(comentarios []models.ComentarioCompleto) {
+	for i := range comentarios {
+		if comentarios[i].ContenidoHTML == "" {
+			comentarios[i].ContenidoHTML = markdown.Render(comentarios[i].Contenido)
+		}
+	}
+}
+
+// commentTreeBuilder arma el árbol a partir de las respuestas agrupadas y ordenadas por padre
+type commentTreeBuilder struct {
+	children     map[int][]models.ComentarioCompleto
+	repliesLimit int
+}
+
+// build devuelve una página de las respuestas de parentID con sus subárboles hasta la profundidad indicada
+func (t commentTreeBuilder) build(parentID, offset, limit, depth int) ([]models.ComentarioNodo, *models.CargarMas) {
+	siblings := t.children[parentID]
+	if offset > len(siblings) {
+		offset = len(siblings)
+	}
+	end := offset + limit
+	if end > len(siblings) {
+		end = len(siblings)
+	}
+
+	nodes := make([]models.ComentarioNodo, 0, end-offset)
+	for _, comentario := range siblings[offset:end] {
+		replies := t.children[comentario.ComentarioID]
+		comentario.TotalRespuestas = len(replies)
+
+		node := models.ComentarioNodo{
+			ComentarioCompleto: comentario,
+			Puntuacion:         comentario.TotalLikes - comentario.TotalDislikes,
+			Respuestas:         []models.ComentarioNodo{},
+		}
+		if depth > 1 {
+			node.Respuestas, node.MasRespuestas = t.build(comentario.ComentarioID, 0, t.repliesLimit, depth-1)
+		} else if len(replies) > 0 {
+			// Profundidad máxima alcanzada: la rama se continúa con el cursor
+			node.MasRespuestas = &models.CargarMas{
+				Restantes: len(replies),
+				Cursor:    encodeCommentCursor(comentario.ComentarioID, 0),
+			}
+		}
+		nodes = append(nodes, node)
+	}
+
+	var more *models.CargarMas
+	if end < len(siblings) {
+		more = &models.CargarMas{
+			Restantes: len(siblings) - end,
+			Cursor:    encodeCommentCursor(parentID, end),
+		}
+	}
+	return nodes, more
+}
+
+// countVisible cuenta los comentarios alcanzables desde parentID
+func (t commentTreeBuilder) countVisible(parentID int) int {
+	total := 0
+	for _, comentario := range t.children[parentID] {
+		total += 1 + t.countVisible(comentario.ComentarioID)
+	}
+	return total
+}
+
+// sortComments ordena comentarios hermanos según el modo indicado
+func sortComments(comentarios []models.ComentarioCompleto, mode string) {
+	sort.SliceStable(comentarios, func(i, j int) bool {
+		a, b := comentarios[i], comentarios[j]
+		switch mode {
+		case "new":
+			if !a.CreatedAt.Equal(b.CreatedAt) {
+				return a.CreatedAt.After(b.CreatedAt)
+			}
+		case "old":
+			if !a.CreatedAt.Equal(b.CreatedAt) {
+				return a.CreatedAt.Before(b.CreatedAt)
+			}
+		case "controversial":
+			ca, cb := controversyScore(a.TotalLikes, a.TotalDislikes), controversyScore(b.TotalLikes, b.TotalDislikes)
+			if ca != cb {
+				return ca > cb
+			}
+			if !a.CreatedAt.Equal(b.CreatedAt) {
+				return a.CreatedAt.After(b.CreatedAt)
+			}
+		default: // top
+			sa, sb := a.TotalLikes-a.TotalDislikes, b.TotalLikes-b.TotalDislikes
+			if sa != sb {
+				return sa > sb
+			}
+			if !a.CreatedAt.Equal(b.CreatedAt) {
+				return a.CreatedAt.Before(b.CreatedAt)
+			}
+		}
+		// Desempate estable para que los cursores sean consistentes
+		return a.ComentarioID < b.ComentarioID
+	})
+}
+
+// controversyScore favorece comentarios con muchos votos repartidos entre likes y dislikes
+func controversyScore(likes, dislikes int) float64 {
+	if likes <= 0 || dislikes <= 0 {
+		return 0
+	}
+	magnitude := float64(likes + dislikes)
+	balance := float64(dislikes) / float64(likes)
+	if likes < dislikes {
+		balance = float64(likes) / float64(dislikes)
+	}
+	return math.Pow(magnitude, balance)
+}
+
+// encodeCommentCursor codifica el comentario padre y el desplazamiento de una rama truncada
+func encodeCommentCursor(parentID, offset int) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(fmt.Sprintf("%d:%d", parentID, offset)))
+}
+
+// decodeCommentCursor decodifica un cursor generado por encodeCommentCursor
+func decodeCommentCursor(cursor string) (int, int, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return 0, 0, err
+	}
+	var parentID, offset int
+	if _, err := fmt.Sscanf(string(raw), "%d:%d", &parentID, &offset); err != nil {
+		return 0, 0, err
+	}
+	if parentID < 0 || offset < 0 {
+		return 0, 0, fmt.Errorf("cursor fuera de rango")
+	}
+	return parentID, offset, nil
+}
+
+// queryIntInRange lee un parámetro entero de la query, usando el valor por defecto si es inválido
+func queryIntInRange(r *http.Request, name string, defaultValue, min, max int) int {
+	value, err := strconv.Atoi(r.URL.Query().Get(name))
+	if err != nil || value < min || value > max {
+		return defaultValue
+	}
+	return value
+}
+
 // CreateComment crea un nuevo comentario
 func (h *commentsHandler) CreateComment(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodPost {
@@ -126,6 +497,10 @@ func (h *commentsHandler) CreateComment(w http.ResponseWriter, r *http.Request)
 		http.Error(w, "Contenido es requerido", http.StatusBadRequest)
 		return
 	}
+	if err := markdown.ValidateLength(req.Contenido, markdown.MaxCommentLength); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
 
 	// Obtener userID del contexto de autenticación
 	user, authenticated := middleware.GetUserFromContext(r)
@@ -134,27 +509,72 @@ func (h *commentsHandler) CreateComment(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
+	decision, ok := checkContent(w, h.ContentFilter, contentfilter.Content{
+		Type:     contentfilter.TypeComment,
+		AuthorID: user.UserID,
+		Text:     req.Contenido,
+	})
+	if !ok {
+		return
+	}
+
 	comentario := models.Comentario{
 		PostID:            postID,
 		UsuarioID:         int(user.UserID),
 		ComentarioPadreID: req.ComentarioPadreID,
 		Contenido:         req.Contenido,
+		ContenidoHTML:     markdown.Render(req.Contenido),
 		CreatedAt:         time.Now(),
 		UpdatedAt:         time.Now(),
 		Activo:            true,
 	}
 
-	result := h.DB.Create(&comentario)
-	if result.Error != nil {
-		log.Printf("Error creando comentario: %v", result.Error)
+	// El comentario marcado por el filtro se guarda oculto y se reporta en la misma transacción que lo
+	// crea: no se publica ni genera notificaciones hasta que se revise
+	held := decision.Verdict == contentfilter.Flag
+	comentario.Oculto = held
+	err = h.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&comentario).Error; err != nil {
+			return err
+		}
+		if held {
+			return holdForModeration(tx, contentfilter.TypeComment, uint(comentario.ComentarioID), user.UserID, decision)
+		}
+		return nil
+	})
+	if err != nil {
+		log.Printf("Error creando comentario: %v", err)
 		http.Error(w, "Error interno del servidor", http.StatusInternalServerError)
 		return
 	}
+	if held {
+		writeHeldForReview(w, contentfilter.TypeComment, uint(comentario.ComentarioID), decision)
+		return
+	}
+	indexDocument(h.Searcher, search.CommentDocument(comentario))
+
+	// Resolver las menciones y notificar a los usuarios mencionados
+	menciones, mencionados, err := saveMentions(h.DB, mentionContentComment, uint(comentario.ComentarioID), user.UserID, comentario.Contenido, nil)
+	if err != nil {
+		log.Printf("Error guardando menciones: %v", err)
+	}
+	notifyMentions(h.DB, h.WSHandler, h.SocketIOBroadcaster, mencionados, user.UserID, mentionContentComment, uint(comentario.ComentarioID), comentario.Contenido)
+
+	// Quien comenta empieza a seguir el hilo y se notifica al autor del post, al autor del
+	// comentario padre y a los seguidores del hilo (en segundo plano)
+	if err := followPostThread(h.DB, user.UserID, uint(postID), false); err != nil {
+		log.Printf("Error siguiendo el hilo del post %d: %v", postID, err)
+	}
+	go h.notifyCommentActivity(comentario, mencionados)
+
 	// Obtener el comentario completo
 	var comentarioCompleto models.ComentarioCompleto
 	h.DB.Table("vw_ComentariosCompletos").
 		Where("ComentarioID = ?", comentario.ComentarioID).
 		First(&comentarioCompleto)
+	comentarioCompleto.ContenidoHTML = comentario.ContenidoHTML
+	comentarioCompleto.Menciones = menciones
+	comentarioCompleto.Reacciones = emptyCommentReactionCounts()
 	// Enviar notificación Socket.IO si el broadcaster está configurado
 	if h.SocketIOBroadcaster != nil {
 		h.SocketIOBroadcaster.BroadcastNewComment(uint(postID), map[string]interface{}{
@@ -165,13 +585,19 @@ func (h *commentsHandler) CreateComment(w http.ResponseWriter, r *http.Request)
 			"primer_nombre":       comentarioCompleto.PrimerNombre,
 			"apellido":            comentarioCompleto.Apellido,
 			"contenido":           comentarioCompleto.Contenido,
+			"contenido_html":      comentarioCompleto.ContenidoHTML,
 			"comentario_padre_id": comentarioCompleto.ComentarioPadreID,
 			"total_likes":         comentarioCompleto.TotalLikes,
 			"total_dislikes":      comentarioCompleto.TotalDislikes,
 			"total_respuestas":    comentarioCompleto.TotalRespuestas,
 			"created_at":          comentarioCompleto.CreatedAt,
 			"updated_at":          comentarioCompleto.UpdatedAt,
+			"edited_at":           comentarioCompleto.EditadoEn,
+			"edit_count":          comentarioCompleto.TotalEdiciones,
 			"activo":              comentarioCompleto.Activo,
+			"menciones":           comentarioCompleto.Menciones,
+			"reacciones":          comentarioCompleto.Reacciones,
+			"es_respuesta":        false,
 		})
 	}
 
@@ -218,10 +644,22 @@ func (h *commentsHandler) GetCommentReplies(w http.ResponseWriter, r *http.Reque
 
 	offset := (page - 1) * pageSize
 
+	// Ocultar las respuestas de usuarios bloqueados por el usuario autenticado
+	blockedIDs, err := viewerBlockedUserIDs(h.DB, r)
+	if err != nil {
+		log.Printf("Error obteniendo bloqueos: %v", err)
+		http.Error(w, "Error interno del servidor", http.StatusInternalServerError)
+		return
+	}
+
 	// Consultar respuestas
 	var respuestas []models.ComentarioCompleto
-	result := h.DB.Table("vw_ComentariosCompletos").
-		Where("ComentarioPadreID = ?", comentarioID).
+	query := h.DB.Table("vw_ComentariosCompletos").
+		Where("ComentarioPadreID = ?", comentarioID)
+	if len(blockedIDs) > 0 {
+		query = query.Where("UsuarioID NOT IN ?", blockedIDs)
+	}
+	result := query.
 		Order("CreatedAt ASC").
 		Offset(offset).
 		Limit(pageSize).
@@ -233,11 +671,20 @@ func (h *commentsHandler) GetCommentReplies(w http.ResponseWriter, r *http.Reque
 		return
 	}
 
+	if err := decorateComments(h.DB, respuestas, commentViewerID(r)); err != nil {
+		log.Printf("Error completando respuestas: %v", err)
+		http.Error(w, "Error interno del servidor", http.StatusInternalServerError)
+		return
+	}
+
 	// Contar total de respuestas
 	var total int64
-	h.DB.Model(&models.Comentario{}).
-		Where("ComentarioPadreID = ? AND Activo = 1", comentarioID).
-		Count(&total)
+	countQuery := h.DB.Model(&models.Comentario{}).
+		Where("ComentarioPadreID = ? AND Activo = 1 AND Oculto = 0", comentarioID)
+	if len(blockedIDs) > 0 {
+		countQuery = countQuery.Where("UsuarioID NOT IN ?", blockedIDs)
+	}
+	countQuery.Count(&total)
 
 	response := map[string]interface{}{
 		"respuestas": respuestas,
@@ -279,6 +726,10 @@ func (h *commentsHandler) UpdateComment(w http.ResponseWriter, r *http.Request)
 		http.Error(w, "Contenido es requerido", http.StatusBadRequest)
 		return
 	}
+	if err := markdown.ValidateLength(req.Contenido, markdown.MaxCommentLength); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
 
 	// Obtener userID del contexto de autenticación
 	user, authenticated := middleware.GetUserFromContext(r)
@@ -301,32 +752,100 @@ func (h *commentsHandler) UpdateComment(w http.ResponseWriter, r *http.Request)
 		http.Error(w, "No tienes permiso para actualizar este comentario", http.StatusForbidden)
 		return
 	}
+
+	decision, ok := checkContent(w, h.ContentFilter, contentfilter.Content{
+		Type:     contentfilter.TypeComment,
+		ID:       uint(comentarioID),
+		AuthorID: user.UserID,
+		Text:     req.Contenido,
+	})
+	if !ok {
+		return
+	}
 	// TODO: Extraer userID del token JWT
 
-	// Actualizar comentario
-	result = h.DB.Model(&models.Comentario{}).
-		Where("ComentarioID = ? AND Activo = 1", comentarioID).
-		Updates(map[string]interface{}{
-			"Contenido":  req.Contenido,
-			"UpdatedAt": time.Now(),
-		})
+	// Actualizar comentario (el HTML se vuelve a renderizar con el nuevo contenido) y guardar
+	// la versión anterior en el historial si el texto cambió
+	contenidoHTML := markdown.Render(req.Contenido)
+	held := decision.Verdict == contentfilter.Flag
+	var rowsAffected int64
+	err = h.DB.Transaction(func(tx *gorm.DB) error {
+		now := time.Now()
+		updates := map[string]interface{}{
+			"Contenido":     req.Contenido,
+			"ContenidoHTML": contenidoHTML,
+			"UpdatedAt":     now,
+		}
+		// El contenido marcado por el filtro se guarda ya oculto hasta que lo revise un moderador
+		if held {
+			updates["Oculto"] = true
+		}
+		if req.Contenido != comentario.Contenido {
+			if err := tx.Create(&models.RevisionComentario{
+				ComentarioID:      comentarioID,
+				ContenidoAnterior: comentario.Contenido,
+				EditorID:          &user.UserID,
+				FechaEdicion:      now,
+			}).Error; err != nil {
+				return err
+			}
+			updates["EditadoEn"] = now
+			updates["TotalEdiciones"] = gorm.Expr("TotalEdiciones + 1")
+		}
 
-	if result.Error != nil {
-		log.Printf("Error actualizando comentario: %v", result.Error)
+		result := tx.Model(&models.Comentario{}).
+			Where("ComentarioID = ? AND Activo = 1", comentarioID).
+			Updates(updates)
+		rowsAffected = result.RowsAffected
+		if result.Error != nil || rowsAffected == 0 || !held {
+			return result.Error
+		}
+		return holdForModeration(tx, contentfilter.TypeComment, uint(comentarioID), user.UserID, decision)
+	})
+
+	if err != nil {
+		log.Printf("Error actualizando comentario: %v", err)
 		http.Error(w, "Error interno del servidor", http.StatusInternalServerError)
 		return
 	}
 
-	if result.RowsAffected == 0 {
+	if rowsAffected == 0 {
 		http.Error(w, "Comentario no encontrado", http.StatusNotFound)
 		return
 	}
 
+	if held {
+		unindexDocument(h.Searcher, search.TypeComment, uint(comentarioID))
+		writeHeldForReview(w, contentfilter.TypeComment, uint(comentarioID), decision)
+		return
+	}
+
+	comentario.Contenido = req.Contenido
+	comentario.ContenidoHTML = contenidoHTML
+	// Un comentario que sigue oculto por moderación no vuelve al índice al editarse
+	if !comentario.Oculto {
+		indexDocument(h.Searcher, search.CommentDocument(comentario))
+	}
+
+	// Solo se notifica a los usuarios que no estaban mencionados antes de la edición
+	menciones, mencionados, err := saveMentions(h.DB, mentionContentComment, uint(comentarioID), user.UserID, req.Contenido, nil)
+	if err != nil {
+		log.Printf("Error guardando menciones: %v", err)
+	}
+	notifyMentions(h.DB, h.WSHandler, h.SocketIOBroadcaster, mencionados, user.UserID, mentionContentComment, uint(comentarioID), req.Contenido)
+
 	// Obtener el comentario actualizado
 	var comentarioCompleto models.ComentarioCompleto
 	h.DB.Table("vw_ComentariosCompletos").
 		Where("ComentarioID = ?", comentarioID).
 		First(&comentarioCompleto)
+	decorated := []models.ComentarioCompleto{comentarioCompleto}
+	if err := decorateComments(h.DB, decorated, user.UserID); err != nil {
+		log.Printf("Error completando comentario: %v", err)
+	}
+	comentarioCompleto = decorated[0]
+	comentarioCompleto.ContenidoHTML = contenidoHTML
+	comentarioCompleto.Menciones = menciones
 
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(comentarioCompleto)
@@ -383,6 +902,10 @@ func (h *commentsHandler) DeleteComment(w http.ResponseWriter, r *http.Request)
 		http.Error(w, "Comentario no encontrado", http.StatusNotFound)
 		return
 	}
+	unindexDocument(h.Searcher, search.TypeComment, uint(comentarioID))
+	if err := clearAcceptedAnswer(h.DB, comentario.PostID, comentarioID); err != nil {
+		log.Printf("Error quitando respuesta aceptada: %v", err)
+	}
 
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(map[string]string{
@@ -390,6 +913,249 @@ func (h *commentsHandler) DeleteComment(w http.ResponseWriter, r *http.Request)
 	})
 }
 
+// FollowPostThread hace que el usuario autenticado siga el hilo de comentarios de un post
+func (h *commentsHandler) FollowPostThread(w http.ResponseWriter, r *http.Request) {
+	h.setPostThreadFollow(w, r, true)
+}
+
+// UnfollowPostThread deja de seguir el hilo de comentarios de un post
+func (h *commentsHandler) UnfollowPostThread(w http.ResponseWriter, r *http.Request) {
+	h.setPostThreadFollow(w, r, false)
+}
+
+// GetPostThreadFollow indica si el usuario autenticado sigue el hilo de un post
+func (h *commentsHandler) GetPostThreadFollow(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Método no permitido", http.StatusMethodNotAllowed)
+		return
+	}
+
+	postID, err := extractIDFromPath(r.URL.Path, "posts")
+	if err != nil {
+		http.Error(w, "ID de post inválido", http.StatusBadRequest)
+		return
+	}
+
+	user, authenticated := middleware.GetUserFromContext(r)
+	if !authenticated {
+		http.Error(w, "Autenticación requerida", http.StatusUnauthorized)
+		return
+	}
+
+	var post models.Post
+	if result := h.DB.First(&post, postID); result.Error != nil {
+		http.Error(w, "Post no encontrado", http.StatusNotFound)
+		return
+	}
+
+	following, err := isFollowingPostThread(h.DB, user.UserID, post)
+	if err != nil {
+		http.Error(w, "Error consultando seguimiento: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"post_id":   postID,
+		"siguiendo": following,
+	})
+}
+
+// setPostThreadFollow guarda explícitamente si el usuario sigue o no el hilo de un post
+func (h *commentsHandler) setPostThreadFollow(w http.ResponseWriter, r *http.Request, follow bool) {
+	if (follow && r.Method != http.MethodPost) || (!follow && r.Method != http.MethodDelete) {
+		http.Error(w, "Método no permitido", http.StatusMethodNotAllowed)
+		return
+	}
+
+	postID, err := extractIDFromPath(r.URL.Path, "posts")
+	if err != nil {
+		http.Error(w, "ID de post inválido", http.StatusBadRequest)
+		return
+	}
+
+	user, authenticated := middleware.GetUserFromContext(r)
+	if !authenticated {
+		http.Error(w, "Autenticación requerida para seguir hilos", http.StatusUnauthorized)
+		return
+	}
+
+	var post models.Post
+	if result := h.DB.First(&post, postID); result.Error != nil {
+		http.Error(w, "Post no encontrado", http.StatusNotFound)
+		return
+	}
+
+	if err := setPostThreadFollowState(h.DB, user.UserID, post.ID, follow); err != nil {
+		log.Printf("Error actualizando seguimiento: %v", err)
+		http.Error(w, "Error interno del servidor", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"post_id":   postID,
+		"siguiendo": follow,
+	})
+}
+
+// notifyCommentActivity notifica un comentario nuevo al autor del comentario padre, al autor del post
+// y a los seguidores del hilo. Cada usuario recibe una sola notificación y se omiten los ya
+// notificados por mención y los que tengan un bloqueo con el autor.
+func (h *commentsHandler) notifyCommentActivity(comentario models.Comentario, mencionados []uint) {
+	actorID := uint(comentario.UsuarioID)
+	postID := uint(comentario.PostID)
+
+	var post models.Post
+	if err := h.DB.First(&post, postID).Error; err != nil {
+		log.Printf("Error cargando post %d para notificaciones: %v", postID, err)
+		return
+	}
+
+	blockedIDs, err := blockRelatedUserIDs(h.DB, actorID)
+	if err != nil {
+		log.Printf("Error obteniendo bloqueos para notificaciones: %v", err)
+		return
+	}
+
+	notified := append([]uint{actorID}, mencionados...)
+	notified = append(notified, blockedIDs...)
+
+	// Respuesta a un comentario
+	if comentario.ComentarioPadreID != nil {
+		var padre models.Comentario
+		if err := h.DB.First(&padre, *comentario.ComentarioPadreID).Error; err == nil {
+			padreAutorID := uint(padre.UsuarioID)
+			if !containsUserID(notified, padreAutorID) {
+				err := createCoalescedNotification(h.DB, h.WSHandler, h.SocketIOBroadcaster, padreAutorID,
+					"reply_to_your_comment", uint(padre.ComentarioID), actorID,
+					func(actor string, total int) (string, string) {
+						if total == 1 {
+							return "Nueva respuesta a tu comentario", actor + " respondió a tu comentario: " + comentario.Contenido
+						}
+						return "Nuevas respuestas a tu comentario", fmt.Sprintf("%d personas respondieron a tu comentario", total)
+					})
+				logNotificationError("reply_to_your_comment", padreAutorID, err)
+				notified = append(notified, padreAutorID)
+			}
+		}
+	}
+
+	// Comentario en un post propio (salvo que el autor haya dejado de seguir el hilo)
+	if !containsUserID(notified, post.UsuarioID) {
+		following, err := isFollowingPostThread(h.DB, post.UsuarioID, post)
+		if err == nil && following {
+			err := createCoalescedNotification(h.DB, h.WSHandler, h.SocketIOBroadcaster, post.UsuarioID,
+				"comment_on_your_post", post.ID, actorID,
+				func(actor string, total int) (string, string) {
+					if total == 1 {
+						return "Nuevo comentario en tu publicación", actor + " comentó tu publicación: " + comentario.Contenido
+					}
+					return "Nuevos comentarios en tu publicación", fmt.Sprintf("%d personas comentaron tu publicación", total)
+				})
+			logNotificationError("comment_on_your_post", post.UsuarioID, err)
+		}
+		notified = append(notified, post.UsuarioID)
+	}
+
+	// Seguidores del hilo
+	var followerIDs []uint
+	if err := h.DB.Model(&models.SeguimientoPost{}).
+		Where("PostID = ? AND Siguiendo = 1", post.ID).
+		Pluck("UsuarioID", &followerIDs).Error; err != nil {
+		log.Printf("Error obteniendo seguidores del post %d: %v", post.ID, err)
+		return
+	}
+	for _, followerID := range followerIDs {
+		if containsUserID(notified, followerID) {
+			continue
+		}
+		err := createCoalescedNotification(h.DB, h.WSHandler, h.SocketIOBroadcaster, followerID,
+			"comment_on_followed_post", post.ID, actorID,
+			func(actor string, total int) (string, string) {
+				if total == 1 {
+					return "Actividad en un hilo que sigues", actor + " comentó en una publicación que sigues"
+				}
+				return "Actividad en un hilo que sigues", fmt.Sprintf("%d personas comentaron en una publicación que sigues", total)
+			})
+		logNotificationError("comment_on_followed_post", followerID, err)
+		notified = append(notified, followerID)
+	}
+}
+
+// notifyCommentLiked notifica al autor de un comentario que recibió un like
+func (h *commentsHandler) notifyCommentLiked(comentarioID int, actorID uint) {
+	var comentario models.Comentario
+	if err := h.DB.First(&comentario, comentarioID).Error; err != nil {
+		return
+	}
+	autorID := uint(comentario.UsuarioID)
+	if autorID == actorID {
+		return
+	}
+	if blocked, err := isBlockedBetween(h.DB, actorID, autorID); err != nil || blocked {
+		return
+	}
+
+	err := createCoalescedNotification(h.DB, h.WSHandler, h.SocketIOBroadcaster, autorID,
+		"comment_liked", uint(comentarioID), actorID,
+		func(actor string, total int) (string, string) {
+			if total == 1 {
+				return "A alguien le gustó tu comentario", "A " + actor + " le gustó tu comentario"
+			}
+			return "A varias personas les gustó tu comentario", fmt.Sprintf("A %d personas les gustó tu comentario", total)
+		})
+	logNotificationError("comment_liked", autorID, err)
+}
+
+// isFollowingPostThread indica si el usuario sigue el hilo; el autor del post lo sigue por defecto
+func isFollowingPostThread(db *gorm.DB, userID uint, post models.Post) (bool, error) {
+	var follow models.SeguimientoPost
+	result := db.Where("UsuarioID = ? AND PostID = ?", userID, post.ID).First(&follow)
+	if result.Error == gorm.ErrRecordNotFound {
+		return userID == post.UsuarioID, nil
+	}
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return follow.Siguiendo, nil
+}
+
+// followPostThread hace que el usuario siga el hilo. Si overwrite es false se respeta
+// una decisión previa de dejar de seguirlo.
+func followPostThread(db *gorm.DB, userID, postID uint, overwrite bool) error {
+	var follow models.SeguimientoPost
+	result := db.Where("UsuarioID = ? AND PostID = ?", userID, postID).First(&follow)
+	if result.Error == gorm.ErrRecordNotFound {
+		return db.Create(&models.SeguimientoPost{UsuarioID: userID, PostID: postID, Siguiendo: true}).Error
+	}
+	if result.Error != nil {
+		return result.Error
+	}
+	if overwrite && !follow.Siguiendo {
+		return db.Model(&follow).Update("Siguiendo", true).Error
+	}
+	return nil
+}
+
+// setPostThreadFollowState guarda explícitamente el estado de seguimiento de un hilo
+func setPostThreadFollowState(db *gorm.DB, userID, postID uint, follow bool) error {
+	if follow {
+		return followPostThread(db, userID, postID, true)
+	}
+
+	var existing models.SeguimientoPost
+	result := db.Where("UsuarioID = ? AND PostID = ?", userID, postID).First(&existing)
+	if result.Error == gorm.ErrRecordNotFound {
+		// Se guarda la fila para que el autor del post también pueda dejar de seguirlo
+		return db.Create(&models.SeguimientoPost{UsuarioID: userID, PostID: postID, Siguiendo: false}).Error
+	}
+	if result.Error != nil {
+		return result.Error
+	}
+	return db.Model(&existing).Update("Siguiendo", false).Error
+}
+
 // LikeComment maneja los likes/dislikes en comentarios
 func (h *commentsHandler) LikeComment(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodPost {
@@ -437,7 +1203,11 @@ func (h *commentsHandler) LikeComment(w http.ResponseWriter, r *http.Request) {
 		} else {
 			// Voto diferente, actualizar
 			h.DB.Model(&existingLike).Update("TipoVoto", req.TipoVoto)
-		}	} else if result.Error == gorm.ErrRecordNotFound {
+			if req.TipoVoto == "like" {
+				h.notifyCommentLiked(comentarioID, user.UserID)
+			}
+		}
+	} else if result.Error == gorm.ErrRecordNotFound {
 		// No existe voto, crear nuevo
 		nuevoLike := models.ComentarioLike{
 			ComentarioID: comentarioID,
@@ -446,6 +1216,9 @@ func (h *commentsHandler) LikeComment(w http.ResponseWriter, r *http.Request) {
 			CreatedAt:    time.Now(),
 		}
 		h.DB.Create(&nuevoLike)
+		if req.TipoVoto == "like" {
+			h.notifyCommentLiked(comentarioID, user.UserID)
+		}
 	} else {
 		log.Printf("Error consultando voto existente: %v", result.Error)
 		http.Error(w, "Error interno del servidor", http.StatusInternalServerError)
@@ -603,9 +1376,15 @@ func (h *commentsHandler) getCommentStats(comentarioID int) models.EstadisticasC
 
 	// Contar respuestas
 	h.DB.Model(&models.Comentario{}).
-		Where("ComentarioPadreID = ? AND Activo = 1", comentarioID).
+		Where("ComentarioPadreID = ? AND Activo = 1 AND Oculto = 0", comentarioID).
 		Count(&stats.TotalRespuestas)
 
+	// Totales por tipo de reacción
+	stats.Reacciones = emptyCommentReactionCounts()
+	if counts, _, err := loadCommentReactions(h.DB, []int{comentarioID}, 0); err == nil {
+		stats.Reacciones = counts[comentarioID]
+	}
+
 	return stats
 }
 
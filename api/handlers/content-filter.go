@@ -0,0 +1,109 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+
+	"skillswap/api/contentfilter"
+	"skillswap/api/models"
+
+	"gorm.io/gorm"
+)
+
+// Origen de los reportes creados por el filtro automático
+const (
+	reportOriginUser   = "user"
+	reportOriginFilter = "filter"
+)
+
+// Acción registrada cuando el filtro retiene contenido para revisión
+const moderationActionFilterHold = "filter_hold"
+
+// filterRuleReasons traduce las reglas del filtro a los motivos de reporte de la cola de moderación.
+// Las reglas y sus motivos concretos quedan en la descripción del reporte.
+var filterRuleReasons = map[string]string{
+	"profanity":    "inappropriate",
+	"spam_links":   "spam",
+	"contact_info": "other",
+	"duplicate":    "spam",
+	"velocity":     "spam",
+}
+
+// checkContent evalúa el contenido con el filtro y responde 400 si alguna regla lo rechaza.
+// Devuelve la decisión para que el handler retenga el contenido si quedó marcado.
+func checkContent(w http.ResponseWriter, filter *contentfilter.Pipeline, content contentfilter.Content) (contentfilter.Decision, bool) {
+	decision := filter.Evaluate(content)
+	if decision.Verdict == contentfilter.Reject {
+		http.Error(w, "Contenido rechazado: "+strings.Join(decision.Reasons(contentfilter.Reject), "; "), http.StatusBadRequest)
+		return decision, false
+	}
+	return decision, true
+}
+
+// holdForModeration oculta el contenido marcado por el filtro y lo envía a la cola de moderación.
+// Se llama dentro de la transacción que guarda el contenido para que nunca quede visible y no se
+// publique si la retención falla; tras confirmar, el llamador lo quita del índice de búsqueda.
+// Si el contenido ya tenía un reporte automático pendiente (por ejemplo, tras una edición) se actualiza.
+func holdForModeration(tx *gorm.DB, contentType string, contentID, authorID uint, decision contentfilter.Decision) error {
+	if _, err := hideReportedContent(tx, contentType, contentID); err != nil {
+		return err
+	}
+
+	motivo := "other"
+	if rules := decision.Rules(); len(rules) > 0 && filterRuleReasons[rules[0]] != "" {
+		motivo = filterRuleReasons[rules[0]]
+	}
+	descripcion := truncateRunes(decision.Summary(), maxReportTextLength)
+
+	var reporte models.Reporte
+	err := tx.Where("TipoContenido = ? AND ContenidoID = ? AND Origen = ? AND Estado = ?", contentType, contentID, reportOriginFilter, reportStatusPending).
+		First(&reporte).Error
+	switch {
+	case err == nil:
+		if err := tx.Model(&reporte).Updates(map[string]interface{}{
+			"Motivo":      motivo,
+			"Descripcion": descripcion,
+		}).Error; err != nil {
+			return err
+		}
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		reporte = models.Reporte{
+			TipoContenido:      contentType,
+			ContenidoID:        contentID,
+			UsuarioReportadoID: &authorID,
+			Origen:             reportOriginFilter,
+			Motivo:             motivo,
+			Descripcion:        descripcion,
+			Estado:             reportStatusPending,
+		}
+		if err := tx.Create(&reporte).Error; err != nil {
+			return err
+		}
+	default:
+		return err
+	}
+
+	return recordModerationAction(tx, models.AccionModeracion{
+		Accion:            moderationActionFilterHold,
+		TipoContenido:     contentType,
+		ContenidoID:       contentID,
+		UsuarioAfectadoID: &authorID,
+		ReporteID:         &reporte.ReporteID,
+		Nota:              descripcion,
+	})
+}
+
+// writeHeldForReview responde 202 indicando que el contenido quedó pendiente de revisión
+func writeHeldForReview(w http.ResponseWriter, contentType string, contentID uint, decision contentfilter.Decision) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusAccepted)
+	json.NewEncoder(w).Encode(models.ContenidoRetenidoResponse{
+		Mensaje:       "El contenido quedó pendiente de revisión por un moderador",
+		TipoContenido: contentType,
+		ContenidoID:   contentID,
+		Estado:        "pending_review",
+		Motivos:       decision.Reasons(contentfilter.Flag),
+	})
+}
@@ -0,0 +1,369 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	"skillswap/api/middleware"
+	"skillswap/api/models"
+
+	"gorm.io/gorm"
+)
+
+const (
+	maxEndorsementNoteLength = 280
+	// Respaldantes que se muestran en cada habilidad del perfil
+	topEndorsersPerAbility = 3
+)
+
+type endorsementsHandler struct {
+	DB                  *gorm.DB
+	WSHandler           *WebSocketHandler
+	SocketIOBroadcaster *SocketIOBroadcaster
+}
+
+func NewEndorsementsHandler(db *gorm.DB) *endorsementsHandler {
+	return &endorsementsHandler{
+		DB:                  db,
+		SocketIOBroadcaster: NewSocketIOBroadcaster(),
+	}
+}
+
+// SetWebSocketHandler configura el handler de WebSocket para notificar los respaldos
+func (h *endorsementsHandler) SetWebSocketHandler(wsHandler *WebSocketHandler) {
+	h.WSHandler = wsHandler
+}
+
+// CreateEndorsement respalda una habilidad que otro usuario ofrece. Solo se permite a quien completó
+// al menos una sesión con ese usuario, y una vez por habilidad.
+func (h *endorsementsHandler) CreateEndorsement(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Método no permitido", http.StatusMethodNotAllowed)
+		return
+	}
+
+	user, authenticated := middleware.GetUserFromContext(r)
+	if !authenticated {
+		http.Error(w, "Autenticación requerida", http.StatusUnauthorized)
+		return
+	}
+
+	userAbility, ok := h.loadUserAbility(w, r)
+	if !ok {
+		return
+	}
+	if userAbility.SkillType != "Ofrece" {
+		http.Error(w, "Solo se pueden respaldar las habilidades que el usuario ofrece", http.StatusBadRequest)
+		return
+	}
+	if userAbility.UserID == user.UserID {
+		http.Error(w, "No puedes respaldar tus propias habilidades", http.StatusBadRequest)
+		return
+	}
+
+	var req models.RespaldoRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Error al decodificar la solicitud: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	req.Nota = strings.TrimSpace(req.Nota)
+	if utf8.RuneCountInString(req.Nota) > maxEndorsementNoteLength {
+		http.Error(w, fmt.Sprintf("La nota no puede superar los %d caracteres", maxEndorsementNoteLength), http.StatusBadRequest)
+		return
+	}
+
+	blocked, err := isBlockedBetween(h.DB, user.UserID, userAbility.UserID)
+	if err != nil {
+		http.Error(w, "Error al verificar bloqueos: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if blocked {
+		http.Error(w, "No puedes respaldar a este usuario", http.StatusForbidden)
+		return
+	}
+
+	matchID, err := completedSessionMatchID(h.DB, user.UserID, userAbility.UserID)
+	if err != nil {
+		http.Error(w, "Error al verificar sesiones: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if matchID == 0 {
+		http.Error(w, "Solo puedes respaldar a usuarios con los que completaste al menos una sesión", http.StatusForbidden)
+		return
+	}
+
+	var existing int64
+	if err := h.DB.Model(&models.RespaldoHabilidad{}).
+		Where("UsuarioHabilidadID = ? AND RespaldanteID = ?", userAbility.ID, user.UserID).
+		Count(&existing).Error; err != nil {
+		http.Error(w, "Error al verificar respaldos: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if existing > 0 {
+		http.Error(w, "Ya respaldaste esta habilidad", http.StatusConflict)
+		return
+	}
+
+	respaldo := models.RespaldoHabilidad{
+		UsuarioHabilidadID: userAbility.ID,
+		RespaldanteID:      user.UserID,
+		EmparejamientoID:   &matchID,
+		Nota:               req.Nota,
+	}
+	if err := h.DB.Create(&respaldo).Error; err != nil {
+		http.Error(w, "Error al guardar el respaldo: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	var endorser models.User
+	if err := h.DB.Select("UsuarioID", "NombreUsuario").First(&endorser, user.UserID).Error; err == nil {
+		respaldo.NombreUsuario = endorser.NombreUsuario
+	}
+
+	if notificationEnabled(h.DB, userAbility.UserID, "skill_endorsed") {
+		var ability models.Ability
+		h.DB.Select("HabilidadID", "NombreHabilidad").First(&ability, userAbility.AbilityID)
+		contenido := fmt.Sprintf("%s respaldó tu habilidad «%s»", respaldo.NombreUsuario, ability.Name)
+		if respaldo.Nota != "" {
+			contenido += ": " + truncateRunes(respaldo.Nota, 100)
+		}
+		err := createNotification(h.DB, h.WSHandler, h.SocketIOBroadcaster, models.Notification{
+			UsuarioID:    userAbility.UserID,
+			Tipo:         "skill_endorsed",
+			Titulo:       "Nuevo respaldo",
+			Contenido:    contenido,
+			ReferenciaID: userAbility.ID,
+		})
+		logNotificationError("skill_endorsed", userAbility.UserID, err)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(respaldo)
+}
+
+// DeleteEndorsement revoca el respaldo del usuario autenticado a una habilidad
+func (h *endorsementsHandler) DeleteEndorsement(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodDelete {
+		http.Error(w, "Método no permitido", http.StatusMethodNotAllowed)
+		return
+	}
+
+	user, authenticated := middleware.GetUserFromContext(r)
+	if !authenticated {
+		http.Error(w, "Autenticación requerida", http.StatusUnauthorized)
+		return
+	}
+
+	userAbilityID, err := parseIDFromPath(r, "id")
+	if err != nil {
+		http.Error(w, "ID de habilidad de usuario inválido", http.StatusBadRequest)
+		return
+	}
+
+	result := h.DB.Where("UsuarioHabilidadID = ? AND RespaldanteID = ?", userAbilityID, user.UserID).Delete(&models.RespaldoHabilidad{})
+	if result.Error != nil {
+		http.Error(w, "Error al revocar el respaldo: "+result.Error.Error(), http.StatusInternalServerError)
+		return
+	}
+	if result.RowsAffected == 0 {
+		http.Error(w, "No has respaldado esta habilidad", http.StatusNotFound)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// GetEndorsements lista los respaldos de una habilidad de usuario, los más recientes primero
+func (h *endorsementsHandler) GetEndorsements(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Método no permitido", http.StatusMethodNotAllowed)
+		return
+	}
+
+	userAbility, ok := h.loadUserAbility(w, r)
+	if !ok {
+		return
+	}
+
+	query := endorsementsQuery(h.DB).Where("r.UsuarioHabilidadID = ?", userAbility.ID)
+	blockedIDs, err := viewerBlockedUserIDs(h.DB, r)
+	if err != nil {
+		http.Error(w, "Error al obtener bloqueos: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if len(blockedIDs) > 0 {
+		query = query.Where("r.RespaldanteID NOT IN ?", blockedIDs)
+	}
+
+	respaldos := []models.RespaldoHabilidad{}
+	if err := query.Order("r.FechaCreacion DESC").Find(&respaldos).Error; err != nil {
+		http.Error(w, "Error al obtener respaldos: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(respaldos)
+}
+
+// loadUserAbility obtiene la habilidad de usuario indicada en la ruta
+func (h *endorsementsHandler) loadUserAbility(w http.ResponseWriter, r *http.Request) (models.UserAbility, bool) {
+	var userAbility models.UserAbility
+
+	id, err := parseIDFromPath(r, "id")
+	if err != nil {
+		http.Error(w, "ID de habilidad de usuario inválido", http.StatusBadRequest)
+		return userAbility, false
+	}
+	if result := h.DB.First(&userAbility, id); result.Error != nil {
+		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+			http.Error(w, "Habilidad de usuario no encontrada", http.StatusNotFound)
+		} else {
+			http.Error(w, "Error al buscar la habilidad: "+result.Error.Error(), http.StatusInternalServerError)
+		}
+		return userAbility, false
+	}
+	return userAbility, true
+}
+
+// endorsementsQuery consulta los respaldos con el nombre de usuario de quien respalda
+func endorsementsQuery(db *gorm.DB) *gorm.DB {
+	return db.Table("RespaldosHabilidad r").
+		Select("r.*, u.NombreUsuario").
+		Joins("INNER JOIN Usuarios u ON u.UsuarioID = r.RespaldanteID")
+}
+
+// completedSessionMatchID devuelve el match más reciente entre los dos usuarios que tiene al menos
+// una sesión completada, o 0 si no hay ninguno
+func completedSessionMatchID(db *gorm.DB, userA, userB uint) (uint, error) {
+	var ids []uint
+	err := db.Table("Emparejamientos e").
+		Where("(e.Usuario1ID = ? AND e.Usuario2ID = ?) OR (e.Usuario1ID = ? AND e.Usuario2ID = ?)", userA, userB, userB, userA).
+		Where("EXISTS (SELECT 1 FROM Sesiones s WHERE s.EmparejamientoID = e.EmparejamientoID AND s.Estado IN ?)", models.EstadosSesionCompletada).
+		Order("e.EmparejamientoID DESC").
+		Limit(1).
+		Pluck("e.EmparejamientoID", &ids).Error
+	if err != nil || len(ids) == 0 {
+		return 0, err
+	}
+	return ids[0], nil
+}
+
+// attachEndorsements completa en las habilidades ofrecidas el total de respaldos, los principales
+// respaldantes y si el usuario autenticado (viewerID, 0 si es anónimo) ya la respaldó. Los
+// principales respaldantes son primero quienes tienen más respaldos en esa misma habilidad.
+func attachEndorsements(db *gorm.DB, viewerID uint, abilities []models.UserAbility) error {
+	var ids []uint
+	for _, ability := range abilities {
+		if ability.SkillType == "Ofrece" {
+			ids = append(ids, ability.ID)
+		}
+	}
+	if len(ids) == 0 {
+		return nil
+	}
+
+	var respaldos []models.RespaldoHabilidad
+	if err := endorsementsQuery(db).Where("r.UsuarioHabilidadID IN ?", ids).Find(&respaldos).Error; err != nil {
+		return err
+	}
+	if len(respaldos) == 0 {
+		return nil
+	}
+
+	var hidden []uint
+	if viewerID != 0 {
+		var err error
+		if hidden, err = blockedUserIDs(db, viewerID); err != nil {
+			return err
+		}
+	}
+
+	// Respaldos que cada respaldante recibió en la misma habilidad, para ordenar a los principales
+	// (los IDs se deduplican porque se pueden recibir las habilidades de varios usuarios a la vez)
+	abilityOf := make(map[uint]uint, len(abilities))
+	var skillIDs []uint
+	for _, ability := range abilities {
+		abilityOf[ability.ID] = ability.AbilityID
+		if !containsUint(skillIDs, ability.AbilityID) {
+			skillIDs = append(skillIDs, ability.AbilityID)
+		}
+	}
+	var endorserIDs []uint
+	for _, respaldo := range respaldos {
+		if !containsUint(endorserIDs, respaldo.RespaldanteID) {
+			endorserIDs = append(endorserIDs, respaldo.RespaldanteID)
+		}
+	}
+	var pesos []struct {
+		UsuarioID   uint  `gorm:"column:UsuarioID"`
+		HabilidadID uint  `gorm:"column:HabilidadID"`
+		Total       int64 `gorm:"column:Total"`
+	}
+	if err := db.Table("RespaldosHabilidad r").
+		Select("ua.UsuarioID, ua.HabilidadID, COUNT(*) AS Total").
+		Joins("INNER JOIN UsuariosHabilidades ua ON ua.UsuarioHabilidadID = r.UsuarioHabilidadID").
+		Where("ua.UsuarioID IN ? AND ua.HabilidadID IN ?", endorserIDs, skillIDs).
+		Group("ua.UsuarioID, ua.HabilidadID").
+		Scan(&pesos).Error; err != nil {
+		return err
+	}
+	peso := make(map[[2]uint]int64, len(pesos))
+	for _, p := range pesos {
+		peso[[2]uint{p.UsuarioID, p.HabilidadID}] += p.Total
+	}
+
+	byAbility := make(map[uint][]models.RespaldoHabilidad)
+	for _, respaldo := range respaldos {
+		byAbility[respaldo.UsuarioHabilidadID] = append(byAbility[respaldo.UsuarioHabilidadID], respaldo)
+	}
+
+	for i := range abilities {
+		list := byAbility[abilities[i].ID]
+		abilities[i].TotalRespaldos = int64(len(list))
+
+		var visible []models.RespaldoHabilidad
+		for _, respaldo := range list {
+			if respaldo.RespaldanteID == viewerID {
+				abilities[i].RespaldadaPorMi = true
+			}
+			if !containsUint(hidden, respaldo.RespaldanteID) {
+				visible = append(visible, respaldo)
+			}
+		}
+		skillID := abilityOf[abilities[i].ID]
+		sort.SliceStable(visible, func(a, b int) bool {
+			pa := peso[[2]uint{visible[a].RespaldanteID, skillID}]
+			pb := peso[[2]uint{visible[b].RespaldanteID, skillID}]
+			if pa != pb {
+				return pa > pb
+			}
+			return visible[a].FechaCreacion.After(visible[b].FechaCreacion)
+		})
+		if len(visible) > topEndorsersPerAbility {
+			visible = visible[:topEndorsersPerAbility]
+		}
+		abilities[i].TopRespaldantes = visible
+	}
+	return nil
+}
+
+// endorsementCountFor devuelve los respaldos de la habilidad abilityID que ofrece el usuario
+func endorsementCountFor(user models.User, abilityID uint) int64 {
+	for _, ability := range user.UserAbilities {
+		if ability.AbilityID == abilityID && ability.SkillType == "Ofrece" {
+			return ability.TotalRespaldos
+		}
+	}
+	return 0
+}
+
+// deleteEndorsementsBy elimina los respaldos dados por un usuario
+func deleteEndorsementsBy(db *gorm.DB, userID uint) error {
+	return db.Where("RespaldanteID = ?", userID).Delete(&models.RespaldoHabilidad{}).Error
+}
@@ -0,0 +1,515 @@
+package handlers
+
+import (
+	"bufio"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"html"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"skillswap/api/middleware"
+	"skillswap/api/models"
+
+	"gorm.io/gorm"
+)
+
+const (
+	// Las conversaciones con más mensajes se exportan en segundo plano
+	exportSyncMessageLimit = 2000
+	// Mensajes leídos por consulta al generar la exportación
+	exportBatchSize = 500
+	// Tiempo que un archivo exportado permanece disponible para descarga
+	exportJobTTL = 24 * time.Hour
+)
+
+// Tipos de contenido por formato de exportación
+var exportContentTypes = map[string]string{
+	"json": "application/json; charset=utf-8",
+	"html": "text/html; charset=utf-8",
+	"txt":  "text/plain; charset=utf-8",
+}
+
+// exportJob es una exportación en segundo plano; el archivo generado vive en el directorio temporal
+type exportJob struct {
+	models.ConversationExportJobResponse
+	UserID   uint
+	FilePath string
+}
+
+type exportHandler struct {
+	DB   *gorm.DB
+	mu   sync.Mutex
+	jobs map[string]*exportJob
+}
+
+func NewExportHandler(db *gorm.DB) *exportHandler {
+	return &exportHandler{DB: db, jobs: make(map[string]*exportJob)}
+}
+
+// ExportConversation exporta el historial completo de una conversación en JSON, HTML o texto plano.
+// Las conversaciones grandes (o con async=true) se exportan en segundo plano y se responde 202
+// con el trabajo creado.
+func (h *exportHandler) ExportConversation(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Método no permitido", http.StatusMethodNotAllowed)
+		return
+	}
+
+	user, ok := middleware.GetUserFromContext(r)
+	if !ok {
+		http.Error(w, "Usuario no autenticado", http.StatusUnauthorized)
+		return
+	}
+
+	conversationID, err := parseIDFromPath(r, "conversationID")
+	if err != nil {
+		http.Error(w, "ID de conversación inválido", http.StatusBadRequest)
+		return
+	}
+
+	var conversation models.Conversation
+	if result := h.DB.First(&conversation, conversationID); result.Error != nil {
+		http.Error(w, "Conversación no encontrada", http.StatusNotFound)
+		return
+	}
+
+	// Solo los participantes pueden exportar la conversación
+	participantIDs, err := conversationParticipantIDs(h.DB, conversation)
+	if err != nil {
+		http.Error(w, "Error al obtener participantes: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if !containsUserID(participantIDs, user.UserID) {
+		http.Error(w, "No tienes permiso para exportar esta conversación", http.StatusForbidden)
+		return
+	}
+
+	format := strings.ToLower(r.URL.Query().Get("format"))
+	if format == "" {
+		format = "json"
+	}
+	if _, ok := exportContentTypes[format]; !ok {
+		http.Error(w, "Formato inválido: use json, html o txt", http.StatusBadRequest)
+		return
+	}
+
+	// Las fechas se muestran en la zona horaria del usuario (nombre IANA, por ejemplo America/Bogota)
+	location := time.UTC
+	if tz := r.URL.Query().Get("tz"); tz != "" {
+		if location, err = time.LoadLocation(tz); err != nil {
+			http.Error(w, "Zona horaria inválida", http.StatusBadRequest)
+			return
+		}
+	}
+
+	var messageCount int64
+	if err := h.DB.Model(&models.Message{}).Where("ConversationID = ?", conversation.ID).Count(&messageCount).Error; err != nil {
+		http.Error(w, "Error al contar mensajes: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	if messageCount > exportSyncMessageLimit || r.URL.Query().Get("async") == "true" {
+		job, err := h.startExportJob(conversation, participantIDs, user.UserID, format, location)
+		if err != nil {
+			http.Error(w, "Error al crear la exportación: "+err.Error(), http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Location", "/exports/"+job.ID)
+		w.WriteHeader(http.StatusAccepted)
+		json.NewEncoder(w).Encode(job)
+		return
+	}
+
+	w.Header().Set("Content-Type", exportContentTypes[format])
+	w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="conversacion-%d.%s"`, conversation.ID, format))
+
+	// La respuesta ya comenzó a enviarse, por lo que los errores solo se registran
+	if err := writeConversationExport(h.DB, w, conversation, participantIDs, format, location); err != nil {
+		log.Printf("Error exportando la conversación %d: %v", conversation.ID, err)
+	}
+}
+
+// GetExportJob devuelve el estado de una exportación en segundo plano
+func (h *exportHandler) GetExportJob(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Método no permitido", http.StatusMethodNotAllowed)
+		return
+	}
+
+	user, ok := middleware.GetUserFromContext(r)
+	if !ok {
+		http.Error(w, "Usuario no autenticado", http.StatusUnauthorized)
+		return
+	}
+
+	job, found := h.findExportJob(r.PathValue("jobID"), user.UserID)
+	if !found {
+		http.Error(w, "Exportación no encontrada", http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(job.ConversationExportJobResponse)
+}
+
+// DownloadExportJob descarga el archivo de una exportación terminada
+func (h *exportHandler) DownloadExportJob(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Método no permitido", http.StatusMethodNotAllowed)
+		return
+	}
+
+	user, ok := middleware.GetUserFromContext(r)
+	if !ok {
+		http.Error(w, "Usuario no autenticado", http.StatusUnauthorized)
+		return
+	}
+
+	job, found := h.findExportJob(r.PathValue("jobID"), user.UserID)
+	if !found {
+		http.Error(w, "Exportación no encontrada", http.StatusNotFound)
+		return
+	}
+	if job.Status != "completed" {
+		http.Error(w, "La exportación aún no está lista", http.StatusConflict)
+		return
+	}
+
+	file, err := os.Open(job.FilePath)
+	if err != nil {
+		http.Error(w, "El archivo de la exportación ya no está disponible", http.StatusGone)
+		return
+	}
+	defer file.Close()
+
+	w.Header().Set("Content-Type", exportContentTypes[job.Format])
+	w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="conversacion-%d.%s"`, job.ConversationID, job.Format))
+	if _, err := io.Copy(w, file); err != nil {
+		log.Printf("Error enviando la exportación %s: %v", job.ID, err)
+	}
+}
+
+// startExportJob registra una exportación y la genera en una goroutine
+func (h *exportHandler) startExportJob(conversation models.Conversation, participantIDs []uint, userID uint, format string, location *time.Location) (models.ConversationExportJobResponse, error) {
+	idBytes := make([]byte, 16)
+	if _, err := rand.Read(idBytes); err != nil {
+		return models.ConversationExportJobResponse{}, err
+	}
+
+	job := &exportJob{
+		ConversationExportJobResponse: models.ConversationExportJobResponse{
+			ID:             hex.EncodeToString(idBytes),
+			ConversationID: conversation.ID,
+			Format:         format,
+			Status:         "pending",
+			CreatedAt:      time.Now(),
+		},
+		UserID: userID,
+	}
+
+	h.mu.Lock()
+	h.purgeExpiredJobsLocked()
+	h.jobs[job.ID] = job
+	response := job.ConversationExportJobResponse
+	h.mu.Unlock()
+
+	go h.runExportJob(job, conversation, participantIDs, location)
+	return response, nil
+}
+
+// runExportJob genera el archivo de una exportación en segundo plano
+func (h *exportHandler) runExportJob(job *exportJob, conversation models.Conversation, participantIDs []uint, location *time.Location) {
+	h.updateExportJob(job, func(j *exportJob) { j.Status = "running" })
+
+	filePath, err := func() (string, error) {
+		file, err := os.CreateTemp("", fmt.Sprintf("conversacion-%d-*.%s", conversation.ID, job.Format))
+		if err != nil {
+			return "", err
+		}
+		defer file.Close()
+
+		buffered := bufio.NewWriter(file)
+		if err := writeConversationExport(h.DB, buffered, conversation, participantIDs, job.Format, location); err != nil {
+			os.Remove(file.Name())
+			return "", err
+		}
+		if err := buffered.Flush(); err != nil {
+			os.Remove(file.Name())
+			return "", err
+		}
+		return file.Name(), nil
+	}()
+
+	now := time.Now()
+	h.updateExportJob(job, func(j *exportJob) {
+		j.CompletedAt = &now
+		if err != nil {
+			log.Printf("Error en la exportación %s de la conversación %d: %v", j.ID, j.ConversationID, err)
+			j.Status = "failed"
+			j.Error = err.Error()
+			return
+		}
+		expiresAt := now.Add(exportJobTTL)
+		j.Status = "completed"
+		j.FilePath = filePath
+		j.DownloadURL = "/exports/" + j.ID + "/download"
+		j.ExpiresAt = &expiresAt
+	})
+}
+
+// updateExportJob modifica un trabajo bajo el lock del handler
+func (h *exportHandler) updateExportJob(job *exportJob, update func(*exportJob)) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	update(job)
+}
+
+// findExportJob devuelve una copia del trabajo si pertenece al usuario
+func (h *exportHandler) findExportJob(jobID string, userID uint) (exportJob, bool) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.purgeExpiredJobsLocked()
+	job, ok := h.jobs[jobID]
+	if !ok || job.UserID != userID {
+		return exportJob{}, false
+	}
+	return *job, true
+}
+
+// purgeExpiredJobsLocked elimina los trabajos vencidos y sus archivos; requiere h.mu
+func (h *exportHandler) purgeExpiredJobsLocked() {
+	now := time.Now()
+	for id, job := range h.jobs {
+		expired := job.ExpiresAt != nil && now.After(*job.ExpiresAt)
+		failedLongAgo := job.Status == "failed" && job.CompletedAt != nil && now.Sub(*job.CompletedAt) > exportJobTTL
+		if expired || failedLongAgo {
+			if job.FilePath != "" {
+				os.Remove(job.FilePath)
+			}
+			delete(h.jobs, id)
+		}
+	}
+}
+
+// conversationExportMeta son los datos de cabecera de una exportación
+type conversationExportMeta struct {
+	ConversationID uint     `json:"conversation_id"`
+	Title          string   `json:"title,omitempty"`
+	Participants   []string `json:"participants"`
+	Timezone       string   `json:"timezone"`
+	ExportedAt     string   `json:"exported_at"`
+}
+
+// conversationExportWriter escribe una exportación en un formato concreto
+type conversationExportWriter interface {
+	WriteHeader(meta conversationExportMeta) error
+	WriteMessage(message models.ExportedMessage, first bool) error
+	WriteFooter() error
+}
+
+// writeConversationExport escribe el historial completo de la conversación leyendo los mensajes por lotes
+func writeConversationExport(db *gorm.DB, w io.Writer, conversation models.Conversation, participantIDs []uint, format string, location *time.Location) error {
+	var exporter conversationExportWriter
+	switch format {
+	case "json":
+		exporter = &jsonExportWriter{w: w}
+	case "html":
+		exporter = &htmlExportWriter{w: w}
+	default:
+		exporter = &textExportWriter{w: w}
+	}
+
+	var participants []models.User
+	if err := db.Where("UsuarioID IN ?", participantIDs).Find(&participants).Error; err != nil {
+		return err
+	}
+	meta := conversationExportMeta{
+		ConversationID: conversation.ID,
+		Title:          conversation.Title,
+		Participants:   make([]string, 0, len(participants)),
+		Timezone:       location.String(),
+		ExportedAt:     time.Now().In(location).Format(time.RFC3339),
+	}
+	for _, participant := range participants {
+		meta.Participants = append(meta.Participants, userDisplayName(participant))
+	}
+
+	if err := exporter.WriteHeader(meta); err != nil {
+		return err
+	}
+
+	first := true
+	var messages []models.Message
+	result := db.Where("ConversationID = ?", conversation.ID).
+		Preload("Sender").
+		FindInBatches(&messages, exportBatchSize, func(tx *gorm.DB, batch int) error {
+			for _, message := range messages {
+				if err := exporter.WriteMessage(toExportedMessage(message, location), first); err != nil {
+					return err
+				}
+				first = false
+			}
+			// Enviar cada lote al cliente en cuanto está listo
+			if flusher, ok := w.(http.Flusher); ok {
+				flusher.Flush()
+			}
+			return nil
+		})
+	if result.Error != nil {
+		return result.Error
+	}
+
+	return exporter.WriteFooter()
+}
+
+// toExportedMessage convierte un mensaje al formato de exportación
+func toExportedMessage(message models.Message, location *time.Location) models.ExportedMessage {
+	exported := models.ExportedMessage{
+		ID:          message.ID,
+		SenderID:    message.SenderID,
+		SenderName:  userDisplayName(message.Sender),
+		Content:     message.Content,
+		MessageType: message.MessageType,
+		SentAt:      message.CreatedAt.In(location).Format(time.RFC3339),
+	}
+
+	// En los mensajes de imagen o archivo el contenido es la referencia al adjunto
+	if message.MessageType == "image" || message.MessageType == "file" {
+		exported.Attachment = &models.ExportedAttachment{Type: message.MessageType, URL: message.Content}
+		exported.Content = ""
+	}
+	return exported
+}
+
+// userDisplayName devuelve el nombre visible de un usuario (nombre y apellido, o su nombre de usuario)
+func userDisplayName(user models.User) string {
+	if name := strings.TrimSpace(user.PrimerNombre + " " + user.PrimerApellido); name != "" {
+		return name
+	}
+	if user.NombreUsuario != "" {
+		return user.NombreUsuario
+	}
+	return fmt.Sprintf("Usuario %d", user.ID)
+}
+
+// jsonExportWriter escribe un objeto JSON con la cabecera y el arreglo de mensajes
+type jsonExportWriter struct {
+	w io.Writer
+}
+
+func (e *jsonExportWriter) WriteHeader(meta conversationExportMeta) error {
+	data, err := json.Marshal(meta)
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(e.w, `{"conversation":%s,"messages":[`, data)
+	return err
+}
+
+func (e *jsonExportWriter) WriteMessage(message models.ExportedMessage, first bool) error {
+	data, err := json.Marshal(message)
+	if err != nil {
+		return err
+	}
+	if !first {
+		if _, err := io.WriteString(e.w, ","); err != nil {
+			return err
+		}
+	}
+	_, err = e.w.Write(data)
+	return err
+}
+
+func (e *jsonExportWriter) WriteFooter() error {
+	_, err := io.WriteString(e.w, "]}\n")
+	return err
+}
+
+// textExportWriter escribe una línea por mensaje
+type textExportWriter struct {
+	w io.Writer
+}
+
+func (e *textExportWriter) WriteHeader(meta conversationExportMeta) error {
+	title := meta.Title
+	if title == "" {
+		title = fmt.Sprintf("Conversación %d", meta.ConversationID)
+	}
+	_, err := fmt.Fprintf(e.w, "%s\nParticipantes: %s\nExportado: %s (%s)\n\n",
+		title, strings.Join(meta.Participants, ", "), meta.ExportedAt, meta.Timezone)
+	return err
+}
+
+func (e *textExportWriter) WriteMessage(message models.ExportedMessage, first bool) error {
+	content := message.Content
+	if message.Attachment != nil {
+		content = fmt.Sprintf("[Adjunto %s] %s", message.Attachment.Type, message.Attachment.URL)
+	}
+	_, err := fmt.Fprintf(e.w, "[%s] %s: %s\n", message.SentAt, message.SenderName, content)
+	return err
+}
+
+func (e *textExportWriter) WriteFooter() error {
+	return nil
+}
+
+// htmlExportWriter escribe un documento HTML autónomo con el contenido escapado
+type htmlExportWriter struct {
+	w io.Writer
+}
+
+func (e *htmlExportWriter) WriteHeader(meta conversationExportMeta) error {
+	title := meta.Title
+	if title == "" {
+		title = fmt.Sprintf("Conversación %d", meta.ConversationID)
+	}
+	_, err := fmt.Fprintf(e.w, `<!DOCTYPE html>
+<html lang="es">
+<head>
+<meta charset="utf-8">
+<title>%[1]s</title>
+<style>
+body{font-family:sans-serif;max-width:800px;margin:2em auto;color:#222}
+.message{border-bottom:1px solid #eee;padding:.5em 0}
+.time{color:#888;font-size:.85em}
+.content{white-space:pre-wrap;margin:.25em 0 0}
+</style>
+</head>
+<body>
+<h1>%[1]s</h1>
+<p>Participantes: %[2]s<br>Exportado: %[3]s (%[4]s)</p>
+`, html.EscapeString(title), html.EscapeString(strings.Join(meta.Participants, ", ")),
+		html.EscapeString(meta.ExportedAt), html.EscapeString(meta.Timezone))
+	return err
+}
+
+func (e *htmlExportWriter) WriteMessage(message models.ExportedMessage, first bool) error {
+	content := html.EscapeString(message.Content)
+	if message.Attachment != nil {
+		url := html.EscapeString(message.Attachment.URL)
+		// Solo se enlazan URLs http(s) para no introducir esquemas peligrosos
+		if strings.HasPrefix(message.Attachment.URL, "http://") || strings.HasPrefix(message.Attachment.URL, "https://") {
+			content = fmt.Sprintf(`Adjunto (%s): <a href="%s">%s</a>`, html.EscapeString(message.Attachment.Type), url, url)
+		} else {
+			content = fmt.Sprintf("Adjunto (%s): %s", html.EscapeString(message.Attachment.Type), url)
+		}
+	}
+	_, err := fmt.Fprintf(e.w, "<div class=\"message\"><span class=\"time\">%s</span> <strong>%s</strong><p class=\"content\">%s</p></div>\n",
+		html.EscapeString(message.SentAt), html.EscapeString(message.SenderName), content)
+	return err
+}
+
+func (e *htmlExportWriter) WriteFooter() error {
+	_, err := io.WriteString(e.w, "</body>\n</html>\n")
+	return err
+}
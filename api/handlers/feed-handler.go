@@ -0,0 +1,371 @@
+package handlers
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"skillswap/api/middleware"
+	"skillswap/api/models"
+
+	"gorm.io/gorm"
+)
+
+const (
+	feedDefaultPageSize = 20
+	feedMaxPageSize     = 50
+	feedMaxCandidates   = 1000 // Posts complementarios más recientes que se puntúan por solicitud
+
+	// Pesos de la puntuación del feed
+	feedWeightComplement = 1.0
+	feedWeightMutual     = 0.5
+	feedWeightRecency    = 1.0
+	feedWeightSameCity   = 0.3
+	feedWeightRating     = 0.5
+	feedRecencyHalfLife  = 7 * 24 * time.Hour // La recencia vale la mitad cada semana
+	feedWellRatedMinimum = 4.0
+)
+
+// feedCandidate es un post complementario con los datos necesarios para puntuarlo
+type feedCandidate struct {
+	PostID      uint      `gorm:"column:PostID"`
+	UsuarioID   uint      `gorm:"column:UsuarioID"`
+	TipoPost    string    `gorm:"column:TipoPost"`
+	HabilidadID uint      `gorm:"column:HabilidadID"`
+	CreatedAt   time.Time `gorm:"column:CreatedAt"`
+
+	score   float64
+	reasons []string
+	rating  *float64
+}
+
+// GetUserFeed devuelve los posts abiertos ordenados según su complementariedad con las habilidades
+// que el usuario Busca y Ofrece, la recencia, la ciudad y la valoración del autor. La paginación usa
+// un cursor que fija el instante de cálculo para que las páginas siguientes sean estables.
+func (h *postsHandler) GetUserFeed(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Método no permitido", http.StatusMethodNotAllowed)
+		return
+	}
+
+	userID, ok := authorizeFeedOwner(w, r)
+	if !ok {
+		return
+	}
+
+	pageSize := queryIntInRange(r, "pageSize", feedDefaultPageSize, 1, feedMaxPageSize)
+	asOf := time.Now()
+	var afterScore float64
+	var afterID uint
+	if cursor := r.URL.Query().Get("cursor"); cursor != "" {
+		var err error
+		if asOf, afterScore, afterID, err = decodeFeedCursor(cursor); err != nil {
+			http.Error(w, "Cursor inválido", http.StatusBadRequest)
+			return
+		}
+	}
+
+	candidates, err := loadFeedCandidates(h.DB, userID, asOf)
+	if err != nil {
+		http.Error(w, "Error al obtener el feed: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if err := scoreFeedCandidates(h.DB, userID, candidates, asOf); err != nil {
+		http.Error(w, "Error al puntuar el feed: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+	sort.Slice(candidates, func(i, j int) bool {
+		if candidates[i].score != candidates[j].score {
+			return candidates[i].score > candidates[j].score
+		}
+		return candidates[i].PostID > candidates[j].PostID
+	})
+
+	// Saltar lo ya entregado: el cursor guarda la puntuación y el ID del último post de la página anterior
+	start := 0
+	if afterID != 0 {
+		start = sort.Search(len(candidates), func(i int) bool {
+			c := candidates[i]
+			return c.score < afterScore || (c.score == afterScore && c.PostID < afterID)
+		})
+	}
+	end := start + pageSize
+	if end > len(candidates) {
+		end = len(candidates)
+	}
+	page := candidates[start:end]
+
+	response := models.FeedResponse{Posts: []models.PostFeedItem{}}
+	if len(page) > 0 {
+		if response.Posts, err = loadFeedItems(h.DB, userID, page); err != nil {
+			http.Error(w, "Error al obtener el feed: "+err.Error(), http.StatusInternalServerError)
+			return
+		}
+		if end < len(candidates) {
+			last := page[len(page)-1]
+			response.NextCursor = encodeFeedCursor(asOf, last.score, last.PostID)
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
+
+// authorizeFeedOwner verifica que el feed solicitado sea el del usuario autenticado
+func authorizeFeedOwner(w http.ResponseWriter, r *http.Request) (uint, bool) {
+	userID, err := parseIDFromPath(r, "id")
+	if err != nil {
+		http.Error(w, "ID de usuario inválido", http.StatusBadRequest)
+		return 0, false
+	}
+
+	user, authenticated := middleware.GetUserFromContext(r)
+	if !authenticated {
+		http.Error(w, "Autenticación requerida", http.StatusUnauthorized)
+		return 0, false
+	}
+	if user.UserID != userID {
+		http.Error(w, "Solo puedes ver tu propio feed", http.StatusForbidden)
+		return 0, false
+	}
+	return userID, true
+}
+
+// loadFeedCandidates obtiene los posts abiertos que complementan las habilidades del usuario:
+// OFREZCO de lo que Busca y BUSCO de lo que Ofrece. Excluye sus propios posts, los de usuarios con
+// bloqueo en cualquier dirección y los publicados después del instante del cursor.
+func loadFeedCandidates(db *gorm.DB, userID uint, asOf time.Time) ([]feedCandidate, error) {
+	busca, ofrece, err := loadUserSkillIDs(db, userID)
+	if err != nil {
+		return nil, err
+	}
+	candidates := []feedCandidate{}
+	if len(busca) == 0 && len(ofrece) == 0 {
+		return candidates, nil
+	}
+
+	query := db.Model(&models.Post{}).
+		Select("PostID, UsuarioID, TipoPost, HabilidadID, CreatedAt").
+		Where("Oculto = 0 AND UsuarioID <> ? AND CreatedAt <= ?", userID, asOf).
+		Where(postEffectiveStatusSQL+" = ?", models.PostAbierto).
+		Where("(UPPER(TipoPost) = 'OFREZCO' AND HabilidadID IN ?) OR (UPPER(TipoPost) = 'BUSCO' AND HabilidadID IN ?)",
+			idsOrZero(busca), idsOrZero(ofrece))
+
+	blocked, err := blockRelatedUserIDs(db, userID)
+	if err != nil {
+		return nil, err
+	}
+	if len(blocked) > 0 {
+		query = query.Where("UsuarioID NOT IN ?", blocked)
+	}
+
+	err = query.Order("CreatedAt DESC, PostID DESC").Limit(feedMaxCandidates).Find(&candidates).Error
+	return candidates, err
+}
+
+// scoreFeedCandidates calcula la puntuación y los motivos de cada candidato
+func scoreFeedCandidates(db *gorm.DB, userID uint, candidates []feedCandidate, asOf time.Time) error {
+	if len(candidates) == 0 {
+		return nil
+	}
+	_, ofrece, err := loadUserSkillIDs(db, userID)
+	if err != nil {
+		return err
+	}
+
+	authorIDs := make([]uint, 0, len(candidates))
+	for _, c := range candidates {
+		authorIDs = append(authorIDs, c.UsuarioID)
+	}
+
+	var viewer models.User
+	if err := db.Select("UsuarioID", "CiudadTrabajo").First(&viewer, userID).Error; err != nil {
+		return err
+	}
+	var authors []models.User
+	if err := db.Select("UsuarioID", "CiudadTrabajo").Where("UsuarioID IN ?", authorIDs).Find(&authors).Error; err != nil {
+		return err
+	}
+	viewerCity := normalizedPhrase(viewer.CiudadTrabajo)
+	sameCity := make(map[uint]bool, len(authors))
+	for _, author := range authors {
+		sameCity[author.ID] = viewerCity != "" && normalizedPhrase(author.CiudadTrabajo) == viewerCity
+	}
+
+	// Autores que además Buscan alguna habilidad que el usuario Ofrece: el intercambio es mutuo
+	mutual := make(map[uint]bool)
+	if len(ofrece) > 0 {
+		var mutualIDs []uint
+		if err := db.Model(&models.UserAbility{}).
+			Where("UsuarioID IN ? AND TipoHabilidad = ? AND HabilidadID IN ?", authorIDs, "Busca", ofrece).
+			Distinct().Pluck("UsuarioID", &mutualIDs).Error; err != nil {
+			return err
+		}
+		for _, id := range mutualIDs {
+			mutual[id] = true
+		}
+	}
+
+	ratings, err := loadAuthorRatings(db, authorIDs)
+	if err != nil {
+		return err
+	}
+
+	for i := range candidates {
+		c := &candidates[i]
+		score := feedWeightComplement
+		if strings.EqualFold(c.TipoPost, "OFREZCO") {
+			c.reasons = append(c.reasons, models.MotivoOfreceLoQueBuscas)
+		} else {
+			c.reasons = append(c.reasons, models.MotivoBuscaLoQueOfreces)
+		}
+		if mutual[c.UsuarioID] {
+			score += feedWeightMutual
+			c.reasons = append(c.reasons, models.MotivoIntercambioMutuo)
+		}
+
+		age := asOf.Sub(c.CreatedAt)
+		if age < 0 {
+			age = 0
+		}
+		score += feedWeightRecency * math.Exp(-math.Ln2*float64(age)/float64(feedRecencyHalfLife))
+
+		if sameCity[c.UsuarioID] {
+			score += feedWeightSameCity
+			c.reasons = append(c.reasons, models.MotivoMismaCiudad)
+		}
+		if rating, ok := ratings[c.UsuarioID]; ok {
+			c.rating = &rating
+			// Escala de 1 a 5 llevada a 0..1; los autores sin reseñas no suman ni restan
+			score += feedWeightRating * (rating - 1) / 4
+			if rating >= feedWellRatedMinimum {
+				c.reasons = append(c.reasons, models.MotivoAutorValorado)
+			}
+		}
+
+		// Redondear deja la puntuación idéntica entre solicitudes para comparar con el cursor
+		c.score = math.Round(score*1e6) / 1e6
+	}
+	return nil
+}
+
+// loadUserSkillIDs devuelve las habilidades que el usuario Busca y las que Ofrece
+func loadUserSkillIDs(db *gorm.DB, userID uint) ([]uint, []uint, error) {
+	var abilities []models.UserAbility
+	if err := db.Where("UsuarioID = ?", userID).Find(&abilities).Error; err != nil {
+		return nil, nil, err
+	}
+	var busca, ofrece []uint
+	for _, ability := range abilities {
+		switch ability.SkillType {
+		case "Busca":
+			busca = append(busca, ability.AbilityID)
+		case "Ofrece":
+			ofrece = append(ofrece, ability.AbilityID)
+		}
+	}
+	return busca, ofrece, nil
+}
+
+// loadAuthorRatings devuelve el promedio de las reseñas recibidas por cada autor que tenga alguna
+func loadAuthorRatings(db *gorm.DB, authorIDs []uint) (map[uint]float64, error) {
+	var rows []struct {
+		UsuarioID uint    `gorm:"column:UsuarioRevisadoID"`
+		Promedio  float64 `gorm:"column:Promedio"`
+	}
+	if err := db.Table("Resenas").
+		Select("UsuarioRevisadoID, AVG(CAST(Calificacion AS FLOAT)) AS Promedio").
+		Where("UsuarioRevisadoID IN ? AND Calificacion IS NOT NULL", authorIDs).
+		Group("UsuarioRevisadoID").
+		Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+	ratings := make(map[uint]float64, len(rows))
+	for _, row := range rows {
+		ratings[row.UsuarioID] = math.Round(row.Promedio*100) / 100
+	}
+	return ratings, nil
+}
+
+// loadFeedItems carga la información completa de los posts de la página respetando su orden
+func loadFeedItems(db *gorm.DB, viewerID uint, page []feedCandidate) ([]models.PostFeedItem, error) {
+	ids := make([]uint, 0, len(page))
+	for _, c := range page {
+		ids = append(ids, c.PostID)
+	}
+
+	var posts []models.PostFullInfo
+	if err := db.Where("PostID IN ?", ids).Find(&posts).Error; err != nil {
+		return nil, err
+	}
+	if err := attachPostDetails(db, posts); err != nil {
+		return nil, err
+	}
+	if err := markBookmarkedPosts(db, viewerID, posts); err != nil {
+		return nil, err
+	}
+	byID := make(map[uint]models.PostFullInfo, len(posts))
+	for _, post := range posts {
+		byID[post.PostID] = post
+	}
+
+	items := make([]models.PostFeedItem, 0, len(page))
+	for _, c := range page {
+		post, ok := byID[c.PostID]
+		if !ok {
+			continue
+		}
+		items = append(items, models.PostFeedItem{
+			PostFullInfo:      post,
+			Puntuacion:        c.score,
+			Motivos:           c.reasons,
+			CalificacionAutor: c.rating,
+		})
+	}
+	return items, nil
+}
+
+// idsOrZero evita una lista vacía en un IN, que no coincide con ningún ID válido
+func idsOrZero(ids []uint) []uint {
+	if len(ids) == 0 {
+		return []uint{0}
+	}
+	return ids
+}
+
+// encodeFeedCursor codifica el instante de cálculo y la posición del último post entregado
+func encodeFeedCursor(asOf time.Time, score float64, postID uint) string {
+	raw := fmt.Sprintf("%d:%s:%d", asOf.UnixNano(), strconv.FormatFloat(score, 'f', -1, 64), postID)
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+// decodeFeedCursor decodifica un cursor generado por encodeFeedCursor
+func decodeFeedCursor(cursor string) (time.Time, float64, uint, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return time.Time{}, 0, 0, err
+	}
+	parts := strings.Split(string(raw), ":")
+	if len(parts) != 3 {
+		return time.Time{}, 0, 0, fmt.Errorf("cursor con formato inválido")
+	}
+	nanos, err := strconv.ParseInt(parts[0], 10, 64)
+	if err != nil {
+		return time.Time{}, 0, 0, err
+	}
+	score, err := strconv.ParseFloat(parts[1], 64)
+	if err != nil {
+		return time.Time{}, 0, 0, err
+	}
+	postID, err := strconv.ParseUint(parts[2], 10, 64)
+	if err != nil || postID == 0 {
+		return time.Time{}, 0, 0, fmt.Errorf("cursor fuera de rango")
+	}
+	return time.Unix(0, nanos), score, uint(postID), nil
+}
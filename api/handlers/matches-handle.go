@@ -6,6 +6,7 @@ import (
 	"net/http"
 	"reflect"
 	"skillswap/api/models"
+	"sort"
 	"strconv"
 
 	"gorm.io/gorm"
@@ -186,8 +187,20 @@ func (h *matchesHandler) DeleteMatch(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if result := h.DB.Delete(&models.Matches{}, id); result.Error != nil {
-		http.Error(w, "Error al eliminar el emparejamiento: "+result.Error.Error(), http.StatusInternalServerError)
+	err = h.DB.Transaction(func(tx *gorm.DB) error {
+		// Los posts cumplidos con este emparejamiento conservan su estado pero pierden el enlace
+		if err := tx.Model(&models.Post{}).Where("EmparejamientoID = ?", id).
+			UpdateColumn("EmparejamientoID", nil).Error; err != nil {
+			return err
+		}
+		// Las respuestas a posts que propusieron el emparejamiento no tienen sentido sin él
+		if err := tx.Where("EmparejamientoID = ?", id).Delete(&models.RespuestaPost{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&models.Matches{}, id).Error
+	})
+	if err != nil {
+		http.Error(w, "Error al eliminar el emparejamiento: "+err.Error(), http.StatusInternalServerError)
 		return
 	}
 
@@ -264,13 +277,24 @@ func (h *matchesHandler) GetPotentialMatches(w http.ResponseWriter, r *http.Requ
 		return
 	}
 
+	// Usuarios con un bloqueo en cualquier dirección no se proponen como match
+	blockedIDs, err := blockRelatedUserIDs(h.DB, userID)
+	if err != nil {
+		http.Error(w, "Error al obtener bloqueos del usuario: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
 	// 3. Buscar usuarios que "Ofrecen" la abilityID, excluyendo al usuario actual
 	var candidates []models.User
-	if err := h.DB.
+	candidatesQuery := h.DB.
 		Table("Usuarios").
 		Select("Usuarios.*").
 		Joins("JOIN UsuariosHabilidades ua ON ua.UsuarioID = Usuarios.UsuarioID").
-		Where("ua.HabilidadID = ? AND ua.TipoHabilidad = ? AND Usuarios.UsuarioID <> ?", abilityID, "Ofrece", userID).
+		Where("ua.HabilidadID = ? AND ua.TipoHabilidad = ? AND Usuarios.UsuarioID <> ?", abilityID, "Ofrece", userID)
+	if len(blockedIDs) > 0 {
+		candidatesQuery = candidatesQuery.Where("Usuarios.UsuarioID NOT IN ?", blockedIDs)
+	}
+	if err := candidatesQuery.
 		Preload("UserAbilities").
 		Preload("UserAbilities.Ability").
 		Debug().
@@ -314,7 +338,27 @@ func (h *matchesHandler) GetPotentialMatches(w http.ResponseWriter, r *http.Requ
 		}
 	}
 
-	// 5. Devolver JSON con los usuarios que cumplen ambas condiciones
+	// 5. Ordenar por respaldos recibidos en la habilidad buscada: primero quienes otros usuarios avalan.
+	// Los respaldos de todos los candidatos se cargan juntos y se reparten después.
+	var candidateAbilities []models.UserAbility
+	for _, match := range finalMatches {
+		candidateAbilities = append(candidateAbilities, match.UserAbilities...)
+	}
+	if err := attachEndorsements(h.DB, userID, candidateAbilities); err != nil {
+		http.Error(w, "Error al obtener respaldos: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+	offset := 0
+	for i := range finalMatches {
+		end := offset + len(finalMatches[i].UserAbilities)
+		finalMatches[i].UserAbilities = candidateAbilities[offset:end:end]
+		offset = end
+	}
+	sort.SliceStable(finalMatches, func(i, j int) bool {
+		return endorsementCountFor(finalMatches[i], abilityID) > endorsementCountFor(finalMatches[j], abilityID)
+	})
+
+	// 6. Devolver JSON con los usuarios que cumplen ambas condiciones
 	w.Header().Set("Content-Type", "application/json")
 	w.Header().Set("Access-Control-Allow-Origin", "*")
 
@@ -0,0 +1,215 @@
+package handlers
+
+import (
+	"regexp"
+	"strings"
+	"unicode/utf8"
+
+	"skillswap/api/models"
+
+	"gorm.io/gorm"
+)
+
+// Tipos de contenido que admiten menciones
+const (
+	mentionContentComment = "comment"
+	mentionContentMessage = "message"
+)
+
+// mentionPattern reconoce @NombreUsuario cuando la @ no forma parte de otra palabra o de un correo
+var mentionPattern = regexp.MustCompile(`(^|[^\p{L}\p{N}_@.])@([\p{L}\p{N}_][\p{L}\p{N}_.\-]*)`)
+
+// parsedMention es una mención encontrada en el texto, antes de resolver el usuario
+type parsedMention struct {
+	Username string
+	Offset   int // En runas
+	Length   int // En runas, incluyendo la @
+}
+
+// parseMentions extrae las menciones @NombreUsuario de un texto
+func parseMentions(text string) []parsedMention {
+	var mentions []parsedMention
+	for _, match := range mentionPattern.FindAllStringSubmatchIndex(text, -1) {
+		atIndex := match[4] - 1
+		// Los puntos o guiones finales son puntuación, no parte del nombre
+		username := strings.TrimRight(text[match[4]:match[5]], ".-")
+		if username == "" {
+			continue
+		}
+		mentions = append(mentions, parsedMention{
+			Username: username,
+			Offset:   utf8.RuneCountInString(text[:atIndex]),
+			Length:   utf8.RuneCountInString(username) + 1,
+		})
+	}
+	return mentions
+}
+
+// resolveMentions convierte las menciones del texto en filas de Menciones para usuarios existentes.
+// Se ignoran las auto-menciones, los usuarios con bloqueo en cualquier dirección y los que no
+// cumplan allowed (por ejemplo, quienes no participan en la conversación).
+func resolveMentions(db *gorm.DB, authorID uint, text string, allowed func(userID uint) bool) ([]models.Mencion, map[uint]models.User, error) {
+	parsed := parseMentions(text)
+	users := make(map[uint]models.User)
+	if len(parsed) == 0 {
+		return nil, users, nil
+	}
+
+	usernames := make([]string, 0, len(parsed))
+	for _, mention := range parsed {
+		usernames = append(usernames, mention.Username)
+	}
+
+	var found []models.User
+	if err := db.Where("NombreUsuario IN ?", usernames).Find(&found).Error; err != nil {
+		return nil, nil, err
+	}
+	byUsername := make(map[string]models.User, len(found))
+	for _, user := range found {
+		byUsername[strings.ToLower(user.NombreUsuario)] = user
+	}
+
+	blockedIDs, err := blockRelatedUserIDs(db, authorID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var mentions []models.Mencion
+	for _, mention := range parsed {
+		user, ok := byUsername[strings.ToLower(mention.Username)]
+		if !ok || user.ID == authorID || containsUserID(blockedIDs, user.ID) {
+			continue
+		}
+		if allowed != nil && !allowed(user.ID) {
+			continue
+		}
+		users[user.ID] = user
+		mentions = append(mentions, models.Mencion{
+			UsuarioMencionadoID: user.ID,
+			AutorID:             authorID,
+			Inicio:              mention.Offset,
+			Longitud:            mention.Length,
+		})
+	}
+	return mentions, users, nil
+}
+
+// saveMentions reemplaza las menciones guardadas de un contenido y devuelve las entidades resueltas
+// junto con los IDs de los usuarios mencionados por primera vez (a quienes hay que notificar).
+func saveMentions(db *gorm.DB, contentType string, contentID, authorID uint, text string, allowed func(userID uint) bool) ([]models.MentionEntity, []uint, error) {
+	mentions, users, err := resolveMentions(db, authorID, text, allowed)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var newlyMentioned []uint
+	err = db.Transaction(func(tx *gorm.DB) error {
+		var previousIDs []uint
+		if err := tx.Model(&models.Mencion{}).
+			Where("TipoContenido = ? AND ContenidoID = ?", contentType, contentID).
+			Pluck("UsuarioMencionadoID", &previousIDs).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("TipoContenido = ? AND ContenidoID = ?", contentType, contentID).
+			Delete(&models.Mencion{}).Error; err != nil {
+			return err
+		}
+
+		for i := range mentions {
+			mentions[i].TipoContenido = contentType
+			mentions[i].ContenidoID = contentID
+			userID := mentions[i].UsuarioMencionadoID
+			if !containsUserID(previousIDs, userID) && !containsUserID(newlyMentioned, userID) {
+				newlyMentioned = append(newlyMentioned, userID)
+			}
+		}
+		if len(mentions) > 0 {
+			return tx.Create(&mentions).Error
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	entities := make([]models.MentionEntity, 0, len(mentions))
+	for _, mention := range mentions {
+		entities = append(entities, models.MentionEntity{
+			UserID:        mention.UsuarioMencionadoID,
+			NombreUsuario: users[mention.UsuarioMencionadoID].NombreUsuario,
+			Offset:        mention.Inicio,
+			Length:        mention.Longitud,
+		})
+	}
+	return entities, newlyMentioned, nil
+}
+
+// loadMentionEntities obtiene las menciones de varios contenidos agrupadas por ID de contenido
+func loadMentionEntities(db *gorm.DB, contentType string, contentIDs []uint) (map[uint][]models.MentionEntity, error) {
+	entitiesByContent := make(map[uint][]models.MentionEntity)
+	if len(contentIDs) == 0 {
+		return entitiesByContent, nil
+	}
+
+	var rows []struct {
+		ContenidoID uint `gorm:"column:ContenidoID"`
+		models.MentionEntity
+	}
+	if err := db.Table("Menciones m").
+		Select("m.ContenidoID, m.UsuarioMencionadoID AS user_id, u.NombreUsuario AS nombre_usuario, m.Inicio AS [offset], m.Longitud AS [length]").
+		Joins("INNER JOIN Usuarios u ON u.UsuarioID = m.UsuarioMencionadoID").
+		Where("m.TipoContenido = ? AND m.ContenidoID IN ?", contentType, contentIDs).
+		Order("m.ContenidoID, m.Inicio").
+		Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+
+	for _, row := range rows {
+		entitiesByContent[row.ContenidoID] = append(entitiesByContent[row.ContenidoID], row.MentionEntity)
+	}
+	return entitiesByContent, nil
+}
+
+// attachCommentMentions agrega las menciones a una lista de comentarios
+func attachCommentMentions(db *gorm.DB, comentarios []models.ComentarioCompleto) error {
+	ids := make([]uint, 0, len(comentarios))
+	for _, comentario := range comentarios {
+		ids = append(ids, uint(comentario.ComentarioID))
+	}
+	mentions, err := loadMentionEntities(db, mentionContentComment, ids)
+	if err != nil {
+		return err
+	}
+	for i := range comentarios {
+		comentarios[i].Menciones = mentions[uint(comentarios[i].ComentarioID)]
+	}
+	return nil
+}
+
+// notifyMentions crea una notificación de tipo mention para cada usuario mencionado que la tenga habilitada
+func notifyMentions(db *gorm.DB, wsHandler *WebSocketHandler, broadcaster *SocketIOBroadcaster, userIDs []uint, authorID uint, contentType string, contentID uint, text string) {
+	if len(userIDs) == 0 {
+		return
+	}
+
+	authorName := loadUserDisplayName(db, authorID)
+
+	titulo := "Te mencionaron en un comentario"
+	if contentType == mentionContentMessage {
+		titulo = "Te mencionaron en un mensaje"
+	}
+
+	for _, userID := range userIDs {
+		if !notificationEnabled(db, userID, "mention") {
+			continue
+		}
+		err := createNotification(db, wsHandler, broadcaster, models.Notification{
+			UsuarioID:    userID,
+			Tipo:         "mention",
+			Titulo:       titulo,
+			Contenido:    authorName + " te mencionó: " + text,
+			ReferenciaID: contentID,
+		})
+		logNotificationError("mention", userID, err)
+	}
+}
@@ -3,8 +3,14 @@ package handlers
 import (
 	"encoding/json"
 	"net/http"
+	"os"
+	"skillswap/api/contentfilter"
+	"skillswap/api/markdown"
+	"skillswap/api/middleware"
 	"skillswap/api/models"
+	"skillswap/api/search"
 	"strconv"
+	"strings"
 	"time"
 
 	"gorm.io/gorm"
@@ -14,6 +20,8 @@ type messagesHandler struct {
 	DB                 *gorm.DB
 	WSHandler          *WebSocketHandler
 	SocketIOBroadcaster *SocketIOBroadcaster
+	Searcher           search.Searcher
+	ContentFilter      *contentfilter.Pipeline
 }
 
 func NewMessagesHandler(db *gorm.DB) *messagesHandler {
@@ -28,6 +36,16 @@ func (h *messagesHandler) SetWebSocketHandler(wsHandler *WebSocketHandler) {
 	h.WSHandler = wsHandler
 }
 
+// SetSearcher configura el índice de búsqueda que se actualiza con cada cambio
+func (h *messagesHandler) SetSearcher(searcher search.Searcher) {
+	h.Searcher = searcher
+}
+
+// SetContentFilter configura el filtro automático que revisa el contenido antes de publicarlo
+func (h *messagesHandler) SetContentFilter(filter *contentfilter.Pipeline) {
+	h.ContentFilter = filter
+}
+
 // CreateConversation crea una nueva conversación entre dos usuarios
 func (h *messagesHandler) CreateConversation(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodPost {
@@ -63,6 +81,17 @@ func (h *messagesHandler) CreateConversation(w http.ResponseWriter, r *http.Requ
 		return
 	}
 
+	// Verificar que ninguno de los usuarios haya bloqueado al otro
+	blocked, err := isBlockedBetween(h.DB, req.User1ID, req.User2ID)
+	if err != nil {
+		http.Error(w, "Error verificando bloqueos: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if blocked {
+		http.Error(w, "No es posible iniciar una conversación con este usuario", http.StatusForbidden)
+		return
+	}
+
 	// Verificar si ya existe una conversación entre estos usuarios
 	var existingConversation models.Conversation
 	result := h.DB.Where(
@@ -179,16 +208,22 @@ func (h *messagesHandler) GetUserConversations(w http.ResponseWriter, r *http.Re
 			WHERE DeletedAt IS NULL
 		) m ON c.ID = m.ConversationID AND m.rn = 1
 		LEFT JOIN (
-			SELECT ConversationID, COUNT(*) as UnreadCount
-			FROM Messages
-			WHERE SenderID != ? AND IsRead = 0 AND DeletedAt IS NULL
-			GROUP BY ConversationID
+			SELECT msg.ConversationID, COUNT(*) as UnreadCount
+			FROM MessageReceipts r
+			INNER JOIN Messages msg ON msg.ID = r.MessageID
+			WHERE r.UserID = ? AND r.ReadAt IS NULL AND msg.DeletedAt IS NULL
+			GROUP BY msg.ConversationID
 		) unread ON c.ID = unread.ConversationID
-		WHERE (c.User1ID = ? OR c.User2ID = ?) AND c.DeletedAt IS NULL
+		WHERE (
+			c.User1ID = ? OR c.User2ID = ? OR EXISTS (
+				SELECT 1 FROM ConversationParticipants cp
+				WHERE cp.ConversationID = c.ID AND cp.UserID = ? AND cp.DeletedAt IS NULL
+			)
+		) AND c.DeletedAt IS NULL
 		ORDER BY COALESCE(c.LastMessageAt, c.CreatedAt) DESC
 	`
 
-	if result := h.DB.Raw(query, userID, userID, userID).Scan(&conversations); result.Error != nil {
+	if result := h.DB.Raw(query, userID, userID, userID, userID).Scan(&conversations); result.Error != nil {
 		http.Error(w, "Error obteniendo conversaciones: "+result.Error.Error(), http.StatusInternalServerError)
 		return
 	}
@@ -245,20 +280,39 @@ func (h *messagesHandler) GetConversationMessages(w http.ResponseWriter, r *http
 		return
 	}
 
+	// Cargar los acuses de entrega/lectura de la página en una sola consulta
+	messageIDs := make([]uint, 0, len(messages))
+	for _, message := range messages {
+		messageIDs = append(messageIDs, message.ID)
+	}
+	receipts, err := loadMessageReceipts(h.DB, messageIDs)
+	if err != nil {
+		http.Error(w, "Error obteniendo acuses de lectura: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	// Reacciones agregadas; reacted_by_me solo se calcula si hay un usuario autenticado
+	var viewerID uint
+	if user, authenticated := middleware.GetUserFromContext(r); authenticated {
+		viewerID = user.UserID
+	}
+	reactions, err := loadMessageReactions(h.DB, messageIDs, viewerID)
+	if err != nil {
+		http.Error(w, "Error obteniendo reacciones: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+	mentions, err := loadMentionEntities(h.DB, mentionContentMessage, messageIDs)
+	if err != nil {
+		http.Error(w, "Error obteniendo menciones: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
 	// Convertir a response format
 	var responses []models.MessageResponse
 	for _, message := range messages {
-		responses = append(responses, models.MessageResponse{
-			ID:             message.ID,
-			ConversationID: message.ConversationID,
-			SenderID:       message.SenderID,
-			Content:        message.Content,
-			MessageType:    message.MessageType,
-			IsRead:         message.IsRead,
-			ReadAt:         message.ReadAt,
-			CreatedAt:      message.CreatedAt,
-			UpdatedAt:      message.UpdatedAt,
-		})
+		response := toMessageResponse(message, receipts[message.ID], reactions[message.ID])
+		response.Mentions = mentions[message.ID]
+		responses = append(responses, response)
 	}
 
 	w.Header().Set("Content-Type", "application/json")
@@ -293,6 +347,10 @@ func (h *messagesHandler) SendMessage(w http.ResponseWriter, r *http.Request) {
 		http.Error(w, "El contenido del mensaje es requerido", http.StatusBadRequest)
 		return
 	}
+	if err := markdown.ValidateLength(req.Content, markdown.MaxMessageLength); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
 
 	// Verificar que la conversación existe
 	var conversation models.Conversation
@@ -302,11 +360,32 @@ func (h *messagesHandler) SendMessage(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Verificar que el remitente es participante de la conversación
-	if conversation.User1ID != req.SenderID && conversation.User2ID != req.SenderID {
+	participantIDs, err := conversationParticipantIDs(h.DB, conversation)
+	if err != nil {
+		http.Error(w, "Error obteniendo participantes: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if !containsUserID(participantIDs, req.SenderID) {
 		http.Error(w, "El usuario no es participante de esta conversación", http.StatusForbidden)
 		return
 	}
 
+	// Verificar que ningún destinatario haya bloqueado al remitente (ni viceversa)
+	for _, participantID := range participantIDs {
+		if participantID == req.SenderID {
+			continue
+		}
+		blocked, err := isBlockedBetween(h.DB, req.SenderID, participantID)
+		if err != nil {
+			http.Error(w, "Error verificando bloqueos: "+err.Error(), http.StatusInternalServerError)
+			return
+		}
+		if blocked {
+			http.Error(w, "No es posible enviar mensajes en esta conversación", http.StatusForbidden)
+			return
+		}
+	}
+
 	// Verificar que el remitente existe
 	var sender models.User
 	if result := h.DB.First(&sender, req.SenderID); result.Error != nil {
@@ -326,11 +405,70 @@ func (h *messagesHandler) SendMessage(w http.ResponseWriter, r *http.Request) {
 	if message.MessageType == "" {
 		message.MessageType = "text"
 	}
+	// Solo los mensajes de texto admiten Markdown y pasan por el filtro; en imágenes y archivos Content es una URL
+	var decision contentfilter.Decision
+	if message.MessageType == "text" {
+		message.ContentHTML = markdown.Render(message.Content)
 
-	if result := h.DB.Create(&message); result.Error != nil {
-		http.Error(w, "Error creando mensaje: "+result.Error.Error(), http.StatusInternalServerError)
+		var ok bool
+		decision, ok = checkContent(w, h.ContentFilter, contentfilter.Content{
+			Type:     contentfilter.TypeMessage,
+			AuthorID: req.SenderID,
+			Text:     message.Content,
+		})
+		if !ok {
+			return
+		}
+	}
+
+	// Un mensaje marcado se crea ya oculto (borrado lógico), con su reporte en la misma transacción,
+	// y no se entrega hasta que un moderador lo revise
+	held := decision.Verdict == contentfilter.Flag
+	if held {
+		message.DeletedAt = gorm.DeletedAt{Time: time.Now(), Valid: true}
+	}
+	err = h.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&message).Error; err != nil {
+			return err
+		}
+		if held {
+			return holdForModeration(tx, contentfilter.TypeMessage, message.ID, message.SenderID, decision)
+		}
+		return nil
+	})
+	if err != nil {
+		http.Error(w, "Error creando mensaje: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if held {
+		writeHeldForReview(w, contentfilter.TypeMessage, message.ID, decision)
+		return
+	}
+
+	indexDocument(h.Searcher, search.MessageDocument(message))
+
+	// Crear un acuse pendiente para cada destinatario
+	receipts, err := createMessageReceipts(h.DB, message, participantIDs)
+	if err != nil {
+		http.Error(w, "Error creando acuses del mensaje: "+err.Error(), http.StatusInternalServerError)
 		return
-	}	// Actualizar última actividad de la conversación
+	}
+
+	// Solo se puede mencionar a participantes de la conversación
+	var mentions []models.MentionEntity
+	if message.MessageType == "text" {
+		var mentioned []uint
+		mentions, mentioned, err = saveMentions(h.DB, mentionContentMessage, message.ID, req.SenderID, message.Content, func(userID uint) bool {
+			return containsUserID(participantIDs, userID)
+		})
+		if err != nil {
+			http.Error(w, "Error guardando menciones: "+err.Error(), http.StatusInternalServerError)
+			return
+		}
+		notifyMentions(h.DB, h.WSHandler, h.SocketIOBroadcaster, mentioned, req.SenderID, mentionContentMessage, message.ID, message.Content)
+	}
+
+	// Actualizar última actividad de la conversación
 	now := time.Now()
 	h.DB.Model(&conversation).Update("LastMessageAt", now)
 
@@ -341,26 +479,24 @@ func (h *messagesHandler) SendMessage(w http.ResponseWriter, r *http.Request) {
 			"conversation_id": message.ConversationID,
 			"sender_id":       message.SenderID,
 			"content":         message.Content,
+			"content_html":    message.ContentHTML,
 			"message_type":    message.MessageType,
 			"is_read":         message.IsRead,
 			"read_at":         message.ReadAt,
 			"created_at":      message.CreatedAt,
 			"updated_at":      message.UpdatedAt,
+			"mentions":        mentions,
 		})
 	}
 
-	response := models.MessageResponse{
-		ID:             message.ID,
-		ConversationID: message.ConversationID,
-		SenderID:       message.SenderID,
-		Content:        message.Content,
-		MessageType:    message.MessageType,
-		IsRead:         message.IsRead,
-		ReadAt:         message.ReadAt,
-		CreatedAt:      message.CreatedAt,
-		UpdatedAt:      message.UpdatedAt,
+	// Enviar por WebSocket para que los clientes conectados confirmen la entrega
+	if h.WSHandler != nil {
+		h.WSHandler.BroadcastNewMessage(message)
 	}
 
+	response := toMessageResponse(message, receipts, nil)
+	response.Mentions = mentions
+
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusCreated)
 	json.NewEncoder(w).Encode(response)
@@ -406,20 +542,20 @@ func (h *messagesHandler) MarkMessageAsRead(w http.ResponseWriter, r *http.Reque
 		return
 	}
 
-	if conversation.User1ID != req.UserID && conversation.User2ID != req.UserID {
+	participantIDs, err := conversationParticipantIDs(h.DB, conversation)
+	if err != nil {
+		http.Error(w, "Error obteniendo participantes: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if !containsUserID(participantIDs, req.UserID) {
 		http.Error(w, "El usuario no es participante de esta conversación", http.StatusForbidden)
 		return
 	}
 
-	// Marcar como leído
+	// Marcar como leído solo para este participante
 	now := time.Now()
-	updates := map[string]interface{}{
-		"IsRead": true,
-		"ReadAt": now,
-	}
-
-	if result := h.DB.Model(&message).Updates(updates); result.Error != nil {
-		http.Error(w, "Error actualizando mensaje: "+result.Error.Error(), http.StatusInternalServerError)
+	if err := markReceiptsAsRead(h.DB, []uint{message.ID}, req.UserID, now); err != nil {
+		http.Error(w, "Error actualizando mensaje: "+err.Error(), http.StatusInternalServerError)
 		return
 	}
 
@@ -428,6 +564,8 @@ func (h *messagesHandler) MarkMessageAsRead(w http.ResponseWriter, r *http.Reque
 		Where("ConversationID = ? AND UserID = ?", message.ConversationID, req.UserID).
 		Update("LastReadAt", now)
 
+	h.broadcastMessagesRead(message.ConversationID, req.UserID, message.ID, now)
+
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusOK)
 	json.NewEncoder(w).Encode(map[string]string{"message": "Mensaje marcado como leído"})
@@ -460,24 +598,31 @@ func (h *messagesHandler) MarkConversationAsRead(w http.ResponseWriter, r *http.
 	}
 
 	// Verificar que el usuario es participante
-	if conversation.User1ID != req.UserID && conversation.User2ID != req.UserID {
+	participantIDs, err := conversationParticipantIDs(h.DB, conversation)
+	if err != nil {
+		http.Error(w, "Error obteniendo participantes: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if !containsUserID(participantIDs, req.UserID) {
 		http.Error(w, "El usuario no es participante de esta conversación", http.StatusForbidden)
 		return
 	}
 
-	// Marcar todos los mensajes no leídos como leídos (excepto los propios)
-	now := time.Now()
-	updates := map[string]interface{}{
-		"IsRead": true,
-		"ReadAt": now,
+	// Buscar los mensajes que este participante aún no ha leído
+	var unreadIDs []uint
+	if err := h.DB.Model(&models.MessageReceipt{}).
+		Joins("INNER JOIN Messages ON Messages.ID = MessageReceipts.MessageID").
+		Where("Messages.ConversationID = ? AND MessageReceipts.UserID = ? AND MessageReceipts.ReadAt IS NULL AND Messages.DeletedAt IS NULL", conversationID, req.UserID).
+		Order("MessageReceipts.MessageID ASC").
+		Pluck("MessageReceipts.MessageID", &unreadIDs).Error; err != nil {
+		http.Error(w, "Error obteniendo mensajes no leídos: "+err.Error(), http.StatusInternalServerError)
+		return
 	}
 
-	result := h.DB.Model(&models.Message{}).
-		Where("ConversationID = ? AND SenderID != ? AND IsRead = ?", conversationID, req.UserID, false).
-		Updates(updates)
-
-	if result.Error != nil {
-		http.Error(w, "Error actualizando mensajes: "+result.Error.Error(), http.StatusInternalServerError)
+	// Marcar todos los mensajes no leídos como leídos (excepto los propios)
+	now := time.Now()
+	if err := markReceiptsAsRead(h.DB, unreadIDs, req.UserID, now); err != nil {
+		http.Error(w, "Error actualizando mensajes: "+err.Error(), http.StatusInternalServerError)
 		return
 	}
 
@@ -486,11 +631,15 @@ func (h *messagesHandler) MarkConversationAsRead(w http.ResponseWriter, r *http.
 		Where("ConversationID = ? AND UserID = ?", conversationID, req.UserID).
 		Update("LastReadAt", now)
 
+	if len(unreadIDs) > 0 {
+		h.broadcastMessagesRead(conversationID, req.UserID, unreadIDs[len(unreadIDs)-1], now)
+	}
+
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusOK)
 	json.NewEncoder(w).Encode(map[string]interface{}{
 		"message":        "Conversación marcada como leída",
-		"messagesUpdated": result.RowsAffected,
+		"messagesUpdated": len(unreadIDs),
 	})
 }
 
@@ -528,3 +677,341 @@ func (h *messagesHandler) GetConversation(w http.ResponseWriter, r *http.Request
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(response)
 }
+
+// ToggleMessageReaction agrega la reacción del usuario autenticado a un mensaje o la quita si ya existía
+func (h *messagesHandler) ToggleMessageReaction(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Método no permitido", http.StatusMethodNotAllowed)
+		return
+	}
+
+	user, authenticated := middleware.GetUserFromContext(r)
+	if !authenticated {
+		http.Error(w, "Autenticación requerida para reaccionar a mensajes", http.StatusUnauthorized)
+		return
+	}
+
+	messageID, err := parseIDFromPath(r, "messageID")
+	if err != nil {
+		http.Error(w, "ID de mensaje inválido", http.StatusBadRequest)
+		return
+	}
+
+	var req models.ToggleReactionRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Error decodificando JSON: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	req.Emoji = strings.TrimSpace(req.Emoji)
+	if !isAllowedReactionEmoji(req.Emoji) {
+		http.Error(w, "Emoji no permitido", http.StatusBadRequest)
+		return
+	}
+
+	var message models.Message
+	if result := h.DB.Preload("Conversation").First(&message, messageID); result.Error != nil {
+		http.Error(w, "Mensaje no encontrado", http.StatusNotFound)
+		return
+	}
+
+	// Solo los participantes de la conversación pueden reaccionar
+	participantIDs, err := conversationParticipantIDs(h.DB, message.Conversation)
+	if err != nil {
+		http.Error(w, "Error obteniendo participantes: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if !containsUserID(participantIDs, user.UserID) {
+		http.Error(w, "El usuario no es participante de esta conversación", http.StatusForbidden)
+		return
+	}
+
+	// Alternar la reacción: si ya existe se elimina, si no se crea
+	action := "added"
+	var existing models.MessageReaction
+	result := h.DB.Where("MessageID = ? AND UserID = ? AND Emoji = ?", message.ID, user.UserID, req.Emoji).First(&existing)
+	switch {
+	case result.Error == nil:
+		if err := h.DB.Delete(&existing).Error; err != nil {
+			http.Error(w, "Error eliminando reacción: "+err.Error(), http.StatusInternalServerError)
+			return
+		}
+		action = "removed"
+	case result.Error == gorm.ErrRecordNotFound:
+		reaction := models.MessageReaction{MessageID: message.ID, UserID: user.UserID, Emoji: req.Emoji}
+		if err := h.DB.Create(&reaction).Error; err != nil {
+			http.Error(w, "Error creando reacción: "+err.Error(), http.StatusInternalServerError)
+			return
+		}
+	default:
+		http.Error(w, "Error consultando reacción: "+result.Error.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	reactions, err := loadMessageReactions(h.DB, []uint{message.ID}, user.UserID)
+	if err != nil {
+		http.Error(w, "Error obteniendo reacciones: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	h.broadcastReaction(message, user.UserID, req.Emoji, action, reactions[message.ID])
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"message_id": message.ID,
+		"emoji":      req.Emoji,
+		"action":     action,
+		"reactions":  reactionsOrEmpty(reactions[message.ID]),
+	})
+}
+
+// GetAllowedReactions devuelve la lista de emoji permitidos para reaccionar
+func (h *messagesHandler) GetAllowedReactions(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Método no permitido", http.StatusMethodNotAllowed)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"emojis": allowedReactionEmojis(),
+	})
+}
+
+// broadcastReaction notifica a la sala de la conversación que se agregó o quitó una reacción
+func (h *messagesHandler) broadcastReaction(message models.Message, userID uint, emoji, action string, reactions []models.MessageReactionSummary) {
+	eventName := "reaction_added"
+	if action == "removed" {
+		eventName = "reaction_removed"
+	}
+
+	// Los totales se envían sin reacted_by_me, que depende de quién recibe el evento
+	counts := make([]map[string]interface{}, 0, len(reactions))
+	for _, reaction := range reactions {
+		counts = append(counts, map[string]interface{}{
+			"emoji": reaction.Emoji,
+			"count": reaction.Count,
+		})
+	}
+	data := map[string]interface{}{
+		"message_id":      message.ID,
+		"conversation_id": message.ConversationID,
+		"user_id":         userID,
+		"emoji":           emoji,
+		"reactions":       counts,
+	}
+
+	if h.SocketIOBroadcaster != nil {
+		h.SocketIOBroadcaster.BroadcastMessageReaction(message.ConversationID, eventName, data)
+	}
+	if h.WSHandler != nil {
+		h.WSHandler.BroadcastMessageReaction(message.ConversationID, eventName, data)
+	}
+}
+
+// broadcastMessagesRead notifica a la sala de la conversación que un participante leyó mensajes
+func (h *messagesHandler) broadcastMessagesRead(conversationID, userID, lastReadMessageID uint, readAt time.Time) {
+	data := map[string]interface{}{
+		"conversation_id":      conversationID,
+		"user_id":              userID,
+		"last_read_message_id": lastReadMessageID,
+		"read_at":              readAt,
+	}
+
+	if h.SocketIOBroadcaster != nil {
+		h.SocketIOBroadcaster.BroadcastMessagesRead(conversationID, data)
+	}
+	if h.WSHandler != nil {
+		h.WSHandler.BroadcastMessagesRead(conversationID, data)
+	}
+}
+
+// conversationParticipantIDs devuelve los IDs de los participantes activos de una conversación.
+// Si la conversación no tiene filas en ConversationParticipants se usan User1ID y User2ID.
+func conversationParticipantIDs(db *gorm.DB, conversation models.Conversation) ([]uint, error) {
+	var userIDs []uint
+	if err := db.Model(&models.ConversationParticipant{}).
+		Where("ConversationID = ?", conversation.ID).
+		Pluck("UserID", &userIDs).Error; err != nil {
+		return nil, err
+	}
+
+	if len(userIDs) == 0 {
+		userIDs = []uint{conversation.User1ID, conversation.User2ID}
+	}
+	return userIDs, nil
+}
+
+// containsUserID indica si el ID de usuario está en la lista
+func containsUserID(userIDs []uint, userID uint) bool {
+	for _, id := range userIDs {
+		if id == userID {
+			return true
+		}
+	}
+	return false
+}
+
+// createMessageReceipts crea un acuse pendiente por cada destinatario del mensaje
+func createMessageReceipts(db *gorm.DB, message models.Message, participantIDs []uint) ([]models.MessageReceiptResponse, error) {
+	var receipts []models.MessageReceipt
+	for _, userID := range participantIDs {
+		if userID == message.SenderID {
+			continue
+		}
+		receipts = append(receipts, models.MessageReceipt{
+			MessageID: message.ID,
+			UserID:    userID,
+		})
+	}
+
+	if len(receipts) == 0 {
+		return nil, nil
+	}
+	if err := db.Create(&receipts).Error; err != nil {
+		return nil, err
+	}
+
+	responses := make([]models.MessageReceiptResponse, 0, len(receipts))
+	for _, receipt := range receipts {
+		responses = append(responses, models.MessageReceiptResponse{UserID: receipt.UserID})
+	}
+	return responses, nil
+}
+
+// loadMessageReceipts obtiene los acuses de un conjunto de mensajes agrupados por mensaje
+func loadMessageReceipts(db *gorm.DB, messageIDs []uint) (map[uint][]models.MessageReceiptResponse, error) {
+	result := make(map[uint][]models.MessageReceiptResponse)
+	if len(messageIDs) == 0 {
+		return result, nil
+	}
+
+	var receipts []models.MessageReceipt
+	if err := db.Where("MessageID IN ?", messageIDs).Find(&receipts).Error; err != nil {
+		return nil, err
+	}
+
+	for _, receipt := range receipts {
+		result[receipt.MessageID] = append(result[receipt.MessageID], models.MessageReceiptResponse{
+			UserID:      receipt.UserID,
+			DeliveredAt: receipt.DeliveredAt,
+			ReadAt:      receipt.ReadAt,
+		})
+	}
+	return result, nil
+}
+
+// markReceiptsAsRead marca como leídos (y entregados) los acuses de un usuario y
+// actualiza IsRead en los mensajes que ya leyeron todos sus destinatarios
+func markReceiptsAsRead(db *gorm.DB, messageIDs []uint, userID uint, readAt time.Time) error {
+	if len(messageIDs) == 0 {
+		return nil
+	}
+
+	return db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&models.MessageReceipt{}).
+			Where("MessageID IN ? AND UserID = ? AND ReadAt IS NULL", messageIDs, userID).
+			Updates(map[string]interface{}{
+				"ReadAt":      readAt,
+				"DeliveredAt": gorm.Expr("COALESCE(DeliveredAt, ?)", readAt),
+			}).Error; err != nil {
+			return err
+		}
+
+		return tx.Model(&models.Message{}).
+			Where("ID IN ? AND IsRead = ?", messageIDs, false).
+			Where("NOT EXISTS (SELECT 1 FROM MessageReceipts r WHERE r.MessageID = Messages.ID AND r.ReadAt IS NULL)").
+			Updates(map[string]interface{}{
+				"IsRead": true,
+				"ReadAt": readAt,
+			}).Error
+	})
+}
+
+// Emoji permitidos por defecto; se pueden reemplazar con MESSAGE_REACTION_EMOJIS (separados por comas)
+var defaultReactionEmojis = []string{"👍", "❤️", "😂", "😮", "😢", "🙏", "🎉"}
+
+// allowedReactionEmojis devuelve la lista de emoji configurada para reaccionar a mensajes
+func allowedReactionEmojis() []string {
+	configured := os.Getenv("MESSAGE_REACTION_EMOJIS")
+	if configured == "" {
+		return defaultReactionEmojis
+	}
+
+	var emojis []string
+	for _, emoji := range strings.Split(configured, ",") {
+		if emoji = strings.TrimSpace(emoji); emoji != "" {
+			emojis = append(emojis, emoji)
+		}
+	}
+	if len(emojis) == 0 {
+		return defaultReactionEmojis
+	}
+	return emojis
+}
+
+// isAllowedReactionEmoji indica si el emoji está en la lista configurada
+func isAllowedReactionEmoji(emoji string) bool {
+	for _, allowed := range allowedReactionEmojis() {
+		if allowed == emoji {
+			return true
+		}
+	}
+	return false
+}
+
+// loadMessageReactions obtiene las reacciones agregadas por emoji de un conjunto de mensajes.
+// reacted_by_me se calcula para viewerID (0 si no hay usuario autenticado).
+func loadMessageReactions(db *gorm.DB, messageIDs []uint, viewerID uint) (map[uint][]models.MessageReactionSummary, error) {
+	reactionsByMessage := make(map[uint][]models.MessageReactionSummary)
+	if len(messageIDs) == 0 {
+		return reactionsByMessage, nil
+	}
+
+	var rows []struct {
+		MessageID uint `gorm:"column:MessageID"`
+		models.MessageReactionSummary
+	}
+	if err := db.Model(&models.MessageReaction{}).
+		Select("MessageID, Emoji, COUNT(*) AS Count, MAX(CASE WHEN UserID = ? THEN 1 ELSE 0 END) AS ReactedByMe", viewerID).
+		Where("MessageID IN ?", messageIDs).
+		Group("MessageID, Emoji").
+		Order("MessageID, MIN(CreatedAt)").
+		Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+
+	for _, row := range rows {
+		reactionsByMessage[row.MessageID] = append(reactionsByMessage[row.MessageID], row.MessageReactionSummary)
+	}
+	return reactionsByMessage, nil
+}
+
+// reactionsOrEmpty evita serializar null cuando un mensaje se queda sin reacciones
+func reactionsOrEmpty(reactions []models.MessageReactionSummary) []models.MessageReactionSummary {
+	if reactions == nil {
+		return []models.MessageReactionSummary{}
+	}
+	return reactions
+}
+
+// toMessageResponse convierte un mensaje, sus acuses y sus reacciones al formato de respuesta
+func toMessageResponse(message models.Message, receipts []models.MessageReceiptResponse, reactions []models.MessageReactionSummary) models.MessageResponse {
+	// Los mensajes guardados antes de existir ContentHTML se renderizan al vuelo
+	if message.ContentHTML == "" && message.MessageType == "text" {
+		message.ContentHTML = markdown.Render(message.Content)
+	}
+	return models.MessageResponse{
+		ID:             message.ID,
+		ConversationID: message.ConversationID,
+		SenderID:       message.SenderID,
+		Content:        message.Content,
+		ContentHTML:    message.ContentHTML,
+		MessageType:    message.MessageType,
+		IsRead:         message.IsRead,
+		ReadAt:         message.ReadAt,
+		Receipts:       receipts,
+		Reactions:      reactions,
+		CreatedAt:      message.CreatedAt,
+		UpdatedAt:      message.UpdatedAt,
+	}
+}
@@ -0,0 +1,849 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"skillswap/api/middleware"
+	"skillswap/api/models"
+	"skillswap/api/search"
+
+	"gorm.io/gorm"
+)
+
+// Estados de un reporte
+const (
+	reportStatusPending   = "pending"
+	reportStatusResolved  = "resolved"
+	reportStatusDismissed = "dismissed"
+)
+
+// Acciones de moderación; auto_hide solo la registra el sistema al superar el umbral de reportes
+const (
+	moderationActionDismiss  = "dismiss"
+	moderationActionHide     = "hide"
+	moderationActionWarn     = "warn"
+	moderationActionSuspend  = "suspend"
+	moderationActionBan      = "ban"
+	moderationActionUnban    = "unban"
+	moderationActionAutoHide = "auto_hide"
+)
+
+// Motivos aceptados al reportar contenido
+var reportReasons = []string{"spam", "harassment", "hate_speech", "inappropriate", "misinformation", "impersonation", "other"}
+
+// Roles que pueden acceder a la cola de moderación
+var moderatorRoles = []string{"admin", "moderator"}
+
+const (
+	defaultAutoHideThreshold = 3
+	defaultSuspensionDays    = 7
+	maxSuspensionDays        = 365
+	maxReportTextLength      = 1000
+)
+
+type moderationHandler struct {
+	DB                  *gorm.DB
+	WSHandler           *WebSocketHandler
+	SocketIOBroadcaster *SocketIOBroadcaster
+	Searcher            search.Searcher
+}
+
+func NewModerationHandler(db *gorm.DB) *moderationHandler {
+	return &moderationHandler{
+		DB:                  db,
+		SocketIOBroadcaster: NewSocketIOBroadcaster(),
+	}
+}
+
+// SetWebSocketHandler configura el handler de WebSocket para notificar a los usuarios sancionados
+func (h *moderationHandler) SetWebSocketHandler(wsHandler *WebSocketHandler) {
+	h.WSHandler = wsHandler
+}
+
+// SetSearcher configura el índice de búsqueda del que se retira el contenido oculto
+func (h *moderationHandler) SetSearcher(searcher search.Searcher) {
+	h.Searcher = searcher
+}
+
+// CreateReport registra el reporte de un usuario sobre un post, comentario, mensaje o usuario
+func (h *moderationHandler) CreateReport(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Método no permitido", http.StatusMethodNotAllowed)
+		return
+	}
+
+	user, authenticated := middleware.GetUserFromContext(r)
+	if !authenticated {
+		http.Error(w, "Autenticación requerida para reportar contenido", http.StatusUnauthorized)
+		return
+	}
+
+	var req models.CrearReporteRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Error decodificando JSON: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	req.TipoContenido = strings.ToLower(strings.TrimSpace(req.TipoContenido))
+	req.Motivo = strings.ToLower(strings.TrimSpace(req.Motivo))
+	req.Descripcion = strings.TrimSpace(req.Descripcion)
+	if !search.ValidType(req.TipoContenido) {
+		http.Error(w, "Tipo de contenido inválido: use post, comment, message o user", http.StatusBadRequest)
+		return
+	}
+	if req.ContenidoID == 0 {
+		http.Error(w, "ID del contenido es requerido", http.StatusBadRequest)
+		return
+	}
+	if !containsString(reportReasons, req.Motivo) {
+		http.Error(w, "Motivo inválido: use "+strings.Join(reportReasons, ", "), http.StatusBadRequest)
+		return
+	}
+	if len([]rune(req.Descripcion)) > maxReportTextLength {
+		http.Error(w, fmt.Sprintf("La descripción no puede superar los %d caracteres", maxReportTextLength), http.StatusBadRequest)
+		return
+	}
+
+	authorID, err := reportedContentAuthor(h.DB, req.TipoContenido, req.ContenidoID, user.UserID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			http.Error(w, "Contenido no encontrado", http.StatusNotFound)
+		} else {
+			http.Error(w, "Error buscando el contenido: "+err.Error(), http.StatusInternalServerError)
+		}
+		return
+	}
+	if authorID == user.UserID {
+		http.Error(w, "No puedes reportar tu propio contenido", http.StatusBadRequest)
+		return
+	}
+
+	var existing int64
+	if err := h.DB.Model(&models.Reporte{}).
+		Where("TipoContenido = ? AND ContenidoID = ? AND ReportanteID = ?", req.TipoContenido, req.ContenidoID, user.UserID).
+		Count(&existing).Error; err != nil {
+		http.Error(w, "Error verificando reportes: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if existing > 0 {
+		http.Error(w, "Ya reportaste este contenido", http.StatusConflict)
+		return
+	}
+
+	reporte := models.Reporte{
+		TipoContenido:      req.TipoContenido,
+		ContenidoID:        req.ContenidoID,
+		UsuarioReportadoID: &authorID,
+		ReportanteID:       &user.UserID,
+		Origen:             reportOriginUser,
+		Motivo:             req.Motivo,
+		Descripcion:        req.Descripcion,
+		Estado:             reportStatusPending,
+	}
+	if err := h.DB.Create(&reporte).Error; err != nil {
+		http.Error(w, "Error creando el reporte: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	if err := h.autoHideIfNeeded(reporte); err != nil {
+		log.Printf("Error en el ocultamiento automático de %s %d: %v", reporte.TipoContenido, reporte.ContenidoID, err)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(reporte)
+}
+
+// autoHideIfNeeded oculta el contenido cuando acumula el número configurado de reportantes distintos.
+// Solo cuentan los reportes de usuarios: el filtro automático por sí solo no alcanza el umbral.
+func (h *moderationHandler) autoHideIfNeeded(reporte models.Reporte) error {
+	if reporte.TipoContenido == search.TypeUser {
+		// Los usuarios no se ocultan automáticamente; la sanción la decide un moderador
+		return nil
+	}
+
+	var pending int64
+	if err := h.DB.Model(&models.Reporte{}).
+		Where("TipoContenido = ? AND ContenidoID = ? AND Estado = ? AND ReportanteID IS NOT NULL",
+			reporte.TipoContenido, reporte.ContenidoID, reportStatusPending).
+		Count(&pending).Error; err != nil {
+		return err
+	}
+	if pending < int64(autoHideThreshold()) {
+		return nil
+	}
+
+	hidden := false
+	err := h.DB.Transaction(func(tx *gorm.DB) error {
+		changed, err := hideReportedContent(tx, reporte.TipoContenido, reporte.ContenidoID)
+		if err != nil || !changed {
+			return err
+		}
+		hidden = true
+		return recordModerationAction(tx, models.AccionModeracion{
+			Accion:            moderationActionAutoHide,
+			TipoContenido:     reporte.TipoContenido,
+			ContenidoID:       reporte.ContenidoID,
+			UsuarioAfectadoID: reporte.UsuarioReportadoID,
+			ReporteID:         &reporte.ReporteID,
+			Nota:              fmt.Sprintf("Ocultado automáticamente tras %d reportes", pending),
+		})
+	})
+	// El índice solo se actualiza si el ocultamiento se confirmó
+	if err == nil && hidden {
+		unindexDocument(h.Searcher, reporte.TipoContenido, reporte.ContenidoID)
+	}
+	return err
+}
+
+// GetReportQueue devuelve la cola de reportes para moderadores, con filtros y paginación
+func (h *moderationHandler) GetReportQueue(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Método no permitido", http.StatusMethodNotAllowed)
+		return
+	}
+
+	if _, ok := h.authorizeModerator(w, r); !ok {
+		return
+	}
+
+	query := r.URL.Query()
+	page, err := strconv.Atoi(query.Get("page"))
+	if err != nil || page < 1 {
+		page = 1
+	}
+	pageSize, err := strconv.Atoi(query.Get("pageSize"))
+	if err != nil || pageSize <= 0 || pageSize > 100 {
+		pageSize = 20
+	}
+
+	where := []string{"1=1"}
+	var args []interface{}
+
+	// Por defecto solo se muestran los pendientes; estado=all muestra todos
+	estado := query.Get("estado")
+	if estado == "" {
+		estado = reportStatusPending
+	}
+	if estado != "all" {
+		where = append(where, "r.Estado = ?")
+		args = append(args, estado)
+	}
+	if tipo := query.Get("tipo"); tipo != "" {
+		where = append(where, "r.TipoContenido = ?")
+		args = append(args, tipo)
+	}
+	if motivo := query.Get("motivo"); motivo != "" {
+		where = append(where, "r.Motivo = ?")
+		args = append(args, motivo)
+	}
+	if origen := query.Get("origen"); origen != "" {
+		where = append(where, "r.Origen = ?")
+		args = append(args, origen)
+	}
+	if contenidoID, err := strconv.Atoi(query.Get("contenido_id")); err == nil {
+		where = append(where, "r.ContenidoID = ?")
+		args = append(args, contenidoID)
+	}
+	if reportadoID, err := strconv.Atoi(query.Get("usuario_reportado_id")); err == nil {
+		where = append(where, "r.UsuarioReportadoID = ?")
+		args = append(args, reportadoID)
+	}
+	whereClause := strings.Join(where, " AND ")
+
+	var total int64
+	if err := h.DB.Raw("SELECT COUNT(*) FROM Reportes r WHERE "+whereClause, args...).Scan(&total).Error; err != nil {
+		http.Error(w, "Error contando reportes: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	// Los contenidos con más reportes aparecen primero; a igualdad, los más antiguos
+	reportes := []models.ReporteCola{}
+	pageArgs := append(append([]interface{}{}, args...), (page-1)*pageSize, pageSize)
+	if err := h.DB.Raw(`
+		SELECT r.*, ISNULL(u.NombreUsuario, '') AS NombreReportante,
+			(SELECT COUNT(*) FROM Reportes r2
+				WHERE r2.TipoContenido = r.TipoContenido AND r2.ContenidoID = r.ContenidoID) AS TotalReportesContenido,
+			LEFT(CASE r.TipoContenido
+				WHEN 'post' THEN p.Descripcion
+				WHEN 'comment' THEN c.Contenido
+				WHEN 'message' THEN CAST(m.Content AS NVARCHAR(MAX))
+				WHEN 'user' THEN ru.NombreUsuario
+			END, 200) AS VistaPrevia
+		FROM Reportes r
+		LEFT JOIN Usuarios u ON u.UsuarioID = r.ReportanteID
+		LEFT JOIN Posts p ON r.TipoContenido = 'post' AND p.PostID = r.ContenidoID
+		LEFT JOIN Comentarios c ON r.TipoContenido = 'comment' AND c.ComentarioID = r.ContenidoID
+		LEFT JOIN Messages m ON r.TipoContenido = 'message' AND m.ID = r.ContenidoID
+		LEFT JOIN Usuarios ru ON r.TipoContenido = 'user' AND ru.UsuarioID = r.ContenidoID
+		WHERE `+whereClause+`
+		ORDER BY TotalReportesContenido DESC, r.FechaCreacion ASC
+		OFFSET ? ROWS FETCH NEXT ? ROWS ONLY`, pageArgs...).
+		Scan(&reportes).Error; err != nil {
+		http.Error(w, "Error obteniendo reportes: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	response := models.ColaModeracionResponse{
+		Reportes:   reportes,
+		Total:      total,
+		Page:       page,
+		PageSize:   pageSize,
+		TotalPages: int(math.Ceil(float64(total) / float64(pageSize))),
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
+
+// ResolveReport aplica una acción de moderación a un reporte pendiente y la registra en la auditoría
+func (h *moderationHandler) ResolveReport(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Método no permitido", http.StatusMethodNotAllowed)
+		return
+	}
+
+	moderatorID, ok := h.authorizeModerator(w, r)
+	if !ok {
+		return
+	}
+
+	reportID, err := parseIDFromPath(r, "reportID")
+	if err != nil {
+		http.Error(w, "ID de reporte inválido", http.StatusBadRequest)
+		return
+	}
+
+	var req models.AccionModeracionRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Error decodificando JSON: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	req.Accion = strings.ToLower(strings.TrimSpace(req.Accion))
+	req.Nota = strings.TrimSpace(req.Nota)
+	if len([]rune(req.Nota)) > maxReportTextLength {
+		http.Error(w, fmt.Sprintf("La nota no puede superar los %d caracteres", maxReportTextLength), http.StatusBadRequest)
+		return
+	}
+
+	var reporte models.Reporte
+	if result := h.DB.First(&reporte, reportID); result.Error != nil {
+		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+			http.Error(w, "Reporte no encontrado", http.StatusNotFound)
+		} else {
+			http.Error(w, "Error buscando el reporte: "+result.Error.Error(), http.StatusInternalServerError)
+		}
+		return
+	}
+	if reporte.Estado != reportStatusPending {
+		http.Error(w, "El reporte ya fue resuelto", http.StatusConflict)
+		return
+	}
+
+	var affectedID uint
+	if reporte.UsuarioReportadoID != nil {
+		affectedID = *reporte.UsuarioReportadoID
+	}
+
+	var suspendedUntil time.Time
+	switch req.Accion {
+	case moderationActionDismiss, moderationActionWarn, moderationActionBan:
+	case moderationActionHide:
+		if reporte.TipoContenido == search.TypeUser {
+			http.Error(w, "Un usuario no se puede ocultar: use suspend o ban", http.StatusBadRequest)
+			return
+		}
+	case moderationActionSuspend:
+		if req.Dias == 0 {
+			req.Dias = defaultSuspensionDays
+		}
+		if req.Dias < 1 || req.Dias > maxSuspensionDays {
+			http.Error(w, fmt.Sprintf("La suspensión debe durar entre 1 y %d días", maxSuspensionDays), http.StatusBadRequest)
+			return
+		}
+		suspendedUntil = time.Now().AddDate(0, 0, req.Dias)
+	default:
+		http.Error(w, "Acción inválida: use dismiss, hide, warn, suspend o ban", http.StatusBadRequest)
+		return
+	}
+	if req.Accion != moderationActionDismiss && affectedID == 0 {
+		http.Error(w, "El reporte no tiene un usuario asociado", http.StatusBadRequest)
+		return
+	}
+
+	now := time.Now()
+	restored := false
+	err = h.DB.Transaction(func(tx *gorm.DB) error {
+		switch req.Accion {
+		case moderationActionHide:
+			if _, err := hideReportedContent(tx, reporte.TipoContenido, reporte.ContenidoID); err != nil {
+				return err
+			}
+		case moderationActionSuspend:
+			if err := tx.Model(&models.User{}).Where("UsuarioID = ?", affectedID).
+				Update("SuspendidoHasta", suspendedUntil).Error; err != nil {
+				return err
+			}
+		case moderationActionBan:
+			if err := tx.Model(&models.User{}).Where("UsuarioID = ?", affectedID).
+				Update("IsBanned", true).Error; err != nil {
+				return err
+			}
+		}
+
+		// Descartar cierra solo este reporte; cualquier sanción resuelve todos los pendientes del contenido
+		estado := reportStatusResolved
+		resolve := tx.Model(&models.Reporte{}).Where("Estado = ?", reportStatusPending)
+		if req.Accion == moderationActionDismiss {
+			estado = reportStatusDismissed
+			resolve = resolve.Where("ReporteID = ?", reporte.ReporteID)
+		} else {
+			resolve = resolve.Where("TipoContenido = ? AND ContenidoID = ?", reporte.TipoContenido, reporte.ContenidoID)
+		}
+		updates := map[string]interface{}{
+			"Estado":          estado,
+			"Accion":          req.Accion,
+			"ModeradorID":     moderatorID,
+			"FechaResolucion": now,
+		}
+		if req.Nota != "" {
+			updates["NotaResolucion"] = req.Nota
+		}
+		if err := resolve.Updates(updates).Error; err != nil {
+			return err
+		}
+
+		// Al descartar, el contenido retenido por el filtro u ocultado automáticamente vuelve a publicarse
+		if req.Accion == moderationActionDismiss {
+			var err error
+			if restored, err = restoreAutoHiddenContent(tx, reporte.TipoContenido, reporte.ContenidoID); err != nil {
+				return err
+			}
+		}
+
+		action := models.AccionModeracion{
+			ModeradorID:   &moderatorID,
+			Accion:        req.Accion,
+			TipoContenido: reporte.TipoContenido,
+			ContenidoID:   reporte.ContenidoID,
+			ReporteID:     &reporte.ReporteID,
+			Nota:          req.Nota,
+		}
+		if affectedID != 0 {
+			action.UsuarioAfectadoID = &affectedID
+		}
+		return recordModerationAction(tx, action)
+	})
+	if err != nil {
+		http.Error(w, "Error aplicando la acción de moderación: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	if req.Accion == moderationActionHide {
+		unindexDocument(h.Searcher, reporte.TipoContenido, reporte.ContenidoID)
+	}
+	if restored {
+		indexReportedContent(h.DB, h.Searcher, reporte.TipoContenido, reporte.ContenidoID)
+		h.alertSavedSearches(reporte.TipoContenido, reporte.ContenidoID)
+	}
+	h.notifySanctionedUser(affectedID, req.Accion, reporte, suspendedUntil)
+
+	h.DB.First(&reporte, reporte.ReporteID)
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(reporte)
+}
+
+// GetModerationActions devuelve el registro de auditoría de moderación
+func (h *moderationHandler) GetModerationActions(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Método no permitido", http.StatusMethodNotAllowed)
+		return
+	}
+
+	if _, ok := h.authorizeModerator(w, r); !ok {
+		return
+	}
+
+	query := r.URL.Query()
+	page, err := strconv.Atoi(query.Get("page"))
+	if err != nil || page < 1 {
+		page = 1
+	}
+	pageSize, err := strconv.Atoi(query.Get("pageSize"))
+	if err != nil || pageSize <= 0 || pageSize > 100 {
+		pageSize = 50
+	}
+
+	db := h.DB.Model(&models.AccionModeracion{})
+	if tipo := query.Get("tipo"); tipo != "" {
+		db = db.Where("TipoContenido = ?", tipo)
+	}
+	if contenidoID, err := strconv.Atoi(query.Get("contenido_id")); err == nil {
+		db = db.Where("ContenidoID = ?", contenidoID)
+	}
+	if accion := query.Get("accion"); accion != "" {
+		db = db.Where("Accion = ?", accion)
+	}
+	if moderadorID, err := strconv.Atoi(query.Get("moderador_id")); err == nil {
+		db = db.Where("ModeradorID = ?", moderadorID)
+	}
+	if usuarioID, err := strconv.Atoi(query.Get("usuario_afectado_id")); err == nil {
+		db = db.Where("UsuarioAfectadoID = ?", usuarioID)
+	}
+
+	var total int64
+	if err := db.Count(&total).Error; err != nil {
+		http.Error(w, "Error contando acciones: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	acciones := []models.AccionModeracion{}
+	if err := db.Order("FechaCreacion DESC").Limit(pageSize).Offset((page - 1) * pageSize).Find(&acciones).Error; err != nil {
+		http.Error(w, "Error obteniendo acciones: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"acciones":    acciones,
+		"total":       total,
+		"page":        page,
+		"page_size":   pageSize,
+		"total_pages": int(math.Ceil(float64(total) / float64(pageSize))),
+	})
+}
+
+// GetCommentRevisions devuelve el historial de ediciones de un comentario (solo moderadores)
+func (h *moderationHandler) GetCommentRevisions(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Método no permitido", http.StatusMethodNotAllowed)
+		return
+	}
+
+	if _, ok := h.authorizeModerator(w, r); !ok {
+		return
+	}
+
+	comentarioID, err := parseIDFromPath(r, "comentarioId")
+	if err != nil {
+		http.Error(w, "ID de comentario inválido", http.StatusBadRequest)
+		return
+	}
+
+	// Los moderadores también pueden consultar comentarios eliminados u ocultos
+	var comentario models.Comentario
+	if result := h.DB.Where("ComentarioID = ?", comentarioID).First(&comentario); result.Error != nil {
+		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+			http.Error(w, "Comentario no encontrado", http.StatusNotFound)
+		} else {
+			http.Error(w, "Error buscando el comentario: "+result.Error.Error(), http.StatusInternalServerError)
+		}
+		return
+	}
+
+	revisiones := []models.RevisionComentario{}
+	if err := h.DB.Table("RevisionesComentario r").
+		Select("r.*, ISNULL(u.NombreUsuario, '') AS NombreEditor").
+		Joins("LEFT JOIN Usuarios u ON u.UsuarioID = r.EditorID").
+		Where("r.ComentarioID = ?", comentarioID).
+		Order("r.FechaEdicion DESC, r.RevisionID DESC").
+		Scan(&revisiones).Error; err != nil {
+		http.Error(w, "Error obteniendo revisiones: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"comentario": comentario,
+		"revisiones": revisiones,
+	})
+}
+
+// GetPostRevisions devuelve el historial de ediciones de un post (solo moderadores)
+func (h *moderationHandler) GetPostRevisions(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Método no permitido", http.StatusMethodNotAllowed)
+		return
+	}
+
+	if _, ok := h.authorizeModerator(w, r); !ok {
+		return
+	}
+
+	postID, err := parseIDFromPath(r, "postId")
+	if err != nil {
+		http.Error(w, "ID de post inválido", http.StatusBadRequest)
+		return
+	}
+
+	var post models.Post
+	if result := h.DB.First(&post, postID); result.Error != nil {
+		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+			http.Error(w, "Post no encontrado", http.StatusNotFound)
+		} else {
+			http.Error(w, "Error buscando el post: "+result.Error.Error(), http.StatusInternalServerError)
+		}
+		return
+	}
+
+	revisiones := []models.RevisionPost{}
+	if err := h.DB.Table("RevisionesPost r").
+		Select("r.*, ISNULL(u.NombreUsuario, '') AS NombreEditor").
+		Joins("LEFT JOIN Usuarios u ON u.UsuarioID = r.EditorID").
+		Where("r.PostID = ?", postID).
+		Order("r.FechaEdicion DESC, r.RevisionID DESC").
+		Scan(&revisiones).Error; err != nil {
+		http.Error(w, "Error obteniendo revisiones: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"post":       post,
+		"revisiones": revisiones,
+	})
+}
+
+// authorizeModerator verifica que el usuario autenticado tenga rol de moderación
+func (h *moderationHandler) authorizeModerator(w http.ResponseWriter, r *http.Request) (uint, bool) {
+	user, authenticated := middleware.GetUserFromContext(r)
+	if !authenticated {
+		http.Error(w, "Autenticación requerida", http.StatusUnauthorized)
+		return 0, false
+	}
+
+	moderator, err := isModerator(h.DB, user.UserID)
+	if err != nil {
+		http.Error(w, "Error verificando permisos: "+err.Error(), http.StatusInternalServerError)
+		return 0, false
+	}
+	if !moderator {
+		http.Error(w, "No tienes permisos de moderación", http.StatusForbidden)
+		return 0, false
+	}
+	return user.UserID, true
+}
+
+// notifySanctionedUser avisa al usuario afectado por una advertencia, ocultamiento o suspensión
+func (h *moderationHandler) notifySanctionedUser(userID uint, action string, reporte models.Reporte, suspendedUntil time.Time) {
+	if userID == 0 {
+		return
+	}
+
+	var titulo, contenido string
+	switch action {
+	case moderationActionWarn:
+		titulo = "Advertencia de moderación"
+		contenido = "Un moderador revisó un reporte sobre tu contenido (" + reporte.Motivo + "). Por favor respeta las normas de la comunidad."
+	case moderationActionHide:
+		titulo = "Contenido ocultado"
+		contenido = "Un moderador ocultó tu contenido por incumplir las normas de la comunidad (" + reporte.Motivo + ")."
+	case moderationActionSuspend:
+		titulo = "Cuenta suspendida"
+		contenido = "Tu cuenta está suspendida hasta el " + suspendedUntil.Format("02/01/2006 15:04") + " por incumplir las normas de la comunidad."
+	default:
+		return
+	}
+
+	err := createNotification(h.DB, h.WSHandler, h.SocketIOBroadcaster, models.Notification{
+		UsuarioID:    userID,
+		Tipo:         "moderation",
+		Titulo:       titulo,
+		Contenido:    contenido,
+		ReferenciaID: reporte.ReporteID,
+	})
+	logNotificationError("moderation", userID, err)
+}
+
+// reportedContentAuthor devuelve el autor del contenido reportado (o el propio usuario reportado).
+// Los mensajes solo los puede reportar quien participa en la conversación.
+func reportedContentAuthor(db *gorm.DB, contentType string, contentID, reporterID uint) (uint, error) {
+	switch contentType {
+	case search.TypePost:
+		var post models.Post
+		if err := db.Where("Oculto = 0").First(&post, contentID).Error; err != nil {
+			return 0, err
+		}
+		return post.UsuarioID, nil
+	case search.TypeComment:
+		var comentario models.Comentario
+		if err := db.Where("ComentarioID = ? AND Activo = 1 AND Oculto = 0", contentID).First(&comentario).Error; err != nil {
+			return 0, err
+		}
+		return uint(comentario.UsuarioID), nil
+	case search.TypeMessage:
+		var message models.Message
+		if err := db.First(&message, contentID).Error; err != nil {
+			return 0, err
+		}
+		var conversation models.Conversation
+		if err := db.First(&conversation, message.ConversationID).Error; err != nil {
+			return 0, err
+		}
+		participantIDs, err := conversationParticipantIDs(db, conversation)
+		if err != nil {
+			return 0, err
+		}
+		if !containsUserID(participantIDs, reporterID) {
+			return 0, gorm.ErrRecordNotFound
+		}
+		return message.SenderID, nil
+	default:
+		var user models.User
+		if err := db.First(&user, contentID).Error; err != nil {
+			return 0, err
+		}
+		return user.ID, nil
+	}
+}
+
+// hideReportedContent oculta un contenido e indica si cambió de estado
+// (los posts y comentarios usan Oculto y los mensajes el borrado lógico)
+func hideReportedContent(db *gorm.DB, contentType string, contentID uint) (bool, error) {
+	var result *gorm.DB
+	switch contentType {
+	case search.TypePost:
+		result = db.Model(&models.Post{}).Where("PostID = ? AND Oculto = 0", contentID).Update("Oculto", true)
+	case search.TypeComment:
+		result = db.Model(&models.Comentario{}).Where("ComentarioID = ? AND Oculto = 0", contentID).Update("Oculto", true)
+	case search.TypeMessage:
+		result = db.Where("ID = ?", contentID).Delete(&models.Message{})
+	default:
+		return false, fmt.Errorf("tipo de contenido no ocultable: %s", contentType)
+	}
+	return result.RowsAffected > 0, result.Error
+}
+
+// restoreAutoHiddenContent vuelve a mostrar un contenido cuando ya no tiene reportes pendientes y
+// la última acción sobre él fue automática (auto_hide o filter_hold). Lo que oculta un moderador
+// no se restaura al descartar otros reportes.
+func restoreAutoHiddenContent(db *gorm.DB, contentType string, contentID uint) (bool, error) {
+	if contentType == search.TypeUser {
+		return false, nil
+	}
+
+	var pending int64
+	if err := db.Model(&models.Reporte{}).
+		Where("TipoContenido = ? AND ContenidoID = ? AND Estado = ?", contentType, contentID, reportStatusPending).
+		Count(&pending).Error; err != nil {
+		return false, err
+	}
+	if pending > 0 {
+		return false, nil
+	}
+
+	var last models.AccionModeracion
+	err := db.Where("TipoContenido = ? AND ContenidoID = ? AND Accion IN ?", contentType, contentID,
+		[]string{moderationActionHide, moderationActionAutoHide, moderationActionFilterHold}).
+		Order("FechaCreacion DESC, AccionModeracionID DESC").
+		First(&last).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	if last.Accion == moderationActionHide {
+		return false, nil
+	}
+
+	var result *gorm.DB
+	switch contentType {
+	case search.TypePost:
+		result = db.Model(&models.Post{}).Where("PostID = ? AND Oculto = 1", contentID).Update("Oculto", false)
+	case search.TypeComment:
+		result = db.Model(&models.Comentario{}).Where("ComentarioID = ? AND Oculto = 1", contentID).Update("Oculto", false)
+	default:
+		result = db.Unscoped().Model(&models.Message{}).Where("ID = ? AND DeletedAt IS NOT NULL", contentID).Update("DeletedAt", nil)
+	}
+	return result.RowsAffected > 0, result.Error
+}
+
+// alertSavedSearches evalúa las búsquedas guardadas con un post abierto que vuelve a publicarse, como
+// los retenidos por el filtro que nunca llegaron a avisar al crearse
+func (h *moderationHandler) alertSavedSearches(contentType string, contentID uint) {
+	if contentType != search.TypePost {
+		return
+	}
+	var post models.Post
+	if err := h.DB.First(&post, contentID).Error; err != nil {
+		return
+	}
+	if effectivePostStatus(post.Estado, post.ExpiresAt, time.Now()) == models.PostAbierto {
+		go evaluateSavedSearches(h.DB, h.WSHandler, h.SocketIOBroadcaster, post)
+	}
+}
+
+// indexReportedContent vuelve a agregar al índice de búsqueda un contenido restaurado
+func indexReportedContent(db *gorm.DB, searcher search.Searcher, contentType string, contentID uint) {
+	switch contentType {
+	case search.TypePost:
+		var post models.Post
+		if err := db.First(&post, contentID).Error; err == nil {
+			var habilidad models.Ability
+			db.First(&habilidad, post.HabilidadID)
+			indexDocument(searcher, search.PostDocument(post, habilidad.Name))
+		}
+	case search.TypeComment:
+		var comentario models.Comentario
+		if err := db.Where("ComentarioID = ?", contentID).First(&comentario).Error; err == nil {
+			indexDocument(searcher, search.CommentDocument(comentario))
+		}
+	case search.TypeMessage:
+		var message models.Message
+		if err := db.First(&message, contentID).Error; err == nil {
+			indexDocument(searcher, search.MessageDocument(message))
+		}
+	}
+}
+
+// recordModerationAction agrega una entrada al registro de auditoría de moderación
+func recordModerationAction(db *gorm.DB, action models.AccionModeracion) error {
+	action.Nota = truncateRunes(action.Nota, maxReportTextLength)
+	return db.Create(&action).Error
+}
+
+// isModerator indica si el usuario tiene un rol con permisos de moderación
+func isModerator(db *gorm.DB, userID uint) (bool, error) {
+	var user models.User
+	if err := db.Select("UsuarioID", "Rol").First(&user, userID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return false, nil
+		}
+		return false, err
+	}
+	return containsString(moderatorRoles, strings.ToLower(user.Rol)), nil
+}
+
+// autoHideThreshold devuelve cuántos reportantes distintos ocultan automáticamente un contenido
+// (configurable con MODERATION_AUTO_HIDE_THRESHOLD)
+func autoHideThreshold() int {
+	if threshold, err := strconv.Atoi(os.Getenv("MODERATION_AUTO_HIDE_THRESHOLD")); err == nil && threshold > 0 {
+		return threshold
+	}
+	return defaultAutoHideThreshold
+}
+
+// visiblePosts excluye de una consulta sobre vw_PostFullInfo los posts ocultos por moderación
+func visiblePosts(query *gorm.DB) *gorm.DB {
+	return query.Where("PostID NOT IN (SELECT PostID FROM Posts WHERE Oculto = 1)")
+}
+
+// containsString indica si el texto está en la lista
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -2,7 +2,10 @@ package handlers
 
 import (
 	"encoding/json"
+	"fmt"
+	"log"
 	"net/http"
+	"skillswap/api/middleware"
 	"skillswap/api/models"
 	"strconv"
 	"time"
@@ -218,3 +221,295 @@ func (h *notificationsHandler) GetUpcomingSessionsReminders(w http.ResponseWrite
 		"total_new_notifications": createdNotifications,
 	})
 }
+
+// Tipos de notificación que el usuario puede habilitar o deshabilitar
+var notificationPreferenceTypes = []string{
+	"mention",
+	"comment_on_your_post",
+	"reply_to_your_comment",
+	"comment_liked",
+	"comment_on_followed_post",
+	"comment_marked_answer",
+	"post_response",
+	"post_response_accepted",
+	"post_response_declined",
+	"saved_search_match",
+	"saved_search_digest",
+	"skill_proposal_reviewed",
+	"skill_endorsed",
+}
+
+// GetNotificationPreferences obtiene las preferencias de notificación del usuario autenticado
+func (h *notificationsHandler) GetNotificationPreferences(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Método no permitido", http.StatusMethodNotAllowed)
+		return
+	}
+
+	userID, ok := authorizePreferencesOwner(w, r)
+	if !ok {
+		return
+	}
+
+	preferences, err := loadNotificationPreferences(h.DB, userID)
+	if err != nil {
+		http.Error(w, "Error al obtener preferencias: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{"preferencias": preferences})
+}
+
+// UpdateNotificationPreferences habilita o deshabilita tipos de notificación del usuario autenticado
+func (h *notificationsHandler) UpdateNotificationPreferences(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPut {
+		http.Error(w, "Método no permitido", http.StatusMethodNotAllowed)
+		return
+	}
+
+	userID, ok := authorizePreferencesOwner(w, r)
+	if !ok {
+		return
+	}
+
+	var req models.UpdateNotificationPreferencesRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Error al decodificar la solicitud: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	for tipo := range req.Preferencias {
+		if !isNotificationPreferenceType(tipo) {
+			http.Error(w, "Tipo de notificación desconocido: "+tipo, http.StatusBadRequest)
+			return
+		}
+	}
+
+	err := h.DB.Transaction(func(tx *gorm.DB) error {
+		for tipo, habilitada := range req.Preferencias {
+			var preference models.PreferenciaNotificacion
+			result := tx.Where("UsuarioID = ? AND Tipo = ?", userID, tipo).First(&preference)
+			if result.Error == gorm.ErrRecordNotFound {
+				preference = models.PreferenciaNotificacion{UsuarioID: userID, Tipo: tipo, Habilitada: habilitada}
+				if err := tx.Create(&preference).Error; err != nil {
+					return err
+				}
+				continue
+			}
+			if result.Error != nil {
+				return result.Error
+			}
+			if err := tx.Model(&preference).Update("Habilitada", habilitada).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		http.Error(w, "Error al actualizar preferencias: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	preferences, err := loadNotificationPreferences(h.DB, userID)
+	if err != nil {
+		http.Error(w, "Error al obtener preferencias: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{"preferencias": preferences})
+}
+
+// authorizePreferencesOwner verifica que el usuario autenticado sea el dueño de las preferencias
+func authorizePreferencesOwner(w http.ResponseWriter, r *http.Request) (uint, bool) {
+	userID, err := parseIDFromPath(r, "userID")
+	if err != nil {
+		http.Error(w, "ID de usuario inválido", http.StatusBadRequest)
+		return 0, false
+	}
+
+	user, authenticated := middleware.GetUserFromContext(r)
+	if !authenticated {
+		http.Error(w, "Autenticación requerida para gestionar preferencias", http.StatusUnauthorized)
+		return 0, false
+	}
+	if user.UserID != userID {
+		http.Error(w, "No tienes permiso para gestionar las preferencias de este usuario", http.StatusForbidden)
+		return 0, false
+	}
+
+	return userID, true
+}
+
+// isNotificationPreferenceType indica si el tipo de notificación es configurable
+func isNotificationPreferenceType(tipo string) bool {
+	for _, t := range notificationPreferenceTypes {
+		if t == tipo {
+			return true
+		}
+	}
+	return false
+}
+
+// loadNotificationPreferences devuelve todas las preferencias configurables del usuario (habilitadas por defecto)
+func loadNotificationPreferences(db *gorm.DB, userID uint) (map[string]bool, error) {
+	preferences := make(map[string]bool, len(notificationPreferenceTypes))
+	for _, tipo := range notificationPreferenceTypes {
+		preferences[tipo] = true
+	}
+
+	var rows []models.PreferenciaNotificacion
+	if err := db.Where("UsuarioID = ?", userID).Find(&rows).Error; err != nil {
+		return nil, err
+	}
+	for _, row := range rows {
+		preferences[row.Tipo] = row.Habilitada
+	}
+	return preferences, nil
+}
+
+// notificationEnabled indica si el usuario recibe notificaciones del tipo indicado
+func notificationEnabled(db *gorm.DB, userID uint, tipo string) bool {
+	var preference models.PreferenciaNotificacion
+	if err := db.Where("UsuarioID = ? AND Tipo = ?", userID, tipo).First(&preference).Error; err != nil {
+		return true
+	}
+	return preference.Habilitada
+}
+
+// createNotification guarda una notificación y la envía en tiempo real a la sala personal del usuario
+func createNotification(db *gorm.DB, wsHandler *WebSocketHandler, broadcaster *SocketIOBroadcaster, notification models.Notification) error {
+	notification.Titulo = truncateRunes(notification.Titulo, 100)
+	notification.Contenido = truncateRunes(notification.Contenido, 500)
+	if notification.FechaCreacion.IsZero() {
+		notification.FechaCreacion = time.Now()
+	}
+
+	if err := db.Create(&notification).Error; err != nil {
+		return err
+	}
+
+	if broadcaster != nil {
+		broadcaster.BroadcastNotification(notification.UsuarioID, notification)
+	}
+	if wsHandler != nil {
+		wsHandler.BroadcastNotification(notification.UsuarioID, notification)
+	}
+	return nil
+}
+
+// truncateRunes recorta un texto a un máximo de caracteres agregando puntos suspensivos
+func truncateRunes(text string, max int) string {
+	runes := []rune(text)
+	if len(runes) <= max {
+		return text
+	}
+	if max <= 1 {
+		return string(runes[:max])
+	}
+	return string(runes[:max-1]) + "…"
+}
+
+// logNotificationError registra errores al crear notificaciones sin interrumpir la petición
+func logNotificationError(tipo string, userID uint, err error) {
+	if err != nil {
+		log.Printf("Error creando notificación %s para el usuario %d: %v", tipo, userID, err)
+	}
+}
+
+// Ventana en la que una notificación no leída absorbe los nuevos eventos del mismo tipo y referencia
+const notificationCoalesceWindow = 6 * time.Hour
+
+// notificationText construye el título y el contenido de una notificación agrupada
+// a partir del último usuario que la originó y del total de usuarios distintos
+type notificationText func(lastActorName string, totalActors int) (titulo, contenido string)
+
+// createCoalescedNotification crea una notificación o, si el usuario tiene una no leída reciente
+// del mismo tipo y referencia, la agrupa con ella ("5 personas respondieron a tu comentario").
+func createCoalescedNotification(db *gorm.DB, wsHandler *WebSocketHandler, broadcaster *SocketIOBroadcaster, userID uint, tipo string, referenciaID, actorID uint, text notificationText) error {
+	if !notificationEnabled(db, userID, tipo) {
+		return nil
+	}
+
+	actorName := loadUserDisplayName(db, actorID)
+	now := time.Now()
+
+	var notification models.Notification
+	err := db.Transaction(func(tx *gorm.DB) error {
+		// UPDLOCK + HOLDLOCK mantiene bloqueado el rango buscado hasta el final de la transacción para
+		// que dos actividades simultáneas sobre la misma referencia no creen dos notificaciones
+		result := tx.Table("Notificaciones WITH (UPDLOCK, HOLDLOCK)").
+			Where("UsuarioID = ? AND Tipo = ? AND ReferenciaID = ? AND Leida = 0 AND FechaCreacion >= ?",
+				userID, tipo, referenciaID, now.Add(-notificationCoalesceWindow)).
+			Order("FechaCreacion DESC").
+			First(&notification)
+
+		if result.Error == gorm.ErrRecordNotFound {
+			titulo, contenido := text(actorName, 1)
+			notification = models.Notification{
+				UsuarioID:     userID,
+				Tipo:          tipo,
+				Titulo:        truncateRunes(titulo, 100),
+				Contenido:     truncateRunes(contenido, 500),
+				ReferenciaID:  referenciaID,
+				FechaCreacion: now,
+			}
+			if err := tx.Create(&notification).Error; err != nil {
+				return err
+			}
+			return tx.Create(&models.NotificacionActor{NotificacionID: notification.ID, UsuarioID: actorID}).Error
+		}
+		if result.Error != nil {
+			return result.Error
+		}
+
+		var exists int64
+		if err := tx.Model(&models.NotificacionActor{}).
+			Where("NotificacionID = ? AND UsuarioID = ?", notification.ID, actorID).
+			Count(&exists).Error; err != nil {
+			return err
+		}
+		if exists == 0 {
+			if err := tx.Create(&models.NotificacionActor{NotificacionID: notification.ID, UsuarioID: actorID}).Error; err != nil {
+				return err
+			}
+		}
+
+		var totalActors int64
+		if err := tx.Model(&models.NotificacionActor{}).
+			Where("NotificacionID = ?", notification.ID).
+			Count(&totalActors).Error; err != nil {
+			return err
+		}
+
+		titulo, contenido := text(actorName, int(totalActors))
+		notification.Titulo = truncateRunes(titulo, 100)
+		notification.Contenido = truncateRunes(contenido, 500)
+		notification.FechaCreacion = now
+		return tx.Model(&notification).Updates(map[string]interface{}{
+			"Titulo":        notification.Titulo,
+			"Contenido":     notification.Contenido,
+			"FechaCreacion": now,
+		}).Error
+	})
+	if err != nil {
+		return err
+	}
+
+	if broadcaster != nil {
+		broadcaster.BroadcastNotification(userID, notification)
+	}
+	if wsHandler != nil {
+		wsHandler.BroadcastNotification(userID, notification)
+	}
+	return nil
+}
+
+// loadUserDisplayName obtiene el nombre visible de un usuario a partir de su ID
+func loadUserDisplayName(db *gorm.DB, userID uint) string {
+	var user models.User
+	if err := db.First(&user, userID).Error; err != nil {
+		return fmt.Sprintf("Usuario %d", userID)
+	}
+	return userDisplayName(user)
+}
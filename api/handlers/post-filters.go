@@ -0,0 +1,200 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"skillswap/api/models"
+
+	"gorm.io/gorm"
+)
+
+const (
+	postsMaxPageSize = 50
+
+	// Criterios de orden de los listados de posts
+	postSortNewest    = "newest"
+	postSortOldest    = "oldest"
+	postSortRelevance = "relevance"
+)
+
+var postSortOptions = []string{postSortNewest, postSortOldest, postSortRelevance}
+
+// parsePostPagination lee page y pageSize limitando el tamaño de página a postsMaxPageSize
+func parsePostPagination(r *http.Request, defaultPageSize int) (int, int) {
+	page, err := strconv.Atoi(r.URL.Query().Get("page"))
+	if err != nil || page < 1 {
+		page = 1
+	}
+	pageSize, err := strconv.Atoi(r.URL.Query().Get("pageSize"))
+	if err != nil || pageSize <= 0 {
+		pageSize = defaultPageSize
+	}
+	if pageSize > postsMaxPageSize {
+		pageSize = postsMaxPageSize
+	}
+	return page, pageSize
+}
+
+// applyPostListFilters aplica a una consulta sobre vw_PostFullInfo los filtros de la query:
+//   - tipo: tipo de post (OFREZCO o BUSCO)
+//   - search: texto en el nombre de la habilidad
+//   - categoria: categorías de la habilidad, separadas por comas
+//   - habilidad_id: IDs de habilidad, separados por comas
+//   - ciudad: ciudad de trabajo del autor
+//   - nivel: niveles de proficiencia del autor en la habilidad del post, separados por comas
+//   - created_after / created_before: fecha (YYYY-MM-DD) o fecha y hora RFC 3339
+//   - min_rating: promedio mínimo de las reseñas recibidas por el autor (1 a 5)
+func applyPostListFilters(query *gorm.DB, r *http.Request) (*gorm.DB, error) {
+	params := r.URL.Query()
+
+	if tipoPost := strings.TrimSpace(params.Get("tipo")); tipoPost != "" {
+		query = query.Where("TipoPost = ?", tipoPost)
+	}
+	if searchTerm := strings.TrimSpace(params.Get("search")); searchTerm != "" {
+		query = query.Where("NombreHabilidad LIKE ?", "%"+searchTerm+"%")
+	}
+
+	if categorias := splitQueryList(params.Get("categoria")); len(categorias) > 0 {
+		query = query.Where("PostID IN (SELECT p.PostID FROM Posts p INNER JOIN Habilidades h ON h.HabilidadID = p.HabilidadID WHERE h.Categoria IN ?)", categorias)
+	}
+
+	if raw := splitQueryList(params.Get("habilidad_id")); len(raw) > 0 {
+		habilidades := make([]uint, 0, len(raw))
+		for _, value := range raw {
+			id, err := strconv.ParseUint(value, 10, 64)
+			if err != nil || id == 0 {
+				return nil, errors.New("habilidad_id inválido: " + value)
+			}
+			habilidades = append(habilidades, uint(id))
+		}
+		query = query.Where("PostID IN (SELECT PostID FROM Posts WHERE HabilidadID IN ?)", habilidades)
+	}
+
+	if ciudad := strings.TrimSpace(params.Get("ciudad")); ciudad != "" {
+		query = query.Where("UsuarioID IN (SELECT UsuarioID FROM Usuarios WHERE CiudadTrabajo = ?)", ciudad)
+	}
+
+	if niveles := splitQueryList(params.Get("nivel")); len(niveles) > 0 {
+		query = query.Where("PostID IN (SELECT p.PostID FROM Posts p INNER JOIN UsuariosHabilidades ua "+
+			"ON ua.UsuarioID = p.UsuarioID AND ua.HabilidadID = p.HabilidadID WHERE ua.NivelProficiencia IN ?)", niveles)
+	}
+
+	if raw := params.Get("created_after"); raw != "" {
+		after, err := parseQueryDate(raw, false)
+		if err != nil {
+			return nil, errors.New("created_after inválido: use YYYY-MM-DD o RFC 3339")
+		}
+		query = query.Where("CreatedAt >= ?", after)
+	}
+	if raw := params.Get("created_before"); raw != "" {
+		before, err := parseQueryDate(raw, true)
+		if err != nil {
+			return nil, errors.New("created_before inválido: use YYYY-MM-DD o RFC 3339")
+		}
+		query = query.Where("CreatedAt < ?", before)
+	}
+
+	if raw := params.Get("min_rating"); raw != "" {
+		rating, err := strconv.ParseFloat(raw, 64)
+		if err != nil || rating < 1 || rating > 5 {
+			return nil, errors.New("min_rating inválido: use un valor entre 1 y 5")
+		}
+		query = query.Where("UsuarioID IN (SELECT UsuarioRevisadoID FROM Resenas WHERE Calificacion IS NOT NULL "+
+			"GROUP BY UsuarioRevisadoID HAVING AVG(CAST(Calificacion AS FLOAT)) >= ?)", rating)
+	}
+
+	return query, nil
+}
+
+// orderPosts ordena la consulta según el parámetro sort (newest por defecto). relevance prioriza las
+// habilidades cuyo nombre coincide exactamente o empieza con el texto buscado y, sin texto, equivale a newest.
+func orderPosts(query *gorm.DB, r *http.Request) (*gorm.DB, error) {
+	sortBy := strings.ToLower(strings.TrimSpace(r.URL.Query().Get("sort")))
+	if sortBy == "" {
+		sortBy = postSortNewest
+	}
+	if !containsString(postSortOptions, sortBy) {
+		return nil, errors.New("Orden inválido: use " + strings.Join(postSortOptions, ", "))
+	}
+
+	switch sortBy {
+	case postSortOldest:
+		return query.Order("CreatedAt ASC, PostID ASC"), nil
+	case postSortRelevance:
+		if searchTerm := strings.TrimSpace(r.URL.Query().Get("search")); searchTerm != "" {
+			query = query.Order(gorm.Expr("CASE WHEN NombreHabilidad = ? THEN 0 WHEN NombreHabilidad LIKE ? THEN 1 "+
+				"WHEN Descripcion LIKE ? THEN 2 ELSE 3 END", searchTerm, searchTerm+"%", "%"+searchTerm+"%"))
+		}
+	}
+	return query.Order("CreatedAt DESC, PostID DESC"), nil
+}
+
+// loadPostFacets cuenta los posts de la consulta filtrada por categoría de la habilidad y por tipo de post
+func loadPostFacets(db *gorm.DB, query *gorm.DB) (*models.FacetasPosts, error) {
+	type facetRow struct {
+		Valor string `gorm:"column:Valor"`
+		Total int64  `gorm:"column:Total"`
+	}
+
+	facetas := &models.FacetasPosts{
+		Categorias: map[string]int64{},
+		Tipos:      map[string]int64{},
+	}
+
+	var tipos []facetRow
+	if err := query.Session(&gorm.Session{}).
+		Select("ISNULL(TipoPost, '') AS Valor, COUNT(*) AS Total").
+		Group("TipoPost").
+		Scan(&tipos).Error; err != nil {
+		return nil, err
+	}
+	for _, row := range tipos {
+		facetas.Tipos[row.Valor] += row.Total
+	}
+
+	var categorias []facetRow
+	if err := db.Table("Posts p").
+		Select("ISNULL(h.Categoria, '') AS Valor, COUNT(*) AS Total").
+		Joins("INNER JOIN Habilidades h ON h.HabilidadID = p.HabilidadID").
+		Where("p.PostID IN (?)", query.Session(&gorm.Session{}).Select("PostID")).
+		Group("h.Categoria").
+		Scan(&categorias).Error; err != nil {
+		return nil, err
+	}
+	for _, row := range categorias {
+		facetas.Categorias[row.Valor] += row.Total
+	}
+
+	return facetas, nil
+}
+
+// splitQueryList separa un parámetro con valores separados por comas descartando los vacíos
+func splitQueryList(raw string) []string {
+	var values []string
+	for _, value := range strings.Split(raw, ",") {
+		if value = strings.TrimSpace(value); value != "" {
+			values = append(values, value)
+		}
+	}
+	return values
+}
+
+// parseQueryDate interpreta una fecha RFC 3339 o YYYY-MM-DD. Con endOfDay, una fecha sin hora
+// se toma como el inicio del día siguiente para que el límite superior incluya todo ese día.
+func parseQueryDate(raw string, endOfDay bool) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, raw); err == nil {
+		return t, nil
+	}
+	t, err := time.ParseInLocation("2006-01-02", raw, time.Local)
+	if err != nil {
+		return time.Time{}, err
+	}
+	if endOfDay {
+		t = t.AddDate(0, 0, 1)
+	}
+	return t, nil
+}
@@ -0,0 +1,298 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"skillswap/api/middleware"
+	"skillswap/api/models"
+
+	"gorm.io/gorm"
+)
+
+const (
+	defaultPostExpiryDays        = 90
+	defaultPostExpiryCheckPeriod = time.Hour
+)
+
+// postEffectiveStatusSQL calcula el estado de un post considerando vencidos los que pasaron su
+// fecha de expiración aunque el proceso de expiración todavía no los haya actualizado
+const postEffectiveStatusSQL = "CASE WHEN Estado IN ('open', 'paused') AND ExpiresAt <= GETDATE() THEN 'expired' ELSE Estado END"
+
+// parsePostStatusFilter lee el parámetro status (lista separada por comas o "all").
+// Sin parámetro solo se listan los posts abiertos; nil significa no filtrar por estado.
+func parsePostStatusFilter(r *http.Request) ([]string, error) {
+	raw := strings.TrimSpace(r.URL.Query().Get("status"))
+	if raw == "" {
+		return []string{models.PostAbierto}, nil
+	}
+	if raw == "all" {
+		return nil, nil
+	}
+
+	var statuses []string
+	for _, status := range strings.Split(raw, ",") {
+		status = strings.ToLower(strings.TrimSpace(status))
+		if status == "" {
+			continue
+		}
+		if !containsString(models.EstadosPost, status) {
+			return nil, errors.New("Estado inválido: use " + strings.Join(models.EstadosPost, ", ") + " o all")
+		}
+		if !containsString(statuses, status) {
+			statuses = append(statuses, status)
+		}
+	}
+	if len(statuses) == 0 {
+		return []string{models.PostAbierto}, nil
+	}
+	return statuses, nil
+}
+
+// postsWithStatus limita una consulta sobre vw_PostFullInfo a los posts con alguno de los estados indicados
+func postsWithStatus(query *gorm.DB, statuses []string) *gorm.DB {
+	if statuses == nil {
+		return query
+	}
+	return query.Where("PostID IN (SELECT PostID FROM Posts WHERE "+postEffectiveStatusSQL+" IN ?)", statuses)
+}
+
+// effectivePostStatus devuelve el estado del post teniendo en cuenta su fecha de expiración
+func effectivePostStatus(status string, expiresAt *time.Time, now time.Time) string {
+	if status == "" {
+		status = models.PostAbierto
+	}
+	if (status == models.PostAbierto || status == models.PostPausado) && expiresAt != nil && !expiresAt.After(now) {
+		return models.PostExpirado
+	}
+	return status
+}
+
+// defaultPostExpiry devuelve la expiración de un post nuevo según POST_DEFAULT_EXPIRY_DAYS (0 = no expira)
+func defaultPostExpiry(now time.Time) *time.Time {
+	days := defaultPostExpiryDays
+	if value, err := strconv.Atoi(os.Getenv("POST_DEFAULT_EXPIRY_DAYS")); err == nil && value >= 0 {
+		days = value
+	}
+	if days == 0 {
+		return nil
+	}
+	expiresAt := now.AddDate(0, 0, days)
+	return &expiresAt
+}
+
+// ExpireStalePosts marca como vencidos los posts abiertos o pausados cuya fecha de expiración ya pasó
+func ExpireStalePosts(db *gorm.DB) (int64, error) {
+	result := db.Model(&models.Post{}).
+		Where("Estado IN ? AND ExpiresAt <= ?", []string{models.PostAbierto, models.PostPausado}, time.Now()).
+		UpdateColumn("Estado", models.PostExpirado)
+	return result.RowsAffected, result.Error
+}
+
+// RunPostExpiryJob ejecuta ExpireStalePosts al iniciar y luego periódicamente. El intervalo se
+// configura en minutos con POST_EXPIRY_CHECK_MINUTES (por defecto cada hora).
+func RunPostExpiryJob(db *gorm.DB) {
+	period := defaultPostExpiryCheckPeriod
+	if minutes, err := strconv.Atoi(os.Getenv("POST_EXPIRY_CHECK_MINUTES")); err == nil && minutes > 0 {
+		period = time.Duration(minutes) * time.Minute
+	}
+
+	ticker := time.NewTicker(period)
+	defer ticker.Stop()
+	for {
+		expired, err := ExpireStalePosts(db)
+		if err != nil {
+			log.Printf("Error expirando posts: %v", err)
+		} else if expired > 0 {
+			log.Printf("Posts expirados: %d", expired)
+		}
+		<-ticker.C
+	}
+}
+
+// UpdatePostStatus permite al autor pausar o reabrir su post. Los estados fulfilled y expired
+// se asignan con FulfillPost y con el proceso de expiración.
+func (h *postsHandler) UpdatePostStatus(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPut {
+		http.Error(w, "Método no permitido", http.StatusMethodNotAllowed)
+		return
+	}
+
+	post, ok := h.loadOwnPost(w, r)
+	if !ok {
+		return
+	}
+
+	var req models.CambiarEstadoPostRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Error al decodificar la solicitud: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	req.Estado = strings.ToLower(strings.TrimSpace(req.Estado))
+
+	now := time.Now()
+	current := effectivePostStatus(post.Estado, post.ExpiresAt, now)
+	// UpdatedAt marca el cambio de estado (por ejemplo, para el Last-Modified del feed Atom)
+	updates := map[string]interface{}{"Estado": req.Estado, "UpdatedAt": now}
+
+	switch req.Estado {
+	case models.PostPausado:
+		if current != models.PostAbierto {
+			http.Error(w, "Solo se pueden pausar posts abiertos", http.StatusConflict)
+			return
+		}
+		if req.ExpiresAt != nil {
+			http.Error(w, "La fecha de expiración solo se indica al reabrir el post", http.StatusBadRequest)
+			return
+		}
+	case models.PostAbierto:
+		if current == models.PostAbierto {
+			http.Error(w, "El post ya está abierto", http.StatusConflict)
+			return
+		}
+		// Al reabrir se respeta la nueva expiración o, si la anterior ya pasó, se asigna la predeterminada
+		switch {
+		case req.ExpiresAt != nil:
+			if !req.ExpiresAt.After(now) {
+				http.Error(w, "La fecha de expiración debe ser futura", http.StatusBadRequest)
+				return
+			}
+			updates["ExpiresAt"] = *req.ExpiresAt
+		case post.ExpiresAt != nil && !post.ExpiresAt.After(now):
+			updates["ExpiresAt"] = defaultPostExpiry(now)
+		}
+		// Reabrir un post cumplido descarta el emparejamiento asociado
+		updates["EmparejamientoID"] = nil
+		updates["CumplidoEn"] = nil
+	default:
+		http.Error(w, "Estado inválido: use open o paused (para cumplir un post use /posts/{id}/fulfill)", http.StatusBadRequest)
+		return
+	}
+
+	if err := h.DB.Model(&post).UpdateColumns(updates).Error; err != nil {
+		http.Error(w, "Error al actualizar el estado del post: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+	h.writePost(w, post.ID)
+}
+
+// FulfillPost marca el post como cumplido enlazándolo con el emparejamiento que lo satisfizo.
+// El autor del post debe ser uno de los usuarios del emparejamiento, el emparejamiento debe tratar
+// sobre la habilidad del post y estar activo o tener una sesión completada.
+func (h *postsHandler) FulfillPost(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Método no permitido", http.StatusMethodNotAllowed)
+		return
+	}
+
+	post, ok := h.loadOwnPost(w, r)
+	if !ok {
+		return
+	}
+
+	var req models.CumplirPostRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Error al decodificar la solicitud: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	if req.MatchID == 0 {
+		http.Error(w, "match_id es obligatorio", http.StatusBadRequest)
+		return
+	}
+	if post.Estado == models.PostCumplido {
+		http.Error(w, "El post ya está marcado como cumplido", http.StatusConflict)
+		return
+	}
+
+	var match models.Matches
+	if result := h.DB.First(&match, req.MatchID); result.Error != nil {
+		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+			http.Error(w, "Emparejamiento no encontrado", http.StatusNotFound)
+		} else {
+			http.Error(w, "Error al buscar emparejamiento: "+result.Error.Error(), http.StatusInternalServerError)
+		}
+		return
+	}
+	if match.UserID1 != post.UsuarioID && match.UserID2 != post.UsuarioID {
+		http.Error(w, "El emparejamiento no pertenece al autor del post", http.StatusBadRequest)
+		return
+	}
+	if match.Habilidad1ID != post.HabilidadID && match.Habilidad2ID != post.HabilidadID {
+		http.Error(w, "El emparejamiento no corresponde a la habilidad del post", http.StatusBadRequest)
+		return
+	}
+	if match.MatchingState != models.EmparejamientoActivo {
+		var completadas int64
+		if err := h.DB.Table("Sesiones").
+			Where("EmparejamientoID = ? AND Estado IN ?", match.ID, models.EstadosSesionCompletada).
+			Count(&completadas).Error; err != nil {
+			http.Error(w, "Error al verificar las sesiones del emparejamiento: "+err.Error(), http.StatusInternalServerError)
+			return
+		}
+		if completadas == 0 {
+			http.Error(w, "El emparejamiento debe estar activo o tener una sesión completada", http.StatusConflict)
+			return
+		}
+	}
+
+	if err := h.DB.Model(&post).UpdateColumns(map[string]interface{}{
+		"Estado":           models.PostCumplido,
+		"EmparejamientoID": match.ID,
+		"CumplidoEn":       time.Now(),
+		"UpdatedAt":        time.Now(),
+	}).Error; err != nil {
+		http.Error(w, "Error al marcar el post como cumplido: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+	h.writePost(w, post.ID)
+}
+
+// loadOwnPost obtiene el post de la ruta y verifica que el usuario autenticado sea su autor
+func (h *postsHandler) loadOwnPost(w http.ResponseWriter, r *http.Request) (models.Post, bool) {
+	var post models.Post
+
+	user, authenticated := middleware.GetUserFromContext(r)
+	if !authenticated {
+		http.Error(w, "Autenticación requerida", http.StatusUnauthorized)
+		return post, false
+	}
+
+	id, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		http.Error(w, "ID de post inválido", http.StatusBadRequest)
+		return post, false
+	}
+
+	if result := h.DB.First(&post, id); result.Error != nil {
+		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+			http.Error(w, "Post no encontrado", http.StatusNotFound)
+		} else {
+			http.Error(w, "Error al buscar post: "+result.Error.Error(), http.StatusInternalServerError)
+		}
+		return post, false
+	}
+	if post.UsuarioID != user.UserID {
+		http.Error(w, "Solo el autor puede cambiar el estado del post", http.StatusForbidden)
+		return post, false
+	}
+	return post, true
+}
+
+// writePost responde con el post actualizado y su estado efectivo
+func (h *postsHandler) writePost(w http.ResponseWriter, id uint) {
+	var post models.Post
+	if err := h.DB.First(&post, id).Error; err != nil {
+		http.Error(w, "Error al obtener el post: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+	post.Estado = effectivePostStatus(post.Estado, post.ExpiresAt, time.Now())
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(post)
+}
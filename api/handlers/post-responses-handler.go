@@ -0,0 +1,337 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"skillswap/api/middleware"
+	"skillswap/api/models"
+
+	"gorm.io/gorm"
+)
+
+const maxPostResponseMessageLength = 1000
+
+// Estados de respuesta que se pueden listar
+var postResponseStatuses = []string{models.RespuestaPendiente, models.RespuestaAceptada, models.RespuestaRechazada}
+
+type postResponsesHandler struct {
+	DB                  *gorm.DB
+	WSHandler           *WebSocketHandler
+	SocketIOBroadcaster *SocketIOBroadcaster
+}
+
+func NewPostResponsesHandler(db *gorm.DB) *postResponsesHandler {
+	return &postResponsesHandler{
+		DB:                  db,
+		SocketIOBroadcaster: NewSocketIOBroadcaster(),
+	}
+}
+
+// SetWebSocketHandler configura el handler de WebSocket para las notificaciones en tiempo real
+func (h *postResponsesHandler) SetWebSocketHandler(wsHandler *WebSocketHandler) {
+	h.WSHandler = wsHandler
+}
+
+// CreatePostResponse responde a un post ofreciendo una habilidad a cambio. Crea un emparejamiento
+// pendiente entre el autor (Usuario1, habilidad del post) y quien responde (Usuario2, habilidad ofrecida).
+func (h *postResponsesHandler) CreatePostResponse(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Método no permitido", http.StatusMethodNotAllowed)
+		return
+	}
+
+	user, authenticated := middleware.GetUserFromContext(r)
+	if !authenticated {
+		http.Error(w, "Autenticación requerida para responder a posts", http.StatusUnauthorized)
+		return
+	}
+
+	postID, err := parseIDFromPath(r, "id")
+	if err != nil {
+		http.Error(w, "ID de post inválido", http.StatusBadRequest)
+		return
+	}
+
+	var req models.CrearRespuestaPostRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Error al decodificar la solicitud: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	req.Mensaje = strings.TrimSpace(req.Mensaje)
+	if req.HabilidadID == 0 {
+		http.Error(w, "habilidad_id es obligatorio", http.StatusBadRequest)
+		return
+	}
+	if utf8.RuneCountInString(req.Mensaje) > maxPostResponseMessageLength {
+		http.Error(w, "El mensaje es demasiado largo", http.StatusBadRequest)
+		return
+	}
+
+	var post models.Post
+	if result := h.DB.Where("Oculto = 0").First(&post, postID); result.Error != nil {
+		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+			http.Error(w, "Post no encontrado", http.StatusNotFound)
+		} else {
+			http.Error(w, "Error al buscar post: "+result.Error.Error(), http.StatusInternalServerError)
+		}
+		return
+	}
+	if post.UsuarioID == user.UserID {
+		http.Error(w, "No puedes responder a tu propio post", http.StatusBadRequest)
+		return
+	}
+	if effectivePostStatus(post.Estado, post.ExpiresAt, time.Now()) != models.PostAbierto {
+		http.Error(w, "El post no está abierto a respuestas", http.StatusConflict)
+		return
+	}
+	if blocked, err := isBlockedBetween(h.DB, user.UserID, post.UsuarioID); err != nil {
+		http.Error(w, "Error al verificar bloqueos: "+err.Error(), http.StatusInternalServerError)
+		return
+	} else if blocked {
+		http.Error(w, "No puedes responder a este post", http.StatusForbidden)
+		return
+	}
+
+	// La habilidad ofrecida debe estar registrada como "Ofrece" por quien responde
+	var ofrecida models.UserAbility
+	if result := h.DB.Where("UsuarioID = ? AND HabilidadID = ? AND TipoHabilidad = ?", user.UserID, req.HabilidadID, "Ofrece").
+		First(&ofrecida); result.Error != nil {
+		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+			http.Error(w, "Solo puedes ofrecer habilidades que tengas registradas como Ofrece", http.StatusBadRequest)
+		} else {
+			http.Error(w, "Error al buscar habilidad: "+result.Error.Error(), http.StatusInternalServerError)
+		}
+		return
+	}
+
+	var pendientes int64
+	if err := h.DB.Model(&models.RespuestaPost{}).
+		Where("PostID = ? AND UsuarioID = ? AND Estado = ?", post.ID, user.UserID, models.RespuestaPendiente).
+		Count(&pendientes).Error; err != nil {
+		http.Error(w, "Error al verificar respuestas: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if pendientes > 0 {
+		http.Error(w, "Ya tienes una respuesta pendiente para este post", http.StatusConflict)
+		return
+	}
+
+	respuesta := models.RespuestaPost{
+		PostID:              post.ID,
+		UsuarioID:           user.UserID,
+		HabilidadOfrecidaID: req.HabilidadID,
+		Mensaje:             req.Mensaje,
+		Estado:              models.RespuestaPendiente,
+	}
+	err = h.DB.Transaction(func(tx *gorm.DB) error {
+		match := models.Matches{
+			UserID1:       post.UsuarioID,
+			UserID2:       user.UserID,
+			Habilidad1ID:  post.HabilidadID,
+			Habilidad2ID:  req.HabilidadID,
+			MatchingState: models.EmparejamientoPendiente,
+		}
+		if err := tx.Omit("User1", "User2", "Ability1", "Ability2").Create(&match).Error; err != nil {
+			return err
+		}
+		respuesta.EmparejamientoID = match.ID
+		return tx.Create(&respuesta).Error
+	})
+	if err != nil {
+		http.Error(w, "Error al crear la respuesta: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	h.notifyPostResponse(post.UsuarioID, "post_response", "Nueva respuesta a tu post",
+		loadUserDisplayName(h.DB, user.UserID)+" respondió a tu post y propone un intercambio", respuesta.RespuestaID)
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(respuesta)
+}
+
+// GetPostResponses lista las respuestas de un post (solo su autor). Acepta ?estado=pending|accepted|declined.
+func (h *postResponsesHandler) GetPostResponses(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Método no permitido", http.StatusMethodNotAllowed)
+		return
+	}
+
+	post, ok := h.loadAuthorPost(w, r)
+	if !ok {
+		return
+	}
+
+	query := h.DB.Table("RespuestasPost rp").
+		Select("rp.*, u.NombreUsuario, h.NombreHabilidad").
+		Joins("INNER JOIN Usuarios u ON u.UsuarioID = rp.UsuarioID").
+		Joins("LEFT JOIN Habilidades h ON h.HabilidadID = rp.HabilidadOfrecidaID").
+		Where("rp.PostID = ?", post.ID)
+	if estado := r.URL.Query().Get("estado"); estado != "" {
+		if !containsString(postResponseStatuses, estado) {
+			http.Error(w, "Estado inválido: use "+strings.Join(postResponseStatuses, ", "), http.StatusBadRequest)
+			return
+		}
+		query = query.Where("rp.Estado = ?", estado)
+	}
+
+	respuestas := []models.RespuestaPost{}
+	if err := query.Order("rp.FechaCreacion DESC").Scan(&respuestas).Error; err != nil {
+		http.Error(w, "Error al obtener respuestas: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(models.RespuestasPostResponse{
+		PostID:     post.ID,
+		Respuestas: respuestas,
+		Total:      len(respuestas),
+	})
+}
+
+// AcceptPostResponse acepta una respuesta pendiente y activa su emparejamiento
+func (h *postResponsesHandler) AcceptPostResponse(w http.ResponseWriter, r *http.Request) {
+	h.resolvePostResponse(w, r, models.RespuestaAceptada)
+}
+
+// DeclinePostResponse rechaza una respuesta pendiente y su emparejamiento
+func (h *postResponsesHandler) DeclinePostResponse(w http.ResponseWriter, r *http.Request) {
+	h.resolvePostResponse(w, r, models.RespuestaRechazada)
+}
+
+// resolvePostResponse cambia el estado de una respuesta pendiente y del emparejamiento asociado
+func (h *postResponsesHandler) resolvePostResponse(w http.ResponseWriter, r *http.Request, estado string) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Método no permitido", http.StatusMethodNotAllowed)
+		return
+	}
+
+	post, ok := h.loadAuthorPost(w, r)
+	if !ok {
+		return
+	}
+
+	responseID, err := parseIDFromPath(r, "responseID")
+	if err != nil {
+		http.Error(w, "ID de respuesta inválido", http.StatusBadRequest)
+		return
+	}
+
+	var respuesta models.RespuestaPost
+	if result := h.DB.Where("RespuestaID = ? AND PostID = ?", responseID, post.ID).First(&respuesta); result.Error != nil {
+		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+			http.Error(w, "Respuesta no encontrada", http.StatusNotFound)
+		} else {
+			http.Error(w, "Error al buscar respuesta: "+result.Error.Error(), http.StatusInternalServerError)
+		}
+		return
+	}
+	if respuesta.Estado != models.RespuestaPendiente {
+		http.Error(w, "La respuesta ya fue "+respuestaEstadoTexto(respuesta.Estado), http.StatusConflict)
+		return
+	}
+
+	estadoEmparejamiento := models.EmparejamientoActivo
+	if estado == models.RespuestaRechazada {
+		estadoEmparejamiento = models.EmparejamientoRechazado
+	}
+
+	now := time.Now()
+	resolved := false
+	err = h.DB.Transaction(func(tx *gorm.DB) error {
+		// La condición sobre Estado evita que dos resoluciones simultáneas tengan éxito
+		result := tx.Model(&models.RespuestaPost{}).
+			Where("RespuestaID = ? AND Estado = ?", respuesta.RespuestaID, models.RespuestaPendiente).
+			Updates(map[string]interface{}{
+				"Estado":         estado,
+				"FechaRespuesta": now,
+			})
+		if result.Error != nil || result.RowsAffected == 0 {
+			return result.Error
+		}
+		resolved = true
+		return tx.Model(&models.Matches{}).
+			Where("EmparejamientoID = ?", respuesta.EmparejamientoID).
+			Update("EstadoEmparejamiento", estadoEmparejamiento).Error
+	})
+	if err != nil {
+		http.Error(w, "Error al actualizar la respuesta: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if !resolved {
+		http.Error(w, "La respuesta ya fue resuelta", http.StatusConflict)
+		return
+	}
+	respuesta.Estado = estado
+	respuesta.FechaRespuesta = &now
+
+	autor := loadUserDisplayName(h.DB, post.UsuarioID)
+	if estado == models.RespuestaAceptada {
+		h.notifyPostResponse(respuesta.UsuarioID, "post_response_accepted", "Aceptaron tu propuesta",
+			autor+" aceptó tu propuesta de intercambio", respuesta.RespuestaID)
+	} else {
+		h.notifyPostResponse(respuesta.UsuarioID, "post_response_declined", "Rechazaron tu propuesta",
+			autor+" rechazó tu propuesta de intercambio", respuesta.RespuestaID)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(respuesta)
+}
+
+// loadAuthorPost obtiene el post de la ruta y verifica que el usuario autenticado sea su autor
+func (h *postResponsesHandler) loadAuthorPost(w http.ResponseWriter, r *http.Request) (models.Post, bool) {
+	var post models.Post
+
+	user, authenticated := middleware.GetUserFromContext(r)
+	if !authenticated {
+		http.Error(w, "Autenticación requerida", http.StatusUnauthorized)
+		return post, false
+	}
+
+	postID, err := parseIDFromPath(r, "id")
+	if err != nil {
+		http.Error(w, "ID de post inválido", http.StatusBadRequest)
+		return post, false
+	}
+	if result := h.DB.First(&post, postID); result.Error != nil {
+		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+			http.Error(w, "Post no encontrado", http.StatusNotFound)
+		} else {
+			http.Error(w, "Error al buscar post: "+result.Error.Error(), http.StatusInternalServerError)
+		}
+		return post, false
+	}
+	if post.UsuarioID != user.UserID {
+		http.Error(w, "Solo el autor del post puede gestionar sus respuestas", http.StatusForbidden)
+		return post, false
+	}
+	return post, true
+}
+
+// notifyPostResponse crea la notificación de una respuesta si el usuario la tiene habilitada
+func (h *postResponsesHandler) notifyPostResponse(userID uint, tipo, titulo, contenido string, respuestaID uint) {
+	if !notificationEnabled(h.DB, userID, tipo) {
+		return
+	}
+	err := createNotification(h.DB, h.WSHandler, h.SocketIOBroadcaster, models.Notification{
+		UsuarioID:    userID,
+		Tipo:         tipo,
+		Titulo:       titulo,
+		Contenido:    contenido,
+		ReferenciaID: respuestaID,
+	})
+	logNotificationError(tipo, userID, err)
+}
+
+// respuestaEstadoTexto describe el estado de una respuesta ya resuelta
+func respuestaEstadoTexto(estado string) string {
+	if estado == models.RespuestaAceptada {
+		return "aceptada"
+	}
+	return "rechazada"
+}
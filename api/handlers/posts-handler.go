@@ -9,18 +9,45 @@ import (
 	"math"
 	"net/http"
 	"strconv"
+	"time"
 
+	"skillswap/api/contentfilter"
+	"skillswap/api/markdown"
+	"skillswap/api/middleware"
 	"skillswap/api/models"
+	"skillswap/api/search"
 
 	"gorm.io/gorm"
 )
 
 type postsHandler struct {
-	DB *gorm.DB
+	DB                  *gorm.DB
+	Searcher            search.Searcher
+	ContentFilter       *contentfilter.Pipeline
+	WSHandler           *WebSocketHandler
+	SocketIOBroadcaster *SocketIOBroadcaster
 }
 
 func NewPostsHandler(db *gorm.DB) *postsHandler {
-	return &postsHandler{DB: db}
+	return &postsHandler{
+		DB:                  db,
+		SocketIOBroadcaster: NewSocketIOBroadcaster(),
+	}
+}
+
+// SetWebSocketHandler configura el handler de WebSocket para los avisos de búsquedas guardadas
+func (h *postsHandler) SetWebSocketHandler(wsHandler *WebSocketHandler) {
+	h.WSHandler = wsHandler
+}
+
+// SetSearcher configura el índice de búsqueda que se actualiza con cada cambio
+func (h *postsHandler) SetSearcher(searcher search.Searcher) {
+	h.Searcher = searcher
+}
+
+// SetContentFilter configura el filtro automático que revisa el contenido antes de publicarlo
+func (h *postsHandler) SetContentFilter(filter *contentfilter.Pipeline) {
+	h.ContentFilter = filter
 }
 
 type PaginatedPostsFullInfoResponse struct {
@@ -29,6 +56,7 @@ type PaginatedPostsFullInfoResponse struct {
 		Page        int          `json:"page"`
 		PageSize    int          `json:"page_size"`
 		TotalPages  int          `json:"total_pages"`
+		Facetas     *models.FacetasPosts `json:"facets,omitempty"`
 }
 
 func (h *postsHandler) GetPosts(w http.ResponseWriter, r *http.Request) {
@@ -37,38 +65,38 @@ func (h *postsHandler) GetPosts(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Paginación
-	pageStr := r.URL.Query().Get("page")
-	pageSizeStr := r.URL.Query().Get("pageSize")
-	tipoPost := r.URL.Query().Get("tipo")
-	searchTerm := r.URL.Query().Get("search")
-
-	page, err := strconv.Atoi(pageStr)
-	if err != nil || page < 1 {
-		page = 1 // Valor por defecto para la página
-	}
-
-	pageSize, err := strconv.Atoi(pageSizeStr)
-	if err != nil || pageSize <= 0 {
-		// fmt.Println(err.Error())
-		pageSize = 3 // Valor por defecto para el tamaño de página
-	}
-
+	// Paginación (tamaño de página limitado a postsMaxPageSize)
+	page, pageSize := parsePostPagination(r, 10)
 	offset := (page - 1) * pageSize
 
 	var posts []models.PostFullInfo
 	var totalPosts int64
 
-	// Inicializar la consulta base
-	query := h.DB.Model(&models.PostFullInfo{})
+	// Estados a listar (por defecto solo los posts abiertos)
+	statuses, err := parsePostStatusFilter(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	// Inicializar la consulta base (sin los posts ocultos por moderación)
+	query := postsWithStatus(visiblePosts(h.DB.Model(&models.PostFullInfo{})), statuses)
 
 	// Aplicar filtros si están presentes
-	if tipoPost != "" {
-		query = query.Where("TipoPost = ?", tipoPost)
+	query, err = applyPostListFilters(query, r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
 	}
 
-	if searchTerm != "" {
-		query = query.Where("NombreHabilidad LIKE ?", "%"+searchTerm+"%")
+	// Ocultar los posts de usuarios bloqueados por el usuario autenticado
+	blockedIDs, err := viewerBlockedUserIDs(h.DB, r)
+	if err != nil {
+		http.Error(w, "Error al obtener bloqueos: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if len(blockedIDs) > 0 {
+		query = query.Where("UsuarioID NOT IN ?", blockedIDs)
 	}
 
 	// Contar el total de posts filtrados
@@ -77,11 +105,31 @@ func (h *postsHandler) GetPosts(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// Facetas sobre el listado filtrado, antes de paginar
+	facetas, err := loadPostFacets(h.DB, query)
+	if err != nil {
+		http.Error(w, "Error al contar facetas: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
 	// Obtener posts paginados con filtros
+	query, err = orderPosts(query, r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
 	if result := query.Limit(pageSize).Offset(offset).Find(&posts); result.Error != nil {
 		http.Error(w, "Error al obtener posts: "+result.Error.Error(), http.StatusInternalServerError)
 		return
 	}
+	if err := attachPostDetails(h.DB, posts); err != nil {
+		http.Error(w, "Error al obtener posts: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if err := markBookmarkedPosts(h.DB, commentViewerID(r), posts); err != nil {
+		http.Error(w, "Error al obtener marcadores: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
 
 	totalPages := int(totalPosts) / pageSize
 	if totalPosts>0 {
@@ -94,6 +142,7 @@ func (h *postsHandler) GetPosts(w http.ResponseWriter, r *http.Request) {
 		Page:       page,
 		PageSize:   pageSize,
 		TotalPages: totalPages,
+		Facetas:    facetas,
 	}
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(response)
@@ -113,7 +162,7 @@ func (h *postsHandler) GetPost(w http.ResponseWriter, r *http.Request) {
 	}
 
 	var post models.PostFullInfo
-	if result := h.DB.First(&post, id); result.Error != nil {
+	if result := visiblePosts(h.DB).First(&post, id); result.Error != nil {
 		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
 			http.Error(w, "Usuario no encontrado", http.StatusNotFound)
 		} else {
@@ -121,9 +170,18 @@ func (h *postsHandler) GetPost(w http.ResponseWriter, r *http.Request) {
 		}
 		return
 	}
+	posts := []models.PostFullInfo{post}
+	if err := attachPostDetails(h.DB, posts); err != nil {
+		http.Error(w, "Error al obtener post: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if err := markBookmarkedPosts(h.DB, commentViewerID(r), posts); err != nil {
+		http.Error(w, "Error al obtener marcadores: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
 
 	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(post)
+	json.NewEncoder(w).Encode(posts[0])
 }
 
 func (h *postsHandler) UpdatePost(w http.ResponseWriter, r *http.Request) {
@@ -138,6 +196,12 @@ func (h *postsHandler) UpdatePost(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	editor, authenticated := middleware.GetUserFromContext(r)
+	if !authenticated {
+		http.Error(w, "Autenticación requerida", http.StatusUnauthorized)
+		return
+	}
+
 	var req models.UpdatePostRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
 		http.Error(w, err.Error(), http.StatusBadRequest)
@@ -154,6 +218,21 @@ func (h *postsHandler) UpdatePost(w http.ResponseWriter, r *http.Request) {
 		}
 		return
 	}
+
+	// Solo el autor o un moderador pueden editar el post
+	if post.UsuarioID != editor.UserID {
+		moderator, err := isModerator(h.DB, editor.UserID)
+		if err != nil {
+			http.Error(w, "Error verificando permisos: "+err.Error(), http.StatusInternalServerError)
+			return
+		}
+		if !moderator {
+			http.Error(w, "Solo el autor o un moderador pueden editar el post", http.StatusForbidden)
+			return
+		}
+	}
+	// Versión anterior para el historial de ediciones
+	previous := post
 	if req.Descripcion != nil {
 		post.Descripcion = *req.Descripcion
 	}
@@ -173,16 +252,75 @@ func (h *postsHandler) UpdatePost(w http.ResponseWriter, r *http.Request) {
 	}
 
 	if req.Descripcion != nil {
+		if err := markdown.ValidateLength(*req.Descripcion, markdown.MaxPostLength); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
 		post.Descripcion = *req.Descripcion
 	}
+	// El HTML se vuelve a generar en cada edición para que nunca quede desfasado del texto fuente
+	post.DescripcionHTML = markdown.Render(post.Descripcion)
+
+	decision, ok := checkContent(w, h.ContentFilter, contentfilter.Content{
+		Type:     contentfilter.TypePost,
+		ID:       post.ID,
+		AuthorID: post.UsuarioID,
+		Text:     post.Descripcion,
+	})
+	if !ok {
+		return
+	}
 
+	// El contenido marcado por el filtro se guarda ya oculto, junto con su reporte, hasta que lo revise un moderador
+	held := decision.Verdict == contentfilter.Flag
+	if held {
+		post.Oculto = true
+	}
 
-	// Guardar los cambios en la base de datos
-	if result := h.DB.Save(&post); result.Error != nil {
-		http.Error(w, "Error al actualizar usuario: "+result.Error.Error(), http.StatusInternalServerError)
+	// Guardar los cambios en la base de datos junto con la revisión anterior si el contenido cambió
+	err = h.DB.Transaction(func(tx *gorm.DB) error {
+		if post.Descripcion != previous.Descripcion || post.HabilidadID != previous.HabilidadID {
+			now := time.Now()
+			post.EditadoEn = &now
+			post.TotalEdiciones++
+
+			revision := models.RevisionPost{
+				PostID:              post.ID,
+				DescripcionAnterior: previous.Descripcion,
+				HabilidadIDAnterior: &previous.HabilidadID,
+				EditorID:            &editor.UserID,
+				FechaEdicion:        now,
+			}
+			if err := tx.Create(&revision).Error; err != nil {
+				return err
+			}
+		}
+		if err := tx.Save(&post).Error; err != nil {
+			return err
+		}
+		if held {
+			return holdForModeration(tx, contentfilter.TypePost, post.ID, post.UsuarioID, decision)
+		}
+		return nil
+	})
+	if err != nil {
+		http.Error(w, "Error al actualizar usuario: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	if held {
+		unindexDocument(h.Searcher, search.TypePost, post.ID)
+		writeHeldForReview(w, contentfilter.TypePost, post.ID, decision)
 		return
 	}
 
+	// Reindexar el post con la habilidad actual; un post oculto por moderación sigue fuera del índice
+	if !post.Oculto {
+		var habilidad models.Ability
+		h.DB.First(&habilidad, post.HabilidadID)
+		indexDocument(h.Searcher, search.PostDocument(post, habilidad.Name))
+	}
+
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(post)
 }
@@ -199,10 +337,23 @@ func (h *postsHandler) DeletePost(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if result := h.DB.Delete(&models.Post{}, id); result.Error != nil {
-		http.Error(w, "Error al eliminar el usuario: "+result.Error.Error(), http.StatusInternalServerError)
+	// La eliminación queda registrada para que el feed Atom actualice su Last-Modified
+	err = h.DB.Transaction(func(tx *gorm.DB) error {
+		var post models.Post
+		if err := tx.Select("PostID", "HabilidadID", "TipoPost").First(&post, id).Error; err != nil {
+			return err
+		}
+		if err := tx.Create(&models.PostEliminado{PostID: post.ID, HabilidadID: post.HabilidadID, TipoPost: post.TipoPost}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&post).Error
+	})
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		http.Error(w, "Error al eliminar el usuario: "+err.Error(), http.StatusInternalServerError)
 		return
 	}
+	unindexDocument(h.Searcher, search.TypePost, uint(id))
+	deleteBookmarksOf(h.DB, models.MarcadorPost, uint(id))
 
 	w.WriteHeader(http.StatusNoContent)
 }
@@ -238,6 +389,29 @@ func (h *postsHandler) CreatePost(w http.ResponseWriter, r *http.Request) {
 		http.Error(w, "Datos incompletos para crear el post", http.StatusBadRequest)
 		return
 	}
+	if err := markdown.ValidateLength(req.Descripcion, markdown.MaxPostLength); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	decision, ok := checkContent(w, h.ContentFilter, contentfilter.Content{
+		Type:     contentfilter.TypePost,
+		AuthorID: req.UsuarioID,
+		Text:     req.Descripcion,
+	})
+	if !ok {
+		return
+	}
+
+	// La expiración es opcional; si no se indica se usa la predeterminada
+	now := time.Now()
+	expiresAt := defaultPostExpiry(now)
+	if req.ExpiresAt != nil {
+		if !req.ExpiresAt.After(now) {
+			http.Error(w, "La fecha de expiración debe ser futura", http.StatusBadRequest)
+			return
+		}
+		expiresAt = req.ExpiresAt
+	}
 
 	// Verificar que la habilidad existe
 	var habilidad models.Ability
@@ -259,12 +433,33 @@ func (h *postsHandler) CreatePost(w http.ResponseWriter, r *http.Request) {
 		TipoPost:    req.TipoPost,
 		HabilidadID: req.HabilidadID,
 		Descripcion: req.Descripcion,
+		DescripcionHTML: markdown.Render(req.Descripcion),
+		Estado:      models.PostAbierto,
+		ExpiresAt:   expiresAt,
 	}
 
-	if result := h.DB.Create(&post); result.Error != nil {
-		http.Error(w, "Error al crear el post: "+result.Error.Error(), http.StatusInternalServerError)
+	// El post marcado por el filtro se crea ya oculto y su reporte se guarda en la misma transacción
+	held := decision.Verdict == contentfilter.Flag
+	post.Oculto = held
+	err := h.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&post).Error; err != nil {
+			return err
+		}
+		if held {
+			return holdForModeration(tx, contentfilter.TypePost, post.ID, post.UsuarioID, decision)
+		}
+		return nil
+	})
+	if err != nil {
+		http.Error(w, "Error al crear el post: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if held {
+		writeHeldForReview(w, contentfilter.TypePost, post.ID, decision)
 		return
 	}
+	indexDocument(h.Searcher, search.PostDocument(post, habilidad.Name))
+	go evaluateSavedSearches(h.DB, h.WSHandler, h.SocketIOBroadcaster, post)
 
 	// Devolver el post creado
 	w.Header().Set("Content-Type", "application/json")
@@ -285,33 +480,56 @@ func (h *postsHandler) GetPostsByUserID(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
-	// Paginación
-    pageStr := r.URL.Query().Get("page")
-    pageSizeStr := r.URL.Query().Get("pageSize")
-    page, pErr := strconv.Atoi(pageStr)
-    if pErr != nil || page < 1 {
-        page = 1
-    }
-    pageSize, psErr := strconv.Atoi(pageSizeStr)
-    if psErr != nil || pageSize <= 0 {
-        pageSize = 10
-    }
-    offset := (page - 1) * pageSize
+	// Paginación (tamaño de página limitado a postsMaxPageSize)
+	page, pageSize := parsePostPagination(r, 10)
+	offset := (page - 1) * pageSize
 
 
+	// Estados a listar (por defecto solo los posts abiertos)
+	statuses, err := parsePostStatusFilter(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
 	var posts []models.PostFullInfo
 	var totalPosts int64
-	query := h.DB.Model(&models.PostFullInfo{}).Where("UsuarioID = ?", userID) // <--- CAMBIO (necesita UsuarioID en la vista)
+	query := visiblePosts(h.DB.Model(&models.PostFullInfo{})).Where("UsuarioID = ?", userID) // <--- CAMBIO (necesita UsuarioID en la vista)
+	query = postsWithStatus(query, statuses)
+	query, err = applyPostListFilters(query, r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
 
 	if err := query.Count(&totalPosts).Error; err != nil {
         http.Error(w, "Error al contar posts del usuario: "+err.Error(), http.StatusInternalServerError)
         return
     }
 
-    if result := query.Limit(pageSize).Offset(offset).Order("CreatedAt desc").Find(&posts); result.Error != nil { // <--- CAMBIO
+	facetas, err := loadPostFacets(h.DB, query)
+	if err != nil {
+		http.Error(w, "Error al contar facetas: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	query, err = orderPosts(query, r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+    if result := query.Limit(pageSize).Offset(offset).Find(&posts); result.Error != nil { // <--- CAMBIO
         http.Error(w, "Error al obtener posts del usuario: "+result.Error.Error(), http.StatusInternalServerError)
         return
     }
+	if err := attachPostDetails(h.DB, posts); err != nil {
+		http.Error(w, "Error al obtener posts del usuario: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if err := markBookmarkedPosts(h.DB, commentViewerID(r), posts); err != nil {
+		http.Error(w, "Error al obtener marcadores: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
 
     response := PaginatedPostsFullInfoResponse{ // <--- CAMBIO
         Posts:      posts,
@@ -319,8 +537,63 @@ func (h *postsHandler) GetPostsByUserID(w http.ResponseWriter, r *http.Request)
         Page:       page,
         PageSize:   pageSize,
         TotalPages: int(math.Ceil(float64(totalPosts) / float64(pageSize))),
+        Facetas:    facetas,
     }
 
     w.Header().Set("Content-Type", "application/json")
     json.NewEncoder(w).Encode(response)
 }
+
+// attachPostDetails completa el HTML de la descripción, las marcas de edición, la respuesta aceptada y
+// el ciclo de vida, que no forman parte de vw_PostFullInfo. Los posts anteriores a la columna
+// DescripcionHTML se renderizan al vuelo.
+func attachPostDetails(db *gorm.DB, posts []models.PostFullInfo) error {
+	if len(posts) == 0 {
+		return nil
+	}
+	ids := make([]uint, 0, len(posts))
+	for _, post := range posts {
+		ids = append(ids, post.PostID)
+	}
+
+	var rows []struct {
+		PostID          uint       `gorm:"column:PostID"`
+		DescripcionHTML string     `gorm:"column:DescripcionHTML"`
+		EditadoEn       *time.Time `gorm:"column:EditadoEn"`
+		TotalEdiciones  int        `gorm:"column:TotalEdiciones"`
+		RespuestaComentarioID *int `gorm:"column:RespuestaComentarioID"`
+		Estado          string     `gorm:"column:Estado"`
+		ExpiresAt       *time.Time `gorm:"column:ExpiresAt"`
+		EmparejamientoID *uint     `gorm:"column:EmparejamientoID"`
+		CumplidoEn      *time.Time `gorm:"column:CumplidoEn"`
+	}
+	if err := db.Model(&models.Post{}).
+		Select("PostID, ISNULL(DescripcionHTML, '') AS DescripcionHTML, EditadoEn, TotalEdiciones, RespuestaComentarioID, " +
+			"Estado, ExpiresAt, EmparejamientoID, CumplidoEn").
+		Where("PostID IN ?", ids).
+		Scan(&rows).Error; err != nil {
+		return err
+	}
+	now := time.Now()
+	byPost := make(map[uint]int, len(rows))
+	for i, row := range rows {
+		byPost[row.PostID] = i
+	}
+
+	for i := range posts {
+		if index, ok := byPost[posts[i].PostID]; ok {
+			posts[i].DescripcionHTML = rows[index].DescripcionHTML
+			posts[i].EditadoEn = rows[index].EditadoEn
+			posts[i].TotalEdiciones = rows[index].TotalEdiciones
+			posts[i].RespuestaComentarioID = rows[index].RespuestaComentarioID
+			posts[i].Estado = effectivePostStatus(rows[index].Estado, rows[index].ExpiresAt, now)
+			posts[i].ExpiresAt = rows[index].ExpiresAt
+			posts[i].EmparejamientoID = rows[index].EmparejamientoID
+			posts[i].CumplidoEn = rows[index].CumplidoEn
+		}
+		if posts[i].DescripcionHTML == "" {
+			posts[i].DescripcionHTML = markdown.Render(posts[i].Descripcion)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,498 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"skillswap/api/middleware"
+	"skillswap/api/models"
+	"skillswap/api/search"
+
+	"gorm.io/gorm"
+)
+
+const (
+	maxSavedSearchesPerUser          = 20
+	maxSavedSearchPosts              = 50
+	defaultSavedSearchAlertsPerHour  = 5
+	defaultSavedSearchDigestInterval = 24 * time.Hour
+	defaultSavedSearchDigestCheck    = time.Hour
+)
+
+var savedSearchFrequencies = []string{models.FrecuenciaInstantanea, models.FrecuenciaDiaria}
+
+type savedSearchesHandler struct {
+	DB *gorm.DB
+}
+
+func NewSavedSearchesHandler(db *gorm.DB) *savedSearchesHandler {
+	return &savedSearchesHandler{DB: db}
+}
+
+// GetSavedSearches lista las búsquedas guardadas del usuario autenticado
+func (h *savedSearchesHandler) GetSavedSearches(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Método no permitido", http.StatusMethodNotAllowed)
+		return
+	}
+
+	user, authenticated := middleware.GetUserFromContext(r)
+	if !authenticated {
+		http.Error(w, "Autenticación requerida", http.StatusUnauthorized)
+		return
+	}
+
+	busquedas := []models.BusquedaGuardada{}
+	if err := h.DB.Where("UsuarioID = ?", user.UserID).Order("FechaCreacion DESC").Find(&busquedas).Error; err != nil {
+		http.Error(w, "Error al obtener búsquedas guardadas: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(busquedas)
+}
+
+// CreateSavedSearch guarda una búsqueda de posts para recibir avisos de los nuevos que coincidan
+func (h *savedSearchesHandler) CreateSavedSearch(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Método no permitido", http.StatusMethodNotAllowed)
+		return
+	}
+
+	user, authenticated := middleware.GetUserFromContext(r)
+	if !authenticated {
+		http.Error(w, "Autenticación requerida", http.StatusUnauthorized)
+		return
+	}
+
+	var req models.BusquedaGuardadaRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Error al decodificar la solicitud: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	var total int64
+	if err := h.DB.Model(&models.BusquedaGuardada{}).Where("UsuarioID = ?", user.UserID).Count(&total).Error; err != nil {
+		http.Error(w, "Error al contar búsquedas guardadas: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if total >= maxSavedSearchesPerUser {
+		http.Error(w, fmt.Sprintf("Solo puedes tener %d búsquedas guardadas", maxSavedSearchesPerUser), http.StatusConflict)
+		return
+	}
+
+	busqueda := models.BusquedaGuardada{
+		UsuarioID:  user.UserID,
+		Frecuencia: models.FrecuenciaInstantanea,
+		Activa:     true,
+	}
+	if err := applySavedSearchRequest(h.DB, &busqueda, req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if err := h.DB.Create(&busqueda).Error; err != nil {
+		http.Error(w, "Error al guardar la búsqueda: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(busqueda)
+}
+
+// UpdateSavedSearch modifica los criterios, la frecuencia o el estado de una búsqueda guardada
+func (h *savedSearchesHandler) UpdateSavedSearch(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPut {
+		http.Error(w, "Método no permitido", http.StatusMethodNotAllowed)
+		return
+	}
+
+	busqueda, ok := h.loadOwnSavedSearch(w, r)
+	if !ok {
+		return
+	}
+
+	var req models.BusquedaGuardadaRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Error al decodificar la solicitud: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	if err := applySavedSearchRequest(h.DB, &busqueda, req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	// Select("*") guarda también los criterios vaciados y Activa en false
+	if err := h.DB.Model(&busqueda).Select("*").Omit("BusquedaID", "UsuarioID", "FechaCreacion").Updates(&busqueda).Error; err != nil {
+		http.Error(w, "Error al actualizar la búsqueda: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(busqueda)
+}
+
+// DeleteSavedSearch elimina una búsqueda guardada y sus coincidencias
+func (h *savedSearchesHandler) DeleteSavedSearch(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodDelete {
+		http.Error(w, "Método no permitido", http.StatusMethodNotAllowed)
+		return
+	}
+
+	busqueda, ok := h.loadOwnSavedSearch(w, r)
+	if !ok {
+		return
+	}
+
+	err := h.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("BusquedaID = ?", busqueda.BusquedaID).Delete(&models.CoincidenciaBusqueda{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&busqueda).Error
+	})
+	if err != nil {
+		http.Error(w, "Error al eliminar la búsqueda: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// GetSavedSearchPosts devuelve los posts más recientes que coincidieron con una búsqueda guardada
+func (h *savedSearchesHandler) GetSavedSearchPosts(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Método no permitido", http.StatusMethodNotAllowed)
+		return
+	}
+
+	busqueda, ok := h.loadOwnSavedSearch(w, r)
+	if !ok {
+		return
+	}
+
+	posts := []models.PostFullInfo{}
+	query := visiblePosts(h.DB.Model(&models.PostFullInfo{})).
+		Where("PostID IN (SELECT PostID FROM CoincidenciasBusqueda WHERE BusquedaID = ?)", busqueda.BusquedaID)
+	if err := query.Order("CreatedAt DESC").Limit(maxSavedSearchPosts).Find(&posts).Error; err != nil {
+		http.Error(w, "Error al obtener posts: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if err := attachPostDetails(h.DB, posts); err != nil {
+		http.Error(w, "Error al obtener posts: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if err := markBookmarkedPosts(h.DB, busqueda.UsuarioID, posts); err != nil {
+		http.Error(w, "Error al obtener marcadores: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"busqueda": busqueda,
+		"posts":    posts,
+	})
+}
+
+// loadOwnSavedSearch obtiene la búsqueda de la ruta verificando que pertenezca al usuario autenticado
+func (h *savedSearchesHandler) loadOwnSavedSearch(w http.ResponseWriter, r *http.Request) (models.BusquedaGuardada, bool) {
+	var busqueda models.BusquedaGuardada
+
+	user, authenticated := middleware.GetUserFromContext(r)
+	if !authenticated {
+		http.Error(w, "Autenticación requerida", http.StatusUnauthorized)
+		return busqueda, false
+	}
+
+	searchID, err := parseIDFromPath(r, "searchID")
+	if err != nil {
+		http.Error(w, "ID de búsqueda inválido", http.StatusBadRequest)
+		return busqueda, false
+	}
+
+	if result := h.DB.Where("BusquedaID = ? AND UsuarioID = ?", searchID, user.UserID).First(&busqueda); result.Error != nil {
+		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+			http.Error(w, "Búsqueda guardada no encontrada", http.StatusNotFound)
+		} else {
+			http.Error(w, "Error al buscar la búsqueda guardada: "+result.Error.Error(), http.StatusInternalServerError)
+		}
+		return busqueda, false
+	}
+	return busqueda, true
+}
+
+// applySavedSearchRequest valida la solicitud y copia los campos indicados a la búsqueda
+func applySavedSearchRequest(db *gorm.DB, busqueda *models.BusquedaGuardada, req models.BusquedaGuardadaRequest) error {
+	if req.Nombre != nil {
+		busqueda.Nombre = strings.TrimSpace(*req.Nombre)
+	}
+	if req.HabilidadID != nil {
+		if *req.HabilidadID == 0 {
+			busqueda.HabilidadID = nil
+		} else {
+			var habilidad models.Ability
+			if err := db.First(&habilidad, *req.HabilidadID).Error; err != nil {
+				return errors.New("Habilidad no encontrada")
+			}
+			busqueda.HabilidadID = req.HabilidadID
+		}
+	}
+	if req.Categoria != nil {
+		busqueda.Categoria = strings.TrimSpace(*req.Categoria)
+	}
+	if req.TipoPost != nil {
+		busqueda.TipoPost = strings.ToUpper(strings.TrimSpace(*req.TipoPost))
+	}
+	if req.Ciudad != nil {
+		busqueda.Ciudad = strings.TrimSpace(*req.Ciudad)
+	}
+	if req.PalabrasClave != nil {
+		busqueda.PalabrasClave = strings.TrimSpace(*req.PalabrasClave)
+	}
+	if req.Frecuencia != nil {
+		busqueda.Frecuencia = strings.ToLower(strings.TrimSpace(*req.Frecuencia))
+	}
+	if req.Activa != nil {
+		busqueda.Activa = *req.Activa
+	}
+
+	switch {
+	case busqueda.Nombre == "":
+		return errors.New("El nombre es obligatorio")
+	case utf8.RuneCountInString(busqueda.Nombre) > 100:
+		return errors.New("El nombre no puede superar los 100 caracteres")
+	case utf8.RuneCountInString(busqueda.Categoria) > 50:
+		return errors.New("La categoría no puede superar los 50 caracteres")
+	case utf8.RuneCountInString(busqueda.TipoPost) > 20:
+		return errors.New("El tipo de post no puede superar los 20 caracteres")
+	case utf8.RuneCountInString(busqueda.Ciudad) > 100:
+		return errors.New("La ciudad no puede superar los 100 caracteres")
+	case utf8.RuneCountInString(busqueda.PalabrasClave) > 200:
+		return errors.New("Las palabras clave no pueden superar los 200 caracteres")
+	case !containsString(savedSearchFrequencies, busqueda.Frecuencia):
+		return errors.New("Frecuencia inválida: use " + strings.Join(savedSearchFrequencies, " o "))
+	case busqueda.HabilidadID == nil && busqueda.Categoria == "" && busqueda.TipoPost == "" &&
+		busqueda.Ciudad == "" && busqueda.PalabrasClave == "":
+		return errors.New("Indica al menos un criterio de búsqueda")
+	}
+	return nil
+}
+
+// savedSearchPost reúne los datos de un post nuevo que se comparan con las búsquedas guardadas
+type savedSearchPost struct {
+	Post      models.Post
+	Categoria string
+	Ciudad    string
+	Terminos  []string // Términos normalizados de la descripción y del nombre de la habilidad
+}
+
+// matches indica si el post cumple todos los criterios no vacíos de la búsqueda
+func (p savedSearchPost) matches(busqueda models.BusquedaGuardada) bool {
+	if busqueda.HabilidadID != nil && *busqueda.HabilidadID != p.Post.HabilidadID {
+		return false
+	}
+	if busqueda.Categoria != "" && !strings.EqualFold(busqueda.Categoria, p.Categoria) {
+		return false
+	}
+	if busqueda.TipoPost != "" && !strings.EqualFold(busqueda.TipoPost, p.Post.TipoPost) {
+		return false
+	}
+	if busqueda.Ciudad != "" && normalizedPhrase(busqueda.Ciudad) != normalizedPhrase(p.Ciudad) {
+		return false
+	}
+	// Cada palabra clave debe aparecer como inicio de algún término del post
+	for _, keyword := range search.Tokenize(busqueda.PalabrasClave) {
+		found := false
+		for _, term := range p.Terminos {
+			if strings.HasPrefix(term, keyword) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+// normalizedPhrase compara textos sin distinguir mayúsculas, acentos ni espacios
+func normalizedPhrase(text string) string {
+	return strings.Join(search.Tokenize(text), " ")
+}
+
+// evaluateSavedSearches compara un post recién publicado con las búsquedas guardadas activas.
+// Registra las coincidencias y avisa al momento a los usuarios con frecuencia instantánea que no
+// superaron el límite por hora; el resto queda pendiente para el resumen periódico. Las búsquedas que
+// ya coincidieron con el post (por ejemplo, antes de que un moderador lo ocultara) no se vuelven a avisar.
+func evaluateSavedSearches(db *gorm.DB, wsHandler *WebSocketHandler, broadcaster *SocketIOBroadcaster, post models.Post) {
+	var habilidad models.Ability
+	db.First(&habilidad, post.HabilidadID)
+	var autor models.User
+	db.Select("UsuarioID", "CiudadTrabajo").First(&autor, post.UsuarioID)
+
+	candidate := savedSearchPost{
+		Post:      post,
+		Categoria: habilidad.Category,
+		Ciudad:    autor.CiudadTrabajo,
+		Terminos:  search.Tokenize(post.Descripcion + " " + habilidad.Name),
+	}
+
+	// Los criterios de igualdad se filtran en SQL; la ciudad y las palabras clave en memoria
+	var busquedas []models.BusquedaGuardada
+	if err := db.Where("Activa = 1 AND UsuarioID <> ?", post.UsuarioID).
+		Where("HabilidadID IS NULL OR HabilidadID = ?", post.HabilidadID).
+		Where("Categoria IS NULL OR Categoria = '' OR Categoria = ?", habilidad.Category).
+		Where("TipoPost IS NULL OR TipoPost = '' OR TipoPost = ?", post.TipoPost).
+		Order("UsuarioID, BusquedaID").
+		Find(&busquedas).Error; err != nil {
+		log.Printf("Error cargando búsquedas guardadas: %v", err)
+		return
+	}
+
+	notified := make(map[uint]bool)
+	skipped := make(map[uint]bool)
+	for _, busqueda := range busquedas {
+		if skipped[busqueda.UsuarioID] || !candidate.matches(busqueda) {
+			continue
+		}
+		if blocked, err := isBlockedBetween(db, busqueda.UsuarioID, post.UsuarioID); err != nil || blocked {
+			skipped[busqueda.UsuarioID] = true
+			continue
+		}
+
+		coincidencia := models.CoincidenciaBusqueda{BusquedaID: busqueda.BusquedaID, PostID: post.ID}
+		result := db.Where(&coincidencia).FirstOrCreate(&coincidencia)
+		if result.Error != nil {
+			log.Printf("Error registrando coincidencia de la búsqueda %d: %v", busqueda.BusquedaID, result.Error)
+			continue
+		}
+		if result.RowsAffected == 0 {
+			continue
+		}
+
+		// Un mismo post avisa una sola vez a cada usuario aunque coincida con varias búsquedas
+		if busqueda.Frecuencia != models.FrecuenciaInstantanea || notified[busqueda.UsuarioID] {
+			continue
+		}
+		if !notificationEnabled(db, busqueda.UsuarioID, "saved_search_match") || savedSearchAlertLimitReached(db, busqueda.UsuarioID) {
+			continue
+		}
+
+		err := createNotification(db, wsHandler, broadcaster, models.Notification{
+			UsuarioID:    busqueda.UsuarioID,
+			Tipo:         "saved_search_match",
+			Titulo:       "Nuevo post para «" + busqueda.Nombre + "»",
+			Contenido:    habilidad.Name + ": " + post.Descripcion,
+			ReferenciaID: post.ID,
+		})
+		logNotificationError("saved_search_match", busqueda.UsuarioID, err)
+		if err != nil {
+			continue
+		}
+		notified[busqueda.UsuarioID] = true
+		now := time.Now()
+		db.Model(&coincidencia).UpdateColumn("FechaNotificacion", now)
+	}
+}
+
+// savedSearchAlertLimitReached indica si el usuario ya recibió el máximo de avisos instantáneos en
+// la última hora (SAVED_SEARCH_ALERTS_PER_HOUR). Los posts excedentes se incluyen en el resumen.
+func savedSearchAlertLimitReached(db *gorm.DB, userID uint) bool {
+	limit := defaultSavedSearchAlertsPerHour
+	if value, err := strconv.Atoi(os.Getenv("SAVED_SEARCH_ALERTS_PER_HOUR")); err == nil && value >= 0 {
+		limit = value
+	}
+
+	var sent int64
+	if err := db.Model(&models.Notification{}).
+		Where("UsuarioID = ? AND Tipo = ? AND FechaCreacion >= ?", userID, "saved_search_match", time.Now().Add(-time.Hour)).
+		Count(&sent).Error; err != nil {
+		return true
+	}
+	return sent >= int64(limit)
+}
+
+// SendSavedSearchDigests envía un resumen por cada búsqueda con coincidencias pendientes cuyo último
+// resumen tenga al menos un día. Devuelve la cantidad de resúmenes enviados.
+func SendSavedSearchDigests(db *gorm.DB, wsHandler *WebSocketHandler, broadcaster *SocketIOBroadcaster) (int, error) {
+	now := time.Now()
+	var pendientes []struct {
+		BusquedaID uint `gorm:"column:BusquedaID"`
+		Total      int  `gorm:"column:Total"`
+	}
+	if err := db.Table("CoincidenciasBusqueda c").
+		Select("c.BusquedaID, COUNT(*) AS Total").
+		Joins("INNER JOIN BusquedasGuardadas b ON b.BusquedaID = c.BusquedaID").
+		Joins("INNER JOIN Posts p ON p.PostID = c.PostID").
+		Where("c.FechaNotificacion IS NULL AND b.Activa = 1 AND p.Oculto = 0").
+		Where("b.UltimoResumen IS NULL OR b.UltimoResumen <= ?", now.Add(-defaultSavedSearchDigestInterval)).
+		Group("c.BusquedaID").
+		Scan(&pendientes).Error; err != nil {
+		return 0, err
+	}
+
+	sent := 0
+	for _, pendiente := range pendientes {
+		var busqueda models.BusquedaGuardada
+		if err := db.First(&busqueda, pendiente.BusquedaID).Error; err != nil {
+			continue
+		}
+
+		if notificationEnabled(db, busqueda.UsuarioID, "saved_search_digest") {
+			contenido := fmt.Sprintf("Hay %d posts nuevos que coinciden con tu búsqueda «%s»", pendiente.Total, busqueda.Nombre)
+			if pendiente.Total == 1 {
+				contenido = "Hay un post nuevo que coincide con tu búsqueda «" + busqueda.Nombre + "»"
+			}
+			err := createNotification(db, wsHandler, broadcaster, models.Notification{
+				UsuarioID:    busqueda.UsuarioID,
+				Tipo:         "saved_search_digest",
+				Titulo:       "Resumen de tus búsquedas guardadas",
+				Contenido:    contenido,
+				ReferenciaID: busqueda.BusquedaID,
+			})
+			logNotificationError("saved_search_digest", busqueda.UsuarioID, err)
+			if err != nil {
+				continue
+			}
+			sent++
+		}
+
+		// Las coincidencias se dan por avisadas aunque el usuario haya desactivado los resúmenes
+		db.Model(&models.CoincidenciaBusqueda{}).
+			Where("BusquedaID = ? AND FechaNotificacion IS NULL", busqueda.BusquedaID).
+			UpdateColumn("FechaNotificacion", now)
+		db.Model(&busqueda).UpdateColumn("UltimoResumen", now)
+	}
+	return sent, nil
+}
+
+// RunSavedSearchDigestJob envía periódicamente los resúmenes de búsquedas guardadas. El intervalo de
+// revisión se configura en minutos con SAVED_SEARCH_DIGEST_CHECK_MINUTES (por defecto cada hora).
+func RunSavedSearchDigestJob(db *gorm.DB, wsHandler *WebSocketHandler) {
+	period := defaultSavedSearchDigestCheck
+	if minutes, err := strconv.Atoi(os.Getenv("SAVED_SEARCH_DIGEST_CHECK_MINUTES")); err == nil && minutes > 0 {
+		period = time.Duration(minutes) * time.Minute
+	}
+
+	broadcaster := NewSocketIOBroadcaster()
+	ticker := time.NewTicker(period)
+	defer ticker.Stop()
+	for {
+		sent, err := SendSavedSearchDigests(db, wsHandler, broadcaster)
+		if err != nil {
+			log.Printf("Error enviando resúmenes de búsquedas guardadas: %v", err)
+		} else if sent > 0 {
+			log.Printf("Resúmenes de búsquedas guardadas enviados: %d", sent)
+		}
+		<-ticker.C
+	}
+}
@@ -0,0 +1,145 @@
+package handlers
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"skillswap/api/middleware"
+	"skillswap/api/models"
+	"skillswap/api/search"
+
+	"gorm.io/gorm"
+)
+
+type searchHandler struct {
+	DB       *gorm.DB
+	Searcher search.Searcher
+}
+
+func NewSearchHandler(db *gorm.DB, searcher search.Searcher) *searchHandler {
+	return &searchHandler{DB: db, Searcher: searcher}
+}
+
+// SearchResponse representa la respuesta paginada de la búsqueda unificada
+type SearchResponse struct {
+	Query      string          `json:"query"`
+	Type       string          `json:"type"`
+	Results    []search.Result `json:"results"`
+	Total      int             `json:"total"`
+	Page       int             `json:"page"`
+	PageSize   int             `json:"page_size"`
+	TotalPages int             `json:"total_pages"`
+}
+
+// Search busca en posts, comentarios, mensajes y usuarios.
+// Los mensajes solo se devuelven si pertenecen a conversaciones del usuario autenticado.
+func (h *searchHandler) Search(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Método no permitido", http.StatusMethodNotAllowed)
+		return
+	}
+
+	queryText := strings.TrimSpace(r.URL.Query().Get("q"))
+	if queryText == "" {
+		http.Error(w, "El parámetro q es requerido", http.StatusBadRequest)
+		return
+	}
+
+	docType := r.URL.Query().Get("type")
+	if docType == "" {
+		docType = "all"
+	}
+	if docType != "all" && !search.ValidType(docType) {
+		http.Error(w, "Tipo inválido: use post, comment, message, user o all", http.StatusBadRequest)
+		return
+	}
+
+	// Paginación
+	page, err := strconv.Atoi(r.URL.Query().Get("page"))
+	if err != nil || page < 1 {
+		page = 1
+	}
+	pageSize, err := strconv.Atoi(r.URL.Query().Get("pageSize"))
+	if err != nil || pageSize <= 0 || pageSize > 50 {
+		pageSize = 20
+	}
+
+	q := search.Query{
+		Text:   queryText,
+		Limit:  pageSize,
+		Offset: (page - 1) * pageSize,
+	}
+	if docType != "all" {
+		q.Types = []string{docType}
+	}
+
+	// Los mensajes solo son visibles para los participantes de la conversación
+	if user, authenticated := middleware.GetUserFromContext(r); authenticated {
+		conversationIDs, err := userConversationIDs(h.DB, user.UserID)
+		if err != nil {
+			http.Error(w, "Error al obtener conversaciones: "+err.Error(), http.StatusInternalServerError)
+			return
+		}
+		q.ConversationIDs = conversationIDs
+	}
+
+	blockedIDs, err := viewerBlockedUserIDs(h.DB, r)
+	if err != nil {
+		http.Error(w, "Error al obtener bloqueos: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+	q.ExcludeOwnerIDs = blockedIDs
+
+	results, err := h.Searcher.Search(q)
+	if err != nil {
+		log.Printf("Error en la búsqueda: %v", err)
+		http.Error(w, "Error al realizar la búsqueda: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	response := SearchResponse{
+		Query:      queryText,
+		Type:       docType,
+		Results:    results.Results,
+		Total:      results.Total,
+		Page:       page,
+		PageSize:   pageSize,
+		TotalPages: (results.Total + pageSize - 1) / pageSize,
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
+
+// userConversationIDs devuelve los IDs de las conversaciones en las que participa un usuario
+func userConversationIDs(db *gorm.DB, userID uint) ([]uint, error) {
+	var ids []uint
+	err := db.Model(&models.Conversation{}).
+		Where("User1ID = ? OR User2ID = ? OR ID IN (?)", userID, userID,
+			db.Model(&models.ConversationParticipant{}).Select("ConversationID").Where("UserID = ?", userID)).
+		Pluck("ID", &ids).Error
+	return ids, err
+}
+
+// indexDocument agrega un documento al índice de búsqueda registrando los errores
+func indexDocument(searcher search.Searcher, doc search.Document) {
+	if searcher == nil {
+		return
+	}
+	if err := searcher.Index(doc); err != nil {
+		log.Printf("Error indexando %s %d: %v", doc.Type, doc.ID, err)
+	}
+}
+
+// unindexDocument elimina un documento del índice de búsqueda registrando los errores
+func unindexDocument(searcher search.Searcher, docType string, id uint) {
+	if searcher == nil {
+		return
+	}
+	if err := searcher.Delete(docType, id); err != nil {
+		log.Printf("Error eliminando %s %d del índice: %v", docType, id, err)
+	}
+}
@@ -0,0 +1,604 @@
+package handlers
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"net/http"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"skillswap/api/models"
+
+	"gorm.io/gorm"
+)
+
+const (
+	// Meses de historia que se guardan para las tendencias
+	skillAnalyticsTrendMonths    = 24
+	defaultSkillAnalyticsTrend   = 12
+	defaultSkillAnalyticsRefresh = time.Hour
+)
+
+// skillAnalyticsSnapshot son los datos agregados en la última recomputación. Los reportes filtran y
+// agrupan en memoria a partir de estas filas.
+type skillAnalyticsSnapshot struct {
+	GeneradoEn    time.Time
+	Habilidades   map[uint]skillAnalyticsSkill
+	Declaraciones []skillAnalyticsDeclaration // Usuario que ofrece o busca cada habilidad, con su ciudad
+	OfertaDemanda []skillAnalyticsSupplyRow   // Posts por habilidad y ciudad
+	Tendencias    []skillAnalyticsTrendRow    // Por habilidad y mes
+	Conversion    []skillAnalyticsMatchRow    // Por habilidad
+}
+
+type skillAnalyticsSkill struct {
+	Nombre    string
+	Categoria string
+}
+
+// skillAnalyticsDeclaration es una habilidad declarada por un usuario. Se guarda por usuario para que
+// los reportes agrupados cuenten usuarios distintos y no declaraciones.
+type skillAnalyticsDeclaration struct {
+	HabilidadID uint   `gorm:"column:HabilidadID"`
+	Ciudad      string `gorm:"column:Ciudad"`
+	UsuarioID   uint   `gorm:"column:UsuarioID"`
+	Ofrece      bool   `gorm:"column:Ofrece"` // false = la busca
+}
+
+type skillAnalyticsSupplyRow struct {
+	HabilidadID  uint   `gorm:"column:HabilidadID"`
+	Ciudad       string `gorm:"column:Ciudad"`
+	PostsOfrezco int64  `gorm:"column:PostsOfrezco"`
+	PostsBusco   int64  `gorm:"column:PostsBusco"`
+}
+
+type skillAnalyticsTrendRow struct {
+	HabilidadID     uint   `gorm:"column:HabilidadID"`
+	Mes             string `gorm:"column:Mes"`
+	Ofrecen         int64  `gorm:"column:Ofrecen"`
+	Buscan          int64  `gorm:"column:Buscan"`
+	PostsOfrezco    int64  `gorm:"column:PostsOfrezco"`
+	PostsBusco      int64  `gorm:"column:PostsBusco"`
+	Emparejamientos int64  `gorm:"column:Emparejamientos"`
+}
+
+type skillAnalyticsMatchRow struct {
+	HabilidadID     uint  `gorm:"column:HabilidadID"`
+	Emparejamientos int64 `gorm:"column:Emparejamientos"`
+	Convertidos     int64 `gorm:"column:Convertidos"`
+	Rechazados      int64 `gorm:"column:Rechazados"`
+}
+
+// skillAnalyticsFilter son los filtros comunes de los reportes
+type skillAnalyticsFilter struct {
+	Ciudad      string
+	Categoria   string
+	Habilidades []uint
+}
+
+type skillAnalyticsHandler struct {
+	DB       *gorm.DB
+	mu       sync.RWMutex
+	snapshot *skillAnalyticsSnapshot
+}
+
+func NewSkillAnalyticsHandler(db *gorm.DB) *skillAnalyticsHandler {
+	return &skillAnalyticsHandler{DB: db}
+}
+
+// GetSupplyDemand devuelve cuántos usuarios ofrecen y buscan cada habilidad, con el índice de escasez.
+// Filtros: ciudad, categoria, habilidad_id; orden con sort (shortage, demand, supply, name) y limit.
+func (h *skillAnalyticsHandler) GetSupplyDemand(w http.ResponseWriter, r *http.Request) {
+	snapshot, filter, ok := h.prepareReport(w, r)
+	if !ok {
+		return
+	}
+
+	rows := aggregateSupplyDemand(snapshot, filter, func(row skillAnalyticsSupplyRow) string {
+		return strconv.FormatUint(uint64(row.HabilidadID), 10)
+	}, func(row skillAnalyticsSupplyRow, item *models.OfertaDemanda) {
+		skill := snapshot.Habilidades[row.HabilidadID]
+		item.HabilidadID, item.Nombre, item.Categoria = row.HabilidadID, skill.Nombre, skill.Categoria
+		item.Ciudad = filter.Ciudad
+	})
+	rows, err := sortSupplyDemand(rows, r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	header := []string{"skill_id", "skill", "category", "offered_by", "sought_by", "offer_posts", "request_posts", "shortage_ratio"}
+	writeAnalyticsReport(w, r, "oferta-demanda-habilidades", snapshot.GeneradoEn, rows, header, len(rows), func(i int) []string {
+		row := rows[i]
+		return append([]string{strconv.FormatUint(uint64(row.HabilidadID), 10), row.Nombre, row.Categoria}, supplyDemandCounts(row)...)
+	})
+}
+
+// GetSupplyDemandByCity agrupa la oferta y la demanda por CiudadTrabajo de los usuarios.
+// Acepta categoria y habilidad_id para acotar las habilidades consideradas.
+func (h *skillAnalyticsHandler) GetSupplyDemandByCity(w http.ResponseWriter, r *http.Request) {
+	snapshot, filter, ok := h.prepareReport(w, r)
+	if !ok {
+		return
+	}
+
+	rows := aggregateSupplyDemand(snapshot, filter, func(row skillAnalyticsSupplyRow) string {
+		return row.Ciudad
+	}, func(row skillAnalyticsSupplyRow, item *models.OfertaDemanda) {
+		item.Ciudad, item.Categoria = row.Ciudad, filter.Categoria
+	})
+	rows, err := sortSupplyDemand(rows, r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	header := []string{"city", "offered_by", "sought_by", "offer_posts", "request_posts", "shortage_ratio"}
+	writeAnalyticsReport(w, r, "oferta-demanda-ciudades", snapshot.GeneradoEn, rows, header, len(rows), func(i int) []string {
+		return append([]string{rows[i].Ciudad}, supplyDemandCounts(rows[i])...)
+	})
+}
+
+// GetSupplyDemandByCategory agrupa la oferta y la demanda por categoría de la habilidad.
+// Acepta ciudad para ver las categorías que faltan en una ciudad.
+func (h *skillAnalyticsHandler) GetSupplyDemandByCategory(w http.ResponseWriter, r *http.Request) {
+	snapshot, filter, ok := h.prepareReport(w, r)
+	if !ok {
+		return
+	}
+
+	rows := aggregateSupplyDemand(snapshot, filter, func(row skillAnalyticsSupplyRow) string {
+		return snapshot.Habilidades[row.HabilidadID].Categoria
+	}, func(row skillAnalyticsSupplyRow, item *models.OfertaDemanda) {
+		item.Categoria, item.Ciudad = snapshot.Habilidades[row.HabilidadID].Categoria, filter.Ciudad
+	})
+	rows, err := sortSupplyDemand(rows, r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	header := []string{"category", "offered_by", "sought_by", "offer_posts", "request_posts", "shortage_ratio"}
+	writeAnalyticsReport(w, r, "oferta-demanda-categorias", snapshot.GeneradoEn, rows, header, len(rows), func(i int) []string {
+		return append([]string{rows[i].Categoria}, supplyDemandCounts(rows[i])...)
+	})
+}
+
+// GetTrends devuelve por mes las habilidades declaradas, los posts y los matches creados en los
+// últimos months meses (12 por defecto, hasta 24). Acepta categoria y habilidad_id.
+func (h *skillAnalyticsHandler) GetTrends(w http.ResponseWriter, r *http.Request) {
+	snapshot, filter, ok := h.prepareReport(w, r)
+	if !ok {
+		return
+	}
+	if filter.Ciudad != "" {
+		http.Error(w, "Las tendencias no se pueden filtrar por ciudad", http.StatusBadRequest)
+		return
+	}
+	months := queryIntInRange(r, "months", defaultSkillAnalyticsTrend, 1, skillAnalyticsTrendMonths)
+
+	// Todos los meses del periodo, aunque no tengan actividad
+	start := time.Date(snapshot.GeneradoEn.Year(), snapshot.GeneradoEn.Month(), 1, 0, 0, 0, 0, time.Local).AddDate(0, 1-months, 0)
+	rows := make([]models.TendenciaMensual, months)
+	index := make(map[string]int, months)
+	for i := range rows {
+		rows[i].Mes = start.AddDate(0, i, 0).Format("2006-01")
+		index[rows[i].Mes] = i
+	}
+	for _, trend := range snapshot.Tendencias {
+		i, inRange := index[trend.Mes]
+		if !inRange || !filter.matchesSkill(snapshot, trend.HabilidadID) {
+			continue
+		}
+		rows[i].Ofrecen += trend.Ofrecen
+		rows[i].Buscan += trend.Buscan
+		rows[i].PostsOfrezco += trend.PostsOfrezco
+		rows[i].PostsBusco += trend.PostsBusco
+		rows[i].Emparejamientos += trend.Emparejamientos
+	}
+
+	header := []string{"month", "offered_by", "sought_by", "offer_posts", "request_posts", "matches"}
+	writeAnalyticsReport(w, r, "tendencias-habilidades", snapshot.GeneradoEn, rows, header, len(rows), func(i int) []string {
+		row := rows[i]
+		return []string{row.Mes, formatCount(row.Ofrecen), formatCount(row.Buscan), formatCount(row.PostsOfrezco),
+			formatCount(row.PostsBusco), formatCount(row.Emparejamientos)}
+	})
+}
+
+// GetMatchConversion devuelve por habilidad cuántos matches se crearon y qué proporción se concretó.
+// Acepta categoria y habilidad_id; se ordena por cantidad de matches o con sort=conversion.
+func (h *skillAnalyticsHandler) GetMatchConversion(w http.ResponseWriter, r *http.Request) {
+	snapshot, filter, ok := h.prepareReport(w, r)
+	if !ok {
+		return
+	}
+	if filter.Ciudad != "" {
+		http.Error(w, "La conversión no se puede filtrar por ciudad", http.StatusBadRequest)
+		return
+	}
+
+	rows := []models.ConversionHabilidad{}
+	for _, match := range snapshot.Conversion {
+		if !filter.matchesSkill(snapshot, match.HabilidadID) {
+			continue
+		}
+		skill := snapshot.Habilidades[match.HabilidadID]
+		row := models.ConversionHabilidad{
+			HabilidadID:     match.HabilidadID,
+			Nombre:          skill.Nombre,
+			Categoria:       skill.Categoria,
+			Emparejamientos: match.Emparejamientos,
+			Convertidos:     match.Convertidos,
+			Rechazados:      match.Rechazados,
+		}
+		if row.Emparejamientos > 0 {
+			row.TasaConversion = roundRatio(float64(row.Convertidos) / float64(row.Emparejamientos))
+		}
+		rows = append(rows, row)
+	}
+
+	sortBy := strings.ToLower(strings.TrimSpace(r.URL.Query().Get("sort")))
+	switch sortBy {
+	case "", "matches":
+		sort.SliceStable(rows, func(i, j int) bool { return rows[i].Emparejamientos > rows[j].Emparejamientos })
+	case "conversion":
+		sort.SliceStable(rows, func(i, j int) bool { return rows[i].TasaConversion > rows[j].TasaConversion })
+	default:
+		http.Error(w, "Orden inválido: use matches o conversion", http.StatusBadRequest)
+		return
+	}
+	if limit := queryIntInRange(r, "limit", 0, 1, math.MaxInt32); limit > 0 && len(rows) > limit {
+		rows = rows[:limit]
+	}
+
+	header := []string{"skill_id", "skill", "category", "matches", "converted", "rejected", "conversion_rate"}
+	writeAnalyticsReport(w, r, "conversion-matches", snapshot.GeneradoEn, rows, header, len(rows), func(i int) []string {
+		row := rows[i]
+		return []string{strconv.FormatUint(uint64(row.HabilidadID), 10), row.Nombre, row.Categoria, formatCount(row.Emparejamientos),
+			formatCount(row.Convertidos), formatCount(row.Rechazados), strconv.FormatFloat(row.TasaConversion, 'f', 4, 64)}
+	})
+}
+
+// RefreshAnalytics recalcula los datos en el momento, sin esperar la recomputación periódica
+func (h *skillAnalyticsHandler) RefreshAnalytics(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Método no permitido", http.StatusMethodNotAllowed)
+		return
+	}
+	if _, ok := authorizeAdmin(h.DB, w, r); !ok {
+		return
+	}
+
+	snapshot, err := h.Recompute()
+	if err != nil {
+		http.Error(w, "Error al calcular la analítica: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{"generated_at": snapshot.GeneradoEn})
+}
+
+// Recompute recalcula los agregados y reemplaza los datos en caché
+func (h *skillAnalyticsHandler) Recompute() (*skillAnalyticsSnapshot, error) {
+	snapshot, err := computeSkillAnalytics(h.DB)
+	if err != nil {
+		return nil, err
+	}
+	h.mu.Lock()
+	h.snapshot = snapshot
+	h.mu.Unlock()
+	return snapshot, nil
+}
+
+// RunRecomputeJob recalcula periódicamente la analítica. El intervalo se configura en minutos con
+// SKILL_ANALYTICS_REFRESH_MINUTES (por defecto cada hora).
+func (h *skillAnalyticsHandler) RunRecomputeJob() {
+	period := defaultSkillAnalyticsRefresh
+	if minutes, err := strconv.Atoi(os.Getenv("SKILL_ANALYTICS_REFRESH_MINUTES")); err == nil && minutes > 0 {
+		period = time.Duration(minutes) * time.Minute
+	}
+
+	ticker := time.NewTicker(period)
+	defer ticker.Stop()
+	for {
+		if _, err := h.Recompute(); err != nil {
+			log.Printf("Error calculando la analítica de habilidades: %v", err)
+		}
+		<-ticker.C
+	}
+}
+
+// prepareReport valida el método y el rol, obtiene los datos en caché y lee los filtros comunes
+func (h *skillAnalyticsHandler) prepareReport(w http.ResponseWriter, r *http.Request) (*skillAnalyticsSnapshot, skillAnalyticsFilter, bool) {
+	var filter skillAnalyticsFilter
+	if r.Method != http.MethodGet {
+		http.Error(w, "Método no permitido", http.StatusMethodNotAllowed)
+		return nil, filter, false
+	}
+	if _, ok := authorizeAdmin(h.DB, w, r); !ok {
+		return nil, filter, false
+	}
+
+	params := r.URL.Query()
+	filter.Ciudad = strings.TrimSpace(params.Get("ciudad"))
+	filter.Categoria = strings.TrimSpace(params.Get("categoria"))
+	for _, value := range splitQueryList(params.Get("habilidad_id")) {
+		id, err := strconv.ParseUint(value, 10, 64)
+		if err != nil || id == 0 {
+			http.Error(w, "habilidad_id inválido: "+value, http.StatusBadRequest)
+			return nil, filter, false
+		}
+		filter.Habilidades = append(filter.Habilidades, uint(id))
+	}
+	if format := strings.ToLower(params.Get("format")); format != "" && format != "json" && format != "csv" {
+		http.Error(w, "Formato inválido: use json o csv", http.StatusBadRequest)
+		return nil, filter, false
+	}
+
+	h.mu.RLock()
+	snapshot := h.snapshot
+	h.mu.RUnlock()
+	if snapshot == nil {
+		// Primera consulta antes de que termine la recomputación inicial
+		var err error
+		if snapshot, err = h.Recompute(); err != nil {
+			http.Error(w, "Error al calcular la analítica: "+err.Error(), http.StatusInternalServerError)
+			return nil, filter, false
+		}
+	}
+	return snapshot, filter, true
+}
+
+// matchesSkill indica si la habilidad pasa los filtros de categoría e IDs
+func (f skillAnalyticsFilter) matchesSkill(snapshot *skillAnalyticsSnapshot, habilidadID uint) bool {
+	if len(f.Habilidades) > 0 && !containsUint(f.Habilidades, habilidadID) {
+		return false
+	}
+	if f.Categoria != "" && !strings.EqualFold(snapshot.Habilidades[habilidadID].Categoria, f.Categoria) {
+		return false
+	}
+	return true
+}
+
+// aggregateSupplyDemand agrupa por la clave que devuelve groupKey las declaraciones y los posts por
+// habilidad y ciudad que pasan los filtros; describe completa los datos descriptivos de cada grupo.
+// Ofrecen y Buscan cuentan usuarios distintos en el grupo, aunque declaren varias de sus habilidades.
+func aggregateSupplyDemand(snapshot *skillAnalyticsSnapshot, filter skillAnalyticsFilter,
+	groupKey func(skillAnalyticsSupplyRow) string, describe func(skillAnalyticsSupplyRow, *models.OfertaDemanda)) []models.OfertaDemanda {
+	groups := make(map[string]*models.OfertaDemanda)
+	var order []string
+	group := func(row skillAnalyticsSupplyRow) (string, *models.OfertaDemanda) {
+		if filter.Ciudad != "" && !strings.EqualFold(row.Ciudad, filter.Ciudad) {
+			return "", nil
+		}
+		if !filter.matchesSkill(snapshot, row.HabilidadID) {
+			return "", nil
+		}
+		key := groupKey(row)
+		item, exists := groups[key]
+		if !exists {
+			item = &models.OfertaDemanda{}
+			describe(row, item)
+			groups[key] = item
+			order = append(order, key)
+		}
+		return key, item
+	}
+
+	type groupUser struct {
+		key       string
+		usuarioID uint
+		ofrece    bool
+	}
+	counted := make(map[groupUser]bool)
+	for _, declaration := range snapshot.Declaraciones {
+		key, item := group(skillAnalyticsSupplyRow{HabilidadID: declaration.HabilidadID, Ciudad: declaration.Ciudad})
+		if item == nil {
+			continue
+		}
+		user := groupUser{key, declaration.UsuarioID, declaration.Ofrece}
+		if counted[user] {
+			continue
+		}
+		counted[user] = true
+		if declaration.Ofrece {
+			item.Ofrecen++
+		} else {
+			item.Buscan++
+		}
+	}
+	for _, row := range snapshot.OfertaDemanda {
+		if _, item := group(row); item != nil {
+			item.PostsOfrezco += row.PostsOfrezco
+			item.PostsBusco += row.PostsBusco
+		}
+	}
+
+	rows := make([]models.OfertaDemanda, 0, len(order))
+	for _, key := range order {
+		item := groups[key]
+		item.IndiceEscasez = roundRatio(float64(item.Buscan+1) / float64(item.Ofrecen+1))
+		rows = append(rows, *item)
+	}
+	return rows
+}
+
+// sortSupplyDemand ordena las filas según sort (shortage por defecto) y aplica limit si se indica
+func sortSupplyDemand(rows []models.OfertaDemanda, r *http.Request) ([]models.OfertaDemanda, error) {
+	sortBy := strings.ToLower(strings.TrimSpace(r.URL.Query().Get("sort")))
+	if sortBy == "" {
+		sortBy = models.OrdenEscasez
+	}
+	if !containsString(models.OrdenesAnalitica, sortBy) {
+		return nil, errors.New("Orden inválido: use " + strings.Join(models.OrdenesAnalitica, ", "))
+	}
+
+	label := func(row models.OfertaDemanda) string {
+		return strings.ToLower(row.Nombre + row.Ciudad + row.Categoria)
+	}
+	sort.SliceStable(rows, func(i, j int) bool {
+		a, b := rows[i], rows[j]
+		switch sortBy {
+		case models.OrdenEscasez:
+			if a.IndiceEscasez != b.IndiceEscasez {
+				return a.IndiceEscasez > b.IndiceEscasez
+			}
+			if a.Buscan != b.Buscan {
+				return a.Buscan > b.Buscan
+			}
+		case models.OrdenDemanda:
+			if a.Buscan != b.Buscan {
+				return a.Buscan > b.Buscan
+			}
+		case models.OrdenOferta:
+			if a.Ofrecen != b.Ofrecen {
+				return a.Ofrecen > b.Ofrecen
+			}
+		}
+		return label(a) < label(b)
+	})
+
+	if limit := queryIntInRange(r, "limit", 0, 1, math.MaxInt32); limit > 0 && len(rows) > limit {
+		rows = rows[:limit]
+	}
+	return rows, nil
+}
+
+// writeAnalyticsReport responde en JSON o, con format=csv, como archivo CSV descargable
+func writeAnalyticsReport(w http.ResponseWriter, r *http.Request, name string, generatedAt time.Time,
+	rows interface{}, header []string, count int, record func(int) []string) {
+	if strings.ToLower(r.URL.Query().Get("format")) != "csv" {
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(models.AnaliticaResponse{GeneradoEn: generatedAt, Filas: rows})
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/csv; charset=utf-8")
+	w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="%s-%s.csv"`, name, generatedAt.Format("20060102-1504")))
+	writer := csv.NewWriter(w)
+	writer.Write(header)
+	for i := 0; i < count; i++ {
+		writer.Write(csvSafeRecord(record(i)))
+	}
+	writer.Flush()
+	if err := writer.Error(); err != nil {
+		log.Printf("Error escribiendo el reporte %s: %v", name, err)
+	}
+}
+
+// csvFormulaPrefixes son los caracteres iniciales con los que una hoja de cálculo puede interpretar una celda
+// como fórmula (incluidos el tabulador y el retorno de carro)
+const csvFormulaPrefixes = "=+-@\t\r"
+
+// csvSafeRecord antepone ' a las celdas que una hoja de cálculo interpretaría como fórmula, para que
+// los nombres cargados por usuarios no se ejecuten al abrir el CSV
+func csvSafeRecord(values []string) []string {
+	for i, value := range values {
+		if value != "" && strings.ContainsRune(csvFormulaPrefixes, rune(value[0])) {
+			values[i] = "'" + value
+		}
+	}
+	return values
+}
+
+// supplyDemandCounts da formato CSV a los conteos de una fila de oferta y demanda
+func supplyDemandCounts(row models.OfertaDemanda) []string {
+	return []string{formatCount(row.Ofrecen), formatCount(row.Buscan), formatCount(row.PostsOfrezco),
+		formatCount(row.PostsBusco), strconv.FormatFloat(row.IndiceEscasez, 'f', 4, 64)}
+}
+
+func formatCount(value int64) string {
+	return strconv.FormatInt(value, 10)
+}
+
+// roundRatio redondea una proporción a cuatro decimales
+func roundRatio(value float64) float64 {
+	return math.Round(value*10000) / 10000
+}
+
+// computeSkillAnalytics agrega UsuariosHabilidades, Posts y Emparejamientos por habilidad
+func computeSkillAnalytics(db *gorm.DB) (*skillAnalyticsSnapshot, error) {
+	snapshot := &skillAnalyticsSnapshot{
+		GeneradoEn:  time.Now(),
+		Habilidades: make(map[uint]skillAnalyticsSkill),
+	}
+
+	var habilidades []models.HabilidadTaxonomia
+	if err := db.Select("HabilidadID", "NombreHabilidad", "Categoria").Find(&habilidades).Error; err != nil {
+		return nil, err
+	}
+	for _, habilidad := range habilidades {
+		snapshot.Habilidades[habilidad.HabilidadID] = skillAnalyticsSkill{Nombre: habilidad.Nombre, Categoria: habilidad.Categoria}
+	}
+
+	// Oferta y demanda: usuarios distintos que declaran cada habilidad, con su ciudad, y posts visibles
+	// por habilidad y ciudad
+	if err := db.Table("UsuariosHabilidades ua").
+		Distinct("ua.HabilidadID, ISNULL(u.CiudadTrabajo, '') AS Ciudad, ua.UsuarioID, "+
+			"CAST(CASE WHEN ua.TipoHabilidad = 'Ofrece' THEN 1 ELSE 0 END AS BIT) AS Ofrece").
+		Joins("INNER JOIN Usuarios u ON u.UsuarioID = ua.UsuarioID").
+		Where("ua.TipoHabilidad IN ?", []string{"Ofrece", "Busca"}).
+		Scan(&snapshot.Declaraciones).Error; err != nil {
+		return nil, err
+	}
+	if err := db.Table("Posts p").
+		Select("p.HabilidadID, ISNULL(u.CiudadTrabajo, '') AS Ciudad, " +
+			"SUM(CASE WHEN UPPER(p.TipoPost) = 'OFREZCO' THEN 1 ELSE 0 END) AS PostsOfrezco, " +
+			"SUM(CASE WHEN UPPER(p.TipoPost) = 'BUSCO' THEN 1 ELSE 0 END) AS PostsBusco").
+		Joins("INNER JOIN Usuarios u ON u.UsuarioID = p.UsuarioID").
+		Where("p.Oculto = 0").
+		Group("p.HabilidadID, u.CiudadTrabajo").
+		Scan(&snapshot.OfertaDemanda).Error; err != nil {
+		return nil, err
+	}
+
+	// Tendencias por mes desde el inicio del periodo guardado
+	now := snapshot.GeneradoEn
+	since := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.Local).AddDate(0, 1-skillAnalyticsTrendMonths, 0)
+	const month = "CONVERT(CHAR(7), %s, 126)"
+	var trends []skillAnalyticsTrendRow
+	if err := db.Raw("SELECT HabilidadID, Mes, SUM(Ofrecen) AS Ofrecen, SUM(Buscan) AS Buscan, "+
+		"SUM(PostsOfrezco) AS PostsOfrezco, SUM(PostsBusco) AS PostsBusco, SUM(Emparejamientos) AS Emparejamientos FROM ("+
+		"SELECT HabilidadID, "+fmt.Sprintf(month, "FechaCreacion")+" AS Mes, "+
+		"CASE WHEN TipoHabilidad = 'Ofrece' THEN 1 ELSE 0 END AS Ofrecen, CASE WHEN TipoHabilidad = 'Busca' THEN 1 ELSE 0 END AS Buscan, "+
+		"0 AS PostsOfrezco, 0 AS PostsBusco, 0 AS Emparejamientos FROM UsuariosHabilidades WHERE FechaCreacion >= @since "+
+		"UNION ALL SELECT HabilidadID, "+fmt.Sprintf(month, "CreatedAt")+", 0, 0, "+
+		"CASE WHEN UPPER(TipoPost) = 'OFREZCO' THEN 1 ELSE 0 END, CASE WHEN UPPER(TipoPost) = 'BUSCO' THEN 1 ELSE 0 END, 0 "+
+		"FROM Posts WHERE Oculto = 0 AND CreatedAt >= @since "+
+		"UNION ALL SELECT m.HabilidadID, "+fmt.Sprintf(month, "m.FechaCreacion")+", 0, 0, 0, 0, 1 FROM ("+
+		"SELECT EmparejamientoID, FechaCreacion, Habilidad1ID AS HabilidadID FROM Emparejamientos WHERE Habilidad1ID IS NOT NULL "+
+		"UNION SELECT EmparejamientoID, FechaCreacion, Habilidad2ID FROM Emparejamientos WHERE Habilidad2ID IS NOT NULL) m "+
+		"WHERE m.FechaCreacion >= @since"+
+		") t GROUP BY HabilidadID, Mes", map[string]interface{}{"since": since}).
+		Scan(&trends).Error; err != nil {
+		return nil, err
+	}
+	snapshot.Tendencias = trends
+
+	// Conversión: un match cuenta una vez por cada habilidad distinta que intercambia
+	var conversion []skillAnalyticsMatchRow
+	if err := db.Raw("SELECT m.HabilidadID, COUNT(*) AS Emparejamientos, "+
+		"SUM(CASE WHEN m.EstadoEmparejamiento = @activo OR EXISTS (SELECT 1 FROM Sesiones s "+
+		"WHERE s.EmparejamientoID = m.EmparejamientoID AND s.Estado IN @completadas) THEN 1 ELSE 0 END) AS Convertidos, "+
+		"SUM(CASE WHEN m.EstadoEmparejamiento = @rechazado THEN 1 ELSE 0 END) AS Rechazados FROM ("+
+		"SELECT EmparejamientoID, EstadoEmparejamiento, Habilidad1ID AS HabilidadID FROM Emparejamientos WHERE Habilidad1ID IS NOT NULL "+
+		"UNION SELECT EmparejamientoID, EstadoEmparejamiento, Habilidad2ID FROM Emparejamientos WHERE Habilidad2ID IS NOT NULL"+
+		") m GROUP BY m.HabilidadID", map[string]interface{}{
+		"activo":      models.EmparejamientoActivo,
+		"rechazado":   models.EmparejamientoRechazado,
+		"completadas": models.EstadosSesionCompletada,
+	}).Scan(&conversion).Error; err != nil {
+		return nil, err
+	}
+	snapshot.Conversion = conversion
+
+	return snapshot, nil
+}
@@ -0,0 +1,32 @@
+package handlers
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestCSVSafeRecord(t *testing.T) {
+	tests := []struct {
+		name   string
+		values []string
+		want   []string
+	}{
+		{"texto normal", []string{"Python", "Bogotá", "12"}, []string{"Python", "Bogotá", "12"}},
+		{"celda vacía", []string{""}, []string{""}},
+		{"igual", []string{"=HYPERLINK(\"http://evil.example\")"}, []string{"'=HYPERLINK(\"http://evil.example\")"}},
+		{"más", []string{"+1+1"}, []string{"'+1+1"}},
+		{"menos", []string{"-2+3"}, []string{"'-2+3"}},
+		{"arroba", []string{"@SUM(A1:A2)"}, []string{"'@SUM(A1:A2)"}},
+		{"tabulador", []string{"\t=1+1"}, []string{"'\t=1+1"}},
+		{"retorno de carro", []string{"\r=1+1"}, []string{"'\r=1+1"}},
+		{"solo la primera posición cuenta", []string{"C++", "a=b", "x@y"}, []string{"C++", "a=b", "x@y"}},
+		{"varias celdas", []string{"Go", "=1", "ok", "@x"}, []string{"Go", "'=1", "ok", "'@x"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := csvSafeRecord(append([]string(nil), tt.values...)); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("csvSafeRecord(%q) = %q, se esperaba %q", tt.values, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,772 @@
+package handlers
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"skillswap/api/middleware"
+	"skillswap/api/models"
+
+	"gorm.io/gorm"
+)
+
+const (
+	maxImportRows       = 2000
+	maxImportBodyBytes  = 5 << 20
+	maxProficiencyLevel = 20
+	// Separador de los alias dentro de la columna aliases del CSV
+	importAliasSeparator = "|"
+)
+
+// Columnas de los CSV de importación y exportación
+var (
+	abilityImportColumns     = []string{"name", "category", "description", "aliases"}
+	userAbilityImportColumns = []string{"skill", "skill_type", "proficiency_level"}
+)
+
+type skillImportHandler struct {
+	DB           *gorm.DB
+	SuggestIndex *SkillSuggestIndex
+}
+
+func NewSkillImportHandler(db *gorm.DB) *skillImportHandler {
+	return &skillImportHandler{DB: db}
+}
+
+// SetSuggestIndex configura el índice de autocompletado que se invalida al importar
+func (h *skillImportHandler) SetSuggestIndex(index *SkillSuggestIndex) {
+	h.SuggestIndex = index
+}
+
+// abilityImportRow es una fila del catálogo con su número de línea (CSV) o posición (JSON)
+type abilityImportRow struct {
+	Fila int
+	models.HabilidadImportada
+}
+
+// userAbilityImportRow es una fila de habilidades de usuario con su número de línea o posición
+type userAbilityImportRow struct {
+	Fila int
+	models.HabilidadUsuarioImportada
+}
+
+// abilityImportOp es el cambio planificado para una fila del catálogo
+type abilityImportOp struct {
+	Cambio  *models.CambioImportacion
+	Crear   *models.HabilidadTaxonomia
+	Campos  map[string]interface{}
+	Aliases []models.AliasHabilidad
+}
+
+// skillResolver resuelve nombres y alias normalizados a la habilidad canónica
+type skillResolver struct {
+	Claves      map[string]uint
+	Habilidades map[uint]models.HabilidadTaxonomia
+}
+
+// ImportAbilities importa el catálogo de habilidades desde CSV o JSON. Las filas que coinciden con una
+// habilidad existente por nombre o alias la actualizan en lugar de duplicarla. Con dry_run=true solo
+// devuelve el diff; si alguna fila es inválida no se aplica nada y se responde 400 con los errores.
+func (h *skillImportHandler) ImportAbilities(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Método no permitido", http.StatusMethodNotAllowed)
+		return
+	}
+	if _, ok := authorizeAdmin(h.DB, w, r); !ok {
+		return
+	}
+
+	rows, err := decodeAbilityImport(w, r)
+	if err != nil {
+		http.Error(w, "Error al leer la importación: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	resolver, err := loadSkillResolver(h.DB)
+	if err != nil {
+		http.Error(w, "Error al obtener habilidades: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+	var categorias []models.CategoriaHabilidad
+	if err := h.DB.Select("CategoriaID", "Nombre").Find(&categorias).Error; err != nil {
+		http.Error(w, "Error al obtener categorías: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+	categoriaIDs := make(map[string][]uint)
+	for _, categoria := range categorias {
+		key := strings.ToLower(categoria.Nombre)
+		categoriaIDs[key] = append(categoriaIDs[key], categoria.CategoriaID)
+	}
+
+	result := newImportResult(r)
+	var plan []abilityImportOp
+	seen := make(map[string]int)    // Clave normalizada → fila que la usa (nombre o alias nuevo)
+	pending := make(map[string]int) // Claves de habilidades y alias que la importación va a crear
+
+	for _, row := range rows {
+		row.Nombre = strings.TrimSpace(row.Nombre)
+		row.Categoria = strings.TrimSpace(row.Categoria)
+		row.Descripcion = strings.TrimSpace(row.Descripcion)
+
+		key := normalizeSkillName(row.Nombre)
+		switch {
+		case key == "":
+			addImportError(&result, row.Fila, "El nombre es obligatorio")
+			continue
+		case utf8.RuneCountInString(row.Nombre) > maxSkillNameLength, utf8.RuneCountInString(row.Categoria) > maxSkillNameLength:
+			addImportError(&result, row.Fila, fmt.Sprintf("El nombre y la categoría no pueden superar los %d caracteres", maxSkillNameLength))
+			continue
+		case utf8.RuneCountInString(row.Descripcion) > maxSkillDescriptionLength:
+			addImportError(&result, row.Fila, fmt.Sprintf("La descripción no puede superar los %d caracteres", maxSkillDescriptionLength))
+			continue
+		}
+
+		cambio := &models.CambioImportacion{Fila: row.Fila, Nombre: row.Nombre}
+		if first, dup := seen[key]; dup {
+			cambio.Accion = models.ImportarOmitir
+			cambio.Detalle = fmt.Sprintf("Repite la habilidad de la fila %d", first)
+			result.Cambios = append(result.Cambios, *cambio)
+			continue
+		}
+		seen[key] = row.Fila
+
+		// La categoría del árbol se asigna si el nombre corresponde a una sola categoría
+		var categoriaID *uint
+		if ids := categoriaIDs[strings.ToLower(row.Categoria)]; row.Categoria != "" && len(ids) == 1 {
+			categoriaID = &ids[0]
+		}
+
+		op := abilityImportOp{Cambio: cambio}
+		habilidadID, exists := resolver.Claves[key]
+		if !exists {
+			cambio.Accion = models.ImportarCrear
+			op.Crear = &models.HabilidadTaxonomia{
+				Nombre:      row.Nombre,
+				Categoria:   row.Categoria,
+				CategoriaID: categoriaID,
+				Descripcion: row.Descripcion,
+			}
+			pending[key] = row.Fila
+		} else {
+			existente := resolver.Habilidades[habilidadID]
+			cambio.HabilidadID = habilidadID
+			if normalizeSkillName(existente.Nombre) != key {
+				cambio.Detalle = fmt.Sprintf("Coincide por alias con «%s»", existente.Nombre)
+			}
+			op.Campos = make(map[string]interface{})
+			cambio.Campos = make(map[string]models.CambioCampo)
+			// Los campos vacíos no borran los valores existentes
+			if row.Categoria != "" && row.Categoria != existente.Categoria {
+				op.Campos["Categoria"] = row.Categoria
+				cambio.Campos["category"] = models.CambioCampo{Antes: existente.Categoria, Despues: row.Categoria}
+			}
+			// La categoría del árbol acompaña al nombre: si el nuevo nombre no está en el árbol se desasigna
+			if row.Categoria != "" && (categoriaID != nil || row.Categoria != existente.Categoria) &&
+				formatCategoryID(categoriaID) != formatCategoryID(existente.CategoriaID) {
+				op.Campos["CategoriaID"] = categoriaID
+				cambio.Campos["category_id"] = models.CambioCampo{Antes: formatCategoryID(existente.CategoriaID), Despues: formatCategoryID(categoriaID)}
+			}
+			if row.Descripcion != "" && row.Descripcion != existente.Descripcion {
+				op.Campos["Descripcion"] = row.Descripcion
+				cambio.Campos["description"] = models.CambioCampo{Antes: existente.Descripcion, Despues: row.Descripcion}
+			}
+		}
+
+		valid := true
+		for _, alias := range row.Aliases {
+			alias = strings.TrimSpace(alias)
+			aliasKey := normalizeSkillName(alias)
+			if aliasKey == "" || aliasKey == key {
+				continue
+			}
+			if strings.Contains(alias, importAliasSeparator) {
+				addImportError(&result, row.Fila, fmt.Sprintf("El alias «%s» no puede contener «%s»", alias, importAliasSeparator))
+				valid = false
+				continue
+			}
+			if utf8.RuneCountInString(alias) > maxSkillNameLength {
+				addImportError(&result, row.Fila, fmt.Sprintf("El alias «%s» supera los %d caracteres", alias, maxSkillNameLength))
+				valid = false
+				continue
+			}
+			if otherID, used := resolver.Claves[aliasKey]; used {
+				if !exists || otherID != habilidadID {
+					addImportError(&result, row.Fila, fmt.Sprintf("El alias «%s» ya corresponde a la habilidad «%s»", alias, resolver.Habilidades[otherID].Nombre))
+					valid = false
+				}
+				continue
+			}
+			if otherRow, used := pending[aliasKey]; used {
+				if otherRow != row.Fila {
+					addImportError(&result, row.Fila, fmt.Sprintf("El alias «%s» ya se usa en la fila %d", alias, otherRow))
+					valid = false
+				}
+				continue
+			}
+			pending[aliasKey] = row.Fila
+			seen[aliasKey] = row.Fila
+			op.Aliases = append(op.Aliases, models.AliasHabilidad{Alias: alias, AliasNormalizado: aliasKey})
+			cambio.AliasNuevos = append(cambio.AliasNuevos, alias)
+		}
+		if !valid {
+			continue
+		}
+
+		if exists {
+			if len(op.Campos) > 0 || len(op.Aliases) > 0 {
+				cambio.Accion = models.ImportarActualizar
+			} else {
+				cambio.Accion = models.ImportarSinCambios
+			}
+		}
+		plan = append(plan, op)
+		result.Cambios = append(result.Cambios, *cambio)
+	}
+
+	if len(result.Errores) > 0 || result.DryRun {
+		writeImportResult(w, result)
+		return
+	}
+
+	err = h.DB.Transaction(func(tx *gorm.DB) error {
+		for i := range plan {
+			op := &plan[i]
+			habilidadID := op.Cambio.HabilidadID
+			if op.Crear != nil {
+				if err := tx.Omit("Aliases").Create(op.Crear).Error; err != nil {
+					return fmt.Errorf("fila %d: %w", op.Cambio.Fila, err)
+				}
+				habilidadID = op.Crear.HabilidadID
+				op.Cambio.HabilidadID = habilidadID
+			} else if len(op.Campos) > 0 {
+				if err := tx.Model(&models.HabilidadTaxonomia{}).Where("HabilidadID = ?", habilidadID).
+					UpdateColumns(op.Campos).Error; err != nil {
+					return fmt.Errorf("fila %d: %w", op.Cambio.Fila, err)
+				}
+			}
+			for j := range op.Aliases {
+				op.Aliases[j].HabilidadID = habilidadID
+				if err := tx.Create(&op.Aliases[j]).Error; err != nil {
+					return fmt.Errorf("fila %d: %w", op.Cambio.Fila, err)
+				}
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		http.Error(w, "Error al aplicar la importación: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	// Los IDs de las habilidades creadas se conocen al aplicar
+	result.Cambios = result.Cambios[:0]
+	for _, op := range plan {
+		result.Cambios = append(result.Cambios, *op.Cambio)
+	}
+	result.Aplicado = true
+	h.SuggestIndex.Invalidate()
+	writeImportResult(w, result)
+}
+
+// ExportAbilities exporta el catálogo de habilidades con sus alias, en el formato de la importación
+func (h *skillImportHandler) ExportAbilities(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Método no permitido", http.StatusMethodNotAllowed)
+		return
+	}
+	if _, ok := authorizeAdmin(h.DB, w, r); !ok {
+		return
+	}
+	format, ok := exportFormat(w, r)
+	if !ok {
+		return
+	}
+
+	var habilidades []models.HabilidadTaxonomia
+	if err := h.DB.Preload("Aliases", func(tx *gorm.DB) *gorm.DB {
+		return tx.Order("Alias")
+	}).Order("NombreHabilidad").Find(&habilidades).Error; err != nil {
+		http.Error(w, "Error al obtener habilidades: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	rows := make([]models.HabilidadImportada, 0, len(habilidades))
+	for _, habilidad := range habilidades {
+		row := models.HabilidadImportada{
+			Nombre:      habilidad.Nombre,
+			Categoria:   habilidad.Categoria,
+			Descripcion: habilidad.Descripcion,
+			Aliases:     []string{},
+		}
+		for _, alias := range habilidad.Aliases {
+			row.Aliases = append(row.Aliases, alias.Alias)
+		}
+		rows = append(rows, row)
+	}
+
+	writeImportExport(w, format, "habilidades", rows, abilityImportColumns, len(rows), func(i int) []string {
+		return []string{rows[i].Nombre, rows[i].Categoria, rows[i].Descripcion, strings.Join(rows[i].Aliases, importAliasSeparator)}
+	})
+}
+
+// ImportUserAbilities importa las habilidades que un usuario ofrece o busca desde CSV o JSON. Cada
+// habilidad se resuelve por nombre o alias; las que no existen se reportan como error. Con
+// dry_run=true solo devuelve el diff.
+func (h *skillImportHandler) ImportUserAbilities(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Método no permitido", http.StatusMethodNotAllowed)
+		return
+	}
+	userID, ok := h.authorizeUserSkills(w, r)
+	if !ok {
+		return
+	}
+
+	rows, err := decodeUserAbilityImport(w, r)
+	if err != nil {
+		http.Error(w, "Error al leer la importación: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	resolver, err := loadSkillResolver(h.DB)
+	if err != nil {
+		http.Error(w, "Error al obtener habilidades: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+	var actuales []models.UserAbility
+	if err := h.DB.Where("UsuarioID = ?", userID).Find(&actuales).Error; err != nil {
+		http.Error(w, "Error al obtener las habilidades del usuario: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+	existing := make(map[string]models.UserAbility, len(actuales))
+	for _, actual := range actuales {
+		existing[userAbilityKey(actual.AbilityID, actual.SkillType)] = actual
+	}
+
+	result := newImportResult(r)
+	var crear []models.UserAbility
+	actualizar := make(map[uint]string) // UsuarioHabilidadID → nuevo nivel
+	seen := make(map[string]int)
+
+	for _, row := range rows {
+		row.Habilidad = strings.TrimSpace(row.Habilidad)
+		row.Nivel = strings.TrimSpace(row.Nivel)
+		tipo, validType := normalizeSkillType(row.Tipo)
+		switch {
+		case normalizeSkillName(row.Habilidad) == "":
+			addImportError(&result, row.Fila, "La habilidad es obligatoria")
+			continue
+		case !validType:
+			addImportError(&result, row.Fila, "skill_type inválido: use Ofrece o Busca")
+			continue
+		case utf8.RuneCountInString(row.Nivel) > maxProficiencyLevel:
+			addImportError(&result, row.Fila, fmt.Sprintf("El nivel no puede superar los %d caracteres", maxProficiencyLevel))
+			continue
+		}
+
+		habilidadID, found := resolver.Claves[normalizeSkillName(row.Habilidad)]
+		if !found {
+			addImportError(&result, row.Fila, fmt.Sprintf("La habilidad «%s» no existe; puedes proponerla en /ability-proposals", row.Habilidad))
+			continue
+		}
+		habilidad := resolver.Habilidades[habilidadID]
+		cambio := models.CambioImportacion{Fila: row.Fila, Nombre: habilidad.Nombre, HabilidadID: habilidadID}
+		if normalizeSkillName(habilidad.Nombre) != normalizeSkillName(row.Habilidad) {
+			cambio.Detalle = fmt.Sprintf("«%s» coincide por alias", row.Habilidad)
+		}
+
+		key := userAbilityKey(habilidadID, tipo)
+		if first, dup := seen[key]; dup {
+			cambio.Accion = models.ImportarOmitir
+			cambio.Detalle = fmt.Sprintf("Repite la habilidad de la fila %d", first)
+			result.Cambios = append(result.Cambios, cambio)
+			continue
+		}
+		seen[key] = row.Fila
+
+		actual, exists := existing[key]
+		switch {
+		case !exists:
+			cambio.Accion = models.ImportarCrear
+			crear = append(crear, models.UserAbility{UserID: userID, AbilityID: habilidadID, SkillType: tipo, ProficiencyLevel: row.Nivel})
+		case row.Nivel != "" && row.Nivel != actual.ProficiencyLevel:
+			cambio.Accion = models.ImportarActualizar
+			cambio.Campos = map[string]models.CambioCampo{"proficiency_level": {Antes: actual.ProficiencyLevel, Despues: row.Nivel}}
+			actualizar[actual.ID] = row.Nivel
+		default:
+			cambio.Accion = models.ImportarSinCambios
+		}
+		result.Cambios = append(result.Cambios, cambio)
+	}
+
+	if len(result.Errores) > 0 || result.DryRun {
+		writeImportResult(w, result)
+		return
+	}
+
+	err = h.DB.Transaction(func(tx *gorm.DB) error {
+		if len(crear) > 0 {
+			if err := tx.Omit("User", "Ability").Create(&crear).Error; err != nil {
+				return err
+			}
+		}
+		for id, nivel := range actualizar {
+			if err := tx.Model(&models.UserAbility{}).Where("UsuarioHabilidadID = ?", id).
+				UpdateColumn("NivelProficiencia", nivel).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		http.Error(w, "Error al aplicar la importación: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	result.Aplicado = true
+	// La popularidad de las sugerencias depende de cuántos usuarios ofrecen o buscan cada habilidad
+	h.SuggestIndex.Invalidate()
+	writeImportResult(w, result)
+}
+
+// ExportUserAbilities exporta las habilidades de un usuario en el formato de la importación
+func (h *skillImportHandler) ExportUserAbilities(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Método no permitido", http.StatusMethodNotAllowed)
+		return
+	}
+	userID, ok := h.authorizeUserSkills(w, r)
+	if !ok {
+		return
+	}
+	format, ok := exportFormat(w, r)
+	if !ok {
+		return
+	}
+
+	var actuales []models.UserAbility
+	if err := h.DB.Preload("Ability").Where("UsuarioID = ?", userID).
+		Order("TipoHabilidad, HabilidadID").Find(&actuales).Error; err != nil {
+		http.Error(w, "Error al obtener las habilidades del usuario: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	rows := make([]models.HabilidadUsuarioImportada, 0, len(actuales))
+	for _, actual := range actuales {
+		rows = append(rows, models.HabilidadUsuarioImportada{
+			Habilidad: actual.Ability.Name,
+			Tipo:      actual.SkillType,
+			Nivel:     actual.ProficiencyLevel,
+		})
+	}
+
+	writeImportExport(w, format, fmt.Sprintf("habilidades-usuario-%d", userID), rows, userAbilityImportColumns, len(rows), func(i int) []string {
+		return []string{rows[i].Habilidad, rows[i].Tipo, rows[i].Nivel}
+	})
+}
+
+// authorizeUserSkills permite gestionar las habilidades del usuario de la ruta a él mismo o a un administrador
+func (h *skillImportHandler) authorizeUserSkills(w http.ResponseWriter, r *http.Request) (uint, bool) {
+	userID, err := parseIDFromPath(r, "id")
+	if err != nil {
+		http.Error(w, "ID de usuario inválido", http.StatusBadRequest)
+		return 0, false
+	}
+
+	user, authenticated := middleware.GetUserFromContext(r)
+	if !authenticated {
+		http.Error(w, "Autenticación requerida", http.StatusUnauthorized)
+		return 0, false
+	}
+	if user.UserID != userID {
+		admin, err := isAdmin(h.DB, user.UserID)
+		if err != nil {
+			http.Error(w, "Error verificando permisos: "+err.Error(), http.StatusInternalServerError)
+			return 0, false
+		}
+		if !admin {
+			http.Error(w, "No tienes permiso para gestionar las habilidades de este usuario", http.StatusForbidden)
+			return 0, false
+		}
+	}
+
+	var target models.User
+	if result := h.DB.Select("UsuarioID").First(&target, userID); result.Error != nil {
+		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+			http.Error(w, "Usuario no encontrado", http.StatusNotFound)
+		} else {
+			http.Error(w, "Error al buscar el usuario: "+result.Error.Error(), http.StatusInternalServerError)
+		}
+		return 0, false
+	}
+	return userID, true
+}
+
+// loadSkillResolver carga las claves normalizadas de los nombres y alias de todas las habilidades
+func loadSkillResolver(db *gorm.DB) (*skillResolver, error) {
+	var habilidades []models.HabilidadTaxonomia
+	if err := db.Preload("Aliases").Find(&habilidades).Error; err != nil {
+		return nil, err
+	}
+
+	resolver := &skillResolver{
+		Claves:      make(map[string]uint, len(habilidades)),
+		Habilidades: make(map[uint]models.HabilidadTaxonomia, len(habilidades)),
+	}
+	for _, habilidad := range habilidades {
+		resolver.Habilidades[habilidad.HabilidadID] = habilidad
+		if key := normalizeSkillName(habilidad.Nombre); key != "" {
+			if _, taken := resolver.Claves[key]; !taken {
+				resolver.Claves[key] = habilidad.HabilidadID
+			}
+		}
+	}
+	// Los alias se registran después para que no oculten el nombre propio de otra habilidad
+	for _, habilidad := range habilidades {
+		for _, alias := range habilidad.Aliases {
+			if _, taken := resolver.Claves[alias.AliasNormalizado]; !taken {
+				resolver.Claves[alias.AliasNormalizado] = habilidad.HabilidadID
+			}
+		}
+	}
+	return resolver, nil
+}
+
+// decodeAbilityImport lee las filas del catálogo desde el cuerpo en CSV o JSON
+func decodeAbilityImport(w http.ResponseWriter, r *http.Request) ([]abilityImportRow, error) {
+	body := http.MaxBytesReader(w, r.Body, maxImportBodyBytes)
+	var rows []abilityImportRow
+
+	format, err := importFormat(r)
+	if err != nil {
+		return nil, err
+	}
+	if format == "csv" {
+		records, err := readImportCSV(body, []string{"name"})
+		if err != nil {
+			return nil, err
+		}
+		for _, record := range records {
+			row := abilityImportRow{Fila: record.Fila, HabilidadImportada: models.HabilidadImportada{
+				Nombre:      record.Campos["name"],
+				Categoria:   record.Campos["category"],
+				Descripcion: record.Campos["description"],
+			}}
+			if aliases := record.Campos["aliases"]; aliases != "" {
+				row.Aliases = strings.Split(aliases, importAliasSeparator)
+			}
+			rows = append(rows, row)
+		}
+	} else {
+		var items []models.HabilidadImportada
+		if err := json.NewDecoder(body).Decode(&items); err != nil {
+			return nil, errors.New("se esperaba un arreglo JSON de habilidades: " + err.Error())
+		}
+		for i, item := range items {
+			rows = append(rows, abilityImportRow{Fila: i + 1, HabilidadImportada: item})
+		}
+	}
+	return rows, checkImportSize(len(rows))
+}
+
+// decodeUserAbilityImport lee las filas de habilidades de usuario desde el cuerpo en CSV o JSON
+func decodeUserAbilityImport(w http.ResponseWriter, r *http.Request) ([]userAbilityImportRow, error) {
+	body := http.MaxBytesReader(w, r.Body, maxImportBodyBytes)
+	var rows []userAbilityImportRow
+
+	format, err := importFormat(r)
+	if err != nil {
+		return nil, err
+	}
+	if format == "csv" {
+		records, err := readImportCSV(body, []string{"skill", "skill_type"})
+		if err != nil {
+			return nil, err
+		}
+		for _, record := range records {
+			rows = append(rows, userAbilityImportRow{Fila: record.Fila, HabilidadUsuarioImportada: models.HabilidadUsuarioImportada{
+				Habilidad: record.Campos["skill"],
+				Tipo:      record.Campos["skill_type"],
+				Nivel:     record.Campos["proficiency_level"],
+			}})
+		}
+	} else {
+		var items []models.HabilidadUsuarioImportada
+		if err := json.NewDecoder(body).Decode(&items); err != nil {
+			return nil, errors.New("se esperaba un arreglo JSON de habilidades: " + err.Error())
+		}
+		for i, item := range items {
+			rows = append(rows, userAbilityImportRow{Fila: i + 1, HabilidadUsuarioImportada: item})
+		}
+	}
+	return rows, checkImportSize(len(rows))
+}
+
+func checkImportSize(rows int) error {
+	if rows == 0 {
+		return errors.New("no hay filas para importar")
+	}
+	if rows > maxImportRows {
+		return fmt.Errorf("se admiten hasta %d filas por importación", maxImportRows)
+	}
+	return nil
+}
+
+// importCSVRecord es una fila de CSV con sus valores por nombre de columna
+type importCSVRecord struct {
+	Fila   int
+	Campos map[string]string
+}
+
+// readImportCSV lee un CSV con encabezado; Fila es el número de línea del archivo (el encabezado es la 1)
+func readImportCSV(body io.Reader, required []string) ([]importCSVRecord, error) {
+	reader := csv.NewReader(body)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err != nil {
+		if errors.Is(err, io.EOF) {
+			return nil, errors.New("el CSV está vacío")
+		}
+		return nil, err
+	}
+	for i := range header {
+		header[i] = strings.ToLower(strings.TrimSpace(strings.TrimPrefix(header[i], "\ufeff")))
+	}
+	for _, column := range required {
+		if !containsString(header, column) {
+			return nil, fmt.Errorf("falta la columna %s en el encabezado", column)
+		}
+	}
+
+	var records []importCSVRecord
+	for {
+		values, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		line, _ := reader.FieldPos(0)
+		record := importCSVRecord{Fila: line, Campos: make(map[string]string, len(header))}
+		empty := true
+		for i, value := range values {
+			// Se quita el ' que csvSafeRecord agrega al exportar, para que una exportación se pueda reimportar
+			if len(value) > 1 && value[0] == '\'' && strings.ContainsRune(csvFormulaPrefixes, rune(value[1])) {
+				value = value[1:]
+			}
+			if i < len(header) {
+				record.Campos[header[i]] = value
+			}
+			if strings.TrimSpace(value) != "" {
+				empty = false
+			}
+		}
+		if !empty {
+			records = append(records, record)
+		}
+	}
+	return records, nil
+}
+
+// importFormat determina el formato del cuerpo: ?format= (json o csv) o, si no se indica, el Content-Type
+func importFormat(r *http.Request) (string, error) {
+	if format := strings.ToLower(r.URL.Query().Get("format")); format != "" {
+		if format != "json" && format != "csv" {
+			return "", errors.New("formato inválido: use json o csv")
+		}
+		return format, nil
+	}
+	if strings.Contains(strings.ToLower(r.Header.Get("Content-Type")), "csv") {
+		return "csv", nil
+	}
+	return "json", nil
+}
+
+// exportFormat lee el formato de exportación (json por defecto)
+func exportFormat(w http.ResponseWriter, r *http.Request) (string, bool) {
+	format := strings.ToLower(r.URL.Query().Get("format"))
+	if format == "" {
+		format = "json"
+	}
+	if format != "json" && format != "csv" {
+		http.Error(w, "Formato inválido: use json o csv", http.StatusBadRequest)
+		return "", false
+	}
+	return format, true
+}
+
+// normalizeSkillType acepta Ofrece y Busca sin distinguir mayúsculas
+func normalizeSkillType(tipo string) (string, bool) {
+	switch strings.ToLower(strings.TrimSpace(tipo)) {
+	case "ofrece":
+		return "Ofrece", true
+	case "busca":
+		return "Busca", true
+	}
+	return "", false
+}
+
+func userAbilityKey(abilityID uint, skillType string) string {
+	return strconv.FormatUint(uint64(abilityID), 10) + "|" + skillType
+}
+
+func addImportError(result *models.ResultadoImportacion, fila int, mensaje string) {
+	result.Errores = append(result.Errores, models.ErrorImportacion{Fila: fila, Mensaje: mensaje})
+}
+
+func newImportResult(r *http.Request) models.ResultadoImportacion {
+	dryRun, _ := strconv.ParseBool(r.URL.Query().Get("dry_run"))
+	return models.ResultadoImportacion{DryRun: dryRun, Resumen: map[string]int{}, Cambios: []models.CambioImportacion{}}
+}
+
+// writeImportResult responde con el diff; si hubo errores de validación la respuesta es 400
+func writeImportResult(w http.ResponseWriter, result models.ResultadoImportacion) {
+	for _, cambio := range result.Cambios {
+		result.Resumen[cambio.Accion]++
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if len(result.Errores) > 0 {
+		w.WriteHeader(http.StatusBadRequest)
+	}
+	json.NewEncoder(w).Encode(result)
+}
+
+// writeImportExport escribe una exportación descargable en JSON o CSV
+func writeImportExport(w http.ResponseWriter, format, name string, rows interface{}, header []string, count int, record func(int) []string) {
+	filename := fmt.Sprintf("%s-%s.%s", name, time.Now().Format("20060102"), format)
+	w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="%s"`, filename))
+
+	if format == "json" {
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(rows)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/csv; charset=utf-8")
+	writer := csv.NewWriter(w)
+	writer.Write(header)
+	for i := 0; i < count; i++ {
+		writer.Write(csvSafeRecord(record(i)))
+	}
+	writer.Flush()
+}
+
+// formatCategoryID da formato a una categoría del árbol opcional para el detalle de cambios
+func formatCategoryID(id *uint) string {
+	if id == nil {
+		return ""
+	}
+	return strconv.FormatUint(uint64(*id), 10)
+}
@@ -0,0 +1,372 @@
+package handlers
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+
+	"skillswap/api/models"
+
+	"golang.org/x/text/runes"
+	"golang.org/x/text/transform"
+	"golang.org/x/text/unicode/norm"
+	"gorm.io/gorm"
+)
+
+const (
+	skillSuggestDefaultLimit = 10
+	skillSuggestMaxLimit     = 25
+	skillSuggestMaxQuery     = 100
+)
+
+// Orden de las coincidencias: las más precisas primero
+var skillMatchRank = map[string]int{
+	models.CoincidenciaExacta:  0,
+	models.CoincidenciaPrefijo: 1,
+	models.CoincidenciaPalabra: 2,
+	models.CoincidenciaTexto:   3,
+	models.CoincidenciaAprox:   4,
+}
+
+// skillSuggestKey es un nombre indexado de una habilidad: su nombre canónico o uno de sus alias
+type skillSuggestKey struct {
+	Alias   string   // Vacío para el nombre canónico
+	Compact []rune   // Clave de normalizeSkillName
+	Words   []string // Palabras sin acentos y en minúsculas
+}
+
+type skillSuggestEntry struct {
+	models.SugerenciaHabilidad
+	Keys []skillSuggestKey
+}
+
+// SkillSuggestIndex mantiene en memoria las habilidades, sus alias y su popularidad para el
+// autocompletado. Se recarga en la siguiente consulta cuando las habilidades cambian
+// (Invalidate) o cuando vence el intervalo de SKILL_SUGGEST_REFRESH_MINUTES, que refleja los
+// cambios de popularidad.
+type SkillSuggestIndex struct {
+	DB       *gorm.DB
+	mu       sync.RWMutex
+	entries  []skillSuggestEntry
+	loadedAt time.Time
+	stale    bool
+}
+
+func NewSkillSuggestIndex(db *gorm.DB) *SkillSuggestIndex {
+	return &SkillSuggestIndex{DB: db, stale: true}
+}
+
+// Invalidate marca el índice para recargarlo en la siguiente consulta
+func (idx *SkillSuggestIndex) Invalidate() {
+	if idx == nil {
+		return
+	}
+	idx.mu.Lock()
+	idx.stale = true
+	idx.mu.Unlock()
+}
+
+// snapshot devuelve las entradas vigentes, recargándolas si es necesario. Si la recarga falla
+// se siguen usando las anteriores.
+func (idx *SkillSuggestIndex) snapshot() ([]skillSuggestEntry, error) {
+	idx.mu.RLock()
+	entries, fresh := idx.entries, !idx.stale && time.Since(idx.loadedAt) < skillSuggestRefreshInterval()
+	idx.mu.RUnlock()
+	if fresh {
+		return entries, nil
+	}
+
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+	// Otra solicitud pudo recargarlo mientras se esperaba el bloqueo
+	if !idx.stale && time.Since(idx.loadedAt) < skillSuggestRefreshInterval() {
+		return idx.entries, nil
+	}
+	loaded, err := loadSkillSuggestEntries(idx.DB)
+	if err != nil {
+		if idx.entries != nil {
+			log.Printf("Error recargando el índice de sugerencias de habilidades: %v", err)
+			return idx.entries, nil
+		}
+		return nil, err
+	}
+	idx.entries, idx.loadedAt, idx.stale = loaded, time.Now(), false
+	return loaded, nil
+}
+
+// skillSuggestRefreshInterval lee SKILL_SUGGEST_REFRESH_MINUTES (10 por defecto)
+func skillSuggestRefreshInterval() time.Duration {
+	if minutes, err := strconv.Atoi(os.Getenv("SKILL_SUGGEST_REFRESH_MINUTES")); err == nil && minutes > 0 {
+		return time.Duration(minutes) * time.Minute
+	}
+	return 10 * time.Minute
+}
+
+// loadSkillSuggestEntries carga las habilidades con sus alias y cuántos usuarios las ofrecen o buscan
+func loadSkillSuggestEntries(db *gorm.DB) ([]skillSuggestEntry, error) {
+	var habilidades []models.HabilidadTaxonomia
+	if err := db.Preload("Aliases").Find(&habilidades).Error; err != nil {
+		return nil, err
+	}
+
+	var totales []struct {
+		HabilidadID   uint   `gorm:"column:HabilidadID"`
+		TipoHabilidad string `gorm:"column:TipoHabilidad"`
+		Total         int64  `gorm:"column:Total"`
+	}
+	if err := db.Table("UsuariosHabilidades").
+		Select("HabilidadID, TipoHabilidad, COUNT(DISTINCT UsuarioID) AS Total").
+		Group("HabilidadID, TipoHabilidad").
+		Scan(&totales).Error; err != nil {
+		return nil, err
+	}
+	ofrecen := make(map[uint]int64)
+	buscan := make(map[uint]int64)
+	for _, total := range totales {
+		switch total.TipoHabilidad {
+		case "Ofrece":
+			ofrecen[total.HabilidadID] = total.Total
+		case "Busca":
+			buscan[total.HabilidadID] = total.Total
+		}
+	}
+
+	entries := make([]skillSuggestEntry, 0, len(habilidades))
+	for _, habilidad := range habilidades {
+		entry := skillSuggestEntry{
+			SugerenciaHabilidad: models.SugerenciaHabilidad{
+				ID:        habilidad.HabilidadID,
+				Nombre:    habilidad.Nombre,
+				Categoria: habilidad.Categoria,
+				Ofrecen:   ofrecen[habilidad.HabilidadID],
+				Buscan:    buscan[habilidad.HabilidadID],
+			},
+			Keys: []skillSuggestKey{newSkillSuggestKey(habilidad.Nombre, "")},
+		}
+		for _, alias := range habilidad.Aliases {
+			entry.Keys = append(entry.Keys, newSkillSuggestKey(alias.Alias, alias.Alias))
+		}
+		entries = append(entries, entry)
+	}
+	return entries, nil
+}
+
+func newSkillSuggestKey(text, alias string) skillSuggestKey {
+	return skillSuggestKey{
+		Alias:   alias,
+		Compact: []rune(normalizeSkillName(text)),
+		Words:   strings.Fields(foldSkillText(text)),
+	}
+}
+
+// foldSkillText pasa el texto a minúsculas sin acentos y reemplaza por espacios los signos
+// que separan palabras (se conservan + y #)
+func foldSkillText(text string) string {
+	t := transform.Chain(norm.NFD, runes.Remove(runes.In(unicode.Mn)), norm.NFC)
+	folded, _, err := transform.String(t, text)
+	if err != nil {
+		folded = text
+	}
+	return strings.Map(func(r rune) rune {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) || r == '+' || r == '#' {
+			return unicode.ToLower(r)
+		}
+		return ' '
+	}, folded)
+}
+
+// SuggestAbilities autocompleta nombres de habilidades (GET /abilities/suggest?q=&limit=). Coincide por
+// nombre o alias, sin distinguir acentos ni mayúsculas, por prefijo, por palabra, por subcadena y, desde
+// 4 caracteres, tolerando errores de tipeo (distancia de Levenshtein con transposiciones). Los resultados se ordenan por la
+// precisión de la coincidencia y, a igual precisión, por popularidad (usuarios que la ofrecen o buscan).
+// Sin q devuelve las habilidades más populares.
+func (h *abilityHandler) SuggestAbilities(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Método no permitido", http.StatusMethodNotAllowed)
+		return
+	}
+
+	q := strings.TrimSpace(r.URL.Query().Get("q"))
+	if len([]rune(q)) > skillSuggestMaxQuery {
+		http.Error(w, "La búsqueda es demasiado larga", http.StatusBadRequest)
+		return
+	}
+	limit := queryIntInRange(r, "limit", skillSuggestDefaultLimit, 1, skillSuggestMaxLimit)
+
+	entries, err := h.SuggestIndex.snapshot()
+	if err != nil {
+		http.Error(w, "Error al obtener habilidades: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(suggestSkills(entries, q, limit))
+}
+
+// suggestSkills busca q en las entradas del índice y devuelve las mejores limit sugerencias
+func suggestSkills(entries []skillSuggestEntry, q string, limit int) []models.SugerenciaHabilidad {
+	type candidate struct {
+		models.SugerenciaHabilidad
+		rank     int
+		distance int
+	}
+
+	compact := []rune(normalizeSkillName(q))
+	words := strings.Fields(foldSkillText(q))
+
+	var candidates []candidate
+	for _, entry := range entries {
+		if len(compact) == 0 {
+			candidates = append(candidates, candidate{SugerenciaHabilidad: entry.SugerenciaHabilidad})
+			continue
+		}
+
+		best := candidate{rank: -1}
+		for _, key := range entry.Keys {
+			match, distance, ok := matchSkillKey(key, compact, words)
+			if !ok {
+				continue
+			}
+			rank := skillMatchRank[match]
+			// A igual precisión se prefiere el nombre canónico sobre un alias
+			if best.rank == -1 || rank < best.rank || (rank == best.rank && distance < best.distance) {
+				best = candidate{SugerenciaHabilidad: entry.SugerenciaHabilidad, rank: rank, distance: distance}
+				best.Coincidencia = match
+				best.Alias = key.Alias
+			}
+		}
+		if best.rank != -1 {
+			candidates = append(candidates, best)
+		}
+	}
+
+	sort.Slice(candidates, func(i, j int) bool {
+		a, b := candidates[i], candidates[j]
+		if a.rank != b.rank {
+			return a.rank < b.rank
+		}
+		if a.distance != b.distance {
+			return a.distance < b.distance
+		}
+		if pa, pb := a.Ofrecen+a.Buscan, b.Ofrecen+b.Buscan; pa != pb {
+			return pa > pb
+		}
+		return strings.ToLower(a.Nombre) < strings.ToLower(b.Nombre)
+	})
+
+	if len(candidates) > limit {
+		candidates = candidates[:limit]
+	}
+	suggestions := make([]models.SugerenciaHabilidad, 0, len(candidates))
+	for _, c := range candidates {
+		suggestions = append(suggestions, c.SugerenciaHabilidad)
+	}
+	return suggestions
+}
+
+// matchSkillKey compara la consulta con un nombre indexado y devuelve el tipo de coincidencia y,
+// para las aproximadas, la cantidad de errores
+func matchSkillKey(key skillSuggestKey, compact []rune, words []string) (string, int, bool) {
+	keyText, query := string(key.Compact), string(compact)
+	switch {
+	case keyText == query:
+		return models.CoincidenciaExacta, 0, true
+	case strings.HasPrefix(keyText, query):
+		return models.CoincidenciaPrefijo, 0, true
+	}
+
+	// Cada palabra de la consulta debe iniciar alguna palabra del nombre ("web des" → "Desarrollo Web")
+	if len(words) > 0 {
+		all := true
+		for _, word := range words {
+			found := false
+			for _, keyWord := range key.Words {
+				if strings.HasPrefix(keyWord, word) {
+					found = true
+					break
+				}
+			}
+			if !found {
+				all = false
+				break
+			}
+		}
+		if all {
+			return models.CoincidenciaPalabra, 0, true
+		}
+	}
+
+	if len(compact) >= 3 && strings.Contains(keyText, query) {
+		return models.CoincidenciaTexto, 0, true
+	}
+
+	// Errores de tipeo: se compara con el nombre completo, con cada palabra y con sus prefijos del largo
+	// de la consulta
+	maxDistance := skillTypoTolerance(len(compact))
+	if maxDistance == 0 {
+		return "", 0, false
+	}
+	targets := [][]rune{key.Compact}
+	for _, keyWord := range key.Words {
+		targets = append(targets, []rune(keyWord))
+	}
+	distance := maxDistance + 1
+	for _, target := range targets {
+		if d := editDistance(compact, target); d < distance {
+			distance = d
+		}
+		if len(target) > len(compact) {
+			if d := editDistance(compact, target[:len(compact)]); d < distance {
+				distance = d
+			}
+		}
+	}
+	if distance <= maxDistance {
+		return models.CoincidenciaAprox, distance, true
+	}
+	return "", 0, false
+}
+
+// skillTypoTolerance indica cuántos errores se toleran según el largo de la consulta
+func skillTypoTolerance(length int) int {
+	switch {
+	case length < 4:
+		return 0
+	case length < 8:
+		return 1
+	}
+	return 2
+}
+
+// editDistance calcula la distancia de Levenshtein contando además la transposición de dos
+// caracteres contiguos como un solo error ("pyhton" → "python")
+func editDistance(a, b []rune) int {
+	rows := make([][]int, len(a)+1)
+	for i := range rows {
+		rows[i] = make([]int, len(b)+1)
+		rows[i][0] = i
+	}
+	for j := range rows[0] {
+		rows[0][j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			rows[i][j] = min(rows[i-1][j]+1, rows[i][j-1]+1, rows[i-1][j-1]+cost)
+			if i > 1 && j > 1 && a[i-1] == b[j-2] && a[i-2] == b[j-1] {
+				rows[i][j] = min(rows[i][j], rows[i-2][j-2]+1)
+			}
+		}
+	}
+	return rows[len(a)][len(b)]
+}
@@ -0,0 +1,98 @@
+package handlers
+
+import (
+	"testing"
+
+	"skillswap/api/models"
+)
+
+func TestEditDistance(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want int
+	}{
+		{"", "", 0},
+		{"go", "", 2},
+		{"", "sql", 3},
+		{"python", "python", 0},
+		{"pyton", "python", 1},
+		{"pythonn", "python", 1},
+		{"pithon", "python", 1},
+		{"pyhton", "python", 1},
+		{"jaav", "java", 1},
+		{"diseño", "diseno", 1},
+		{"kotlin", "swift", 6},
+	}
+	for _, tt := range tests {
+		t.Run(tt.a+"/"+tt.b, func(t *testing.T) {
+			if got := editDistance([]rune(tt.a), []rune(tt.b)); got != tt.want {
+				t.Errorf("editDistance(%q, %q) = %d, se esperaba %d", tt.a, tt.b, got, tt.want)
+			}
+			if got := editDistance([]rune(tt.b), []rune(tt.a)); got != tt.want {
+				t.Errorf("editDistance(%q, %q) = %d, se esperaba %d", tt.b, tt.a, got, tt.want)
+			}
+		})
+	}
+}
+
+// testSuggestEntry arma una entrada del índice como lo hace loadSkillSuggestEntries
+func testSuggestEntry(id uint, nombre string, ofrecen int64, aliases ...string) skillSuggestEntry {
+	entry := skillSuggestEntry{
+		SugerenciaHabilidad: models.SugerenciaHabilidad{ID: id, Nombre: nombre, Ofrecen: ofrecen},
+		Keys:                []skillSuggestKey{newSkillSuggestKey(nombre, "")},
+	}
+	for _, alias := range aliases {
+		entry.Keys = append(entry.Keys, newSkillSuggestKey(alias, alias))
+	}
+	return entry
+}
+
+func TestSuggestSkills(t *testing.T) {
+	entries := []skillSuggestEntry{
+		testSuggestEntry(1, "Python", 10),
+		testSuggestEntry(2, "JavaScript", 30, "JS"),
+		testSuggestEntry(3, "Java", 20),
+		testSuggestEntry(4, "Desarrollo Web", 5),
+		testSuggestEntry(5, "Diseño Gráfico", 8),
+		testSuggestEntry(6, "C#", 4),
+		testSuggestEntry(7, "C++", 3),
+	}
+
+	type suggestion struct {
+		id    uint
+		match string
+		alias string
+	}
+	tests := []struct {
+		name  string
+		q     string
+		limit int
+		want  []suggestion
+	}{
+		{"sin consulta ordena por popularidad", "", 3, []suggestion{{2, "", ""}, {3, "", ""}, {1, "", ""}}},
+		{"exacta antes que prefijo", "java", 10, []suggestion{{3, models.CoincidenciaExacta, ""}, {2, models.CoincidenciaPrefijo, ""}}},
+		{"sin acentos ni mayúsculas", "DISENO", 10, []suggestion{{5, models.CoincidenciaPrefijo, ""}}},
+		{"por alias", "js", 10, []suggestion{{2, models.CoincidenciaExacta, "JS"}}},
+		{"palabras en otro orden", "web des", 10, []suggestion{{4, models.CoincidenciaPalabra, ""}}},
+		{"subcadena", "script", 10, []suggestion{{2, models.CoincidenciaTexto, ""}}},
+		{"transposición", "pyhton", 10, []suggestion{{1, models.CoincidenciaAprox, ""}}},
+		{"errores en consultas cortas no cuentan", "pyh", 10, nil},
+		{"signos que distinguen habilidades", "c#", 10, []suggestion{{6, models.CoincidenciaExacta, ""}}},
+		{"a igual precisión gana la más popular", "ja", 1, []suggestion{{2, models.CoincidenciaPrefijo, ""}}},
+		{"sin coincidencias", "cocina", 10, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := suggestSkills(entries, tt.q, tt.limit)
+			if len(got) != len(tt.want) {
+				t.Fatalf("suggestSkills(%q) devolvió %d sugerencias (%+v), se esperaban %d", tt.q, len(got), got, len(tt.want))
+			}
+			for i, want := range tt.want {
+				if got[i].ID != want.id || got[i].Coincidencia != want.match || got[i].Alias != want.alias {
+					t.Errorf("sugerencia %d = {%d %q %q}, se esperaba {%d %q %q}",
+						i, got[i].ID, got[i].Coincidencia, got[i].Alias, want.id, want.match, want.alias)
+				}
+			}
+		})
+	}
+}
@@ -90,6 +90,24 @@ func (s *SocketIOBroadcaster) BroadcastNewMessage(conversationID uint, messageDa
 	s.BroadcastMessage(roomName, "new_message", messageData)
 }
 
+// BroadcastMessagesRead envía notificación de mensajes leídos por un participante
+func (s *SocketIOBroadcaster) BroadcastMessagesRead(conversationID uint, readData interface{}) {
+	roomName := fmt.Sprintf("conversation_%d", conversationID)
+	s.BroadcastMessage(roomName, "messages_read", readData)
+}
+
+// BroadcastMessageReaction envía notificación de reacción agregada o quitada (reaction_added/reaction_removed)
+func (s *SocketIOBroadcaster) BroadcastMessageReaction(conversationID uint, eventName string, reactionData interface{}) {
+	roomName := fmt.Sprintf("conversation_%d", conversationID)
+	s.BroadcastMessage(roomName, eventName, reactionData)
+}
+
+// BroadcastNotification envía una notificación a la sala personal del usuario
+func (s *SocketIOBroadcaster) BroadcastNotification(userID uint, notificationData interface{}) {
+	roomName := fmt.Sprintf("user_%d", userID)
+	s.BroadcastMessage(roomName, "notification", notificationData)
+}
+
 // BroadcastNewComment envía notificación de nuevo comentario
 func (s *SocketIOBroadcaster) BroadcastNewComment(postID uint, commentData interface{}) {
 	roomName := fmt.Sprintf("post_%d", postID)
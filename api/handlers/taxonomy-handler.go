@@ -0,0 +1,1067 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"skillswap/api/middleware"
+	"skillswap/api/models"
+	"skillswap/api/search"
+
+	"gorm.io/gorm"
+)
+
+const (
+	maxSkillNameLength        = 100
+	maxSkillDescriptionLength = 500
+	maxCategoryDepth          = 20
+)
+
+type taxonomyHandler struct {
+	DB                  *gorm.DB
+	Searcher            search.Searcher
+	WSHandler           *WebSocketHandler
+	SocketIOBroadcaster *SocketIOBroadcaster
+	SuggestIndex        *SkillSuggestIndex
+}
+
+func NewTaxonomyHandler(db *gorm.DB) *taxonomyHandler {
+	return &taxonomyHandler{
+		DB:                  db,
+		SocketIOBroadcaster: NewSocketIOBroadcaster(),
+	}
+}
+
+// SetSearcher configura el índice de búsqueda que se actualiza al fusionar habilidades
+func (h *taxonomyHandler) SetSearcher(searcher search.Searcher) {
+	h.Searcher = searcher
+}
+
+// SetWebSocketHandler configura el handler de WebSocket para avisar la revisión de las propuestas
+func (h *taxonomyHandler) SetWebSocketHandler(wsHandler *WebSocketHandler) {
+	h.WSHandler = wsHandler
+}
+
+// SetSuggestIndex configura el índice de autocompletado que se invalida al cambiar la taxonomía
+func (h *taxonomyHandler) SetSuggestIndex(index *SkillSuggestIndex) {
+	h.SuggestIndex = index
+}
+
+// GetCategoryTree devuelve el árbol de categorías con la cantidad de habilidades de cada nodo
+func (h *taxonomyHandler) GetCategoryTree(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Método no permitido", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var categorias []models.CategoriaHabilidad
+	if err := h.DB.Order("Nombre").Find(&categorias).Error; err != nil {
+		http.Error(w, "Error al obtener categorías: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	var totales []struct {
+		CategoriaID uint  `gorm:"column:CategoriaID"`
+		Total       int64 `gorm:"column:Total"`
+	}
+	if err := h.DB.Model(&models.HabilidadTaxonomia{}).
+		Select("CategoriaID, COUNT(*) AS Total").
+		Where("CategoriaID IS NOT NULL").
+		Group("CategoriaID").
+		Scan(&totales).Error; err != nil {
+		http.Error(w, "Error al contar habilidades: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+	counts := make(map[uint]int64, len(totales))
+	for _, total := range totales {
+		counts[total.CategoriaID] = total.Total
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(buildCategoryTree(categorias, counts))
+}
+
+// CreateCategory crea una categoría, en la raíz o debajo de padre_id
+func (h *taxonomyHandler) CreateCategory(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Método no permitido", http.StatusMethodNotAllowed)
+		return
+	}
+	if _, ok := authorizeAdmin(h.DB, w, r); !ok {
+		return
+	}
+
+	var req models.CategoriaHabilidadRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Error al decodificar la solicitud: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	categoria := models.CategoriaHabilidad{}
+	if req.Nombre != nil {
+		categoria.Nombre = strings.TrimSpace(*req.Nombre)
+	}
+	if req.Descripcion != nil {
+		categoria.Descripcion = strings.TrimSpace(*req.Descripcion)
+	}
+	if req.PadreID != nil && *req.PadreID != 0 {
+		categoria.PadreID = req.PadreID
+	}
+	if status, err := h.validateCategory(&categoria); err != nil {
+		http.Error(w, err.Error(), status)
+		return
+	}
+
+	if err := h.DB.Create(&categoria).Error; err != nil {
+		http.Error(w, "Error al crear la categoría: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(categoria)
+}
+
+// UpdateCategory renombra, describe o mueve una categoría dentro del árbol
+func (h *taxonomyHandler) UpdateCategory(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPut {
+		http.Error(w, "Método no permitido", http.StatusMethodNotAllowed)
+		return
+	}
+	if _, ok := authorizeAdmin(h.DB, w, r); !ok {
+		return
+	}
+
+	categoria, ok := h.loadCategory(w, r)
+	if !ok {
+		return
+	}
+
+	var req models.CategoriaHabilidadRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Error al decodificar la solicitud: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	if req.Nombre != nil {
+		categoria.Nombre = strings.TrimSpace(*req.Nombre)
+	}
+	if req.Descripcion != nil {
+		categoria.Descripcion = strings.TrimSpace(*req.Descripcion)
+	}
+	if req.PadreID != nil {
+		if *req.PadreID == 0 {
+			categoria.PadreID = nil
+		} else {
+			categoria.PadreID = req.PadreID
+		}
+	}
+	if status, err := h.validateCategory(&categoria); err != nil {
+		http.Error(w, err.Error(), status)
+		return
+	}
+
+	err := h.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&categoria).Select("Nombre", "Descripcion", "PadreID").Updates(&categoria).Error; err != nil {
+			return err
+		}
+		// Las habilidades de la categoría conservan su nombre en Habilidades.Categoria
+		return tx.Model(&models.HabilidadTaxonomia{}).
+			Where("CategoriaID = ?", categoria.CategoriaID).
+			UpdateColumn("Categoria", categoria.Nombre).Error
+	})
+	if err != nil {
+		http.Error(w, "Error al actualizar la categoría: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+	h.SuggestIndex.Invalidate()
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(categoria)
+}
+
+// DeleteCategory elimina una categoría sin subcategorías; sus habilidades quedan sin categoría del árbol
+func (h *taxonomyHandler) DeleteCategory(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodDelete {
+		http.Error(w, "Método no permitido", http.StatusMethodNotAllowed)
+		return
+	}
+	if _, ok := authorizeAdmin(h.DB, w, r); !ok {
+		return
+	}
+
+	categoria, ok := h.loadCategory(w, r)
+	if !ok {
+		return
+	}
+
+	var hijos int64
+	if err := h.DB.Model(&models.CategoriaHabilidad{}).Where("PadreID = ?", categoria.CategoriaID).Count(&hijos).Error; err != nil {
+		http.Error(w, "Error al verificar subcategorías: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if hijos > 0 {
+		http.Error(w, "La categoría tiene subcategorías: muévelas o elimínalas primero", http.StatusConflict)
+		return
+	}
+
+	err := h.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&models.HabilidadTaxonomia{}).
+			Where("CategoriaID = ?", categoria.CategoriaID).
+			UpdateColumn("CategoriaID", nil).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&categoria).Error
+	})
+	if err != nil {
+		http.Error(w, "Error al eliminar la categoría: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+	h.SuggestIndex.Invalidate()
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// GetAbilityTaxonomy devuelve una habilidad con su ruta en el árbol de categorías y sus alias
+func (h *taxonomyHandler) GetAbilityTaxonomy(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Método no permitido", http.StatusMethodNotAllowed)
+		return
+	}
+
+	id, err := parseIDFromPath(r, "id")
+	if err != nil {
+		http.Error(w, "ID de habilidad inválido", http.StatusBadRequest)
+		return
+	}
+	h.writeAbilityTaxonomy(w, id)
+}
+
+// SetAbilityCategory ubica una habilidad en una categoría del árbol
+func (h *taxonomyHandler) SetAbilityCategory(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPut {
+		http.Error(w, "Método no permitido", http.StatusMethodNotAllowed)
+		return
+	}
+	if _, ok := authorizeAdmin(h.DB, w, r); !ok {
+		return
+	}
+
+	habilidad, ok := h.loadAbility(w, r, "id")
+	if !ok {
+		return
+	}
+
+	var req models.AsignarCategoriaRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Error al decodificar la solicitud: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	var categoria models.CategoriaHabilidad
+	if result := h.DB.First(&categoria, req.CategoriaID); result.Error != nil {
+		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+			http.Error(w, "Categoría no encontrada", http.StatusNotFound)
+		} else {
+			http.Error(w, "Error al buscar la categoría: "+result.Error.Error(), http.StatusInternalServerError)
+		}
+		return
+	}
+
+	if err := h.DB.Model(&models.HabilidadTaxonomia{}).
+		Where("HabilidadID = ?", habilidad.ID).
+		UpdateColumns(map[string]interface{}{"CategoriaID": categoria.CategoriaID, "Categoria": categoria.Nombre}).Error; err != nil {
+		http.Error(w, "Error al asignar la categoría: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+	h.SuggestIndex.Invalidate()
+	h.writeAbilityTaxonomy(w, habilidad.ID)
+}
+
+// AddAbilityAlias registra un sinónimo que se resuelve a la habilidad canónica
+func (h *taxonomyHandler) AddAbilityAlias(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Método no permitido", http.StatusMethodNotAllowed)
+		return
+	}
+	if _, ok := authorizeAdmin(h.DB, w, r); !ok {
+		return
+	}
+
+	habilidad, ok := h.loadAbility(w, r, "id")
+	if !ok {
+		return
+	}
+
+	var req models.AliasHabilidadRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Error al decodificar la solicitud: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	req.Alias = strings.TrimSpace(req.Alias)
+	key := normalizeSkillName(req.Alias)
+	if key == "" || utf8.RuneCountInString(req.Alias) > maxSkillNameLength {
+		http.Error(w, fmt.Sprintf("El alias es obligatorio y no puede superar los %d caracteres", maxSkillNameLength), http.StatusBadRequest)
+		return
+	}
+
+	existente, err := findSkillByName(h.DB, req.Alias)
+	if err != nil {
+		http.Error(w, "Error al buscar habilidades: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if existente != nil {
+		if existente.ID == habilidad.ID {
+			http.Error(w, "El alias ya corresponde a esta habilidad", http.StatusConflict)
+		} else {
+			http.Error(w, fmt.Sprintf("El alias ya corresponde a la habilidad «%s» (ID %d): fusiónalas si son la misma", existente.Name, existente.ID), http.StatusConflict)
+		}
+		return
+	}
+
+	alias := models.AliasHabilidad{HabilidadID: habilidad.ID, Alias: req.Alias, AliasNormalizado: key}
+	if err := h.DB.Create(&alias).Error; err != nil {
+		http.Error(w, "Error al crear el alias: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+	h.SuggestIndex.Invalidate()
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(alias)
+}
+
+// DeleteAbilityAlias elimina un sinónimo de una habilidad
+func (h *taxonomyHandler) DeleteAbilityAlias(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodDelete {
+		http.Error(w, "Método no permitido", http.StatusMethodNotAllowed)
+		return
+	}
+	if _, ok := authorizeAdmin(h.DB, w, r); !ok {
+		return
+	}
+
+	habilidadID, err := parseIDFromPath(r, "id")
+	if err != nil {
+		http.Error(w, "ID de habilidad inválido", http.StatusBadRequest)
+		return
+	}
+	aliasID, err := parseIDFromPath(r, "aliasID")
+	if err != nil {
+		http.Error(w, "ID de alias inválido", http.StatusBadRequest)
+		return
+	}
+
+	result := h.DB.Where("AliasID = ? AND HabilidadID = ?", aliasID, habilidadID).Delete(&models.AliasHabilidad{})
+	if result.Error != nil {
+		http.Error(w, "Error al eliminar el alias: "+result.Error.Error(), http.StatusInternalServerError)
+		return
+	}
+	if result.RowsAffected == 0 {
+		http.Error(w, "Alias no encontrado", http.StatusNotFound)
+		return
+	}
+	h.SuggestIndex.Invalidate()
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// MergeAbility fusiona la habilidad de la ruta en la canónica indicada. En una transacción mueve a la
+// canónica los registros de UsuariosHabilidades, Posts y Emparejamientos (y las demás referencias),
+// conserva el nombre del duplicado y sus alias como alias de la canónica y elimina el duplicado.
+func (h *taxonomyHandler) MergeAbility(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Método no permitido", http.StatusMethodNotAllowed)
+		return
+	}
+	adminID, ok := authorizeAdmin(h.DB, w, r)
+	if !ok {
+		return
+	}
+
+	duplicada, ok := h.loadAbility(w, r, "id")
+	if !ok {
+		return
+	}
+
+	var req models.FusionarHabilidadRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Error al decodificar la solicitud: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	if req.CanonicaID == 0 || req.CanonicaID == duplicada.ID {
+		http.Error(w, "canonical_id debe indicar otra habilidad", http.StatusBadRequest)
+		return
+	}
+	var canonica models.Ability
+	if result := h.DB.First(&canonica, req.CanonicaID); result.Error != nil {
+		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+			http.Error(w, "Habilidad canónica no encontrada", http.StatusNotFound)
+		} else {
+			http.Error(w, "Error al buscar la habilidad canónica: "+result.Error.Error(), http.StatusInternalServerError)
+		}
+		return
+	}
+
+	// Posts que cambian de habilidad, para actualizar el índice de búsqueda al terminar
+	var postIDs []uint
+	if err := h.DB.Model(&models.Post{}).Where("HabilidadID = ?", duplicada.ID).Pluck("PostID", &postIDs).Error; err != nil {
+		http.Error(w, "Error al obtener posts: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	response := models.FusionHabilidadResponse{}
+	err := h.DB.Transaction(func(tx *gorm.DB) error {
+		// Los respaldos de los registros que se van a descartar pasan al registro que se conserva
+		// (el borrado los eliminaría en cascada); se omiten los respaldantes que ya respaldaban ese registro
+		result := tx.Exec("UPDATE r SET r.UsuarioHabilidadID = c.UsuarioHabilidadID FROM RespaldosHabilidad r "+
+			"INNER JOIN UsuariosHabilidades d ON d.UsuarioHabilidadID = r.UsuarioHabilidadID "+
+			"INNER JOIN UsuariosHabilidades c ON c.UsuarioID = d.UsuarioID AND c.TipoHabilidad = d.TipoHabilidad AND c.HabilidadID = ? "+
+			"WHERE d.HabilidadID = ? AND NOT EXISTS "+
+			"(SELECT 1 FROM RespaldosHabilidad e WHERE e.UsuarioHabilidadID = c.UsuarioHabilidadID AND e.RespaldanteID = r.RespaldanteID)",
+			canonica.ID, duplicada.ID)
+		if result.Error != nil {
+			return result.Error
+		}
+		response.RespaldosTrasladados = result.RowsAffected
+
+		// Un usuario que ya tenía la canónica con el mismo tipo conserva solo ese registro
+		result = tx.Exec("DELETE d FROM UsuariosHabilidades d WHERE d.HabilidadID = ? AND EXISTS "+
+			"(SELECT 1 FROM UsuariosHabilidades c WHERE c.UsuarioID = d.UsuarioID AND c.TipoHabilidad = d.TipoHabilidad AND c.HabilidadID = ?)",
+			duplicada.ID, canonica.ID)
+		if result.Error != nil {
+			return result.Error
+		}
+		response.DuplicadosDescartados = result.RowsAffected
+
+		result = tx.Model(&models.UserAbility{}).Where("HabilidadID = ?", duplicada.ID).UpdateColumn("HabilidadID", canonica.ID)
+		if result.Error != nil {
+			return result.Error
+		}
+		response.UsuariosHabilidades = result.RowsAffected
+
+		result = tx.Model(&models.Post{}).Where("HabilidadID = ?", duplicada.ID).UpdateColumn("HabilidadID", canonica.ID)
+		if result.Error != nil {
+			return result.Error
+		}
+		response.Posts = result.RowsAffected
+
+		for _, column := range []string{"Habilidad1ID", "Habilidad2ID"} {
+			result = tx.Model(&models.Matches{}).Where(column+" = ?", duplicada.ID).UpdateColumn(column, canonica.ID)
+			if result.Error != nil {
+				return result.Error
+			}
+			response.Emparejamientos += result.RowsAffected
+		}
+
+		// Referencias secundarias a la habilidad
+		references := []struct {
+			model  interface{}
+			column string
+		}{
+			{&models.RespuestaPost{}, "HabilidadOfrecidaID"},
+			{&models.RevisionPost{}, "HabilidadIDAnterior"},
+			{&models.BusquedaGuardada{}, "HabilidadID"},
+			{&models.PropuestaHabilidad{}, "HabilidadID"},
+			{&models.AliasHabilidad{}, "HabilidadID"},
+		}
+		for _, ref := range references {
+			if err := tx.Model(ref.model).Where(ref.column+" = ?", duplicada.ID).UpdateColumn(ref.column, canonica.ID).Error; err != nil {
+				return err
+			}
+		}
+
+		// El nombre del duplicado sigue resolviendo a la canónica
+		if key := normalizeSkillName(duplicada.Name); key != "" && key != normalizeSkillName(canonica.Name) {
+			var existing int64
+			if err := tx.Model(&models.AliasHabilidad{}).Where("AliasNormalizado = ?", key).Count(&existing).Error; err != nil {
+				return err
+			}
+			if existing == 0 {
+				alias := models.AliasHabilidad{HabilidadID: canonica.ID, Alias: duplicada.Name, AliasNormalizado: key}
+				if err := tx.Create(&alias).Error; err != nil {
+					return err
+				}
+			}
+		}
+
+		return tx.Delete(&models.Ability{}, duplicada.ID).Error
+	})
+	if err != nil {
+		http.Error(w, "Error al fusionar habilidades: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+	log.Printf("Habilidad %d (%s) fusionada en %d (%s) por el usuario %d", duplicada.ID, duplicada.Name, canonica.ID, canonica.Name, adminID)
+	h.SuggestIndex.Invalidate()
+
+	if len(postIDs) > 0 {
+		var posts []models.Post
+		if err := h.DB.Where("PostID IN ? AND Oculto = 0", postIDs).Find(&posts).Error; err == nil {
+			for _, post := range posts {
+				indexDocument(h.Searcher, search.PostDocument(post, canonica.Name))
+			}
+		}
+	}
+
+	taxonomia, err := loadAbilityTaxonomy(h.DB, canonica.ID)
+	if err != nil {
+		http.Error(w, "Error al obtener la habilidad: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+	response.Habilidad = taxonomia
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
+
+// CreateAbilityProposal registra una habilidad propuesta por el usuario en la cola de aprobación
+func (h *taxonomyHandler) CreateAbilityProposal(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Método no permitido", http.StatusMethodNotAllowed)
+		return
+	}
+
+	user, authenticated := middleware.GetUserFromContext(r)
+	if !authenticated {
+		http.Error(w, "Autenticación requerida", http.StatusUnauthorized)
+		return
+	}
+
+	var req models.PropuestaHabilidadRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Error al decodificar la solicitud: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	req.Nombre = strings.TrimSpace(req.Nombre)
+	req.Categoria = strings.TrimSpace(req.Categoria)
+	req.Descripcion = strings.TrimSpace(req.Descripcion)
+	key := normalizeSkillName(req.Nombre)
+	switch {
+	case key == "":
+		http.Error(w, "El nombre de la habilidad es obligatorio", http.StatusBadRequest)
+		return
+	case utf8.RuneCountInString(req.Nombre) > maxSkillNameLength, utf8.RuneCountInString(req.Categoria) > maxSkillNameLength:
+		http.Error(w, fmt.Sprintf("El nombre y la categoría no pueden superar los %d caracteres", maxSkillNameLength), http.StatusBadRequest)
+		return
+	case utf8.RuneCountInString(req.Descripcion) > maxSkillDescriptionLength:
+		http.Error(w, fmt.Sprintf("La descripción no puede superar los %d caracteres", maxSkillDescriptionLength), http.StatusBadRequest)
+		return
+	}
+
+	existente, err := findSkillByName(h.DB, req.Nombre)
+	if err != nil {
+		http.Error(w, "Error al buscar habilidades: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if existente != nil {
+		http.Error(w, fmt.Sprintf("La habilidad ya existe como «%s» (ID %d)", existente.Name, existente.ID), http.StatusConflict)
+		return
+	}
+
+	var pendientes int64
+	if err := h.DB.Model(&models.PropuestaHabilidad{}).
+		Where("NombreNormalizado = ? AND Estado = ?", key, models.PropuestaPendiente).
+		Count(&pendientes).Error; err != nil {
+		http.Error(w, "Error al buscar propuestas: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if pendientes > 0 {
+		http.Error(w, "Ya hay una propuesta pendiente para esta habilidad", http.StatusConflict)
+		return
+	}
+
+	propuesta := models.PropuestaHabilidad{
+		UsuarioID:         user.UserID,
+		Nombre:            req.Nombre,
+		NombreNormalizado: key,
+		Categoria:         req.Categoria,
+		Descripcion:       req.Descripcion,
+		Estado:            models.PropuestaPendiente,
+	}
+	if err := h.DB.Create(&propuesta).Error; err != nil {
+		http.Error(w, "Error al guardar la propuesta: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(propuesta)
+}
+
+// GetAbilityProposals lista la cola de propuestas (pendientes por defecto, ?estado= para otras).
+// Los administradores ven todas; el resto de usuarios solo las propias.
+func (h *taxonomyHandler) GetAbilityProposals(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Método no permitido", http.StatusMethodNotAllowed)
+		return
+	}
+
+	user, authenticated := middleware.GetUserFromContext(r)
+	if !authenticated {
+		http.Error(w, "Autenticación requerida", http.StatusUnauthorized)
+		return
+	}
+	admin, err := isAdmin(h.DB, user.UserID)
+	if err != nil {
+		http.Error(w, "Error verificando permisos: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	estado := strings.ToLower(strings.TrimSpace(r.URL.Query().Get("estado")))
+	if estado == "" {
+		estado = models.PropuestaPendiente
+	}
+	if !containsString(models.EstadosPropuesta, estado) {
+		http.Error(w, "Estado inválido: use "+strings.Join(models.EstadosPropuesta, ", "), http.StatusBadRequest)
+		return
+	}
+
+	query := h.DB.Table("PropuestasHabilidad p").
+		Select("p.*, u.NombreUsuario").
+		Joins("INNER JOIN Usuarios u ON u.UsuarioID = p.UsuarioID").
+		Where("p.Estado = ?", estado)
+	if !admin {
+		query = query.Where("p.UsuarioID = ?", user.UserID)
+	}
+
+	propuestas := []models.PropuestaHabilidad{}
+	if err := query.Order("p.FechaCreacion ASC").Find(&propuestas).Error; err != nil {
+		http.Error(w, "Error al obtener propuestas: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(propuestas)
+}
+
+// ApproveAbilityProposal aprueba una propuesta: crea la habilidad (con los datos corregidos por el
+// administrador si los indica) o, con habilidad_id, la registra como alias de una existente
+func (h *taxonomyHandler) ApproveAbilityProposal(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Método no permitido", http.StatusMethodNotAllowed)
+		return
+	}
+	adminID, ok := authorizeAdmin(h.DB, w, r)
+	if !ok {
+		return
+	}
+
+	propuesta, ok := h.loadPendingProposal(w, r)
+	if !ok {
+		return
+	}
+
+	var req models.RevisionPropuestaRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Error al decodificar la solicitud: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	var habilidadID uint
+	var contenido string
+	if req.HabilidadID != nil && *req.HabilidadID != 0 {
+		// La propuesta es un sinónimo de una habilidad existente
+		var existente models.Ability
+		if result := h.DB.First(&existente, *req.HabilidadID); result.Error != nil {
+			http.Error(w, "Habilidad no encontrada", http.StatusNotFound)
+			return
+		}
+		err := h.DB.Transaction(func(tx *gorm.DB) error {
+			var existing int64
+			if err := tx.Model(&models.AliasHabilidad{}).Where("AliasNormalizado = ?", propuesta.NombreNormalizado).Count(&existing).Error; err != nil {
+				return err
+			}
+			if existing == 0 && propuesta.NombreNormalizado != normalizeSkillName(existente.Name) {
+				alias := models.AliasHabilidad{HabilidadID: existente.ID, Alias: propuesta.Nombre, AliasNormalizado: propuesta.NombreNormalizado}
+				if err := tx.Create(&alias).Error; err != nil {
+					return err
+				}
+			}
+			return reviewProposal(tx, &propuesta, models.PropuestaAprobada, adminID, &existente.ID, req.Nota)
+		})
+		if err != nil {
+			http.Error(w, "Error al aprobar la propuesta: "+err.Error(), http.StatusInternalServerError)
+			return
+		}
+		habilidadID = existente.ID
+		contenido = fmt.Sprintf("Tu propuesta «%s» se agregó como sinónimo de «%s»", propuesta.Nombre, existente.Name)
+	} else {
+		habilidad := models.HabilidadTaxonomia{
+			Nombre:      propuesta.Nombre,
+			Categoria:   propuesta.Categoria,
+			Descripcion: propuesta.Descripcion,
+		}
+		if req.Nombre != nil {
+			habilidad.Nombre = strings.TrimSpace(*req.Nombre)
+		}
+		if req.Descripcion != nil {
+			habilidad.Descripcion = strings.TrimSpace(*req.Descripcion)
+		}
+		if normalizeSkillName(habilidad.Nombre) == "" || utf8.RuneCountInString(habilidad.Nombre) > maxSkillNameLength {
+			http.Error(w, fmt.Sprintf("El nombre es obligatorio y no puede superar los %d caracteres", maxSkillNameLength), http.StatusBadRequest)
+			return
+		}
+		if req.CategoriaID != nil && *req.CategoriaID != 0 {
+			var categoria models.CategoriaHabilidad
+			if result := h.DB.First(&categoria, *req.CategoriaID); result.Error != nil {
+				http.Error(w, "Categoría no encontrada", http.StatusNotFound)
+				return
+			}
+			habilidad.CategoriaID = &categoria.CategoriaID
+			habilidad.Categoria = categoria.Nombre
+		}
+
+		existente, err := findSkillByName(h.DB, habilidad.Nombre)
+		if err != nil {
+			http.Error(w, "Error al buscar habilidades: "+err.Error(), http.StatusInternalServerError)
+			return
+		}
+		if existente != nil {
+			http.Error(w, fmt.Sprintf("La habilidad ya existe como «%s» (ID %d): apruébala como sinónimo con habilidad_id", existente.Name, existente.ID), http.StatusConflict)
+			return
+		}
+
+		err = h.DB.Transaction(func(tx *gorm.DB) error {
+			if err := tx.Omit("Aliases").Create(&habilidad).Error; err != nil {
+				return err
+			}
+			return reviewProposal(tx, &propuesta, models.PropuestaAprobada, adminID, &habilidad.HabilidadID, req.Nota)
+		})
+		if err != nil {
+			http.Error(w, "Error al aprobar la propuesta: "+err.Error(), http.StatusInternalServerError)
+			return
+		}
+		habilidadID = habilidad.HabilidadID
+		contenido = fmt.Sprintf("Tu propuesta «%s» fue aprobada y ya está disponible", habilidad.Nombre)
+	}
+
+	h.SuggestIndex.Invalidate()
+	h.notifyProposalReviewed(propuesta, "Habilidad aprobada", contenido)
+
+	h.writeAbilityTaxonomy(w, habilidadID)
+}
+
+// RejectAbilityProposal rechaza una propuesta, con una nota opcional para el usuario
+func (h *taxonomyHandler) RejectAbilityProposal(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Método no permitido", http.StatusMethodNotAllowed)
+		return
+	}
+	adminID, ok := authorizeAdmin(h.DB, w, r)
+	if !ok {
+		return
+	}
+
+	propuesta, ok := h.loadPendingProposal(w, r)
+	if !ok {
+		return
+	}
+
+	var req models.RevisionPropuestaRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Error al decodificar la solicitud: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	if err := reviewProposal(h.DB, &propuesta, models.PropuestaRechazada, adminID, nil, req.Nota); err != nil {
+		http.Error(w, "Error al rechazar la propuesta: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	contenido := fmt.Sprintf("Tu propuesta «%s» no fue aprobada", propuesta.Nombre)
+	if propuesta.NotaRevision != "" {
+		contenido += ": " + propuesta.NotaRevision
+	}
+	h.notifyProposalReviewed(propuesta, "Habilidad no aprobada", contenido)
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(propuesta)
+}
+
+// reviewProposal guarda la decisión sobre una propuesta
+func reviewProposal(db *gorm.DB, propuesta *models.PropuestaHabilidad, estado string, revisorID uint, habilidadID *uint, nota string) error {
+	now := time.Now()
+	propuesta.Estado = estado
+	propuesta.RevisorID = &revisorID
+	propuesta.HabilidadID = habilidadID
+	propuesta.NotaRevision = truncateRunes(strings.TrimSpace(nota), maxSkillDescriptionLength)
+	propuesta.FechaRevision = &now
+	return db.Model(propuesta).
+		Select("Estado", "RevisorID", "HabilidadID", "NotaRevision", "FechaRevision").
+		Updates(propuesta).Error
+}
+
+// notifyProposalReviewed avisa al autor de la propuesta la decisión del administrador
+func (h *taxonomyHandler) notifyProposalReviewed(propuesta models.PropuestaHabilidad, titulo, contenido string) {
+	if !notificationEnabled(h.DB, propuesta.UsuarioID, "skill_proposal_reviewed") {
+		return
+	}
+	err := createNotification(h.DB, h.WSHandler, h.SocketIOBroadcaster, models.Notification{
+		UsuarioID:    propuesta.UsuarioID,
+		Tipo:         "skill_proposal_reviewed",
+		Titulo:       titulo,
+		Contenido:    contenido,
+		ReferenciaID: propuesta.PropuestaID,
+	})
+	logNotificationError("skill_proposal_reviewed", propuesta.UsuarioID, err)
+}
+
+// loadPendingProposal obtiene la propuesta de la ruta verificando que siga pendiente
+func (h *taxonomyHandler) loadPendingProposal(w http.ResponseWriter, r *http.Request) (models.PropuestaHabilidad, bool) {
+	var propuesta models.PropuestaHabilidad
+
+	id, err := parseIDFromPath(r, "proposalID")
+	if err != nil {
+		http.Error(w, "ID de propuesta inválido", http.StatusBadRequest)
+		return propuesta, false
+	}
+	if result := h.DB.First(&propuesta, id); result.Error != nil {
+		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+			http.Error(w, "Propuesta no encontrada", http.StatusNotFound)
+		} else {
+			http.Error(w, "Error al buscar la propuesta: "+result.Error.Error(), http.StatusInternalServerError)
+		}
+		return propuesta, false
+	}
+	if propuesta.Estado != models.PropuestaPendiente {
+		http.Error(w, "La propuesta ya fue revisada", http.StatusConflict)
+		return propuesta, false
+	}
+	return propuesta, true
+}
+
+// loadCategory obtiene la categoría de la ruta
+func (h *taxonomyHandler) loadCategory(w http.ResponseWriter, r *http.Request) (models.CategoriaHabilidad, bool) {
+	var categoria models.CategoriaHabilidad
+
+	id, err := parseIDFromPath(r, "categoryID")
+	if err != nil {
+		http.Error(w, "ID de categoría inválido", http.StatusBadRequest)
+		return categoria, false
+	}
+	if result := h.DB.First(&categoria, id); result.Error != nil {
+		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+			http.Error(w, "Categoría no encontrada", http.StatusNotFound)
+		} else {
+			http.Error(w, "Error al buscar la categoría: "+result.Error.Error(), http.StatusInternalServerError)
+		}
+		return categoria, false
+	}
+	return categoria, true
+}
+
+// loadAbility obtiene la habilidad indicada por el parámetro de la ruta
+func (h *taxonomyHandler) loadAbility(w http.ResponseWriter, r *http.Request, param string) (models.Ability, bool) {
+	var habilidad models.Ability
+
+	id, err := parseIDFromPath(r, param)
+	if err != nil {
+		http.Error(w, "ID de habilidad inválido", http.StatusBadRequest)
+		return habilidad, false
+	}
+	if result := h.DB.First(&habilidad, id); result.Error != nil {
+		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+			http.Error(w, "Habilidad no encontrada", http.StatusNotFound)
+		} else {
+			http.Error(w, "Error al buscar la habilidad: "+result.Error.Error(), http.StatusInternalServerError)
+		}
+		return habilidad, false
+	}
+	return habilidad, true
+}
+
+// validateCategory valida el nombre, la unicidad entre hermanas y que el padre no genere un ciclo
+func (h *taxonomyHandler) validateCategory(categoria *models.CategoriaHabilidad) (int, error) {
+	if categoria.Nombre == "" || utf8.RuneCountInString(categoria.Nombre) > maxSkillNameLength {
+		return http.StatusBadRequest, fmt.Errorf("El nombre es obligatorio y no puede superar los %d caracteres", maxSkillNameLength)
+	}
+	if utf8.RuneCountInString(categoria.Descripcion) > maxSkillDescriptionLength {
+		return http.StatusBadRequest, fmt.Errorf("La descripción no puede superar los %d caracteres", maxSkillDescriptionLength)
+	}
+
+	if categoria.PadreID != nil {
+		// Recorrer los ancestros del nuevo padre: la categoría no puede quedar debajo de sí misma
+		current := *categoria.PadreID
+		for depth := 0; ; depth++ {
+			if categoria.CategoriaID != 0 && current == categoria.CategoriaID {
+				return http.StatusBadRequest, errors.New("Una categoría no puede moverse debajo de sí misma o de sus subcategorías")
+			}
+			if depth >= maxCategoryDepth {
+				return http.StatusBadRequest, fmt.Errorf("El árbol no puede superar los %d niveles", maxCategoryDepth)
+			}
+			var padre models.CategoriaHabilidad
+			if result := h.DB.Select("CategoriaID", "PadreID").First(&padre, current); result.Error != nil {
+				if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+					return http.StatusNotFound, errors.New("Categoría padre no encontrada")
+				}
+				return http.StatusInternalServerError, result.Error
+			}
+			if padre.PadreID == nil {
+				break
+			}
+			current = *padre.PadreID
+		}
+	}
+
+	query := h.DB.Model(&models.CategoriaHabilidad{}).Where("Nombre = ? AND CategoriaID <> ?", categoria.Nombre, categoria.CategoriaID)
+	if categoria.PadreID == nil {
+		query = query.Where("PadreID IS NULL")
+	} else {
+		query = query.Where("PadreID = ?", *categoria.PadreID)
+	}
+	var duplicadas int64
+	if err := query.Count(&duplicadas).Error; err != nil {
+		return http.StatusInternalServerError, err
+	}
+	if duplicadas > 0 {
+		return http.StatusConflict, errors.New("Ya existe una categoría con ese nombre en el mismo nivel")
+	}
+	return 0, nil
+}
+
+// writeAbilityTaxonomy responde con la habilidad, su ruta de categorías y sus alias
+func (h *taxonomyHandler) writeAbilityTaxonomy(w http.ResponseWriter, id uint) {
+	taxonomia, err := loadAbilityTaxonomy(h.DB, id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			http.Error(w, "Habilidad no encontrada", http.StatusNotFound)
+		} else {
+			http.Error(w, "Error al obtener la habilidad: "+err.Error(), http.StatusInternalServerError)
+		}
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(taxonomia)
+}
+
+// loadAbilityTaxonomy carga una habilidad con sus alias y la ruta de su categoría desde la raíz
+func loadAbilityTaxonomy(db *gorm.DB, id uint) (models.HabilidadTaxonomia, error) {
+	var taxonomia models.HabilidadTaxonomia
+	if err := db.Preload("Aliases", func(tx *gorm.DB) *gorm.DB {
+		return tx.Order("Alias")
+	}).First(&taxonomia, id).Error; err != nil {
+		return taxonomia, err
+	}
+
+	taxonomia.Ruta = []string{}
+	if taxonomia.CategoriaID != nil {
+		current := taxonomia.CategoriaID
+		for depth := 0; current != nil && depth < maxCategoryDepth; depth++ {
+			var categoria models.CategoriaHabilidad
+			if err := db.First(&categoria, *current).Error; err != nil {
+				break
+			}
+			taxonomia.Ruta = append([]string{categoria.Nombre}, taxonomia.Ruta...)
+			current = categoria.PadreID
+		}
+	}
+	return taxonomia, nil
+}
+
+// buildCategoryTree arma el árbol a partir de la lista plana de categorías
+func buildCategoryTree(categorias []models.CategoriaHabilidad, counts map[uint]int64) []models.CategoriaHabilidad {
+	children := make(map[uint][]models.CategoriaHabilidad)
+	var roots []models.CategoriaHabilidad
+	for _, categoria := range categorias {
+		categoria.TotalHabilidades = counts[categoria.CategoriaID]
+		if categoria.PadreID == nil {
+			roots = append(roots, categoria)
+		} else {
+			children[*categoria.PadreID] = append(children[*categoria.PadreID], categoria)
+		}
+	}
+
+	var attach func(nodes []models.CategoriaHabilidad, depth int) []models.CategoriaHabilidad
+	attach = func(nodes []models.CategoriaHabilidad, depth int) []models.CategoriaHabilidad {
+		sort.Slice(nodes, func(i, j int) bool { return nodes[i].Nombre < nodes[j].Nombre })
+		for i := range nodes {
+			nodes[i].Subcategorias = []models.CategoriaHabilidad{}
+			if depth < maxCategoryDepth {
+				nodes[i].Subcategorias = attach(children[nodes[i].CategoriaID], depth+1)
+			}
+		}
+		return nodes
+	}
+	if roots == nil {
+		roots = []models.CategoriaHabilidad{}
+	}
+	return attach(roots, 0)
+}
+
+// normalizeSkillName reduce un nombre de habilidad a una clave de comparación: minúsculas, sin
+// acentos y sin espacios ni signos, salvo + y # ("Go Lang" y "golang" producen la misma clave,
+// "C++" y "C#" siguen siendo distintas)
+func normalizeSkillName(name string) string {
+	return strings.Join(strings.Fields(foldSkillText(name)), "")
+}
+
+// findSkillByName busca la habilidad canónica que corresponde a un nombre, ya sea por su propio
+// nombre normalizado o por uno de sus alias. Devuelve nil si no existe.
+func findSkillByName(db *gorm.DB, name string) (*models.Ability, error) {
+	key := normalizeSkillName(name)
+	if key == "" {
+		return nil, nil
+	}
+
+	var alias models.AliasHabilidad
+	result := db.Where("AliasNormalizado = ?", key).Limit(1).Find(&alias)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	if result.RowsAffected > 0 {
+		var habilidad models.Ability
+		if err := db.First(&habilidad, alias.HabilidadID).Error; err != nil {
+			return nil, err
+		}
+		return &habilidad, nil
+	}
+
+	// Los nombres de habilidades se comparan en memoria porque la normalización no es expresable en SQL
+	var habilidades []models.Ability
+	if err := db.Select("HabilidadID", "NombreHabilidad", "Categoria").Find(&habilidades).Error; err != nil {
+		return nil, err
+	}
+	for i := range habilidades {
+		if normalizeSkillName(habilidades[i].Name) == key {
+			return &habilidades[i], nil
+		}
+	}
+	return nil, nil
+}
+
+// isAdmin indica si el usuario tiene rol de administrador
+func isAdmin(db *gorm.DB, userID uint) (bool, error) {
+	var user models.User
+	if err := db.Select("UsuarioID", "Rol").First(&user, userID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return false, nil
+		}
+		return false, err
+	}
+	return strings.EqualFold(user.Rol, "admin"), nil
+}
+
+// authorizeAdmin verifica que el usuario autenticado sea administrador
+func authorizeAdmin(db *gorm.DB, w http.ResponseWriter, r *http.Request) (uint, bool) {
+	user, authenticated := middleware.GetUserFromContext(r)
+	if !authenticated {
+		http.Error(w, "Autenticación requerida", http.StatusUnauthorized)
+		return 0, false
+	}
+
+	admin, err := isAdmin(db, user.UserID)
+	if err != nil {
+		http.Error(w, "Error verificando permisos: "+err.Error(), http.StatusInternalServerError)
+		return 0, false
+	}
+	if !admin {
+		http.Error(w, "Solo un administrador puede gestionar las habilidades; para sugerir una nueva usa /ability-proposals", http.StatusForbidden)
+		return 0, false
+	}
+	return user.UserID, true
+}
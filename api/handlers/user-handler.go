@@ -14,19 +14,27 @@ import (
 
 	"golang.org/x/crypto/bcrypt"
 
+	"skillswap/api/middleware"
 	"skillswap/api/models"
+	"skillswap/api/search"
 
 	"gorm.io/gorm"
 )
 
 type userHandler struct {
-	DB *gorm.DB
+	DB       *gorm.DB
+	Searcher search.Searcher
 }
 
 func NewUserHandler(db *gorm.DB) *userHandler {
 	return &userHandler{DB: db}
 }
 
+// SetSearcher configura el índice de búsqueda que se actualiza con cada cambio
+func (h *userHandler) SetSearcher(searcher search.Searcher) {
+	h.Searcher = searcher
+}
+
 func (h *userHandler) GetUsers(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodGet {
 		http.Error(w, "Método no permitido", http.StatusMethodNotAllowed)
@@ -75,6 +83,10 @@ func (h *userHandler) GetUsers(w http.ResponseWriter, r *http.Request) {
 		http.Error(w, "Error al obtener usuarios: "+result.Error.Error(), http.StatusInternalServerError)
 		return
 	}
+	if err := markBookmarkedUsers(h.DB, commentViewerID(r), users); err != nil {
+		http.Error(w, "Error al obtener marcadores: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
 
 	// Estructura de respuesta que incluye los usuarios y la información de paginación
 	type PaginatedUsersResponse struct {
@@ -116,10 +128,20 @@ func (h *userHandler) GetUser(w http.ResponseWriter, r *http.Request) {
 
 	var user models.User
 
-	if result := h.DB.First(&user, id); result.Error != nil {
+	if result := h.DB.Preload("UserAbilities").Preload("UserAbilities.Ability").First(&user, id); result.Error != nil {
 		http.Error(w, "Usuario no encontrado", http.StatusInternalServerError)
 		return
 	}
+	users := []models.User{user}
+	if err := markBookmarkedUsers(h.DB, commentViewerID(r), users); err != nil {
+		http.Error(w, "Error al obtener marcadores: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+	user = users[0]
+	if err := attachEndorsements(h.DB, commentViewerID(r), user.UserAbilities); err != nil {
+		http.Error(w, "Error al obtener respaldos: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
 
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(user)
@@ -206,6 +228,7 @@ func (h *userHandler) CreateUser(w http.ResponseWriter, r *http.Request) {
 	}
 
 	log.Printf("CreateUser: Usuario creado con éxito: ID=%d", user.ID)
+	indexDocument(h.Searcher, search.UserDocument(user))
 
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusCreated)
@@ -213,7 +236,7 @@ func (h *userHandler) CreateUser(w http.ResponseWriter, r *http.Request) {
 }
 
 func (h *userHandler) BanUser (w http.ResponseWriter, r *http.Request) {
-	if r.Method != http.MethodGet {
+	if r.Method != http.MethodPost {
 		http.Error(w, "Método no permitido", http.StatusMethodNotAllowed)
 		return
 	}
@@ -224,6 +247,26 @@ func (h *userHandler) BanUser (w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// Solo moderadores y administradores pueden banear usuarios
+	moderator, authenticated := middleware.GetUserFromContext(r)
+	if !authenticated {
+		http.Error(w, "Autenticación requerida", http.StatusUnauthorized)
+		return
+	}
+	allowed, err := isModerator(h.DB, moderator.UserID)
+	if err != nil {
+		http.Error(w, "Error verificando permisos: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if !allowed {
+		http.Error(w, "Solo un moderador puede banear usuarios", http.StatusForbidden)
+		return
+	}
+	if uint(userId) == moderator.UserID {
+		http.Error(w, "No puedes banearte a ti mismo", http.StatusBadRequest)
+		return
+	}
+
 	var user models.User
 	if result := h.DB.First(&user, userId); result.Error != nil {
 		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
@@ -242,6 +285,21 @@ func (h *userHandler) BanUser (w http.ResponseWriter, r *http.Request) {
 		return // Añadido return para evitar continuar si hay error
 	}
 
+	// Registrar el cambio en la auditoría de moderación
+	action := models.AccionModeracion{
+		Accion:            moderationActionUnban,
+		TipoContenido:     search.TypeUser,
+		ContenidoID:       user.ID,
+		UsuarioAfectadoID: &user.ID,
+		ModeradorID:       &moderator.UserID,
+	}
+	if user.IsBanned {
+		action.Accion = moderationActionBan
+	}
+	if err := recordModerationAction(h.DB, action); err != nil {
+		log.Printf("Error registrando la acción de moderación sobre el usuario %d: %v", user.ID, err)
+	}
+
 	w.Header().Set("Content-Type", "application/json") // Cambiado a application/json
 	json.NewEncoder(w).Encode(user);
 }
@@ -335,6 +393,7 @@ func (h *userHandler) UpdateUser(w http.ResponseWriter, r *http.Request) {
 		http.Error(w, "Error al actualizar usuario: "+result.Error.Error(), http.StatusInternalServerError)
 		return
 	}
+	indexDocument(h.Searcher, search.UserDocument(user))
 
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(user)
@@ -352,10 +411,18 @@ func (h *userHandler) DeleteUser(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// Los respaldos dados no se eliminan en cascada con el usuario
+	if err := deleteEndorsementsBy(h.DB, uint(id)); err != nil {
+		http.Error(w, "Error al eliminar respaldos: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
 	if result := h.DB.Delete(&models.User{}, id); result.Error != nil {
 		http.Error(w, result.Error.Error(), http.StatusInternalServerError)
 		return
 	}
+	unindexDocument(h.Searcher, search.TypeUser, uint(id))
+	deleteBookmarksOf(h.DB, models.MarcadorUsuario, uint(id))
 
 	w.WriteHeader(http.StatusNoContent)
 }
@@ -136,6 +136,13 @@ func (h *Hub) run() {
 		case client := <-h.Register:
 			h.mu.Lock()
 			h.Clients[client] = true
+			// Cada cliente se une a su sala personal para recibir notificaciones
+			userRoom := fmt.Sprintf("user_%d", client.UserID)
+			if h.Rooms[userRoom] == nil {
+				h.Rooms[userRoom] = make(map[*Client]bool)
+			}
+			h.Rooms[userRoom][client] = true
+			client.Rooms[userRoom] = true
 			h.mu.Unlock()
 
 			log.Printf("Cliente %s (UserID: %d) conectado. Total clientes: %d",
@@ -265,6 +272,11 @@ func (c *Client) writePump() {
 				return
 			}
 
+			// Confirmar la entrega cuando el destinatario recibe un mensaje nuevo
+			if message.Type == "new_message" {
+				go c.markMessageDelivered(message)
+			}
+
 		case <-ticker.C:
 			c.Conn.SetWriteDeadline(time.Now().Add(10 * time.Second))
 			if err := c.Conn.WriteMessage(websocket.PingMessage, nil); err != nil {
@@ -495,14 +507,71 @@ func (c *Client) handlePing(	) {
 func (c *Client) canJoinConversation(conversationID string) bool {
 	var conversation models.Conversation
 
-	if err := c.Hub.DB.Where("id = ? AND (user1_id = ? OR user2_id = ?)",
-		conversationID, c.UserID, c.UserID).First(&conversation).Error; err != nil {
+	if err := c.Hub.DB.Where("ID = ?", conversationID).First(&conversation).Error; err != nil {
+		return false
+	}
+
+	participantIDs, err := conversationParticipantIDs(c.Hub.DB, conversation)
+	if err != nil || !containsUserID(participantIDs, c.UserID) {
 		return false
 	}
 
+	// Un usuario bloqueado no puede unirse a la sala de quien lo bloqueó
+	for _, participantID := range participantIDs {
+		if participantID == c.UserID {
+			continue
+		}
+		blocked, err := isBlockedBetween(c.Hub.DB, c.UserID, participantID)
+		if err != nil || blocked {
+			return false
+		}
+	}
+
 	return true
 }
 
+// markMessageDelivered registra la entrega de un mensaje a este cliente y lo notifica a la sala
+func (c *Client) markMessageDelivered(msg WebSocketMessage) {
+	data, ok := msg.Data.(map[string]interface{})
+	if !ok {
+		return
+	}
+
+	messageID, ok := data["id"].(uint)
+	if !ok {
+		return
+	}
+	senderID, _ := data["sender_id"].(uint)
+	if senderID == c.UserID {
+		return
+	}
+
+	now := time.Now()
+	result := c.Hub.DB.Model(&models.MessageReceipt{}).
+		Where("MessageID = ? AND UserID = ? AND DeliveredAt IS NULL", messageID, c.UserID).
+		Update("DeliveredAt", now)
+	if result.Error != nil {
+		log.Printf("Error registrando entrega del mensaje %d: %v", messageID, result.Error)
+		return
+	}
+	if result.RowsAffected == 0 {
+		// Ya estaba entregado (por ejemplo, en otra pestaña del mismo usuario)
+		return
+	}
+
+	c.Hub.Broadcast <- WebSocketMessage{
+		Type: "message_delivered",
+		Data: map[string]interface{}{
+			"message_id": messageID,
+			"conversation_id": data["conversation_id"],
+			"user_id": c.UserID,
+			"delivered_at": now,
+		},
+		RoomID: msg.RoomID,
+		Time: now,
+	}
+}
+
 // BroadcastNewMessage envía una notificación de nuevo mensaje
 func (ws *WebSocketHandler) BroadcastNewMessage(message models.Message) {
 	roomID := fmt.Sprintf("conversation_%d", message.ConversationID)
@@ -524,6 +593,48 @@ func (ws *WebSocketHandler) BroadcastNewMessage(message models.Message) {
 	ws.Hub.Broadcast <- broadcastMsg
 }
 
+// BroadcastMessagesRead envía una notificación de mensajes leídos a la sala de la conversación
+func (ws *WebSocketHandler) BroadcastMessagesRead(conversationID uint, readData interface{}) {
+	roomID := fmt.Sprintf("conversation_%d", conversationID)
+
+	broadcastMsg := WebSocketMessage{
+		Type: "messages_read",
+		Data: readData,
+		RoomID: roomID,
+		Time: time.Now(),
+	}
+
+	ws.Hub.Broadcast <- broadcastMsg
+}
+
+// BroadcastMessageReaction envía una reacción agregada o quitada a la sala de la conversación
+func (ws *WebSocketHandler) BroadcastMessageReaction(conversationID uint, eventName string, reactionData interface{}) {
+	roomID := fmt.Sprintf("conversation_%d", conversationID)
+
+	broadcastMsg := WebSocketMessage{
+		Type: eventName,
+		Data: reactionData,
+		RoomID: roomID,
+		Time: time.Now(),
+	}
+
+	ws.Hub.Broadcast <- broadcastMsg
+}
+
+// BroadcastNotification envía una notificación a la sala personal del usuario
+func (ws *WebSocketHandler) BroadcastNotification(userID uint, notificationData interface{}) {
+	roomID := fmt.Sprintf("user_%d", userID)
+
+	broadcastMsg := WebSocketMessage{
+		Type: "notification",
+		Data: notificationData,
+		RoomID: roomID,
+		Time: time.Now(),
+	}
+
+	ws.Hub.Broadcast <- broadcastMsg
+}
+
 // BroadcastNewComment envía una notificación de nuevo comentario
 func (ws *WebSocketHandler) BroadcastNewComment(comment models.ComentarioCompleto) {
 	roomID := fmt.Sprintf("post_%d", comment.PostID)
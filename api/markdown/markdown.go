@@ -0,0 +1,245 @@
+// Package markdown renderiza el dialecto limitado de Markdown que aceptan posts, comentarios
+// y mensajes: negritas, cursivas, código, enlaces y listas. Todo el texto se escapa, por lo
+// que el HTML generado solo contiene las etiquetas que produce este paquete.
+package markdown
+
+import (
+	"fmt"
+	"html"
+	"net/url"
+	"regexp"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Longitudes máximas (en caracteres) del texto fuente por tipo de contenido
+const (
+	MaxPostLength    = 5000
+	MaxCommentLength = 2000
+	MaxMessageLength = 4000
+	maxURLLength     = 2048
+)
+
+// Esquemas permitidos en los enlaces; cualquier otro (javascript:, data:, etc.) se descarta
+var allowedSchemes = map[string]bool{
+	"http":   true,
+	"https":  true,
+	"mailto": true,
+}
+
+var (
+	unorderedItem = regexp.MustCompile(`^\s{0,3}[-*+]\s+(.*)$`)
+	orderedItem   = regexp.MustCompile(`^\s{0,3}\d{1,9}[.)]\s+(.*)$`)
+)
+
+// ValidateLength devuelve un error si el texto supera la longitud máxima permitida
+func ValidateLength(source string, max int) error {
+	if utf8.RuneCountInString(source) > max {
+		return fmt.Errorf("el contenido supera el máximo de %d caracteres", max)
+	}
+	return nil
+}
+
+// Render convierte el texto fuente en HTML seguro
+func Render(source string) string {
+	source = strings.ReplaceAll(source, "\r\n", "\n")
+	lines := strings.Split(source, "\n")
+
+	var b strings.Builder
+	var paragraph []string
+
+	flushParagraph := func() {
+		if len(paragraph) == 0 {
+			return
+		}
+		b.WriteString("<p>")
+		for i, line := range paragraph {
+			if i > 0 {
+				b.WriteString("<br>")
+			}
+			b.WriteString(renderInline(line))
+		}
+		b.WriteString("</p>")
+		paragraph = nil
+	}
+
+	for i := 0; i < len(lines); i++ {
+		line := lines[i]
+		trimmed := strings.TrimSpace(line)
+
+		switch {
+		case strings.HasPrefix(trimmed, "```"):
+			// Bloque de código: se copia escapado hasta la siguiente cerca
+			flushParagraph()
+			var code []string
+			for i++; i < len(lines) && !strings.HasPrefix(strings.TrimSpace(lines[i]), "```"); i++ {
+				code = append(code, lines[i])
+			}
+			b.WriteString("<pre><code>")
+			b.WriteString(html.EscapeString(strings.Join(code, "\n")))
+			b.WriteString("</code></pre>")
+
+		case unorderedItem.MatchString(line) || orderedItem.MatchString(line):
+			flushParagraph()
+			pattern, tag := unorderedItem, "ul"
+			if !unorderedItem.MatchString(line) {
+				pattern, tag = orderedItem, "ol"
+			}
+			b.WriteString("<" + tag + ">")
+			for ; i < len(lines) && pattern.MatchString(lines[i]); i++ {
+				item := pattern.FindStringSubmatch(lines[i])[1]
+				b.WriteString("<li>" + renderInline(item) + "</li>")
+			}
+			b.WriteString("</" + tag + ">")
+			i--
+
+		case trimmed == "":
+			flushParagraph()
+
+		default:
+			paragraph = append(paragraph, trimmed)
+		}
+	}
+	flushParagraph()
+
+	return b.String()
+}
+
+// renderInline procesa código, enlaces, negritas y cursivas dentro de una línea
+func renderInline(text string) string {
+	var b strings.Builder
+	runes := []rune(text)
+
+	for i := 0; i < len(runes); i++ {
+		r := runes[i]
+		switch {
+		case r == '\\' && i+1 < len(runes) && (unicode.IsPunct(runes[i+1]) || unicode.IsSymbol(runes[i+1])):
+			// Carácter escapado: se imprime literalmente
+			b.WriteString(html.EscapeString(string(runes[i+1])))
+			i++
+
+		case r == '`':
+			if end := indexRune(runes, '`', i+1); end > i+1 {
+				b.WriteString("<code>" + html.EscapeString(string(runes[i+1:end])) + "</code>")
+				i = end
+				continue
+			}
+			b.WriteString(html.EscapeString(string(r)))
+
+		case r == '[':
+			if label, target, end, ok := parseLink(runes, i); ok {
+				if href, safe := sanitizeURL(target); safe {
+					b.WriteString(`<a href="` + html.EscapeString(href) + `" rel="nofollow noopener noreferrer" target="_blank">`)
+					b.WriteString(renderInline(label))
+					b.WriteString("</a>")
+				} else {
+					// Enlace con esquema no permitido: se conserva solo el texto
+					b.WriteString(renderInline(label))
+				}
+				i = end
+				continue
+			}
+			b.WriteString(html.EscapeString(string(r)))
+
+		case r == '*' || r == '_':
+			if inner, end, strong, ok := parseEmphasis(runes, i); ok {
+				tag := "em"
+				if strong {
+					tag = "strong"
+				}
+				b.WriteString("<" + tag + ">" + renderInline(inner) + "</" + tag + ">")
+				i = end
+				continue
+			}
+			b.WriteString(html.EscapeString(string(r)))
+
+		default:
+			b.WriteString(html.EscapeString(string(r)))
+		}
+	}
+	return b.String()
+}
+
+// parseLink reconoce [texto](url) a partir de la posición start
+func parseLink(runes []rune, start int) (label, target string, end int, ok bool) {
+	closeLabel := indexRune(runes, ']', start+1)
+	if closeLabel < 0 || closeLabel+1 >= len(runes) || runes[closeLabel+1] != '(' {
+		return "", "", 0, false
+	}
+	closeTarget := indexRune(runes, ')', closeLabel+2)
+	if closeTarget < 0 {
+		return "", "", 0, false
+	}
+	label = string(runes[start+1 : closeLabel])
+	target = strings.TrimSpace(string(runes[closeLabel+2 : closeTarget]))
+	if label == "" || target == "" {
+		return "", "", 0, false
+	}
+	return label, target, closeTarget, true
+}
+
+// parseEmphasis reconoce **negrita**, __negrita__, *cursiva* y _cursiva_ a partir de start.
+// El guion bajo solo cuenta en límites de palabra para no romper nombres como snake_case.
+func parseEmphasis(runes []rune, start int) (inner string, end int, strong bool, ok bool) {
+	marker := runes[start]
+	width := 1
+	if start+1 < len(runes) && runes[start+1] == marker {
+		width = 2
+	}
+
+	if marker == '_' && start > 0 && isWordRune(runes[start-1]) {
+		return "", 0, false, false
+	}
+
+	contentStart := start + width
+	if contentStart >= len(runes) || unicode.IsSpace(runes[contentStart]) {
+		return "", 0, false, false
+	}
+
+	for i := contentStart + 1; i+width <= len(runes); i++ {
+		if runes[i] != marker {
+			continue
+		}
+		if width == 2 && (i+1 >= len(runes) || runes[i+1] != marker) {
+			continue
+		}
+		if unicode.IsSpace(runes[i-1]) {
+			continue
+		}
+		closeEnd := i + width - 1
+		if marker == '_' && closeEnd+1 < len(runes) && isWordRune(runes[closeEnd+1]) {
+			continue
+		}
+		return string(runes[contentStart:i]), closeEnd, width == 2, true
+	}
+	return "", 0, false, false
+}
+
+// sanitizeURL valida que el enlace sea absoluto, corto y con un esquema permitido
+func sanitizeURL(raw string) (string, bool) {
+	if len(raw) > maxURLLength || strings.ContainsAny(raw, " \t\n\"'<>`") {
+		return "", false
+	}
+	parsed, err := url.Parse(raw)
+	if err != nil || !allowedSchemes[strings.ToLower(parsed.Scheme)] {
+		return "", false
+	}
+	if !strings.EqualFold(parsed.Scheme, "mailto") && parsed.Host == "" {
+		return "", false
+	}
+	return parsed.String(), true
+}
+
+func indexRune(runes []rune, target rune, from int) int {
+	for i := from; i < len(runes); i++ {
+		if runes[i] == target {
+			return i
+		}
+	}
+	return -1
+}
+
+func isWordRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r)
+}
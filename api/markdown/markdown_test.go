@@ -0,0 +1,111 @@
+package markdown
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestRender(t *testing.T) {
+	tests := []struct {
+		name   string
+		source string
+		want   string
+	}{
+		{"texto plano", "hola", "<p>hola</p>"},
+		{"negrita y cursiva", "**fuerte** y *suave*", "<p><strong>fuerte</strong> y <em>suave</em></p>"},
+		{"guion bajo dentro de palabra", "snake_case_name", "<p>snake_case_name</p>"},
+		{"código en línea escapado", "`<b>`", "<p><code>&lt;b&gt;</code></p>"},
+		{"saltos de línea", "uno\r\ndos", "<p>uno<br>dos</p>"},
+		{"lista sin orden", "- uno\n- dos", "<ul><li>uno</li><li>dos</li></ul>"},
+		{"lista ordenada", "1. uno\n2) dos", "<ol><li>uno</li><li>dos</li></ol>"},
+		{"bloque de código", "```\n<script>x</script>\n```", "<pre><code>&lt;script&gt;x&lt;/script&gt;</code></pre>"},
+		{"enlace permitido", "[sitio](https://example.com/a?b=1&c=2)",
+			`<p><a href="https://example.com/a?b=1&amp;c=2" rel="nofollow noopener noreferrer" target="_blank">sitio</a></p>`},
+		{"carácter escapado", `\*literal\*`, "<p>*literal*</p>"},
+
+		{"etiqueta script", "<script>alert(1)</script>", "<p>&lt;script&gt;alert(1)&lt;/script&gt;</p>"},
+		{"atributo de evento", `<img src=x onerror="alert(1)">`, "<p>&lt;img src=x onerror=&#34;alert(1)&#34;&gt;</p>"},
+		{"enlace javascript", "[clic](javascript:alert(1))", "<p>clic)</p>"},
+		{"enlace javascript en mayúsculas", "[clic](JavaScript:alert`1`)", "<p>clic</p>"},
+		{"enlace data", "[clic](data:text/html;base64,PHNjcmlwdD4=)", "<p>clic</p>"},
+		{"etiqueta dentro del texto del enlace", "[<b>x</b>](https://example.com)",
+			`<p><a href="https://example.com" rel="nofollow noopener noreferrer" target="_blank">&lt;b&gt;x&lt;/b&gt;</a></p>`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Render(tt.source); got != tt.want {
+				t.Errorf("Render(%q) = %q, se esperaba %q", tt.source, got, tt.want)
+			}
+		})
+	}
+}
+
+// Ningún texto de entrada debe producir etiquetas ni atributos fuera de los que genera el paquete
+func TestRenderNoActiveContent(t *testing.T) {
+	inputs := []string{
+		"<script>alert(1)</script>",
+		"<a href=\"javascript:alert(1)\">x</a>",
+		"<div onclick=alert(1)>x</div>",
+		"[x](javascript:alert(1))",
+		"[x](vbscript:msgbox)",
+		"[x](https://example.com\" onmouseover=\"alert(1))",
+		"**<svg onload=alert(1)>**",
+		"- <iframe src=//evil.example>",
+	}
+	for _, input := range inputs {
+		got := strings.ToLower(Render(input))
+		for _, bad := range []string{"<script", "<div", "<svg", "<iframe", `href="javascript`, `href="vbscript`, `" onmouseover`} {
+			if strings.Contains(got, bad) {
+				t.Errorf("Render(%q) = %q contiene %q", input, got, bad)
+			}
+		}
+	}
+}
+
+func TestSanitizeURL(t *testing.T) {
+	tests := []struct {
+		raw    string
+		wantOK bool
+	}{
+		{"https://example.com", true},
+		{"http://example.com/ruta", true},
+		{"mailto:ana@example.com", true},
+		{"javascript:alert(1)", false},
+		{"JAVASCRIPT:alert(1)", false},
+		{"data:text/html,hola", false},
+		{"vbscript:msgbox", false},
+		{"/ruta/relativa", false},
+		{"//example.com", false},
+		{"https://", false},
+		{"https://example.com/\"onmouseover", false},
+		{"https://example.com/<script>", false},
+		{"https://example.com/" + strings.Repeat("a", maxURLLength), false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.raw, func(t *testing.T) {
+			if _, ok := sanitizeURL(tt.raw); ok != tt.wantOK {
+				t.Errorf("sanitizeURL(%q) ok = %v, se esperaba %v", tt.raw, ok, tt.wantOK)
+			}
+		})
+	}
+}
+
+func TestValidateLength(t *testing.T) {
+	tests := []struct {
+		name    string
+		source  string
+		max     int
+		wantErr bool
+	}{
+		{"dentro del límite", "hola", 4, false},
+		{"cuenta caracteres y no bytes", "ñandú", 5, false},
+		{"supera el límite", "hola!", 4, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := ValidateLength(tt.source, tt.max); (err != nil) != tt.wantErr {
+				t.Errorf("ValidateLength(%q, %d) = %v, se esperaba error: %v", tt.source, tt.max, err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -20,6 +20,25 @@ type ContextKey string
 
 const UserContextKey ContextKey = "user"
 
+// AccountStatusChecker indica si la cuenta de un token válido puede seguir usándose; reason explica
+// el rechazo (baneo o suspensión aplicados después de emitir el token)
+type AccountStatusChecker func(userID uint) (allowed bool, reason string, err error)
+
+var accountStatusChecker AccountStatusChecker
+
+// SetAccountStatusChecker configura la verificación del estado de la cuenta en cada petición autenticada
+func SetAccountStatusChecker(checker AccountStatusChecker) {
+	accountStatusChecker = checker
+}
+
+// checkAccountStatus aplica la verificación configurada; sin verificación toda cuenta está permitida
+func checkAccountStatus(userID uint) (bool, string, error) {
+	if accountStatusChecker == nil {
+		return true, "", nil
+	}
+	return accountStatusChecker(userID)
+}
+
 // AuthMiddleware middleware para validar tokens JWT
 func AuthMiddleware(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -82,6 +101,18 @@ func AuthMiddleware(next http.Handler) http.Handler {
 			return
 		}
 
+		// Rechazar cuentas baneadas o suspendidas después de emitir el token
+		allowed, reason, err := checkAccountStatus(uint(userID))
+		if err != nil {
+			log.Printf("Error verificando el estado de la cuenta %d: %v", uint(userID), err)
+			http.Error(w, "Error verificando la cuenta", http.StatusInternalServerError)
+			return
+		}
+		if !allowed {
+			http.Error(w, reason, http.StatusForbidden)
+			return
+		}
+
 		// Agregar información del usuario al contexto
 		userContext := AuthContext{
 			UserID: uint(userID),
@@ -132,7 +163,9 @@ func OptionalAuthMiddleware(next http.Handler) http.Handler {
 		if err == nil && token.Valid {
 			if claims, ok := token.Claims.(jwt.MapClaims); ok {
 				if userID, ok := claims["user_id"].(float64); ok {
-					if email, ok := claims["email"].(string); ok {
+					// Una cuenta baneada o suspendida se atiende como anónima
+					allowed, _, err := checkAccountStatus(uint(userID))
+					if email, ok := claims["email"].(string); ok && err == nil && allowed {
 						userContext := AuthContext{
 							UserID: uint(userID),
 							Email:  email,
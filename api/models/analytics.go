@@ -0,0 +1,57 @@
+package models
+
+import "time"
+
+// Criterios de orden de los reportes de oferta y demanda
+const (
+	OrdenEscasez = "shortage"
+	OrdenDemanda = "demand"
+	OrdenOferta  = "supply"
+	OrdenNombre  = "name"
+)
+
+// OrdenesAnalitica enumera los criterios de orden válidos
+var OrdenesAnalitica = []string{OrdenEscasez, OrdenDemanda, OrdenOferta, OrdenNombre}
+
+// OfertaDemanda resume cuántos usuarios ofrecen y buscan habilidades, agrupadas por habilidad,
+// ciudad o categoría según el reporte. IndiceEscasez es (buscan + 1) / (ofrecen + 1): mayor que 1
+// indica que hay más demanda que oferta, y no se indefine cuando nadie ofrece la habilidad.
+type OfertaDemanda struct {
+	HabilidadID   uint    `json:"skill_id,omitempty"`
+	Nombre        string  `json:"skill,omitempty"`
+	Ciudad        string  `json:"city,omitempty"`
+	Categoria     string  `json:"category"`
+	Ofrecen       int64   `json:"offered_by"`
+	Buscan        int64   `json:"sought_by"`
+	PostsOfrezco  int64   `json:"offer_posts"`
+	PostsBusco    int64   `json:"request_posts"`
+	IndiceEscasez float64 `json:"shortage_ratio"`
+}
+
+// TendenciaMensual cuenta lo que se creó en un mes (YYYY-MM): habilidades declaradas, posts y matches
+type TendenciaMensual struct {
+	Mes             string `json:"month"`
+	Ofrecen         int64  `json:"offered_by"`
+	Buscan          int64  `json:"sought_by"`
+	PostsOfrezco    int64  `json:"offer_posts"`
+	PostsBusco      int64  `json:"request_posts"`
+	Emparejamientos int64  `json:"matches"`
+}
+
+// ConversionHabilidad mide cuántos matches de una habilidad se concretaron: un match convertido
+// está activo o tiene al menos una sesión completada
+type ConversionHabilidad struct {
+	HabilidadID     uint    `json:"skill_id"`
+	Nombre          string  `json:"skill"`
+	Categoria       string  `json:"category"`
+	Emparejamientos int64   `json:"matches"`
+	Convertidos     int64   `json:"converted"`
+	Rechazados      int64   `json:"rejected"`
+	TasaConversion  float64 `json:"conversion_rate"`
+}
+
+// AnaliticaResponse envuelve las filas de un reporte con la fecha en que se calcularon los datos
+type AnaliticaResponse struct {
+	GeneradoEn time.Time   `json:"generated_at"`
+	Filas      interface{} `json:"rows"`
+}
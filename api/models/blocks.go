@@ -0,0 +1,24 @@
+package models
+
+import "time"
+
+// UserBlock representa el bloqueo de un usuario por parte de otro
+type UserBlock struct {
+	ID            uint      `json:"id" gorm:"primaryKey;column:BloqueoID"`
+	UsuarioID     uint      `json:"usuario_id" gorm:"column:UsuarioID"`     // Usuario que bloquea
+	BloqueadoID   uint      `json:"bloqueado_id" gorm:"column:BloqueadoID"` // Usuario bloqueado
+	FechaCreacion time.Time `json:"fecha_creacion" gorm:"column:FechaCreacion;autoCreateTime"`
+
+	// Relaciones
+	Bloqueado *User `json:"bloqueado,omitempty" gorm:"foreignKey:BloqueadoID"`
+}
+
+// TableName establece el nombre personalizado de la tabla
+func (UserBlock) TableName() string {
+	return "BloqueosUsuarios"
+}
+
+// BlockUserRequest representa la estructura para bloquear o desbloquear a un usuario
+type BlockUserRequest struct {
+	BloqueadoID uint `json:"bloqueado_id"`
+}
@@ -0,0 +1,48 @@
+package models
+
+import "time"
+
+// Tipos de elemento que se pueden guardar en marcadores
+const (
+	MarcadorPost    = "post"
+	MarcadorUsuario = "user"
+)
+
+// TiposMarcador enumera los tipos de elemento válidos
+var TiposMarcador = []string{MarcadorPost, MarcadorUsuario}
+
+// Marcador representa un post o un perfil que un usuario guardó para más tarde
+type Marcador struct {
+	MarcadorID    uint      `json:"marcador_id" gorm:"primaryKey;column:MarcadorID"`
+	UsuarioID     uint      `json:"usuario_id" gorm:"column:UsuarioID;not null"`
+	TipoElemento  string    `json:"tipo" gorm:"column:TipoElemento;size:10;not null"` // post, user
+	ElementoID    uint      `json:"elemento_id" gorm:"column:ElementoID;not null"`
+	FechaCreacion time.Time `json:"fecha_creacion" gorm:"column:FechaCreacion;autoCreateTime"`
+}
+
+// TableName establece el nombre personalizado de la tabla
+func (Marcador) TableName() string {
+	return "Marcadores"
+}
+
+// MarcadorRequest representa la estructura para guardar un elemento en marcadores
+type MarcadorRequest struct {
+	Tipo       string `json:"tipo"`
+	ElementoID uint   `json:"elemento_id"`
+}
+
+// MarcadorCompleto es un marcador con el post o el usuario guardado
+type MarcadorCompleto struct {
+	Marcador
+	Post    *PostFullInfo `json:"post,omitempty"`
+	Usuario *User         `json:"usuario,omitempty"`
+}
+
+// MarcadoresResponse representa una página de marcadores
+type MarcadoresResponse struct {
+	Marcadores      []MarcadorCompleto `json:"marcadores"`
+	TotalMarcadores int64              `json:"total_marcadores"`
+	Page            int                `json:"page"`
+	PageSize        int                `json:"page_size"`
+	TotalPages      int                `json:"total_pages"`
+}
@@ -11,9 +11,13 @@ type Comentario struct {
 	UsuarioID         int        `json:"usuario_id" gorm:"column:UsuarioID;not null"`
 	ComentarioPadreID *int       `json:"comentario_padre_id" gorm:"column:ComentarioPadreID"`
 	Contenido         string     `json:"contenido" gorm:"column:Contenido;type:nvarchar(max);not null"`
+	ContenidoHTML     string     `json:"contenido_html" gorm:"column:ContenidoHTML;type:nvarchar(max)"` // Markdown renderizado y saneado
 	CreatedAt         time.Time  `json:"created_at" gorm:"column:CreatedAt;default:GETDATE()"`
 	UpdatedAt         time.Time  `json:"updated_at" gorm:"column:UpdatedAt;default:GETDATE()"`
 	Activo            bool       `json:"activo" gorm:"column:Activo;default:true"`
+	Oculto            bool       `json:"oculto" gorm:"column:Oculto"` // Oculto por moderación
+	EditadoEn         *time.Time `json:"edited_at" gorm:"column:EditadoEn"`
+	TotalEdiciones    int        `json:"edit_count" gorm:"column:TotalEdiciones;default:0"`
 
 	// Relaciones
 	Post             *Post              `json:"post,omitempty" gorm:"foreignKey:PostID"`
@@ -45,6 +49,44 @@ func (ComentarioLike) TableName() string {
 	return "ComentarioLikes"
 }
 
+// Tipos de reacción a comentarios; los likes/dislikes se mantienen aparte para la puntuación
+const (
+	ReaccionUtil        = "helpful"
+	ReaccionInteresante = "insightful"
+	ReaccionGracias     = "thanks"
+)
+
+// TiposReaccionComentario lista los tipos de reacción permitidos en el orden en que se muestran
+var TiposReaccionComentario = []string{ReaccionUtil, ReaccionInteresante, ReaccionGracias}
+
+// ComentarioReaccion representa la reacción de un usuario a un comentario
+type ComentarioReaccion struct {
+	ReaccionID   int       `json:"reaccion_id" gorm:"primaryKey;column:ReaccionID;autoIncrement"`
+	ComentarioID int       `json:"comentario_id" gorm:"column:ComentarioID;not null"`
+	UsuarioID    int       `json:"usuario_id" gorm:"column:UsuarioID;not null"`
+	Tipo         string    `json:"tipo" gorm:"column:Tipo;type:nvarchar(20);not null;check:Tipo IN ('helpful','insightful','thanks')"`
+	CreatedAt    time.Time `json:"created_at" gorm:"column:CreatedAt;default:GETDATE()"`
+}
+
+// TableName especifica el nombre de la tabla
+func (ComentarioReaccion) TableName() string {
+	return "ComentarioReacciones"
+}
+
+// SeguimientoPost indica si un usuario sigue el hilo de comentarios de un post
+type SeguimientoPost struct {
+	SeguimientoID uint      `json:"seguimiento_id" gorm:"primaryKey;column:SeguimientoID;autoIncrement"`
+	UsuarioID     uint      `json:"usuario_id" gorm:"column:UsuarioID;not null"`
+	PostID        uint      `json:"post_id" gorm:"column:PostID;not null"`
+	Siguiendo     bool      `json:"siguiendo" gorm:"column:Siguiendo"` // Sin default en GORM: un false se guardaría como true
+	FechaCreacion time.Time `json:"fecha_creacion" gorm:"column:FechaCreacion;autoCreateTime"`
+}
+
+// TableName especifica el nombre de la tabla
+func (SeguimientoPost) TableName() string {
+	return "SeguimientosPost"
+}
+
 // ComentarioCompleto representa la vista completa de un comentario
 type ComentarioCompleto struct {
 	ComentarioID      int       `json:"comentario_id" gorm:"column:ComentarioID"`
@@ -52,15 +94,22 @@ type ComentarioCompleto struct {
 	UsuarioID         int       `json:"usuario_id" gorm:"column:UsuarioID"`
 	ComentarioPadreID *int      `json:"comentario_padre_id" gorm:"column:ComentarioPadreID"`
 	Contenido         string    `json:"contenido" gorm:"column:Contenido"`
+	ContenidoHTML     string    `json:"contenido_html" gorm:"column:ContenidoHTML"`
 	CreatedAt         time.Time `json:"created_at" gorm:"column:CreatedAt"`
 	UpdatedAt         time.Time `json:"updated_at" gorm:"column:UpdatedAt"`
 	Activo            bool      `json:"activo" gorm:"column:Activo"`
+	EditadoEn         *time.Time `json:"edited_at" gorm:"column:EditadoEn"`
+	TotalEdiciones    int       `json:"edit_count" gorm:"column:TotalEdiciones"`
 	NombreUsuario     string    `json:"nombre_usuario" gorm:"column:NombreUsuario"`
 	PrimerNombre      string    `json:"primer_nombre" gorm:"column:PrimerNombre"`
 	Apellido          string    `json:"apellido" gorm:"column:Apellido"`
 	TotalLikes        int       `json:"total_likes" gorm:"column:TotalLikes"`
 	TotalDislikes     int       `json:"total_dislikes" gorm:"column:TotalDislikes"`
 	TotalRespuestas   int       `json:"total_respuestas" gorm:"column:TotalRespuestas"`
+	Menciones         []MentionEntity `json:"menciones,omitempty" gorm:"-"`
+	Reacciones        map[string]int  `json:"reacciones" gorm:"-"`                // Total por tipo de reacción
+	MisReacciones     []string        `json:"mis_reacciones,omitempty" gorm:"-"` // Reacciones del usuario autenticado
+	EsRespuesta       bool            `json:"es_respuesta" gorm:"-"`             // Marcado como respuesta por el autor del post
 }
 
 // TableName especifica el nombre de la vista
@@ -68,6 +117,21 @@ func (ComentarioCompleto) TableName() string {
 	return "vw_ComentariosCompletos"
 }
 
+// RevisionComentario guarda el contenido que tenía un comentario antes de una edición
+type RevisionComentario struct {
+	RevisionID        uint      `json:"revision_id" gorm:"primaryKey;column:RevisionID"`
+	ComentarioID      int       `json:"comentario_id" gorm:"column:ComentarioID;not null"`
+	ContenidoAnterior string    `json:"contenido_anterior" gorm:"column:ContenidoAnterior;type:nvarchar(max);not null"`
+	EditorID          *uint     `json:"editor_id,omitempty" gorm:"column:EditorID"`
+	FechaEdicion      time.Time `json:"fecha_edicion" gorm:"column:FechaEdicion;autoCreateTime"`
+	NombreEditor      string    `json:"nombre_editor,omitempty" gorm:"->;column:NombreEditor"`
+}
+
+// TableName especifica el nombre de la tabla
+func (RevisionComentario) TableName() string {
+	return "RevisionesComentario"
+}
+
 // CreateComentarioRequest representa la estructura para crear un comentario
 type CreateComentarioRequest struct {
 	PostID            int    `json:"post_id,omitempty"` // Opcional, se extrae de la URL
@@ -85,6 +149,26 @@ type ComentarioLikeRequest struct {
 	TipoVoto string `json:"tipo_voto"`
 }
 
+// ReaccionComentarioRequest representa la estructura para agregar o quitar una reacción
+type ReaccionComentarioRequest struct {
+	Tipo string `json:"tipo"`
+}
+
+// ReaccionesComentarioResponse representa los totales de reacciones de un comentario tras alternar una
+type ReaccionesComentarioResponse struct {
+	ComentarioID  int            `json:"comentario_id"`
+	Tipo          string         `json:"tipo"`
+	Accion        string         `json:"accion"` // added o removed
+	Reacciones    map[string]int `json:"reacciones"`
+	MisReacciones []string       `json:"mis_reacciones"`
+}
+
+// RespuestaAceptadaResponse representa el comentario marcado como respuesta de un post
+type RespuestaAceptadaResponse struct {
+	PostID       uint `json:"post_id"`
+	ComentarioID *int `json:"comentario_id"` // nil cuando se quita la marca
+}
+
 // ComentariosResponse representa la respuesta paginada de comentarios
 type ComentariosResponse struct {
 	Comentarios     []ComentarioCompleto `json:"comentarios"`
@@ -103,6 +187,31 @@ type RespuestasResponse struct {
 	TotalPages       int                  `json:"total_pages"`
 }
 
+// ComentarioNodo representa un comentario dentro del árbol de un post con sus respuestas anidadas
+type ComentarioNodo struct {
+	ComentarioCompleto
+	Puntuacion    int              `json:"puntuacion"` // likes - dislikes
+	Respuestas    []ComentarioNodo `json:"respuestas"`
+	MasRespuestas *CargarMas       `json:"mas_respuestas,omitempty"`
+}
+
+// CargarMas indica una rama truncada y el cursor para continuar cargándola
+type CargarMas struct {
+	Restantes int    `json:"restantes"`
+	Cursor    string `json:"cursor"`
+}
+
+// ArbolComentariosResponse representa la respuesta del árbol de comentarios de un post
+type ArbolComentariosResponse struct {
+	PostID           int              `json:"post_id"`
+	Orden            string           `json:"orden"`
+	Profundidad      int              `json:"profundidad"`
+	RespuestaAceptada *ComentarioCompleto `json:"respuesta_aceptada,omitempty"` // Fijada arriba del hilo
+	Comentarios      []ComentarioNodo `json:"comentarios"`
+	TotalComentarios int              `json:"total_comentarios"`
+	MasComentarios   *CargarMas       `json:"mas_comentarios,omitempty"`
+}
+
 // ComentarioStats representa estadísticas de comentarios para un post
 type ComentarioStats struct {
 	PostID           int `json:"post_id"`
@@ -116,6 +225,7 @@ type EstadisticasComentario struct {
 	TotalLikes       int64 `json:"total_likes"`
 	TotalDislikes    int64 `json:"total_dislikes"`
 	TotalRespuestas  int64 `json:"total_respuestas"`
+	Reacciones       map[string]int `json:"reacciones"`
 }
 
 // Alias para compatibilidad con el handler
@@ -0,0 +1,27 @@
+package models
+
+import "time"
+
+// RespaldoHabilidad es el respaldo de un usuario a una habilidad que otro ofrece, después de
+// haber completado al menos una sesión juntos
+type RespaldoHabilidad struct {
+	RespaldoID         uint      `json:"id" gorm:"primaryKey;column:RespaldoID"`
+	UsuarioHabilidadID uint      `json:"user_ability_id" gorm:"column:UsuarioHabilidadID;not null"`
+	RespaldanteID      uint      `json:"endorser_id" gorm:"column:RespaldanteID;not null"`
+	EmparejamientoID   *uint     `json:"match_id,omitempty" gorm:"column:EmparejamientoID"`
+	Nota               string    `json:"note,omitempty" gorm:"column:Nota;size:280"`
+	FechaCreacion      time.Time `json:"created_at" gorm:"column:FechaCreacion;autoCreateTime"`
+
+	// Datos de solo lectura para los listados
+	NombreUsuario string `json:"endorser_username,omitempty" gorm:"->;column:NombreUsuario"`
+}
+
+// TableName establece el nombre personalizado de la tabla
+func (RespaldoHabilidad) TableName() string {
+	return "RespaldosHabilidad"
+}
+
+// RespaldoRequest representa la estructura para respaldar una habilidad
+type RespaldoRequest struct {
+	Nota string `json:"note"`
+}
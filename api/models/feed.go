@@ -0,0 +1,24 @@
+package models
+
+// Motivos por los que un post aparece en el feed personalizado
+const (
+	MotivoOfreceLoQueBuscas = "offers_skill_you_seek" // Post OFREZCO de una habilidad que el usuario Busca
+	MotivoBuscaLoQueOfreces = "seeks_skill_you_offer" // Post BUSCO de una habilidad que el usuario Ofrece
+	MotivoIntercambioMutuo  = "mutual_exchange"       // El autor además Busca algo que el usuario Ofrece
+	MotivoMismaCiudad       = "same_city"
+	MotivoAutorValorado     = "well_rated_author"
+)
+
+// PostFeedItem es un post del feed personalizado con su puntuación y los motivos que la explican
+type PostFeedItem struct {
+	PostFullInfo
+	Puntuacion        float64  `json:"score"`
+	Motivos           []string `json:"reasons"`
+	CalificacionAutor *float64 `json:"author_rating,omitempty"` // Promedio de reseñas del autor (1 a 5)
+}
+
+// FeedResponse representa una página del feed personalizado
+type FeedResponse struct {
+	Posts      []PostFeedItem `json:"posts"`
+	NextCursor string         `json:"next_cursor,omitempty"` // Vacío cuando no hay más posts
+}
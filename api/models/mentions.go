@@ -0,0 +1,28 @@
+package models
+
+import "time"
+
+// Mencion representa la mención de un usuario (@NombreUsuario) dentro de un comentario o mensaje
+type Mencion struct {
+	MencionID           uint      `json:"mencion_id" gorm:"primaryKey;column:MencionID;autoIncrement"`
+	TipoContenido       string    `json:"tipo_contenido" gorm:"column:TipoContenido;size:20;not null"` // comment, message
+	ContenidoID         uint      `json:"contenido_id" gorm:"column:ContenidoID;not null"`
+	UsuarioMencionadoID uint      `json:"usuario_mencionado_id" gorm:"column:UsuarioMencionadoID;not null"`
+	AutorID             uint      `json:"autor_id" gorm:"column:AutorID;not null"`
+	Inicio              int       `json:"inicio" gorm:"column:Inicio;not null"`     // Posición (en caracteres) de la @ dentro del texto
+	Longitud            int       `json:"longitud" gorm:"column:Longitud;not null"` // Longitud de la mención incluyendo la @
+	FechaCreacion       time.Time `json:"fecha_creacion" gorm:"column:FechaCreacion;autoCreateTime"`
+}
+
+// TableName especifica el nombre de la tabla
+func (Mencion) TableName() string {
+	return "Menciones"
+}
+
+// MentionEntity representa una mención resuelta dentro del texto de un comentario o mensaje
+type MentionEntity struct {
+	UserID        uint   `json:"user_id"`
+	NombreUsuario string `json:"nombre_usuario"`
+	Offset        int    `json:"offset"` // En caracteres (runas), no en bytes
+	Length        int    `json:"length"`
+}
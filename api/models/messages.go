@@ -12,6 +12,7 @@ type Message struct {
 	ConversationID uint           `json:"conversation_id" gorm:"not null;index;column:ConversationID"`
 	SenderID       uint           `json:"sender_id" gorm:"not null;index;column:SenderID"`
 	Content        string         `json:"content" gorm:"type:text;not null;column:Content"`
+	ContentHTML    string         `json:"content_html,omitempty" gorm:"type:nvarchar(max);column:ContentHTML"` // Markdown renderizado y saneado
 	MessageType    string         `json:"message_type" gorm:"default:'text';column:MessageType"` // text, image, file
 	IsRead         bool           `json:"is_read" gorm:"default:false;column:IsRead"`
 	ReadAt         *time.Time     `json:"read_at,omitempty" gorm:"column:ReadAt"`
@@ -58,6 +59,26 @@ type ConversationParticipant struct {
 	User             User           `json:"user,omitempty" gorm:"foreignKey:UserID"`
 }
 
+// MessageReceipt registra la entrega y lectura de un mensaje para cada destinatario
+type MessageReceipt struct {
+	ID          uint       `json:"id" gorm:"primarykey"`
+	MessageID   uint       `json:"message_id" gorm:"not null;index;column:MessageID"`
+	UserID      uint       `json:"user_id" gorm:"not null;index;column:UserID"`
+	DeliveredAt *time.Time `json:"delivered_at,omitempty" gorm:"column:DeliveredAt"`
+	ReadAt      *time.Time `json:"read_at,omitempty" gorm:"column:ReadAt"`
+	CreatedAt   time.Time  `json:"created_at" gorm:"column:CreatedAt"`
+	UpdatedAt   time.Time  `json:"updated_at" gorm:"column:UpdatedAt"`
+}
+
+// MessageReaction representa la reacción con un emoji de un usuario a un mensaje
+type MessageReaction struct {
+	ID        uint      `json:"id" gorm:"primarykey"`
+	MessageID uint      `json:"message_id" gorm:"not null;index;column:MessageID"`
+	UserID    uint      `json:"user_id" gorm:"not null;index;column:UserID"`
+	Emoji     string    `json:"emoji" gorm:"type:nvarchar(32);not null;column:Emoji"`
+	CreatedAt time.Time `json:"created_at" gorm:"column:CreatedAt"`
+}
+
 // TableName methods para especificar nombres de tabla en SQL Server
 func (Message) TableName() string {
 	return "Messages"
@@ -71,6 +92,14 @@ func (ConversationParticipant) TableName() string {
 	return "ConversationParticipants"
 }
 
+func (MessageReceipt) TableName() string {
+	return "MessageReceipts"
+}
+
+func (MessageReaction) TableName() string {
+	return "MessageReactions"
+}
+
 // Requests para API
 
 // CreateConversationRequest representa una solicitud para crear una nueva conversación
@@ -96,19 +125,42 @@ type UpdateMessageRequest struct {
 	IsRead  *bool   `json:"is_read,omitempty"`
 }
 
+// ToggleReactionRequest representa una solicitud para agregar o quitar una reacción
+type ToggleReactionRequest struct {
+	Emoji string `json:"emoji" binding:"required"`
+}
+
 // Responses para API
 
 // MessageResponse representa la respuesta de un mensaje individual
 type MessageResponse struct {
-	ID             uint       `json:"id"`
-	ConversationID uint       `json:"conversation_id"`
-	SenderID       uint       `json:"sender_id"`
-	Content        string     `json:"content"`
-	MessageType    string     `json:"message_type"`
-	IsRead         bool       `json:"is_read"`
-	ReadAt         *time.Time `json:"read_at,omitempty"`
-	CreatedAt      time.Time  `json:"created_at"`
-	UpdatedAt      time.Time  `json:"updated_at"`
+	ID             uint                     `json:"id"`
+	ConversationID uint                     `json:"conversation_id"`
+	SenderID       uint                     `json:"sender_id"`
+	Content        string                   `json:"content"`
+	ContentHTML    string                   `json:"content_html,omitempty"`
+	MessageType    string                   `json:"message_type"`
+	IsRead         bool                     `json:"is_read"`
+	ReadAt         *time.Time               `json:"read_at,omitempty"`
+	Receipts       []MessageReceiptResponse `json:"receipts,omitempty"`
+	Reactions      []MessageReactionSummary `json:"reactions,omitempty"`
+	Mentions       []MentionEntity          `json:"mentions,omitempty"`
+	CreatedAt      time.Time                `json:"created_at"`
+	UpdatedAt      time.Time                `json:"updated_at"`
+}
+
+// MessageReceiptResponse representa el estado de entrega/lectura de un mensaje para un destinatario
+type MessageReceiptResponse struct {
+	UserID      uint       `json:"user_id"`
+	DeliveredAt *time.Time `json:"delivered_at,omitempty"`
+	ReadAt      *time.Time `json:"read_at,omitempty"`
+}
+
+// MessageReactionSummary representa el total de reacciones de un emoji en un mensaje
+type MessageReactionSummary struct {
+	Emoji       string `json:"emoji" gorm:"column:Emoji"`
+	Count       int    `json:"count" gorm:"column:Count"`
+	ReactedByMe bool   `json:"reacted_by_me" gorm:"column:ReactedByMe"`
 }
 
 // ConversationResponse representa la respuesta de una conversación individual
@@ -155,3 +207,33 @@ type ConversationsListResponse struct {
 	PageSize      int                           `json:"page_size"`
 	TotalPages    int                           `json:"total_pages"`
 }
+
+// ExportedMessage representa un mensaje dentro de una exportación de conversación
+type ExportedMessage struct {
+	ID          uint                `json:"id"`
+	SenderID    uint                `json:"sender_id"`
+	SenderName  string              `json:"sender_name"`
+	Content     string              `json:"content,omitempty"`
+	MessageType string              `json:"message_type"`
+	Attachment  *ExportedAttachment `json:"attachment,omitempty"`
+	SentAt      string              `json:"sent_at"` // RFC 3339 en la zona horaria solicitada
+}
+
+// ExportedAttachment referencia el archivo adjunto de un mensaje de tipo image o file
+type ExportedAttachment struct {
+	Type string `json:"type"`
+	URL  string `json:"url"`
+}
+
+// ConversationExportJobResponse representa el estado de una exportación en segundo plano
+type ConversationExportJobResponse struct {
+	ID             string     `json:"id"`
+	ConversationID uint       `json:"conversation_id"`
+	Format         string     `json:"format"`
+	Status         string     `json:"status"` // pending, running, completed, failed
+	Error          string     `json:"error,omitempty"`
+	DownloadURL    string     `json:"download_url,omitempty"`
+	CreatedAt      time.Time  `json:"created_at"`
+	CompletedAt    *time.Time `json:"completed_at,omitempty"`
+	ExpiresAt      *time.Time `json:"expires_at,omitempty"`
+}
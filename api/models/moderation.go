@@ -0,0 +1,85 @@
+package models
+
+import "time"
+
+// Reporte representa la denuncia de un usuario sobre un post, comentario, mensaje o usuario
+type Reporte struct {
+	ReporteID          uint       `json:"reporte_id" gorm:"primaryKey;column:ReporteID"`
+	TipoContenido      string     `json:"tipo_contenido" gorm:"column:TipoContenido;size:20;not null"` // post, comment, message, user
+	ContenidoID        uint       `json:"contenido_id" gorm:"column:ContenidoID;not null"`
+	UsuarioReportadoID *uint      `json:"usuario_reportado_id,omitempty" gorm:"column:UsuarioReportadoID"`
+	ReportanteID       *uint      `json:"reportante_id,omitempty" gorm:"column:ReportanteID"` // nil en reportes del filtro automático
+	Origen             string     `json:"origen" gorm:"column:Origen;size:20;default:'user'"`  // user, filter
+	Motivo             string     `json:"motivo" gorm:"column:Motivo;size:30;not null"`
+	Descripcion        string     `json:"descripcion,omitempty" gorm:"column:Descripcion;size:1000"`
+	Estado             string     `json:"estado" gorm:"column:Estado;size:20;default:'pending'"` // pending, resolved, dismissed
+	Accion             *string    `json:"accion,omitempty" gorm:"column:Accion;size:20"`
+	NotaResolucion     *string    `json:"nota_resolucion,omitempty" gorm:"column:NotaResolucion;size:1000"`
+	ModeradorID        *uint      `json:"moderador_id,omitempty" gorm:"column:ModeradorID"`
+	FechaCreacion      time.Time  `json:"fecha_creacion" gorm:"column:FechaCreacion;autoCreateTime"`
+	FechaResolucion    *time.Time `json:"fecha_resolucion,omitempty" gorm:"column:FechaResolucion"`
+}
+
+// TableName establece el nombre personalizado de la tabla
+func (Reporte) TableName() string {
+	return "Reportes"
+}
+
+// AccionModeracion es una entrada del registro de auditoría de moderación
+type AccionModeracion struct {
+	AccionModeracionID uint      `json:"accion_moderacion_id" gorm:"primaryKey;column:AccionModeracionID"`
+	ModeradorID        *uint     `json:"moderador_id,omitempty" gorm:"column:ModeradorID"` // nil en acciones automáticas
+	Accion             string    `json:"accion" gorm:"column:Accion;size:20;not null"`
+	TipoContenido      string    `json:"tipo_contenido" gorm:"column:TipoContenido;size:20;not null"`
+	ContenidoID        uint      `json:"contenido_id" gorm:"column:ContenidoID;not null"`
+	UsuarioAfectadoID  *uint     `json:"usuario_afectado_id,omitempty" gorm:"column:UsuarioAfectadoID"`
+	ReporteID          *uint     `json:"reporte_id,omitempty" gorm:"column:ReporteID"`
+	Nota               string    `json:"nota,omitempty" gorm:"column:Nota;size:1000"`
+	FechaCreacion      time.Time `json:"fecha_creacion" gorm:"column:FechaCreacion;autoCreateTime"`
+}
+
+// TableName establece el nombre personalizado de la tabla
+func (AccionModeracion) TableName() string {
+	return "AccionesModeracion"
+}
+
+// CrearReporteRequest representa la estructura para reportar contenido
+type CrearReporteRequest struct {
+	TipoContenido string `json:"tipo_contenido"`
+	ContenidoID   uint   `json:"contenido_id"`
+	Motivo        string `json:"motivo"`
+	Descripcion   string `json:"descripcion"`
+}
+
+// AccionModeracionRequest representa la acción de un moderador sobre un reporte
+type AccionModeracionRequest struct {
+	Accion string `json:"accion"` // dismiss, hide, warn, suspend, ban
+	Nota   string `json:"nota"`
+	Dias   int    `json:"dias"` // Duración de la suspensión
+}
+
+// ReporteCola es un reporte de la cola de moderación con el resumen del contenido reportado
+type ReporteCola struct {
+	Reporte
+	NombreReportante       string `json:"nombre_reportante" gorm:"column:NombreReportante"`
+	TotalReportesContenido int    `json:"total_reportes_contenido" gorm:"column:TotalReportesContenido"`
+	VistaPrevia            string `json:"vista_previa" gorm:"column:VistaPrevia"`
+}
+
+// ColaModeracionResponse representa una página de la cola de moderación
+type ColaModeracionResponse struct {
+	Reportes   []ReporteCola `json:"reportes"`
+	Total      int64         `json:"total"`
+	Page       int           `json:"page"`
+	PageSize   int           `json:"page_size"`
+	TotalPages int           `json:"total_pages"`
+}
+
+// ContenidoRetenidoResponse se devuelve cuando el filtro automático envía el contenido a moderación
+type ContenidoRetenidoResponse struct {
+	Mensaje       string   `json:"mensaje"`
+	TipoContenido string   `json:"tipo_contenido"`
+	ContenidoID   uint     `json:"contenido_id"`
+	Estado        string   `json:"estado"`
+	Motivos       []string `json:"motivos"`
+}
@@ -19,3 +19,36 @@ type Notification struct {
 func (Notification) TableName() string {
 	return "Notificaciones"
 }
+
+// PreferenciaNotificacion indica si un usuario recibe un tipo de notificación.
+// Si no existe la fila, el tipo se considera habilitado.
+type PreferenciaNotificacion struct {
+	PreferenciaID      uint      `json:"preferencia_id" gorm:"primaryKey;column:PreferenciaID"`
+	UsuarioID          uint      `json:"usuario_id" gorm:"column:UsuarioID;not null"`
+	Tipo               string    `json:"tipo" gorm:"column:Tipo;size:50;not null"`
+	Habilitada         bool      `json:"habilitada" gorm:"column:Habilitada"` // Sin default en GORM: un false se guardaría como true (la columna tiene DEFAULT 1)
+	FechaActualizacion time.Time `json:"fecha_actualizacion" gorm:"column:FechaActualizacion;autoUpdateTime"`
+}
+
+// TableName establece el nombre personalizado de la tabla
+func (PreferenciaNotificacion) TableName() string {
+	return "PreferenciasNotificacion"
+}
+
+// UpdateNotificationPreferencesRequest representa los tipos de notificación a habilitar o deshabilitar
+type UpdateNotificationPreferencesRequest struct {
+	Preferencias map[string]bool `json:"preferencias"`
+}
+
+// NotificacionActor registra a cada usuario que originó una notificación agrupada
+type NotificacionActor struct {
+	NotificacionActorID uint      `json:"notificacion_actor_id" gorm:"primaryKey;column:NotificacionActorID"`
+	NotificacionID      uint      `json:"notificacion_id" gorm:"column:NotificacionID;not null"`
+	UsuarioID           uint      `json:"usuario_id" gorm:"column:UsuarioID;not null"`
+	FechaCreacion       time.Time `json:"fecha_creacion" gorm:"column:FechaCreacion;autoCreateTime"`
+}
+
+// TableName establece el nombre personalizado de la tabla
+func (NotificacionActor) TableName() string {
+	return "NotificacionActores"
+}
@@ -0,0 +1,46 @@
+package models
+
+import "time"
+
+// Estados de una respuesta a un post
+const (
+	RespuestaPendiente = "pending"
+	RespuestaAceptada  = "accepted"
+	RespuestaRechazada = "declined"
+)
+
+// RespuestaPost representa la respuesta de un usuario a un post: ofrece una de sus habilidades
+// a cambio y genera un emparejamiento pendiente con el autor del post
+type RespuestaPost struct {
+	RespuestaID         uint       `json:"respuesta_id" gorm:"primaryKey;column:RespuestaID"`
+	PostID              uint       `json:"post_id" gorm:"column:PostID;not null"`
+	UsuarioID           uint       `json:"usuario_id" gorm:"column:UsuarioID;not null"`
+	HabilidadOfrecidaID uint       `json:"habilidad_ofrecida_id" gorm:"column:HabilidadOfrecidaID;not null"`
+	EmparejamientoID    uint       `json:"match_id" gorm:"column:EmparejamientoID;not null"`
+	Mensaje             string     `json:"mensaje,omitempty" gorm:"column:Mensaje;size:1000"`
+	Estado              string     `json:"estado" gorm:"column:Estado;size:20;default:'pending'"` // pending, accepted, declined
+	FechaCreacion       time.Time  `json:"fecha_creacion" gorm:"column:FechaCreacion;autoCreateTime"`
+	FechaRespuesta      *time.Time `json:"fecha_respuesta,omitempty" gorm:"column:FechaRespuesta"`
+
+	// Datos de solo lectura para los listados
+	NombreUsuario   string `json:"nombre_usuario,omitempty" gorm:"->;column:NombreUsuario"`
+	NombreHabilidad string `json:"nombre_habilidad_ofrecida,omitempty" gorm:"->;column:NombreHabilidad"`
+}
+
+// TableName establece el nombre personalizado de la tabla
+func (RespuestaPost) TableName() string {
+	return "RespuestasPost"
+}
+
+// CrearRespuestaPostRequest representa la estructura para responder a un post
+type CrearRespuestaPostRequest struct {
+	HabilidadID uint   `json:"habilidad_id"` // Habilidad que quien responde ofrece a cambio (TipoHabilidad Ofrece)
+	Mensaje     string `json:"mensaje"`
+}
+
+// RespuestasPostResponse representa el listado de respuestas de un post
+type RespuestasPostResponse struct {
+	PostID     uint            `json:"post_id"`
+	Respuestas []RespuestaPost `json:"respuestas"`
+	Total      int             `json:"total"`
+}
@@ -8,6 +8,15 @@ type Post struct {
 	TipoPost	string    `json:"tipo_post" gorm:"column:TipoPost"`
 	HabilidadID uint      `json:"habilidad_id" gorm:"column:HabilidadID"`
 	Descripcion string    `json:"descripcion" gorm:"column:Descripcion"`
+	DescripcionHTML string `json:"descripcion_html" gorm:"column:DescripcionHTML"` // Markdown renderizado y saneado
+	Oculto      bool      `json:"oculto" gorm:"column:Oculto"` // Oculto por moderación
+	EditadoEn   *time.Time `json:"edited_at" gorm:"column:EditadoEn"`
+	TotalEdiciones int    `json:"edit_count" gorm:"column:TotalEdiciones"`
+	RespuestaComentarioID *int `json:"respuesta_comentario_id" gorm:"column:RespuestaComentarioID"` // Comentario marcado como respuesta
+	Estado      string    `json:"status" gorm:"column:Estado;default:open"` // open, paused, fulfilled o expired
+	ExpiresAt   *time.Time `json:"expires_at" gorm:"column:ExpiresAt"`      // nil = no expira
+	EmparejamientoID *uint `json:"match_id" gorm:"column:EmparejamientoID"` // Emparejamiento que cumplió el post
+	CumplidoEn  *time.Time `json:"fulfilled_at" gorm:"column:CumplidoEn"`
 	CreatedAt   time.Time `json:"created_at" gorm:"column:CreatedAt;autoCreateTime"`
 	UpdatedAt   time.Time `json:"updated_at" gorm:"column:UpdatedAt;autoUpdateTime"` // Mapeado a FechaActualizacion
 }
@@ -16,17 +25,40 @@ func (Post) TableName() string {
 	return "Posts"
 }
 
+// Estados del ciclo de vida de un post
+const (
+	PostAbierto  = "open"
+	PostPausado  = "paused"
+	PostCumplido = "fulfilled"
+	PostExpirado = "expired"
+)
+
+// EstadosPost lista los estados válidos de un post
+var EstadosPost = []string{PostAbierto, PostPausado, PostCumplido, PostExpirado}
+
 type CreatePostRequest struct {
 	UsuarioID   uint   `json:"usuario_id" binding:"required"`
 	TipoPost	string `json:"tipo_post" binding:"required"`
 	HabilidadID uint   `json:"habilidad_id" binding:"required"`
 	Descripcion string `json:"descripcion" binding:"required"`
+	ExpiresAt   *time.Time `json:"expires_at,omitempty"` // Opcional; por defecto POST_DEFAULT_EXPIRY_DAYS
 }
 type UpdatePostRequest struct {
 	UsuarioID   *uint   `json:"usuario_id,omitempty"`
 	HabilidadID *uint   `json:"habilidad_id,omitempty"`
 	Descripcion *string `json:"descripcion,omitempty"`
 }
+
+// CambiarEstadoPostRequest abre o pausa un post; al reabrirlo se puede indicar una nueva expiración
+type CambiarEstadoPostRequest struct {
+	Estado    string     `json:"status"`
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
+}
+
+// CumplirPostRequest marca un post como cumplido por un emparejamiento
+type CumplirPostRequest struct {
+	MatchID uint `json:"match_id"`
+}
 // PostFullInfo representa los datos de la vista vw_PostFullInfo.
 type PostFullInfo struct {
     PostID          uint      `json:"post_id" gorm:"column:PostID;primaryKey"` // Es buena idea tener una primaryKey para GORM, incluso en vistas
@@ -35,9 +67,52 @@ type PostFullInfo struct {
     NombreHabilidad string    `json:"nombre_habilidad" gorm:"column:NombreHabilidad"`
     TipoPost        string    `json:"tipo_post" gorm:"column:TipoPost"` // Asegúrate que este campo exista en tu vista
     Descripcion     string    `json:"descripcion" gorm:"column:Descripcion"`
+    DescripcionHTML string    `json:"descripcion_html" gorm:"-"` // Se completa desde Posts.DescripcionHTML
+    EditadoEn       *time.Time `json:"edited_at" gorm:"-"`        // Se completa desde Posts.EditadoEn
+    TotalEdiciones  int       `json:"edit_count" gorm:"-"`       // Se completa desde Posts.TotalEdiciones
+    RespuestaComentarioID *int `json:"respuesta_comentario_id" gorm:"-"` // Se completa desde Posts.RespuestaComentarioID
+    Estado          string    `json:"status" gorm:"-"`            // Se completa desde Posts.Estado
+    ExpiresAt       *time.Time `json:"expires_at" gorm:"-"`       // Se completa desde Posts.ExpiresAt
+    EmparejamientoID *uint    `json:"match_id" gorm:"-"`          // Se completa desde Posts.EmparejamientoID
+    CumplidoEn      *time.Time `json:"fulfilled_at" gorm:"-"`     // Se completa desde Posts.CumplidoEn
+    Guardado        bool      `json:"bookmarked" gorm:"-"`         // El usuario autenticado guardó el post
+    TotalGuardados  *int64    `json:"bookmark_count,omitempty" gorm:"-"` // Solo visible para el autor
     CreatedAt       time.Time `json:"created_at" gorm:"column:CreatedAt"` // Asegúrate que el nombre de columna coincida
     UpdatedAt       time.Time `json:"updated_at" gorm:"column:UpdatedAt"` // Asegúrate que el nombre de columna coincida
 }
 func (PostFullInfo) TableName() string {
 	return "vw_PostFullInfo"
 }
+
+// FacetasPosts cuenta los posts de un listado filtrado por categoría de la habilidad y por tipo de post
+type FacetasPosts struct {
+	Categorias map[string]int64 `json:"categorias"`
+	Tipos      map[string]int64 `json:"tipos"`
+}
+
+// RevisionPost guarda la descripción y la habilidad que tenía un post antes de una edición
+type RevisionPost struct {
+	RevisionID          uint      `json:"revision_id" gorm:"primaryKey;column:RevisionID"`
+	PostID              uint      `json:"post_id" gorm:"column:PostID;not null"`
+	DescripcionAnterior string    `json:"descripcion_anterior" gorm:"column:DescripcionAnterior;type:nvarchar(max);not null"`
+	HabilidadIDAnterior *uint     `json:"habilidad_id_anterior,omitempty" gorm:"column:HabilidadIDAnterior"`
+	EditorID            *uint     `json:"editor_id,omitempty" gorm:"column:EditorID"`
+	FechaEdicion        time.Time `json:"fecha_edicion" gorm:"column:FechaEdicion;autoCreateTime"`
+	NombreEditor        string    `json:"nombre_editor,omitempty" gorm:"->;column:NombreEditor"`
+}
+
+func (RevisionPost) TableName() string {
+	return "RevisionesPost"
+}
+
+// PostEliminado registra un post eliminado para que el feed Atom refleje su salida en Last-Modified
+type PostEliminado struct {
+	PostID           uint      `gorm:"primaryKey;column:PostID;autoIncrement:false"`
+	HabilidadID      uint      `gorm:"column:HabilidadID"`
+	TipoPost         string    `gorm:"column:TipoPost"`
+	FechaEliminacion time.Time `gorm:"column:FechaEliminacion;autoCreateTime"`
+}
+
+func (PostEliminado) TableName() string {
+	return "PostsEliminados"
+}
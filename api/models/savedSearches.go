@@ -0,0 +1,57 @@
+package models
+
+import "time"
+
+// Frecuencias de aviso de una búsqueda guardada
+const (
+	FrecuenciaInstantanea = "instant" // Notificación al publicarse el post (con límite por hora)
+	FrecuenciaDiaria      = "daily"   // Un resumen diario con los posts nuevos
+)
+
+// BusquedaGuardada representa los criterios de búsqueda de posts que un usuario quiere seguir.
+// Los criterios vacíos no filtran.
+type BusquedaGuardada struct {
+	BusquedaID    uint       `json:"busqueda_id" gorm:"primaryKey;column:BusquedaID"`
+	UsuarioID     uint       `json:"usuario_id" gorm:"column:UsuarioID;not null"`
+	Nombre        string     `json:"nombre" gorm:"column:Nombre;size:100;not null"`
+	HabilidadID   *uint      `json:"habilidad_id,omitempty" gorm:"column:HabilidadID"`
+	Categoria     string     `json:"categoria,omitempty" gorm:"column:Categoria;size:50"`
+	TipoPost      string     `json:"tipo_post,omitempty" gorm:"column:TipoPost;size:20"`
+	Ciudad        string     `json:"ciudad,omitempty" gorm:"column:Ciudad;size:100"`
+	PalabrasClave string     `json:"palabras_clave,omitempty" gorm:"column:PalabrasClave;size:200"`
+	Frecuencia    string     `json:"frecuencia" gorm:"column:Frecuencia;size:20;default:'instant'"` // instant, daily
+	Activa        bool       `json:"activa" gorm:"column:Activa"`                                   // Sin default en GORM: un false se guardaría como true
+	FechaCreacion time.Time  `json:"fecha_creacion" gorm:"column:FechaCreacion;autoCreateTime"`
+	UltimoResumen *time.Time `json:"ultimo_resumen,omitempty" gorm:"column:UltimoResumen"`
+}
+
+// TableName establece el nombre personalizado de la tabla
+func (BusquedaGuardada) TableName() string {
+	return "BusquedasGuardadas"
+}
+
+// CoincidenciaBusqueda registra un post que coincidió con una búsqueda guardada
+type CoincidenciaBusqueda struct {
+	CoincidenciaID    uint       `json:"coincidencia_id" gorm:"primaryKey;column:CoincidenciaID"`
+	BusquedaID        uint       `json:"busqueda_id" gorm:"column:BusquedaID;not null"`
+	PostID            uint       `json:"post_id" gorm:"column:PostID;not null"`
+	FechaCreacion     time.Time  `json:"fecha_creacion" gorm:"column:FechaCreacion;autoCreateTime"`
+	FechaNotificacion *time.Time `json:"fecha_notificacion,omitempty" gorm:"column:FechaNotificacion"` // nil = pendiente de resumen
+}
+
+// TableName establece el nombre personalizado de la tabla
+func (CoincidenciaBusqueda) TableName() string {
+	return "CoincidenciasBusqueda"
+}
+
+// BusquedaGuardadaRequest representa la estructura para crear o actualizar una búsqueda guardada
+type BusquedaGuardadaRequest struct {
+	Nombre        *string `json:"nombre"`
+	HabilidadID   *uint   `json:"habilidad_id"`
+	Categoria     *string `json:"categoria"`
+	TipoPost      *string `json:"tipo_post"`
+	Ciudad        *string `json:"ciudad"`
+	PalabrasClave *string `json:"palabras_clave"`
+	Frecuencia    *string `json:"frecuencia"`
+	Activa        *bool   `json:"activa"`
+}
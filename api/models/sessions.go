@@ -2,6 +2,16 @@ package models
 
 import "time"
 
+// Estados de un emparejamiento (EstadoEmparejamiento)
+const (
+	EmparejamientoPendiente = "Pendiente"
+	EmparejamientoActivo    = "Activo"
+	EmparejamientoRechazado = "Rechazado"
+)
+
+// Estados de sesión que cuentan como sesión completada
+var EstadosSesionCompletada = []string{"completed", "Completada"}
+
 // Session representa una sesión programada entre dos usuarios
 type Session struct {
 	ID        uint      `json:"id" gorm:"primaryKey;column:SesionID"`
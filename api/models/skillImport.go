@@ -0,0 +1,59 @@
+package models
+
+// Acciones del diff de una importación
+const (
+	ImportarCrear      = "create"
+	ImportarActualizar = "update"
+	ImportarSinCambios = "unchanged"
+	ImportarOmitir     = "skip" // Fila repetida dentro del mismo archivo
+)
+
+// HabilidadImportada es una fila del catálogo de habilidades en la importación y la exportación.
+// En CSV las columnas son name, category, description y aliases (separados por |).
+type HabilidadImportada struct {
+	Nombre      string   `json:"name"`
+	Categoria   string   `json:"category"`
+	Descripcion string   `json:"description"`
+	Aliases     []string `json:"aliases"`
+}
+
+// HabilidadUsuarioImportada es una fila de las habilidades de un usuario en la importación y la
+// exportación. Skill acepta el nombre de la habilidad o uno de sus alias. En CSV las columnas son
+// skill, skill_type y proficiency_level.
+type HabilidadUsuarioImportada struct {
+	Habilidad string `json:"skill"`
+	Tipo      string `json:"skill_type"` // Ofrece o Busca
+	Nivel     string `json:"proficiency_level"`
+}
+
+// CambioCampo es el valor anterior y el nuevo de un campo modificado
+type CambioCampo struct {
+	Antes   string `json:"from"`
+	Despues string `json:"to"`
+}
+
+// CambioImportacion describe lo que la importación hace (o haría, en modo de prueba) con una fila
+type CambioImportacion struct {
+	Fila        int                    `json:"row"`
+	Accion      string                 `json:"action"` // create, update, unchanged, skip
+	Nombre      string                 `json:"name"`
+	HabilidadID uint                   `json:"skill_id,omitempty"`
+	Campos      map[string]CambioCampo `json:"changes,omitempty"`
+	AliasNuevos []string               `json:"new_aliases,omitempty"`
+	Detalle     string                 `json:"detail,omitempty"`
+}
+
+// ErrorImportacion es un problema de validación en una fila; con errores no se aplica ningún cambio
+type ErrorImportacion struct {
+	Fila    int    `json:"row"`
+	Mensaje string `json:"message"`
+}
+
+// ResultadoImportacion es la respuesta de una importación: el diff fila por fila y si se aplicó
+type ResultadoImportacion struct {
+	DryRun   bool                `json:"dry_run"`
+	Aplicado bool                `json:"applied"`
+	Resumen  map[string]int      `json:"summary"` // Cantidad de filas por acción
+	Cambios  []CambioImportacion `json:"changes"`
+	Errores  []ErrorImportacion  `json:"errors,omitempty"`
+}
@@ -0,0 +1,155 @@
+package models
+
+import "time"
+
+// Estados de una habilidad propuesta por un usuario
+const (
+	PropuestaPendiente = "pending"
+	PropuestaAprobada  = "approved"
+	PropuestaRechazada = "rejected"
+)
+
+// EstadosPropuesta enumera los estados válidos de una propuesta
+var EstadosPropuesta = []string{PropuestaPendiente, PropuestaAprobada, PropuestaRechazada}
+
+// CategoriaHabilidad es un nodo del árbol de categorías de habilidades
+type CategoriaHabilidad struct {
+	CategoriaID   uint      `json:"id" gorm:"primaryKey;column:CategoriaID"`
+	Nombre        string    `json:"nombre" gorm:"column:Nombre;size:100;not null"`
+	PadreID       *uint     `json:"padre_id" gorm:"column:PadreID"` // nil = categoría raíz
+	Descripcion   string    `json:"descripcion,omitempty" gorm:"column:Descripcion;size:500"`
+	FechaCreacion time.Time `json:"fecha_creacion" gorm:"column:FechaCreacion;autoCreateTime"`
+
+	// Datos calculados para el árbol
+	TotalHabilidades int64                `json:"total_habilidades" gorm:"-"`
+	Subcategorias    []CategoriaHabilidad `json:"subcategorias" gorm:"-"`
+}
+
+// TableName establece el nombre personalizado de la tabla
+func (CategoriaHabilidad) TableName() string {
+	return "CategoriasHabilidad"
+}
+
+// AliasHabilidad es un sinónimo que se resuelve a una habilidad canónica
+type AliasHabilidad struct {
+	AliasID          uint      `json:"id" gorm:"primaryKey;column:AliasID"`
+	HabilidadID      uint      `json:"habilidad_id" gorm:"column:HabilidadID;not null"`
+	Alias            string    `json:"alias" gorm:"column:Alias;size:100;not null"`
+	AliasNormalizado string    `json:"-" gorm:"column:AliasNormalizado;size:100;not null"`
+	FechaCreacion    time.Time `json:"fecha_creacion" gorm:"column:FechaCreacion;autoCreateTime"`
+}
+
+// TableName establece el nombre personalizado de la tabla
+func (AliasHabilidad) TableName() string {
+	return "AliasesHabilidad"
+}
+
+// HabilidadTaxonomia muestra una habilidad con su ubicación en el árbol de categorías y sus alias
+type HabilidadTaxonomia struct {
+	HabilidadID uint             `json:"id" gorm:"primaryKey;column:HabilidadID"`
+	Nombre      string           `json:"name" gorm:"column:NombreHabilidad"`
+	Categoria   string           `json:"category" gorm:"column:Categoria"`
+	CategoriaID *uint            `json:"category_id" gorm:"column:CategoriaID"`
+	Descripcion string           `json:"description" gorm:"column:Descripcion"`
+	Ruta        []string         `json:"category_path" gorm:"-"` // Categorías desde la raíz
+	Aliases     []AliasHabilidad `json:"aliases" gorm:"foreignKey:HabilidadID;references:HabilidadID"`
+}
+
+// TableName establece el nombre personalizado de la tabla
+func (HabilidadTaxonomia) TableName() string {
+	return "Habilidades"
+}
+
+// PropuestaHabilidad es una habilidad sugerida por un usuario que espera la revisión de un administrador
+type PropuestaHabilidad struct {
+	PropuestaID       uint       `json:"id" gorm:"primaryKey;column:PropuestaID"`
+	UsuarioID         uint       `json:"usuario_id" gorm:"column:UsuarioID;not null"`
+	Nombre            string     `json:"name" gorm:"column:Nombre;size:100;not null"`
+	NombreNormalizado string     `json:"-" gorm:"column:NombreNormalizado;size:100;not null"`
+	Categoria         string     `json:"category,omitempty" gorm:"column:Categoria;size:100"`
+	Descripcion       string     `json:"description,omitempty" gorm:"column:Descripcion;size:500"`
+	Estado            string     `json:"estado" gorm:"column:Estado;size:20;default:'pending'"` // pending, approved, rejected
+	HabilidadID       *uint      `json:"habilidad_id,omitempty" gorm:"column:HabilidadID"`
+	RevisorID         *uint      `json:"revisor_id,omitempty" gorm:"column:RevisorID"`
+	NotaRevision      string     `json:"nota_revision,omitempty" gorm:"column:NotaRevision;size:500"`
+	FechaCreacion     time.Time  `json:"fecha_creacion" gorm:"column:FechaCreacion;autoCreateTime"`
+	FechaRevision     *time.Time `json:"fecha_revision,omitempty" gorm:"column:FechaRevision"`
+
+	// Datos de solo lectura para los listados
+	NombreUsuario string `json:"nombre_usuario,omitempty" gorm:"->;column:NombreUsuario"`
+}
+
+// TableName establece el nombre personalizado de la tabla
+func (PropuestaHabilidad) TableName() string {
+	return "PropuestasHabilidad"
+}
+
+// CategoriaHabilidadRequest representa la estructura para crear o modificar una categoría
+type CategoriaHabilidadRequest struct {
+	Nombre      *string `json:"nombre"`
+	PadreID     *uint   `json:"padre_id"` // 0 = mover a la raíz
+	Descripcion *string `json:"descripcion"`
+}
+
+// AsignarCategoriaRequest representa la estructura para ubicar una habilidad en el árbol
+type AsignarCategoriaRequest struct {
+	CategoriaID uint `json:"categoria_id"`
+}
+
+// AliasHabilidadRequest representa la estructura para agregar un alias a una habilidad
+type AliasHabilidadRequest struct {
+	Alias string `json:"alias"`
+}
+
+// FusionarHabilidadRequest indica la habilidad canónica en la que se fusiona un duplicado
+type FusionarHabilidadRequest struct {
+	CanonicaID uint `json:"canonical_id"`
+}
+
+// FusionHabilidadResponse resume los registros que se movieron a la habilidad canónica
+type FusionHabilidadResponse struct {
+	Habilidad             HabilidadTaxonomia `json:"habilidad"`
+	UsuariosHabilidades   int64              `json:"usuarios_habilidades"`
+	Posts                 int64              `json:"posts"`
+	Emparejamientos       int64              `json:"emparejamientos"`
+	DuplicadosDescartados int64              `json:"duplicados_descartados"` // Registros de usuario que ya tenían la canónica
+	RespaldosTrasladados  int64              `json:"respaldos_trasladados"`  // Respaldos movidos desde los registros descartados
+}
+
+// PropuestaHabilidadRequest representa la estructura para proponer una habilidad nueva
+type PropuestaHabilidadRequest struct {
+	Nombre      string `json:"name"`
+	Categoria   string `json:"category"`
+	Descripcion string `json:"description"`
+}
+
+// RevisionPropuestaRequest representa la decisión del administrador sobre una propuesta.
+// Al aprobar, HabilidadID asocia la propuesta como alias de una habilidad existente;
+// si no se indica, se crea una habilidad nueva con los datos (opcionalmente corregidos).
+type RevisionPropuestaRequest struct {
+	Nombre      *string `json:"name"`
+	CategoriaID *uint   `json:"categoria_id"`
+	Descripcion *string `json:"description"`
+	HabilidadID *uint   `json:"habilidad_id"`
+	Nota        string  `json:"nota"`
+}
+
+// Tipos de coincidencia de una sugerencia de habilidad, de mayor a menor precisión
+const (
+	CoincidenciaExacta  = "exact"
+	CoincidenciaPrefijo = "prefix"
+	CoincidenciaPalabra = "word"
+	CoincidenciaTexto   = "substring"
+	CoincidenciaAprox   = "fuzzy"
+)
+
+// SugerenciaHabilidad es una habilidad propuesta por el autocompletado
+type SugerenciaHabilidad struct {
+	ID           uint   `json:"id"`
+	Nombre       string `json:"name"`
+	Categoria    string `json:"category"`
+	Alias        string `json:"alias,omitempty"` // Alias por el que coincidió, si no fue por el nombre
+	Coincidencia string `json:"match"`
+	Ofrecen      int64  `json:"offered_by"` // Usuarios que la ofrecen
+	Buscan       int64  `json:"sought_by"`  // Usuarios que la buscan
+}
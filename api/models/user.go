@@ -18,12 +18,14 @@ type User struct {
 	HashContrasena    string    `json:"-" gorm:"not null;column:HashContrasena"`
 	Rol               string    `json:"rol" gorm:"default:'user';column:Rol"`
 	IsBanned		  bool 		`json:"is_banned" gorm:"default:0;column:IsBanned"` // Añadido IsBanned a User
+	SuspendidoHasta   *time.Time `json:"suspendido_hasta,omitempty" gorm:"column:SuspendidoHasta"` // Suspensión temporal por moderación
 	FechaNacimiento   string    `json:"fecha_nacimiento,omitempty" gorm:"column:FechaNacimiento"`
 	RolID             *int      `json:"rol_id,omitempty" gorm:"column:RolID"`
 		// Nuevos campos de enlaces sociales
 	LinkedInLink      string    `json:"linkedin_link,omitempty" gorm:"column:LinkedInLink"`
 	GithubLink        string    `json:"github_link" gorm:"column:GithubLink"`
 	OwnWebsiteLink    string    `json:"website_link,omitempty" gorm:"column:OwnWebsiteLink"`
+	Guardado          bool      `json:"bookmarked" gorm:"-"` // El usuario autenticado guardó el perfil
 
 	// Relación con las habilidades del usuario
 	UserAbilities []UserAbility `json:"user_abilities,omitempty" gorm:"foreignKey:UserID;references:UsuarioID"`
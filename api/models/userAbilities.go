@@ -8,6 +8,11 @@ type UserAbility struct {
 	SkillType        string    `json:"skill_type" gorm:"column:TipoHabilidad"`
 	ProficiencyLevel string    `json:"proficiency_level,omitempty" gorm:"column:NivelProficiencia"`
 
+	// Respaldos de otros usuarios (solo para las habilidades que se ofrecen)
+	TotalRespaldos    int64               `json:"endorsement_count" gorm:"-"`
+	TopRespaldantes   []RespaldoHabilidad `json:"top_endorsers,omitempty" gorm:"-"`
+	RespaldadaPorMi   bool                `json:"endorsed_by_viewer" gorm:"-"` // El usuario autenticado la respaldó
+
 	// Campos para cargar datos relacionados
 	User    User    `json:"user,omitempty" gorm:"foreignKey:UserID;references:UsuarioID"`
 	Ability Ability `json:"ability,omitempty" gorm:"foreignKey:AbilityID;references:HabilidadID"`
@@ -5,8 +5,10 @@ import (
 	"net/http"
 	"os"
 
+	"skillswap/api/contentfilter"
 	"skillswap/api/handlers" // Reemplaza con tu módulo si es diferente
 	"skillswap/api/middleware"
+	"skillswap/api/search"
 
 	"gorm.io/gorm"
 )
@@ -115,6 +117,9 @@ func SetupRoutes(db *gorm.DB) http.Handler { // Cambiado para devolver http.Hand
 
         return enableCORS(loggingMiddleware(router))
     }    // Inicialización de handlers normales cuando no estamos en modo de prueba
+    // Los tokens de cuentas baneadas o suspendidas después del login dejan de ser válidos
+    middleware.SetAccountStatusChecker(handlers.AccountStatusChecker(db))
+
     usersHandler := handlers.NewUserHandler(db)
     abilitiesHandler := handlers.NewAbilityHandler(db)
     userAbilitiesHandler := handlers.NewUserAbilitiesHandler(db)
@@ -123,35 +128,104 @@ func SetupRoutes(db *gorm.DB) http.Handler { // Cambiado para devolver http.Hand
     authHandler := handlers.NewAuthHandler(db)
     auditHandler := handlers.NewAuditHandler(db)
     messagesHandler := handlers.NewMessagesHandler(db)
-    commentsHandler := handlers.NewCommentHandler(db)    // Inicializar WebSocket hub y handler
+    commentsHandler := handlers.NewCommentHandler(db)
+    blocksHandler := handlers.NewBlocksHandler(db)    // Inicializar WebSocket hub y handler
     wsHub := handlers.NewHub(db)
     go wsHub.Run() // Ejecutar el hub en una goroutine separada
     wsHandler := handlers.NewWebSocketHandler(db, wsHub)
       // Configurar la conexión WebSocket en los handlers que la necesiten
     messagesHandler.SetWebSocketHandler(wsHandler)
     commentsHandler.SetWebSocketHandler(wsHandler)
+    postsHandler.SetWebSocketHandler(wsHandler)
+
+    // Inicializar el índice de búsqueda y reconstruirlo en segundo plano
+    searcher := search.NewSearcher(db)
+    go func() {
+        if err := search.Rebuild(db, searcher); err != nil {
+            log.Printf("Error al reconstruir el índice de búsqueda: %v", err)
+        }
+    }()
+    usersHandler.SetSearcher(searcher)
+    postsHandler.SetSearcher(searcher)
+    messagesHandler.SetSearcher(searcher)
+    commentsHandler.SetSearcher(searcher)
+    searchHandler := handlers.NewSearchHandler(db, searcher)
+
+    // Expirar periódicamente los posts que pasaron su fecha de expiración
+    go handlers.RunPostExpiryJob(db)
+
+    // Enviar periódicamente los resúmenes de búsquedas guardadas
+    go handlers.RunSavedSearchDigestJob(db, wsHandler)
+
+    // Filtro automático de contenido para posts, comentarios y mensajes
+    contentFilter := contentfilter.NewDefaultPipeline(db)
+    postsHandler.SetContentFilter(contentFilter)
+    commentsHandler.SetContentFilter(contentFilter)
+    messagesHandler.SetContentFilter(contentFilter)
 
     // Inicializar handler de pruebas Socket.IO
     socketIOTestHandler := handlers.NewSocketIOTestHandler()
 
     // Rutas para Usuarios
     // Para las solicitudes GET, definimos la ruta con y sin trailing slash
-    router.HandleFunc("GET /users", usersHandler.GetUsers)
-    router.HandleFunc("GET /users/", usersHandler.GetUsers)
+    router.Handle("GET /users", middleware.OptionalAuthWrapper(usersHandler.GetUsers))
+    router.Handle("GET /users/", middleware.OptionalAuthWrapper(usersHandler.GetUsers))
     // Para las solicitudes POST, también definimos la ruta con y sin trailing slash
     router.HandleFunc("POST /users", usersHandler.CreateUser)
     router.HandleFunc("POST /users/", usersHandler.CreateUser)
-    router.HandleFunc("GET /users/{id}", usersHandler.GetUser)
+    router.Handle("GET /users/{id}", middleware.OptionalAuthWrapper(usersHandler.GetUser))
     router.HandleFunc("PUT /users/{id}", usersHandler.UpdateUser)
     router.HandleFunc("DELETE /users/{id}", usersHandler.DeleteUser)
-    router.HandleFunc("GET /users/actions/ban/{id}", usersHandler.BanUser)
-
-    // Rutas para Habilidades
+    // Solo moderadores; el baneo queda registrado en la auditoría de moderación
+    router.Handle("POST /users/actions/ban/{id}", middleware.RequireAuthWrapper(usersHandler.BanUser))
+
+    // Rutas para bloqueos entre usuarios (requieren autenticación)
+    router.Handle("GET /users/{id}/blocks", middleware.RequireAuthWrapper(blocksHandler.GetUserBlocks))
+    router.Handle("POST /users/{id}/blocks", middleware.RequireAuthWrapper(blocksHandler.BlockUser))
+    router.Handle("DELETE /users/{id}/blocks", middleware.RequireAuthWrapper(blocksHandler.UnblockUser))
+
+    // Rutas para marcadores de posts y perfiles (requieren autenticación)
+    bookmarksHandler := handlers.NewBookmarksHandler(db)
+    router.Handle("GET /users/{id}/bookmarks", middleware.RequireAuthWrapper(bookmarksHandler.GetBookmarks))
+    router.Handle("POST /users/{id}/bookmarks", middleware.RequireAuthWrapper(bookmarksHandler.CreateBookmark))
+    router.Handle("DELETE /users/{id}/bookmarks", middleware.RequireAuthWrapper(bookmarksHandler.DeleteBookmark))
+
+    // Rutas para Habilidades (el índice de autocompletado se comparte con la taxonomía)
+    skillSuggestIndex := handlers.NewSkillSuggestIndex(db)
+    abilitiesHandler.SetSuggestIndex(skillSuggestIndex)
     router.HandleFunc("GET /abilities/", abilitiesHandler.GetAbilities)
-    router.HandleFunc("POST /abilities/", abilitiesHandler.CreateAbility)
+    router.HandleFunc("GET /abilities/suggest", abilitiesHandler.SuggestAbilities)
+    router.Handle("POST /abilities/", middleware.RequireAuthWrapper(abilitiesHandler.CreateAbility))
     router.HandleFunc("GET /abilities/{id}", abilitiesHandler.GetAbility)
-    router.HandleFunc("PUT /abilities/{id}", abilitiesHandler.UpdateAbility)
-    router.HandleFunc("DELETE /abilities/{id}", abilitiesHandler.DeleteAbility)
+    router.Handle("PUT /abilities/{id}", middleware.RequireAuthWrapper(abilitiesHandler.UpdateAbility))
+    router.Handle("DELETE /abilities/{id}", middleware.RequireAuthWrapper(abilitiesHandler.DeleteAbility))
+
+    // Rutas para la taxonomía de habilidades (la gestión requiere rol admin; los usuarios proponen habilidades)
+    taxonomyHandler := handlers.NewTaxonomyHandler(db)
+    taxonomyHandler.SetSearcher(searcher)
+    taxonomyHandler.SetWebSocketHandler(wsHandler)
+    taxonomyHandler.SetSuggestIndex(skillSuggestIndex)
+    router.HandleFunc("GET /skill-categories", taxonomyHandler.GetCategoryTree)
+    router.Handle("POST /skill-categories", middleware.RequireAuthWrapper(taxonomyHandler.CreateCategory))
+    router.Handle("PUT /skill-categories/{categoryID}", middleware.RequireAuthWrapper(taxonomyHandler.UpdateCategory))
+    router.Handle("DELETE /skill-categories/{categoryID}", middleware.RequireAuthWrapper(taxonomyHandler.DeleteCategory))
+    router.HandleFunc("GET /abilities/{id}/taxonomy", taxonomyHandler.GetAbilityTaxonomy)
+    router.Handle("PUT /abilities/{id}/category", middleware.RequireAuthWrapper(taxonomyHandler.SetAbilityCategory))
+    router.Handle("POST /abilities/{id}/aliases", middleware.RequireAuthWrapper(taxonomyHandler.AddAbilityAlias))
+    router.Handle("DELETE /abilities/{id}/aliases/{aliasID}", middleware.RequireAuthWrapper(taxonomyHandler.DeleteAbilityAlias))
+    router.Handle("POST /abilities/{id}/merge", middleware.RequireAuthWrapper(taxonomyHandler.MergeAbility))
+    router.Handle("GET /ability-proposals", middleware.RequireAuthWrapper(taxonomyHandler.GetAbilityProposals))
+    router.Handle("POST /ability-proposals", middleware.RequireAuthWrapper(taxonomyHandler.CreateAbilityProposal))
+    router.Handle("POST /ability-proposals/{proposalID}/approve", middleware.RequireAuthWrapper(taxonomyHandler.ApproveAbilityProposal))
+    router.Handle("POST /ability-proposals/{proposalID}/reject", middleware.RequireAuthWrapper(taxonomyHandler.RejectAbilityProposal))
+
+    // Rutas para importar y exportar habilidades en CSV o JSON (?dry_run=true devuelve solo el diff)
+    skillImportHandler := handlers.NewSkillImportHandler(db)
+    skillImportHandler.SetSuggestIndex(skillSuggestIndex)
+    router.Handle("POST /admin/abilities/import", middleware.RequireAuthWrapper(skillImportHandler.ImportAbilities))
+    router.Handle("GET /admin/abilities/export", middleware.RequireAuthWrapper(skillImportHandler.ExportAbilities))
+    router.Handle("POST /users/{id}/abilities/import", middleware.RequireAuthWrapper(skillImportHandler.ImportUserAbilities))
+    router.Handle("GET /users/{id}/abilities/export", middleware.RequireAuthWrapper(skillImportHandler.ExportUserAbilities))
 
     // Rutas para UserAbilities
     router.HandleFunc("GET /userabilities/", userAbilitiesHandler.GetUserAbilities)
@@ -161,6 +235,13 @@ func SetupRoutes(db *gorm.DB) http.Handler { // Cambiado para devolver http.Hand
     router.HandleFunc("DELETE /userabilities/{id}", userAbilitiesHandler.DeleteUserAbility)
     router.HandleFunc("GET /userabilities/user/{id}", userAbilitiesHandler.GetUserAbilitiesByUserID)
 
+    // Rutas para respaldos de habilidades (requieren una sesión completada entre los usuarios)
+    endorsementsHandler := handlers.NewEndorsementsHandler(db)
+    endorsementsHandler.SetWebSocketHandler(wsHandler)
+    router.Handle("GET /userabilities/endorsements/{id}", middleware.OptionalAuthWrapper(endorsementsHandler.GetEndorsements))
+    router.Handle("POST /userabilities/endorsements/{id}", middleware.RequireAuthWrapper(endorsementsHandler.CreateEndorsement))
+    router.Handle("DELETE /userabilities/endorsements/{id}", middleware.RequireAuthWrapper(endorsementsHandler.DeleteEndorsement))
+
     // Rutas para matches
     router.HandleFunc("POST /matches/", matchesHandler.CreateMatch)
     router.HandleFunc("GET /matches/", matchesHandler.GetMatches)
@@ -176,18 +257,41 @@ func SetupRoutes(db *gorm.DB) http.Handler { // Cambiado para devolver http.Hand
     router.HandleFunc("POST /posts", postsHandler.CreatePost)
     router.HandleFunc("POST /posts/", postsHandler.CreatePost)
     // Para las solicitudes GET, también definimos la ruta con y sin trailing slash
-    router.HandleFunc("GET /posts", postsHandler.GetPosts)
-    router.HandleFunc("GET /posts/", postsHandler.GetPosts)    // Rutas para autenticación
+    // Autenticación opcional para ocultar posts de usuarios bloqueados
+    router.Handle("GET /posts", middleware.OptionalAuthWrapper(postsHandler.GetPosts))
+    router.Handle("GET /posts/", middleware.OptionalAuthWrapper(postsHandler.GetPosts))    // Rutas para autenticación
     router.HandleFunc("POST /auth/login", authHandler.Login)
     router.HandleFunc("GET /auth/validate", authHandler.ValidateToken)
 
     // Rutas para auditoría
     router.HandleFunc("GET /audit", auditHandler.GetAuditRecords)
     router.HandleFunc("GET /audit/", auditHandler.GetAuditRecords)
-    router.HandleFunc("GET /posts/{id}", postsHandler.GetPost)
-    router.HandleFunc("PUT /posts/{id}", postsHandler.UpdatePost)
+    router.Handle("GET /posts/{id}", middleware.OptionalAuthWrapper(postsHandler.GetPost))
+    // Solo el autor o un moderador; el editor queda registrado en el historial de revisiones
+    router.Handle("PUT /posts/{id}", middleware.RequireAuthWrapper(postsHandler.UpdatePost))
     router.HandleFunc("DELETE /posts/{id}", postsHandler.DeletePost)
-    router.HandleFunc("GET /users/{userID}/posts/", postsHandler.GetPostsByUserID)
+    router.Handle("GET /users/{userID}/posts/", middleware.OptionalAuthWrapper(postsHandler.GetPostsByUserID))
+    // Ciclo de vida de los posts (solo el autor)
+    router.Handle("PUT /posts/{id}/status", middleware.RequireAuthWrapper(postsHandler.UpdatePostStatus))
+    router.Handle("POST /posts/{id}/fulfill", middleware.RequireAuthWrapper(postsHandler.FulfillPost))
+    // Feed personalizado según las habilidades que el usuario Busca y Ofrece
+    router.Handle("GET /users/{id}/feed", middleware.RequireAuthWrapper(postsHandler.GetUserFeed))
+
+    // Respuestas a posts: proponen un emparejamiento que el autor acepta o rechaza
+    postResponsesHandler := handlers.NewPostResponsesHandler(db)
+    postResponsesHandler.SetWebSocketHandler(wsHandler)
+    router.Handle("POST /posts/{id}/responses", middleware.RequireAuthWrapper(postResponsesHandler.CreatePostResponse))
+    router.Handle("GET /posts/{id}/responses", middleware.RequireAuthWrapper(postResponsesHandler.GetPostResponses))
+    router.Handle("POST /posts/{id}/responses/{responseID}/accept", middleware.RequireAuthWrapper(postResponsesHandler.AcceptPostResponse))
+    router.Handle("POST /posts/{id}/responses/{responseID}/decline", middleware.RequireAuthWrapper(postResponsesHandler.DeclinePostResponse))
+
+    // Búsquedas guardadas: avisan de los posts nuevos que coinciden con los criterios
+    savedSearchesHandler := handlers.NewSavedSearchesHandler(db)
+    router.Handle("GET /saved-searches", middleware.RequireAuthWrapper(savedSearchesHandler.GetSavedSearches))
+    router.Handle("POST /saved-searches", middleware.RequireAuthWrapper(savedSearchesHandler.CreateSavedSearch))
+    router.Handle("PUT /saved-searches/{searchID}", middleware.RequireAuthWrapper(savedSearchesHandler.UpdateSavedSearch))
+    router.Handle("DELETE /saved-searches/{searchID}", middleware.RequireAuthWrapper(savedSearchesHandler.DeleteSavedSearch))
+    router.Handle("GET /saved-searches/{searchID}/posts", middleware.RequireAuthWrapper(savedSearchesHandler.GetSavedSearchPosts))
 
     // Inicialización del handler de sesiones
     sessionsHandler := handlers.NewSessionsHandler(db)
@@ -204,6 +308,8 @@ func SetupRoutes(db *gorm.DB) http.Handler { // Cambiado para devolver http.Hand
     notificationsHandler := handlers.NewNotificationsHandler(db)    // Rutas para Notificaciones
     router.HandleFunc("GET /users/{userID}/notifications", notificationsHandler.GetUserNotifications)
     router.HandleFunc("PUT /notifications/{id}", notificationsHandler.MarkNotificationAsRead)
+    router.Handle("GET /users/{userID}/notification-preferences", middleware.RequireAuthWrapper(notificationsHandler.GetNotificationPreferences))
+    router.Handle("PUT /users/{userID}/notification-preferences", middleware.RequireAuthWrapper(notificationsHandler.UpdateNotificationPreferences))
     router.HandleFunc("POST /sessions/{sessionID}/send-reminder", notificationsHandler.SendSessionReminder)
     router.HandleFunc("GET /system/upcoming-session-reminders", notificationsHandler.GetUpcomingSessionsReminders)
 
@@ -212,20 +318,42 @@ func SetupRoutes(db *gorm.DB) http.Handler { // Cambiado para devolver http.Hand
     router.HandleFunc("POST /conversations/", messagesHandler.CreateConversation)
     router.HandleFunc("GET /users/{userID}/conversations", messagesHandler.GetUserConversations)
     router.HandleFunc("GET /conversations/{conversationID}", messagesHandler.GetConversation)
-    router.HandleFunc("GET /conversations/{conversationID}/messages", messagesHandler.GetConversationMessages)
+    // Autenticación opcional para calcular reacted_by_me en las reacciones
+    router.Handle("GET /conversations/{conversationID}/messages", middleware.OptionalAuthWrapper(messagesHandler.GetConversationMessages))
     router.HandleFunc("POST /conversations/{conversationID}/messages", messagesHandler.SendMessage)
     router.HandleFunc("PUT /messages/{messageID}/read", messagesHandler.MarkMessageAsRead)
-    router.HandleFunc("PUT /conversations/{conversationID}/read", messagesHandler.MarkConversationAsRead)    // Rutas para Comentarios
+    router.HandleFunc("PUT /conversations/{conversationID}/read", messagesHandler.MarkConversationAsRead)
+
+    // Rutas para reacciones a mensajes
+    router.HandleFunc("GET /messages/reactions/allowed", messagesHandler.GetAllowedReactions)
+    router.Handle("POST /messages/{messageID}/reactions", middleware.RequireAuthWrapper(messagesHandler.ToggleMessageReaction))
+
+    // Rutas para exportar conversaciones (solo participantes)
+    exportHandler := handlers.NewExportHandler(db)
+    router.Handle("GET /conversations/{conversationID}/export", middleware.RequireAuthWrapper(exportHandler.ExportConversation))
+    router.Handle("GET /exports/{jobID}", middleware.RequireAuthWrapper(exportHandler.GetExportJob))
+    router.Handle("GET /exports/{jobID}/download", middleware.RequireAuthWrapper(exportHandler.DownloadExportJob))
+    // Rutas para Comentarios
     // GET no requiere autenticación, los demás sí
-    router.HandleFunc("GET /posts/{postId}/comments", commentsHandler.GetPostComments)
-    router.HandleFunc("GET /comments/{comentarioId}/replies", commentsHandler.GetCommentReplies)
+    // (autenticación opcional para ocultar comentarios de usuarios bloqueados)
+    router.Handle("GET /posts/{postId}/comments", middleware.OptionalAuthWrapper(commentsHandler.GetPostComments))
+    router.Handle("GET /comments/{comentarioId}/replies", middleware.OptionalAuthWrapper(commentsHandler.GetCommentReplies))
     router.HandleFunc("GET /posts/{postId}/comments/stats", commentsHandler.GetPostCommentStats)
+    router.Handle("GET /posts/{postId}/comments/tree", middleware.OptionalAuthWrapper(commentsHandler.GetPostCommentTree))
 
     // Rutas que requieren autenticación
     router.Handle("POST /posts/{postId}/comments", middleware.RequireAuthWrapper(commentsHandler.CreateComment))
     router.Handle("PUT /comments/{comentarioId}", middleware.RequireAuthWrapper(commentsHandler.UpdateComment))
     router.Handle("DELETE /comments/{comentarioId}", middleware.RequireAuthWrapper(commentsHandler.DeleteComment))
-    router.Handle("POST /comments/{comentarioId}/like", middleware.RequireAuthWrapper(commentsHandler.LikeComment))    // Rutas para WebSocket con middleware específico
+    router.Handle("POST /comments/{comentarioId}/like", middleware.RequireAuthWrapper(commentsHandler.LikeComment))
+    router.Handle("POST /comments/{comentarioId}/reactions", middleware.RequireAuthWrapper(commentsHandler.ToggleCommentReaction))
+    router.Handle("POST /comments/{comentarioId}/answer", middleware.RequireAuthWrapper(commentsHandler.MarkCommentAsAnswer))
+    router.Handle("DELETE /comments/{comentarioId}/answer", middleware.RequireAuthWrapper(commentsHandler.UnmarkCommentAsAnswer))
+
+    // Rutas para seguir el hilo de comentarios de un post
+    router.Handle("GET /posts/{postId}/follow", middleware.RequireAuthWrapper(commentsHandler.GetPostThreadFollow))
+    router.Handle("POST /posts/{postId}/follow", middleware.RequireAuthWrapper(commentsHandler.FollowPostThread))
+    router.Handle("DELETE /posts/{postId}/follow", middleware.RequireAuthWrapper(commentsHandler.UnfollowPostThread))    // Rutas para WebSocket con middleware específico
     router.HandleFunc("GET /ws", webSocketCORS(wsHandler.ServeWS))
     router.HandleFunc("GET /ws/status", wsHandler.GetWebSocketStatus)
     router.HandleFunc("GET /ws/clients", wsHandler.GetConnectedClients)
@@ -237,6 +365,34 @@ func SetupRoutes(db *gorm.DB) http.Handler { // Cambiado para devolver http.Hand
     router.HandleFunc("POST /api/test/socketio/custom", socketIOTestHandler.TestSocketIOCustomMessage)
     router.HandleFunc("GET /api/test/socketio/status", socketIOTestHandler.GetSocketIOStatus)
 
+    // Rutas para reportes y cola de moderación (la cola y las acciones requieren rol admin o moderator)
+    moderationHandler := handlers.NewModerationHandler(db)
+    moderationHandler.SetWebSocketHandler(wsHandler)
+    moderationHandler.SetSearcher(searcher)
+    router.Handle("POST /reports", middleware.RequireAuthWrapper(moderationHandler.CreateReport))
+    router.Handle("GET /moderation/reports", middleware.RequireAuthWrapper(moderationHandler.GetReportQueue))
+    router.Handle("POST /moderation/reports/{reportID}/actions", middleware.RequireAuthWrapper(moderationHandler.ResolveReport))
+    router.Handle("GET /moderation/actions", middleware.RequireAuthWrapper(moderationHandler.GetModerationActions))
+    router.Handle("GET /comments/{comentarioId}/revisions", middleware.RequireAuthWrapper(moderationHandler.GetCommentRevisions))
+    router.Handle("GET /posts/{postId}/revisions", middleware.RequireAuthWrapper(moderationHandler.GetPostRevisions))
+
+    // Rutas para la analítica de oferta y demanda de habilidades (requieren rol admin; format=csv exporta)
+    skillAnalyticsHandler := handlers.NewSkillAnalyticsHandler(db)
+    go skillAnalyticsHandler.RunRecomputeJob()
+    router.Handle("GET /analytics/skills", middleware.RequireAuthWrapper(skillAnalyticsHandler.GetSupplyDemand))
+    router.Handle("GET /analytics/skills/cities", middleware.RequireAuthWrapper(skillAnalyticsHandler.GetSupplyDemandByCity))
+    router.Handle("GET /analytics/skills/categories", middleware.RequireAuthWrapper(skillAnalyticsHandler.GetSupplyDemandByCategory))
+    router.Handle("GET /analytics/skills/trends", middleware.RequireAuthWrapper(skillAnalyticsHandler.GetTrends))
+    router.Handle("GET /analytics/matches/conversion", middleware.RequireAuthWrapper(skillAnalyticsHandler.GetMatchConversion))
+    router.Handle("POST /analytics/refresh", middleware.RequireAuthWrapper(skillAnalyticsHandler.RefreshAnalytics))
+
+    // Ruta para búsqueda unificada (autenticación opcional para incluir mensajes propios)
+    router.Handle("GET /search", middleware.OptionalAuthWrapper(searchHandler.Search))
+
+    // Feed Atom público de posts, filtrable por habilidad, categoría y tipo
+    atomFeedHandler := handlers.NewAtomFeedHandler(db)
+    router.HandleFunc("GET /feeds/posts.atom", atomFeedHandler.GetPostsAtomFeed)
+
     // Ruta para health check
     router.HandleFunc("GET /health", handlers.HealthCheckHandler)
 
@@ -0,0 +1,188 @@
+package search
+
+import (
+	"math"
+	"sort"
+	"sync"
+)
+
+// Peso de los términos del título frente a los del cuerpo
+const titleWeight = 3
+
+type docKey struct {
+	Type string
+	ID   uint
+}
+
+type indexedDoc struct {
+	Document
+	terms map[string]int // Frecuencia ponderada de cada término
+}
+
+// MemoryIndex es un índice invertido en memoria, seguro para uso concurrente
+type MemoryIndex struct {
+	mu       sync.RWMutex
+	docs     map[docKey]*indexedDoc
+	postings map[string]map[docKey]int
+}
+
+// NewMemoryIndex crea un índice vacío
+func NewMemoryIndex() *MemoryIndex {
+	return &MemoryIndex{
+		docs:     make(map[docKey]*indexedDoc),
+		postings: make(map[string]map[docKey]int),
+	}
+}
+
+// Index agrega o reemplaza un documento en el índice
+func (m *MemoryIndex) Index(doc Document) error {
+	terms := make(map[string]int)
+	for _, t := range tokenize(doc.Title) {
+		terms[t] += titleWeight
+	}
+	for _, t := range tokenize(doc.Body) {
+		terms[t]++
+	}
+
+	key := docKey{Type: doc.Type, ID: doc.ID}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.removeLocked(key)
+	m.docs[key] = &indexedDoc{Document: doc, terms: terms}
+	for t, freq := range terms {
+		if m.postings[t] == nil {
+			m.postings[t] = make(map[docKey]int)
+		}
+		m.postings[t][key] = freq
+	}
+	return nil
+}
+
+// Delete elimina un documento del índice
+func (m *MemoryIndex) Delete(docType string, id uint) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.removeLocked(docKey{Type: docType, ID: id})
+	return nil
+}
+
+// removeLocked elimina un documento; el llamador debe tener el lock de escritura
+func (m *MemoryIndex) removeLocked(key docKey) {
+	existing, ok := m.docs[key]
+	if !ok {
+		return
+	}
+	for t := range existing.terms {
+		delete(m.postings[t], key)
+		if len(m.postings[t]) == 0 {
+			delete(m.postings, t)
+		}
+	}
+	delete(m.docs, key)
+}
+
+// Search devuelve los documentos que contienen todos los términos de la consulta,
+// ordenados por relevancia (tf-idf)
+func (m *MemoryIndex) Search(q Query) (Results, error) {
+	terms := queryTerms(q.Text)
+	if len(terms) == 0 {
+		return Results{Results: []Result{}}, nil
+	}
+
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	totalDocs := float64(len(m.docs))
+	var scores map[docKey]float64
+
+	for i, qt := range terms {
+		// El último término se expande por prefijo (mínimo 3 caracteres)
+		prefix := i == len(terms)-1 && len(qt) >= 3
+
+		termScores := make(map[docKey]float64)
+		for indexTerm, postings := range m.matchingPostings(qt, prefix) {
+			idf := math.Log(1 + totalDocs/float64(len(postings)))
+			for key, freq := range postings {
+				// Las coincidencias por prefijo puntúan algo menos que las exactas
+				weight := 1.0
+				if indexTerm != qt {
+					weight = 0.7
+				}
+				termScores[key] += float64(freq) * idf * weight
+			}
+		}
+
+		// Todos los términos deben aparecer en el documento
+		if scores == nil {
+			scores = termScores
+			continue
+		}
+		for key, score := range scores {
+			if extra, ok := termScores[key]; ok {
+				scores[key] = score + extra
+			} else {
+				delete(scores, key)
+			}
+		}
+	}
+
+	results := make([]Result, 0, len(scores))
+	for key, score := range scores {
+		doc := m.docs[key]
+		if !allowedDocument(doc.Document, q) {
+			continue
+		}
+		results = append(results, Result{
+			Type:      doc.Type,
+			ID:        doc.ID,
+			OwnerID:   doc.OwnerID,
+			ParentID:  doc.ParentID,
+			Title:     doc.Title,
+			Highlight: documentHighlight(doc.Title, doc.Body, terms),
+			Score:     score,
+			CreatedAt: doc.CreatedAt,
+		})
+	}
+
+	sort.Slice(results, func(i, j int) bool {
+		if results[i].Score != results[j].Score {
+			return results[i].Score > results[j].Score
+		}
+		return results[i].CreatedAt.After(results[j].CreatedAt)
+	})
+
+	return paginate(results, q.Offset, q.Limit), nil
+}
+
+// matchingPostings devuelve las listas de postings de los términos que coinciden con qt
+func (m *MemoryIndex) matchingPostings(qt string, prefix bool) map[string]map[docKey]int {
+	matches := make(map[string]map[docKey]int)
+	if postings, ok := m.postings[qt]; ok {
+		matches[qt] = postings
+	}
+	if !prefix {
+		return matches
+	}
+	for indexTerm, postings := range m.postings {
+		if indexTerm != qt && matchesTerm(indexTerm, qt, true) {
+			matches[indexTerm] = postings
+		}
+	}
+	return matches
+}
+
+// paginate aplica offset y límite a una lista de resultados ordenada
+func paginate(results []Result, offset, limit int) Results {
+	total := len(results)
+	if offset > total {
+		offset = total
+	}
+	end := total
+	if limit > 0 && offset+limit < total {
+		end = offset + limit
+	}
+	return Results{Results: results[offset:end], Total: total}
+}
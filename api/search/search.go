@@ -0,0 +1,236 @@
+// Package search implementa la búsqueda de texto completo sobre posts, comentarios,
+// mensajes y usuarios. El backend por defecto es un índice invertido en memoria;
+// con SEARCH_BACKEND=sqlserver se usa el full-text search de SQL Server.
+package search
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"strings"
+	"time"
+
+	"skillswap/api/models"
+
+	"gorm.io/gorm"
+)
+
+// Tipos de documento indexables
+const (
+	TypePost    = "post"
+	TypeComment = "comment"
+	TypeMessage = "message"
+	TypeUser    = "user"
+)
+
+// Document representa una entidad indexable
+type Document struct {
+	Type      string
+	ID        uint
+	OwnerID   uint   // Autor del post, comentario o mensaje (o el propio usuario)
+	ParentID  uint   // Post de un comentario o conversación de un mensaje
+	Title     string // Texto con mayor peso (habilidad del post, nombre del usuario)
+	Body      string
+	CreatedAt time.Time
+}
+
+// Query representa una consulta de búsqueda
+type Query struct {
+	Text  string
+	Types []string // Vacío = todos los tipos
+	// ConversationIDs limita los mensajes a las conversaciones del usuario que busca.
+	// Si es nil no se devuelven mensajes.
+	ConversationIDs []uint
+	ExcludeOwnerIDs []uint // Autores ocultos (por ejemplo, usuarios bloqueados)
+	Limit           int
+	Offset          int
+}
+
+// Result representa un documento encontrado
+type Result struct {
+	Type      string    `json:"type"`
+	ID        uint      `json:"id"`
+	OwnerID   uint      `json:"owner_id"`
+	ParentID  uint      `json:"parent_id,omitempty"`
+	Title     string    `json:"title,omitempty"`
+	Highlight string    `json:"highlight"`
+	Score     float64   `json:"score"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// Results representa una página de resultados
+type Results struct {
+	Results []Result `json:"results"`
+	Total   int      `json:"total"`
+}
+
+// Searcher es la interfaz común a los backends de búsqueda
+type Searcher interface {
+	// Index agrega o reemplaza un documento en el índice
+	Index(doc Document) error
+	// Delete elimina un documento del índice
+	Delete(docType string, id uint) error
+	// Search ejecuta una consulta respetando los filtros de permisos
+	Search(q Query) (Results, error)
+}
+
+// NewSearcher crea el backend de búsqueda configurado en SEARCH_BACKEND
+func NewSearcher(db *gorm.DB) Searcher {
+	switch strings.ToLower(os.Getenv("SEARCH_BACKEND")) {
+	case "sqlserver":
+		log.Println("Búsqueda: usando full-text search de SQL Server")
+		return NewSQLServerSearcher(db)
+	default:
+		log.Println("Búsqueda: usando índice invertido en memoria")
+		return NewMemoryIndex()
+	}
+}
+
+// ValidType indica si el tipo de documento es válido
+func ValidType(docType string) bool {
+	switch docType {
+	case TypePost, TypeComment, TypeMessage, TypeUser:
+		return true
+	}
+	return false
+}
+
+// PostDocument construye el documento de un post
+func PostDocument(post models.Post, abilityName string) Document {
+	return Document{
+		Type:      TypePost,
+		ID:        post.ID,
+		OwnerID:   post.UsuarioID,
+		Title:     abilityName,
+		Body:      post.Descripcion,
+		CreatedAt: post.CreatedAt,
+	}
+}
+
+// CommentDocument construye el documento de un comentario
+func CommentDocument(comment models.Comentario) Document {
+	return Document{
+		Type:      TypeComment,
+		ID:        uint(comment.ComentarioID),
+		OwnerID:   uint(comment.UsuarioID),
+		ParentID:  uint(comment.PostID),
+		Body:      comment.Contenido,
+		CreatedAt: comment.CreatedAt,
+	}
+}
+
+// MessageDocument construye el documento de un mensaje
+func MessageDocument(message models.Message) Document {
+	return Document{
+		Type:      TypeMessage,
+		ID:        message.ID,
+		OwnerID:   message.SenderID,
+		ParentID:  message.ConversationID,
+		Body:      message.Content,
+		CreatedAt: message.CreatedAt,
+	}
+}
+
+// UserDocument construye el documento de un usuario
+func UserDocument(user models.User) Document {
+	name := strings.Join(strings.Fields(strings.Join([]string{
+		user.PrimerNombre, user.SegundoNombre, user.PrimerApellido, user.SegundoApellido,
+	}, " ")), " ")
+
+	return Document{
+		Type:      TypeUser,
+		ID:        user.ID,
+		OwnerID:   user.ID,
+		Title:     name,
+		Body:      user.NombreUsuario + " " + user.CiudadTrabajo,
+		CreatedAt: user.CreatedAt,
+	}
+}
+
+// Rebuild carga todas las entidades de la base de datos en el índice
+func Rebuild(db *gorm.DB, s Searcher) error {
+	if _, ok := s.(*MemoryIndex); !ok {
+		// Los demás backends mantienen su propio índice
+		return nil
+	}
+
+	var posts []struct {
+		models.Post
+		NombreHabilidad string `gorm:"column:NombreHabilidad"`
+	}
+	if err := db.Table("Posts p").
+		Select("p.*, h.NombreHabilidad").
+		Joins("LEFT JOIN Habilidades h ON h.HabilidadID = p.HabilidadID").
+		Where("p.Oculto = 0").
+		Scan(&posts).Error; err != nil {
+		return fmt.Errorf("error al cargar posts: %w", err)
+	}
+	for _, p := range posts {
+		s.Index(PostDocument(p.Post, p.NombreHabilidad))
+	}
+
+	var comments []models.Comentario
+	if err := db.Where("Activo = 1 AND Oculto = 0").Find(&comments).Error; err != nil {
+		return fmt.Errorf("error al cargar comentarios: %w", err)
+	}
+	for _, c := range comments {
+		s.Index(CommentDocument(c))
+	}
+
+	var messages []models.Message
+	if err := db.Find(&messages).Error; err != nil {
+		return fmt.Errorf("error al cargar mensajes: %w", err)
+	}
+	for _, m := range messages {
+		s.Index(MessageDocument(m))
+	}
+
+	var users []models.User
+	if err := db.Find(&users).Error; err != nil {
+		return fmt.Errorf("error al cargar usuarios: %w", err)
+	}
+	for _, u := range users {
+		s.Index(UserDocument(u))
+	}
+
+	log.Printf("✅ Índice de búsqueda reconstruido: %d posts, %d comentarios, %d mensajes, %d usuarios",
+		len(posts), len(comments), len(messages), len(users))
+	return nil
+}
+
+// queryTerms devuelve los términos normalizados de la consulta
+func queryTerms(text string) []string {
+	return tokenize(text)
+}
+
+// allowedDocument aplica los filtros de tipo y permisos de la consulta
+func allowedDocument(doc Document, q Query) bool {
+	if len(q.Types) > 0 && !containsString(q.Types, doc.Type) {
+		return false
+	}
+	if doc.Type == TypeMessage && !containsUint(q.ConversationIDs, doc.ParentID) {
+		return false
+	}
+	if containsUint(q.ExcludeOwnerIDs, doc.OwnerID) {
+		return false
+	}
+	return true
+}
+
+func containsString(list []string, value string) bool {
+	for _, v := range list {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+func containsUint(list []uint, value uint) bool {
+	for _, v := range list {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,210 @@
+package search
+
+import (
+	"sort"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Máximo de candidatos que se obtienen por tipo antes de combinar y paginar
+const sqlServerMaxCandidates = 500
+
+// SQLServerSearcher usa los índices full-text de SQL Server (ver database/fulltext_search.sql).
+// SQL Server mantiene los índices automáticamente, por lo que Index y Delete no hacen nada.
+type SQLServerSearcher struct {
+	DB *gorm.DB
+}
+
+// NewSQLServerSearcher crea un backend de búsqueda sobre SQL Server
+func NewSQLServerSearcher(db *gorm.DB) *SQLServerSearcher {
+	return &SQLServerSearcher{DB: db}
+}
+
+// Index no hace nada: el índice full-text se actualiza con el seguimiento de cambios
+func (s *SQLServerSearcher) Index(doc Document) error {
+	return nil
+}
+
+// Delete no hace nada: el índice full-text se actualiza con el seguimiento de cambios
+func (s *SQLServerSearcher) Delete(docType string, id uint) error {
+	return nil
+}
+
+// sqlServerRow es una fila de resultado de CONTAINSTABLE
+type sqlServerRow struct {
+	ID        uint      `gorm:"column:ID"`
+	OwnerID   uint      `gorm:"column:OwnerID"`
+	ParentID  uint      `gorm:"column:ParentID"`
+	Title     string    `gorm:"column:Title"`
+	Body      string    `gorm:"column:Body"`
+	Score     float64   `gorm:"column:Score"`
+	CreatedAt time.Time `gorm:"column:CreatedAt"`
+}
+
+// Puntaje que suma cada término encontrado en el nombre de la habilidad de un post, en la escala de
+// RANK de CONTAINSTABLE (0 a 1000); equivale al peso del título en el índice en memoria
+const sqlServerTitleRank = 100
+
+// Consultas por tipo; el primer parámetro es la condición de CONTAINSTABLE. Los posts se arman con
+// sqlServerPostQuery porque también buscan en el nombre de la habilidad.
+var sqlServerQueries = map[string]string{
+	TypeComment: `
+		SELECT TOP (?) c.ComentarioID AS ID, c.UsuarioID AS OwnerID, c.PostID AS ParentID,
+			'' AS Title, c.Contenido AS Body, CAST(ft.[RANK] AS FLOAT) AS Score, c.CreatedAt
+		FROM CONTAINSTABLE(Comentarios, Contenido, ?) ft
+		INNER JOIN Comentarios c ON c.ComentarioID = ft.[KEY]
+		WHERE c.Activo = 1 AND c.Oculto = 0
+		ORDER BY ft.[RANK] DESC`,
+	TypeMessage: `
+		SELECT TOP (?) m.ID, m.SenderID AS OwnerID, m.ConversationID AS ParentID,
+			'' AS Title, CAST(m.Content AS NVARCHAR(MAX)) AS Body,
+			CAST(ft.[RANK] AS FLOAT) AS Score, m.CreatedAt
+		FROM CONTAINSTABLE(Messages, Content, ?) ft
+		INNER JOIN Messages m ON m.ID = ft.[KEY]
+		WHERE m.DeletedAt IS NULL AND m.ConversationID IN ?
+		ORDER BY ft.[RANK] DESC`,
+	TypeUser: `
+		SELECT TOP (?) u.UsuarioID AS ID, u.UsuarioID AS OwnerID, 0 AS ParentID,
+			CONCAT_WS(' ', u.PrimerNombre, u.SegundoNombre, u.PrimerApellido, u.SegundoApellido) AS Title,
+			CONCAT_WS(' ', u.NombreUsuario, u.CiudadTrabajo) AS Body,
+			CAST(ft.[RANK] AS FLOAT) AS Score, u.FechaCreacion AS CreatedAt
+		FROM CONTAINSTABLE(Usuarios, (NombreUsuario, PrimerNombre, SegundoNombre, PrimerApellido, SegundoApellido, CiudadTrabajo), ?) ft
+		INNER JOIN Usuarios u ON u.UsuarioID = ft.[KEY]
+		ORDER BY ft.[RANK] DESC`,
+}
+
+// Search ejecuta la consulta en cada tipo solicitado y combina los resultados por relevancia
+func (s *SQLServerSearcher) Search(q Query) (Results, error) {
+	terms := queryTerms(q.Text)
+	if len(terms) == 0 {
+		return Results{Results: []Result{}}, nil
+	}
+	condition := containsCondition(terms)
+
+	types := q.Types
+	if len(types) == 0 {
+		types = []string{TypePost, TypeComment, TypeMessage, TypeUser}
+	}
+
+	var results []Result
+	for _, docType := range types {
+		query := sqlServerQueries[docType]
+		args := []interface{}{sqlServerMaxCandidates, condition}
+		if docType == TypePost {
+			query, args = sqlServerPostQuery(terms)
+		}
+		if docType == TypeMessage {
+			if len(q.ConversationIDs) == 0 {
+				continue
+			}
+			args = append(args, q.ConversationIDs)
+		}
+
+		var rows []sqlServerRow
+		if err := s.DB.Raw(query, args...).Scan(&rows).Error; err != nil {
+			return Results{}, err
+		}
+
+		for _, row := range rows {
+			doc := Document{
+				Type:      docType,
+				ID:        row.ID,
+				OwnerID:   row.OwnerID,
+				ParentID:  row.ParentID,
+				Title:     row.Title,
+				Body:      row.Body,
+				CreatedAt: row.CreatedAt,
+			}
+			if !allowedDocument(doc, q) {
+				continue
+			}
+			results = append(results, Result{
+				Type:      doc.Type,
+				ID:        doc.ID,
+				OwnerID:   doc.OwnerID,
+				ParentID:  doc.ParentID,
+				Title:     doc.Title,
+				Highlight: documentHighlight(doc.Title, doc.Body, terms),
+				Score:     row.Score,
+				CreatedAt: doc.CreatedAt,
+			})
+		}
+	}
+
+	sort.Slice(results, func(i, j int) bool {
+		if results[i].Score != results[j].Score {
+			return results[i].Score > results[j].Score
+		}
+		return results[i].CreatedAt.After(results[j].CreatedAt)
+	})
+
+	if results == nil {
+		results = []Result{}
+	}
+	return paginate(results, q.Offset, q.Limit), nil
+}
+
+// sqlServerPostQuery arma la búsqueda de posts: igual que en el índice en memoria, cada término debe
+// aparecer en la descripción (full-text) o en el nombre de la habilidad, y el último admite prefijo
+func sqlServerPostQuery(terms []string) (string, []interface{}) {
+	// El nombre se compara sin acentos ni mayúsculas y con los separadores de palabra en los extremos
+	const name = "(N' ' + ISNULL(h.NombreHabilidad, '') + N' ') COLLATE Latin1_General_CI_AI"
+
+	conditions := containsTerms(terms)
+	var score strings.Builder
+	var scoreArgs, whereArgs []interface{}
+	where := []string{"p.Oculto = 0"}
+	for i, t := range terms {
+		pattern := "%[^0-9a-z]" + t + "[^0-9a-z]%"
+		if isPrefixTerm(terms, i) {
+			pattern = "%[^0-9a-z]" + t + "%"
+		}
+		score.WriteString(" + CASE WHEN " + name + " LIKE ? THEN ? ELSE 0 END")
+		scoreArgs = append(scoreArgs, pattern, sqlServerTitleRank)
+		where = append(where, "(CONTAINS(p.Descripcion, ?) OR "+name+" LIKE ?)")
+		whereArgs = append(whereArgs, conditions[i], pattern)
+	}
+
+	query := `
+		SELECT TOP (?) p.PostID AS ID, p.UsuarioID AS OwnerID, 0 AS ParentID,
+			COALESCE(h.NombreHabilidad, '') AS Title, p.Descripcion AS Body,
+			CAST(ISNULL(ft.[RANK], 0)` + score.String() + ` AS FLOAT) AS Score, p.CreatedAt
+		FROM Posts p
+		LEFT JOIN Habilidades h ON h.HabilidadID = p.HabilidadID
+		LEFT JOIN CONTAINSTABLE(Posts, Descripcion, ?) ft ON ft.[KEY] = p.PostID
+		WHERE ` + strings.Join(where, " AND ") + `
+		ORDER BY Score DESC`
+	args := []interface{}{sqlServerMaxCandidates}
+	args = append(args, scoreArgs...)
+	// El ranking full-text considera cualquiera de los términos presentes en la descripción
+	args = append(args, strings.Join(conditions, " OR "))
+	args = append(args, whereArgs...)
+	return query, args
+}
+
+// containsCondition construye la condición de CONTAINS a partir de términos ya normalizados.
+// El último término admite prefijo, igual que en el índice en memoria.
+func containsCondition(terms []string) string {
+	return strings.Join(containsTerms(terms), " AND ")
+}
+
+// containsTerms devuelve cada término como condición de CONTAINS; el último admite prefijo
+func containsTerms(terms []string) []string {
+	parts := make([]string, 0, len(terms))
+	for i, t := range terms {
+		t = strings.ReplaceAll(t, `"`, "")
+		if isPrefixTerm(terms, i) {
+			parts = append(parts, `"`+t+`*"`)
+		} else {
+			parts = append(parts, `"`+t+`"`)
+		}
+	}
+	return parts
+}
+
+// isPrefixTerm indica si el término i de la consulta se busca por prefijo (el último, desde 3 caracteres)
+func isPrefixTerm(terms []string, i int) bool {
+	return i == len(terms)-1 && len(terms[i]) >= 3
+}
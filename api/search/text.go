@@ -0,0 +1,157 @@
+package search
+
+import (
+	"html"
+	"strings"
+	"unicode"
+
+	"golang.org/x/text/runes"
+	"golang.org/x/text/transform"
+	"golang.org/x/text/unicode/norm"
+)
+
+// Longitud aproximada (en runas) de los fragmentos resaltados
+const snippetLength = 160
+
+// stopWords son palabras muy frecuentes en español e inglés que no se indexan
+var stopWords = map[string]bool{
+	"de": true, "la": true, "el": true, "en": true, "y": true, "a": true, "los": true, "las": true,
+	"un": true, "una": true, "que": true, "por": true, "con": true, "para": true, "del": true, "al": true,
+	"lo": true, "se": true, "es": true, "mi": true, "me": true, "su": true, "o": true,
+	"the": true, "and": true, "of": true, "to": true, "in": true, "is": true, "for": true, "on": true,
+	"an": true, "or": true, "my": true, "it": true,
+}
+
+// normalize pasa el texto a minúsculas y elimina acentos y diacríticos
+func normalize(s string) string {
+	t := transform.Chain(norm.NFD, runes.Remove(runes.In(unicode.Mn)), norm.NFC)
+	folded, _, err := transform.String(t, s)
+	if err != nil {
+		folded = s
+	}
+	return strings.ToLower(folded)
+}
+
+// span representa la posición (en bytes) de una palabra dentro del texto original
+type span struct {
+	start int
+	end   int
+	term  string
+}
+
+// tokenSpans divide el texto en palabras normalizadas conservando su posición original
+func tokenSpans(text string) []span {
+	var spans []span
+	start := -1
+	for i, r := range text {
+		isWord := unicode.IsLetter(r) || unicode.IsDigit(r)
+		if isWord && start < 0 {
+			start = i
+		} else if !isWord && start >= 0 {
+			spans = append(spans, span{start: start, end: i, term: normalize(text[start:i])})
+			start = -1
+		}
+	}
+	if start >= 0 {
+		spans = append(spans, span{start: start, end: len(text), term: normalize(text[start:])})
+	}
+	return spans
+}
+
+// tokenize devuelve los términos indexables de un texto
+func tokenize(text string) []string {
+	var terms []string
+	for _, s := range tokenSpans(text) {
+		if len([]rune(s.term)) < 2 || stopWords[s.term] {
+			continue
+		}
+		terms = append(terms, s.term)
+	}
+	return terms
+}
+
+// Tokenize devuelve los términos normalizados (sin acentos ni palabras vacías) de un texto
+func Tokenize(text string) []string {
+	return tokenize(text)
+}
+
+// matchesTerm indica si un término del documento coincide con un término de la consulta.
+// El último término de la consulta se compara por prefijo para permitir búsquedas mientras se escribe.
+func matchesTerm(docTerm, queryTerm string, prefix bool) bool {
+	if prefix {
+		return strings.HasPrefix(docTerm, queryTerm)
+	}
+	return docTerm == queryTerm
+}
+
+// Highlight devuelve un fragmento del texto alrededor de la primera coincidencia, escapado
+// como HTML y con los términos encontrados envueltos en <mark>.
+func Highlight(text string, queryTerms []string) string {
+	spans := tokenSpans(text)
+
+	matched := make([]bool, len(spans))
+	first := -1
+	for i, s := range spans {
+		for j, q := range queryTerms {
+			if matchesTerm(s.term, q, j == len(queryTerms)-1 && len(q) >= 3) {
+				matched[i] = true
+				if first < 0 {
+					first = i
+				}
+				break
+			}
+		}
+	}
+
+	// Calcular la ventana del fragmento alrededor de la primera coincidencia
+	windowStart, windowEnd := 0, len(text)
+	if first >= 0 {
+		windowStart = spans[first].start
+		for back := 0; back < 40 && windowStart > 0; back++ {
+			windowStart--
+			for windowStart > 0 && !isRuneStart(text[windowStart]) {
+				windowStart--
+			}
+		}
+	}
+	if runeCount := len([]rune(text[windowStart:])); runeCount > snippetLength {
+		windowEnd = windowStart + len(string([]rune(text[windowStart:])[:snippetLength]))
+	}
+
+	var b strings.Builder
+	if windowStart > 0 {
+		b.WriteString("…")
+	}
+	cursor := windowStart
+	for i, s := range spans {
+		if !matched[i] || s.start < windowStart || s.end > windowEnd {
+			continue
+		}
+		b.WriteString(html.EscapeString(text[cursor:s.start]))
+		b.WriteString("<mark>")
+		b.WriteString(html.EscapeString(text[s.start:s.end]))
+		b.WriteString("</mark>")
+		cursor = s.end
+	}
+	b.WriteString(html.EscapeString(text[cursor:windowEnd]))
+	if windowEnd < len(text) {
+		b.WriteString("…")
+	}
+	return b.String()
+}
+
+// documentHighlight resalta el título y el cuerpo de un documento como un único fragmento
+func documentHighlight(title, body string, queryTerms []string) string {
+	if title == "" {
+		return Highlight(body, queryTerms)
+	}
+	if body == "" {
+		return Highlight(title, queryTerms)
+	}
+	return Highlight(title+" · "+body, queryTerms)
+}
+
+// isRuneStart indica si el byte es el inicio de una runa UTF-8
+func isRuneStart(b byte) bool {
+	return b&0xC0 != 0x80
+}
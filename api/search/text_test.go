@@ -0,0 +1,72 @@
+package search
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestTokenize(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		want []string
+	}{
+		{"vacío", "", nil},
+		{"minúsculas", "Guitarra ELÉCTRICA", []string{"guitarra", "electrica"}},
+		{"acentos y eñes", "Diseño gráfico en Bogotá", []string{"diseno", "grafico", "bogota"}},
+		{"palabras vacías", "clases de la guitarra y el piano", []string{"clases", "guitarra", "piano"}},
+		{"palabras vacías en inglés", "the art of cooking", []string{"art", "cooking"}},
+		{"puntuación como separador", "go,sql;python/c++", []string{"go", "sql", "python"}},
+		{"números", "Excel 2019 nivel 3", []string{"excel", "2019", "nivel"}},
+		{"solo palabras vacías", "de la y", nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tokenize(tt.text); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("tokenize(%q) = %q, se esperaba %q", tt.text, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestContainsCondition(t *testing.T) {
+	tests := []struct {
+		name  string
+		terms []string
+		want  string
+	}{
+		{"un término con prefijo", []string{"guitarra"}, `"guitarra*"`},
+		{"último término corto sin prefijo", []string{"go"}, `"go"`},
+		{"solo el último admite prefijo", []string{"clases", "guitar"}, `"clases" AND "guitar*"`},
+		{"comillas eliminadas", []string{`gui"tar`, "piano"}, `"guitar" AND "piano*"`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := containsCondition(tt.terms); got != tt.want {
+				t.Errorf("containsCondition(%q) = %s, se esperaba %s", tt.terms, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestHighlight(t *testing.T) {
+	tests := []struct {
+		name  string
+		text  string
+		terms []string
+		want  string
+	}{
+		{"término exacto", "Clases de guitarra", []string{"guitarra"}, "Clases de <mark>guitarra</mark>"},
+		{"sin acentos en la consulta", "Diseño gráfico", []string{"diseno"}, "<mark>Diseño</mark> gráfico"},
+		{"prefijo en el último término", "Guitarra eléctrica", []string{"guit"}, "<mark>Guitarra</mark> eléctrica"},
+		{"HTML escapado", "<b>piano</b>", []string{"piano"}, "&lt;b&gt;<mark>piano</mark>&lt;/b&gt;"},
+		{"sin coincidencias", "Clases de piano", []string{"violin"}, "Clases de piano"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Highlight(tt.text, tt.terms); got != tt.want {
+				t.Errorf("Highlight(%q) = %q, se esperaba %q", tt.text, got, tt.want)
+			}
+		})
+	}
+}
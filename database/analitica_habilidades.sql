@@ -0,0 +1,37 @@
+-- Script para la analítica de oferta y demanda de habilidades
+-- SkillSwap - Fecha de alta de las habilidades de usuario para las tendencias e índices de los reportes
+
+USE [SkillSwapDB];
+GO
+
+-- Fecha en la que el usuario declaró la habilidad. Los registros anteriores quedan en NULL
+-- y no aparecen en las tendencias por mes.
+IF COL_LENGTH('dbo.UsuariosHabilidades', 'FechaCreacion') IS NULL
+BEGIN
+    ALTER TABLE [dbo].[UsuariosHabilidades] ADD [FechaCreacion] DATETIME NULL
+        CONSTRAINT [DF_UsuariosHabilidades_FechaCreacion] DEFAULT GETDATE();
+    PRINT 'Columna UsuariosHabilidades.FechaCreacion agregada.';
+END
+GO
+
+-- Índices para los conteos por habilidad y tipo, y por fecha
+IF NOT EXISTS (SELECT * FROM sys.indexes WHERE name = 'IX_UsuariosHabilidades_Habilidad_Tipo')
+BEGIN
+    CREATE INDEX [IX_UsuariosHabilidades_Habilidad_Tipo] ON [dbo].[UsuariosHabilidades] ([HabilidadID], [TipoHabilidad]) INCLUDE ([UsuarioID]);
+    PRINT 'Índice IX_UsuariosHabilidades_Habilidad_Tipo creado.';
+END
+GO
+
+IF NOT EXISTS (SELECT * FROM sys.indexes WHERE name = 'IX_UsuariosHabilidades_FechaCreacion')
+BEGIN
+    CREATE INDEX [IX_UsuariosHabilidades_FechaCreacion] ON [dbo].[UsuariosHabilidades] ([FechaCreacion]) INCLUDE ([HabilidadID], [TipoHabilidad]);
+    PRINT 'Índice IX_UsuariosHabilidades_FechaCreacion creado.';
+END
+GO
+
+IF NOT EXISTS (SELECT * FROM sys.indexes WHERE name = 'IX_Emparejamientos_FechaCreacion')
+BEGIN
+    CREATE INDEX [IX_Emparejamientos_FechaCreacion] ON [dbo].[Emparejamientos] ([FechaCreacion]) INCLUDE ([Habilidad1ID], [Habilidad2ID], [EstadoEmparejamiento]);
+    PRINT 'Índice IX_Emparejamientos_FechaCreacion creado.';
+END
+GO
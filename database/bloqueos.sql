@@ -0,0 +1,26 @@
+-- Tabla de bloqueos entre usuarios para SkillSwap
+-- Un usuario bloqueado no puede contactar al usuario que lo bloqueó
+
+IF NOT EXISTS (SELECT * FROM INFORMATION_SCHEMA.TABLES WHERE TABLE_NAME = 'BloqueosUsuarios')
+BEGIN
+    CREATE TABLE BloqueosUsuarios (
+        BloqueoID INT IDENTITY(1,1) PRIMARY KEY,
+        UsuarioID INT NOT NULL,      -- Usuario que bloquea
+        BloqueadoID INT NOT NULL,    -- Usuario bloqueado
+        FechaCreacion DATETIME DEFAULT GETDATE(),
+        CONSTRAINT FK_Bloqueos_Usuario FOREIGN KEY (UsuarioID) REFERENCES Usuarios(UsuarioID),
+        CONSTRAINT FK_Bloqueos_Bloqueado FOREIGN KEY (BloqueadoID) REFERENCES Usuarios(UsuarioID),
+        CONSTRAINT CK_Bloqueos_DistintoUsuario CHECK (UsuarioID <> BloqueadoID),
+        CONSTRAINT UQ_Bloqueos_Par UNIQUE (UsuarioID, BloqueadoID)
+    );
+
+    -- Índices para las consultas en ambas direcciones
+    CREATE INDEX idx_bloqueos_usuario ON BloqueosUsuarios(UsuarioID);
+    CREATE INDEX idx_bloqueos_bloqueado ON BloqueosUsuarios(BloqueadoID);
+
+    PRINT 'Tabla BloqueosUsuarios creada correctamente';
+END
+ELSE
+BEGIN
+    PRINT 'La tabla BloqueosUsuarios ya existe';
+END
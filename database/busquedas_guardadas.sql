@@ -0,0 +1,71 @@
+-- Script para las búsquedas guardadas y sus alertas
+-- SkillSwap - Avisar cuando se publican posts que coinciden con una búsqueda guardada
+
+USE [SkillSwapDB];
+GO
+
+-- Búsquedas guardadas por usuario (los criterios vacíos no filtran)
+IF NOT EXISTS (SELECT * FROM sysobjects WHERE name='BusquedasGuardadas' AND xtype='U')
+BEGIN
+    CREATE TABLE [dbo].[BusquedasGuardadas] (
+        [BusquedaID] INT IDENTITY(1,1) PRIMARY KEY,
+        [UsuarioID] INT NOT NULL,
+        [Nombre] NVARCHAR(100) NOT NULL,
+        [HabilidadID] INT NULL,
+        [Categoria] NVARCHAR(50) NULL,
+        [TipoPost] NVARCHAR(20) NULL,
+        [Ciudad] NVARCHAR(100) NULL,
+        [PalabrasClave] NVARCHAR(200) NULL,
+        [Frecuencia] NVARCHAR(20) NOT NULL DEFAULT 'instant',
+        [Activa] BIT NOT NULL DEFAULT 1,
+        [FechaCreacion] DATETIME NOT NULL DEFAULT GETDATE(),
+        [UltimoResumen] DATETIME NULL,
+
+        -- Constraints
+        CONSTRAINT [FK_BusquedasGuardadas_Usuario] FOREIGN KEY ([UsuarioID])
+            REFERENCES [dbo].[Usuarios]([UsuarioID]) ON DELETE CASCADE,
+        CONSTRAINT [FK_BusquedasGuardadas_Habilidad] FOREIGN KEY ([HabilidadID])
+            REFERENCES [dbo].[Habilidades]([HabilidadID]) ON DELETE NO ACTION,
+        CONSTRAINT [CK_BusquedasGuardadas_Frecuencia] CHECK ([Frecuencia] IN ('instant', 'daily'))
+    );
+
+    -- Índices
+    CREATE INDEX [IX_BusquedasGuardadas_Usuario] ON [dbo].[BusquedasGuardadas] ([UsuarioID]);
+    CREATE INDEX [IX_BusquedasGuardadas_Activa_Habilidad] ON [dbo].[BusquedasGuardadas] ([Activa], [HabilidadID]);
+
+    PRINT 'Tabla BusquedasGuardadas creada exitosamente.';
+END
+ELSE
+BEGIN
+    PRINT 'La tabla BusquedasGuardadas ya existe.';
+END
+GO
+
+-- Posts que coincidieron con una búsqueda; FechaNotificacion es NULL mientras esperan el resumen
+IF NOT EXISTS (SELECT * FROM sysobjects WHERE name='CoincidenciasBusqueda' AND xtype='U')
+BEGIN
+    CREATE TABLE [dbo].[CoincidenciasBusqueda] (
+        [CoincidenciaID] INT IDENTITY(1,1) PRIMARY KEY,
+        [BusquedaID] INT NOT NULL,
+        [PostID] INT NOT NULL,
+        [FechaCreacion] DATETIME NOT NULL DEFAULT GETDATE(),
+        [FechaNotificacion] DATETIME NULL,
+
+        -- Constraints
+        CONSTRAINT [FK_CoincidenciasBusqueda_Busqueda] FOREIGN KEY ([BusquedaID])
+            REFERENCES [dbo].[BusquedasGuardadas]([BusquedaID]) ON DELETE CASCADE,
+        CONSTRAINT [FK_CoincidenciasBusqueda_Post] FOREIGN KEY ([PostID])
+            REFERENCES [dbo].[Posts]([PostID]) ON DELETE CASCADE
+    );
+
+    -- Índices
+    CREATE UNIQUE INDEX [IX_CoincidenciasBusqueda_Busqueda_Post] ON [dbo].[CoincidenciasBusqueda] ([BusquedaID], [PostID]);
+    CREATE INDEX [IX_CoincidenciasBusqueda_Pendientes] ON [dbo].[CoincidenciasBusqueda] ([FechaNotificacion], [BusquedaID]);
+
+    PRINT 'Tabla CoincidenciasBusqueda creada exitosamente.';
+END
+ELSE
+BEGIN
+    PRINT 'La tabla CoincidenciasBusqueda ya existe.';
+END
+GO
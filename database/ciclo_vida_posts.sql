@@ -0,0 +1,40 @@
+-- Script para el ciclo de vida de los posts
+-- SkillSwap - Estado (open, paused, fulfilled, expired), expiración y cumplimiento
+
+USE [SkillSwapDB];
+GO
+
+-- Estado del post; los posts existentes quedan abiertos
+IF COL_LENGTH('dbo.Posts', 'Estado') IS NULL
+BEGIN
+    ALTER TABLE [dbo].[Posts] ADD [Estado] NVARCHAR(20) NOT NULL CONSTRAINT [DF_Posts_Estado] DEFAULT 'open'
+        CONSTRAINT [CK_Posts_Estado] CHECK ([Estado] IN ('open', 'paused', 'fulfilled', 'expired'));
+    PRINT 'Columna Posts.Estado agregada.';
+END
+GO
+
+-- Fecha a partir de la cual el proceso de expiración cierra el post (NULL = no expira)
+IF COL_LENGTH('dbo.Posts', 'ExpiresAt') IS NULL
+BEGIN
+    ALTER TABLE [dbo].[Posts] ADD [ExpiresAt] DATETIME NULL;
+    PRINT 'Columna Posts.ExpiresAt agregada.';
+END
+GO
+
+-- Emparejamiento con el que el autor dio por cumplido el post
+IF COL_LENGTH('dbo.Posts', 'EmparejamientoID') IS NULL
+BEGIN
+    ALTER TABLE [dbo].[Posts] ADD [EmparejamientoID] INT NULL
+        CONSTRAINT [FK_Posts_Emparejamiento] FOREIGN KEY REFERENCES [dbo].[Emparejamientos]([EmparejamientoID]),
+        [CumplidoEn] DATETIME NULL;
+    PRINT 'Columnas Posts.EmparejamientoID y CumplidoEn agregadas.';
+END
+GO
+
+-- Índice para los listados por estado y para el proceso de expiración
+IF NOT EXISTS (SELECT * FROM sys.indexes WHERE name = 'IX_Posts_Estado_ExpiresAt')
+BEGIN
+    CREATE INDEX [IX_Posts_Estado_ExpiresAt] ON [dbo].[Posts] ([Estado], [ExpiresAt]);
+    PRINT 'Índice IX_Posts_Estado_ExpiresAt creado.';
+END
+GO
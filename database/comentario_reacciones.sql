@@ -0,0 +1,44 @@
+-- Script para las reacciones a comentarios y la respuesta aceptada de un post
+-- SkillSwap - Reacciones (útil, interesante, gracias) y "marcar como respuesta"
+
+USE [SkillSwapDB];
+GO
+
+-- Reacciones a comentarios (una fila por comentario, usuario y tipo). Son independientes
+-- de los likes/dislikes de ComentarioLikes, que se siguen usando para la puntuación.
+IF NOT EXISTS (SELECT * FROM sysobjects WHERE name='ComentarioReacciones' AND xtype='U')
+BEGIN
+    CREATE TABLE [dbo].[ComentarioReacciones] (
+        [ReaccionID] INT IDENTITY(1,1) PRIMARY KEY,
+        [ComentarioID] INT NOT NULL,
+        [UsuarioID] INT NOT NULL,
+        [Tipo] NVARCHAR(20) NOT NULL,
+        [CreatedAt] DATETIME NOT NULL DEFAULT GETDATE(),
+
+        -- Constraints
+        CONSTRAINT [FK_ComentarioReacciones_Comentario] FOREIGN KEY ([ComentarioID])
+            REFERENCES [dbo].[Comentarios]([ComentarioID]) ON DELETE CASCADE,
+        CONSTRAINT [FK_ComentarioReacciones_Usuario] FOREIGN KEY ([UsuarioID])
+            REFERENCES [dbo].[Usuarios]([UsuarioID]) ON DELETE NO ACTION,
+        CONSTRAINT [CK_ComentarioReacciones_Tipo] CHECK ([Tipo] IN ('helpful', 'insightful', 'thanks'))
+    );
+
+    -- Índices
+    CREATE UNIQUE INDEX [IX_ComentarioReacciones_Comentario_Usuario_Tipo] ON [dbo].[ComentarioReacciones] ([ComentarioID], [UsuarioID], [Tipo]);
+
+    PRINT 'Tabla ComentarioReacciones creada exitosamente.';
+END
+ELSE
+BEGIN
+    PRINT 'La tabla ComentarioReacciones ya existe.';
+END
+GO
+
+-- Comentario marcado por el autor del post como la respuesta que resolvió su solicitud.
+-- Sin clave foránea: los comentarios se eliminan lógicamente y la API limpia la marca.
+IF COL_LENGTH('dbo.Posts', 'RespuestaComentarioID') IS NULL
+BEGIN
+    ALTER TABLE [dbo].[Posts] ADD [RespuestaComentarioID] INT NULL;
+    PRINT 'Columna Posts.RespuestaComentarioID agregada.';
+END
+GO
@@ -0,0 +1,95 @@
+-- Script para el filtro automático de contenido
+-- SkillSwap - Los reportes pueden venir de usuarios o del filtro (sin reportante)
+
+USE [SkillSwapDB];
+GO
+
+IF COL_LENGTH('dbo.Reportes', 'Origen') IS NULL
+BEGIN
+    ALTER TABLE [dbo].[Reportes] ADD [Origen] NVARCHAR(20) NOT NULL CONSTRAINT [DF_Reportes_Origen] DEFAULT 'user';
+    PRINT 'Columna Reportes.Origen agregada.';
+END
+GO
+
+-- El filtro crea reportes sin reportante, por lo que la unicidad solo aplica a reportes de usuarios
+IF EXISTS (SELECT * FROM sys.indexes WHERE name = 'IX_Reportes_Contenido_Reportante' AND object_id = OBJECT_ID('dbo.Reportes'))
+BEGIN
+    DROP INDEX [IX_Reportes_Contenido_Reportante] ON [dbo].[Reportes];
+END
+GO
+
+ALTER TABLE [dbo].[Reportes] ALTER COLUMN [ReportanteID] INT NULL;
+GO
+
+CREATE UNIQUE INDEX [IX_Reportes_Contenido_Reportante] ON [dbo].[Reportes] ([TipoContenido], [ContenidoID], [ReportanteID])
+    WHERE [ReportanteID] IS NOT NULL;
+GO
+
+IF NOT EXISTS (SELECT * FROM sys.check_constraints WHERE name = 'CK_Reportes_Origen')
+BEGIN
+    ALTER TABLE [dbo].[Reportes] ADD CONSTRAINT [CK_Reportes_Origen] CHECK ([Origen] IN ('user', 'filter'));
+    PRINT 'Restricción CK_Reportes_Origen agregada.';
+END
+GO
+
+-- Los comentarios retenidos u ocultos por moderación se marcan aparte de Activo, que indica el borrado
+IF COL_LENGTH('dbo.Comentarios', 'Oculto') IS NULL
+BEGIN
+    ALTER TABLE [dbo].[Comentarios] ADD [Oculto] BIT NOT NULL CONSTRAINT [DF_Comentarios_Oculto] DEFAULT 0;
+    PRINT 'Columna Comentarios.Oculto agregada.';
+END
+GO
+
+-- Hasta ahora la moderación ocultaba comentarios con Activo = 0: los que tienen una acción de
+-- moderación pasan a Oculto para que restaurarlos no reviva comentarios borrados por su autor
+UPDATE c SET c.Activo = 1, c.Oculto = 1
+FROM [dbo].[Comentarios] c
+WHERE c.Activo = 0 AND EXISTS (
+    SELECT 1 FROM [dbo].[AccionesModeracion] a
+    WHERE a.TipoContenido = 'comment' AND a.ContenidoID = c.ComentarioID AND a.Accion IN ('hide', 'auto_hide', 'filter_hold')
+);
+GO
+
+-- La vista de comentarios excluye también los ocultos por moderación
+ALTER VIEW vw_ComentariosCompletos AS
+SELECT
+    c.ComentarioID,
+    c.PostID,
+    c.UsuarioID,
+    c.ComentarioPadreID,
+    c.Contenido,
+    c.ContenidoHTML,
+    c.CreatedAt,
+    c.UpdatedAt,
+    c.Activo,
+    u.NombreUsuario,
+    u.PrimerNombre,
+    u.Apellido,
+    u.CorreoElectronico,
+    -- Conteo de likes y dislikes
+    ISNULL(likes.total_likes, 0) AS TotalLikes,
+    ISNULL(dislikes.total_dislikes, 0) AS TotalDislikes,
+    -- Conteo de respuestas
+    ISNULL(respuestas.total_respuestas, 0) AS TotalRespuestas
+FROM Comentarios c
+JOIN Usuarios u ON c.UsuarioID = u.UsuarioID
+LEFT JOIN (
+    SELECT ComentarioID, COUNT(*) as total_likes
+    FROM ComentarioLikes
+    WHERE TipoVoto = 'like'
+    GROUP BY ComentarioID
+) likes ON c.ComentarioID = likes.ComentarioID
+LEFT JOIN (
+    SELECT ComentarioID, COUNT(*) as total_dislikes
+    FROM ComentarioLikes
+    WHERE TipoVoto = 'dislike'
+    GROUP BY ComentarioID
+) dislikes ON c.ComentarioID = dislikes.ComentarioID
+LEFT JOIN (
+    SELECT ComentarioPadreID, COUNT(*) as total_respuestas
+    FROM Comentarios
+    WHERE ComentarioPadreID IS NOT NULL AND Activo = 1 AND Oculto = 0
+    GROUP BY ComentarioPadreID
+) respuestas ON c.ComentarioID = respuestas.ComentarioPadreID
+WHERE c.Activo = 1 AND c.Oculto = 0;
+GO
@@ -0,0 +1,64 @@
+-- Script para habilitar el backend de búsqueda full-text de SQL Server
+-- SkillSwap - Búsqueda (usar con SEARCH_BACKEND=sqlserver)
+
+USE [SkillSwapDB];
+GO
+
+-- Catálogo full-text insensible a acentos
+IF NOT EXISTS (SELECT * FROM sys.fulltext_catalogs WHERE name = 'SkillSwapCatalog')
+BEGIN
+    CREATE FULLTEXT CATALOG [SkillSwapCatalog] WITH ACCENT_SENSITIVITY = OFF;
+    PRINT 'Catálogo SkillSwapCatalog creado exitosamente.';
+END
+GO
+
+-- Índices únicos de una sola columna requeridos como KEY INDEX
+IF NOT EXISTS (SELECT * FROM sys.indexes WHERE name = 'UX_Posts_PostID')
+    CREATE UNIQUE INDEX [UX_Posts_PostID] ON [dbo].[Posts] ([PostID]);
+IF NOT EXISTS (SELECT * FROM sys.indexes WHERE name = 'UX_Comentarios_ComentarioID')
+    CREATE UNIQUE INDEX [UX_Comentarios_ComentarioID] ON [dbo].[Comentarios] ([ComentarioID]);
+IF NOT EXISTS (SELECT * FROM sys.indexes WHERE name = 'UX_Messages_ID')
+    CREATE UNIQUE INDEX [UX_Messages_ID] ON [dbo].[Messages] ([ID]);
+IF NOT EXISTS (SELECT * FROM sys.indexes WHERE name = 'UX_Usuarios_UsuarioID')
+    CREATE UNIQUE INDEX [UX_Usuarios_UsuarioID] ON [dbo].[Usuarios] ([UsuarioID]);
+GO
+
+-- Índices full-text (LANGUAGE 3082 = español) con seguimiento automático de cambios
+IF NOT EXISTS (SELECT * FROM sys.fulltext_indexes WHERE object_id = OBJECT_ID('dbo.Posts'))
+BEGIN
+    CREATE FULLTEXT INDEX ON [dbo].[Posts] ([Descripcion] LANGUAGE 3082)
+        KEY INDEX [UX_Posts_PostID] ON [SkillSwapCatalog] WITH CHANGE_TRACKING AUTO;
+    PRINT 'Índice full-text de Posts creado exitosamente.';
+END
+GO
+
+IF NOT EXISTS (SELECT * FROM sys.fulltext_indexes WHERE object_id = OBJECT_ID('dbo.Comentarios'))
+BEGIN
+    CREATE FULLTEXT INDEX ON [dbo].[Comentarios] ([Contenido] LANGUAGE 3082)
+        KEY INDEX [UX_Comentarios_ComentarioID] ON [SkillSwapCatalog] WITH CHANGE_TRACKING AUTO;
+    PRINT 'Índice full-text de Comentarios creado exitosamente.';
+END
+GO
+
+IF NOT EXISTS (SELECT * FROM sys.fulltext_indexes WHERE object_id = OBJECT_ID('dbo.Messages'))
+BEGIN
+    CREATE FULLTEXT INDEX ON [dbo].[Messages] ([Content] LANGUAGE 3082)
+        KEY INDEX [UX_Messages_ID] ON [SkillSwapCatalog] WITH CHANGE_TRACKING AUTO;
+    PRINT 'Índice full-text de Messages creado exitosamente.';
+END
+GO
+
+IF NOT EXISTS (SELECT * FROM sys.fulltext_indexes WHERE object_id = OBJECT_ID('dbo.Usuarios'))
+BEGIN
+    CREATE FULLTEXT INDEX ON [dbo].[Usuarios] (
+        [NombreUsuario] LANGUAGE 3082,
+        [PrimerNombre] LANGUAGE 3082,
+        [SegundoNombre] LANGUAGE 3082,
+        [PrimerApellido] LANGUAGE 3082,
+        [SegundoApellido] LANGUAGE 3082,
+        [CiudadTrabajo] LANGUAGE 3082
+    )
+        KEY INDEX [UX_Usuarios_UsuarioID] ON [SkillSwapCatalog] WITH CHANGE_TRACKING AUTO;
+    PRINT 'Índice full-text de Usuarios creado exitosamente.';
+END
+GO
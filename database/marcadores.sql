@@ -0,0 +1,34 @@
+-- Script para los marcadores (posts y perfiles guardados)
+-- SkillSwap - Guardar posts interesantes o usuarios para más tarde
+
+USE [SkillSwapDB];
+GO
+
+-- Marcadores de cada usuario. ElementoID apunta a Posts o a Usuarios según TipoElemento,
+-- por eso no tiene clave foránea: la limpieza se hace al eliminar el post o el usuario.
+IF NOT EXISTS (SELECT * FROM sysobjects WHERE name='Marcadores' AND xtype='U')
+BEGIN
+    CREATE TABLE [dbo].[Marcadores] (
+        [MarcadorID] INT IDENTITY(1,1) PRIMARY KEY,
+        [UsuarioID] INT NOT NULL,
+        [TipoElemento] NVARCHAR(10) NOT NULL,
+        [ElementoID] INT NOT NULL,
+        [FechaCreacion] DATETIME NOT NULL DEFAULT GETDATE(),
+
+        -- Constraints
+        CONSTRAINT [FK_Marcadores_Usuario] FOREIGN KEY ([UsuarioID])
+            REFERENCES [dbo].[Usuarios]([UsuarioID]) ON DELETE CASCADE,
+        CONSTRAINT [CK_Marcadores_TipoElemento] CHECK ([TipoElemento] IN ('post', 'user'))
+    );
+
+    -- Índices: un marcador por elemento y usuario, y conteo por elemento para los autores
+    CREATE UNIQUE INDEX [IX_Marcadores_Usuario_Elemento] ON [dbo].[Marcadores] ([UsuarioID], [TipoElemento], [ElementoID]);
+    CREATE INDEX [IX_Marcadores_Elemento] ON [dbo].[Marcadores] ([TipoElemento], [ElementoID]);
+
+    PRINT 'Tabla Marcadores creada exitosamente.';
+END
+ELSE
+BEGIN
+    PRINT 'La tabla Marcadores ya existe.';
+END
+GO
@@ -0,0 +1,71 @@
+-- Script para guardar el HTML renderizado (Markdown saneado) junto al texto fuente
+-- SkillSwap - Posts, comentarios y mensajes
+
+USE [SkillSwapDB];
+GO
+
+IF COL_LENGTH('dbo.Posts', 'DescripcionHTML') IS NULL
+BEGIN
+    ALTER TABLE [dbo].[Posts] ADD [DescripcionHTML] NVARCHAR(MAX) NULL;
+    PRINT 'Columna Posts.DescripcionHTML agregada.';
+END
+GO
+
+IF COL_LENGTH('dbo.Comentarios', 'ContenidoHTML') IS NULL
+BEGIN
+    ALTER TABLE [dbo].[Comentarios] ADD [ContenidoHTML] NVARCHAR(MAX) NULL;
+    PRINT 'Columna Comentarios.ContenidoHTML agregada.';
+END
+GO
+
+IF COL_LENGTH('dbo.Messages', 'ContentHTML') IS NULL
+BEGIN
+    ALTER TABLE [dbo].[Messages] ADD [ContentHTML] NVARCHAR(MAX) NULL;
+    PRINT 'Columna Messages.ContentHTML agregada.';
+END
+GO
+
+-- La vista de comentarios expone también el HTML renderizado.
+-- Los registros existentes quedan con NULL y la API los renderiza al leerlos.
+ALTER VIEW vw_ComentariosCompletos AS
+SELECT
+    c.ComentarioID,
+    c.PostID,
+    c.UsuarioID,
+    c.ComentarioPadreID,
+    c.Contenido,
+    c.ContenidoHTML,
+    c.CreatedAt,
+    c.UpdatedAt,
+    c.Activo,
+    u.NombreUsuario,
+    u.PrimerNombre,
+    u.Apellido,
+    u.CorreoElectronico,
+    -- Conteo de likes y dislikes
+    ISNULL(likes.total_likes, 0) AS TotalLikes,
+    ISNULL(dislikes.total_dislikes, 0) AS TotalDislikes,
+    -- Conteo de respuestas
+    ISNULL(respuestas.total_respuestas, 0) AS TotalRespuestas
+FROM Comentarios c
+JOIN Usuarios u ON c.UsuarioID = u.UsuarioID
+LEFT JOIN (
+    SELECT ComentarioID, COUNT(*) as total_likes
+    FROM ComentarioLikes
+    WHERE TipoVoto = 'like'
+    GROUP BY ComentarioID
+) likes ON c.ComentarioID = likes.ComentarioID
+LEFT JOIN (
+    SELECT ComentarioID, COUNT(*) as total_dislikes
+    FROM ComentarioLikes
+    WHERE TipoVoto = 'dislike'
+    GROUP BY ComentarioID
+) dislikes ON c.ComentarioID = dislikes.ComentarioID
+LEFT JOIN (
+    SELECT ComentarioPadreID, COUNT(*) as total_respuestas
+    FROM Comentarios
+    WHERE ComentarioPadreID IS NOT NULL AND Activo = 1
+    GROUP BY ComentarioPadreID
+) respuestas ON c.ComentarioID = respuestas.ComentarioPadreID
+WHERE c.Activo = 1;
+GO
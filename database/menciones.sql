@@ -0,0 +1,64 @@
+-- Script para crear las tablas de menciones y preferencias de notificación
+-- SkillSwap - Menciones (@NombreUsuario) en comentarios y mensajes
+
+USE [SkillSwapDB];
+GO
+
+-- Tabla de menciones (una fila por usuario mencionado en un comentario o mensaje)
+IF NOT EXISTS (SELECT * FROM sysobjects WHERE name='Menciones' AND xtype='U')
+BEGIN
+    CREATE TABLE [dbo].[Menciones] (
+        [MencionID] INT IDENTITY(1,1) PRIMARY KEY,
+        [TipoContenido] NVARCHAR(20) NOT NULL,
+        [ContenidoID] INT NOT NULL,
+        [UsuarioMencionadoID] INT NOT NULL,
+        [AutorID] INT NOT NULL,
+        [Inicio] INT NOT NULL,
+        [Longitud] INT NOT NULL,
+        [FechaCreacion] DATETIME NOT NULL DEFAULT GETDATE(),
+
+        -- Constraints
+        CONSTRAINT [CK_Menciones_TipoContenido] CHECK ([TipoContenido] IN ('comment', 'message')),
+        CONSTRAINT [FK_Menciones_UsuarioMencionado] FOREIGN KEY ([UsuarioMencionadoID])
+            REFERENCES [dbo].[Usuarios]([UsuarioID]) ON DELETE NO ACTION,
+        CONSTRAINT [FK_Menciones_Autor] FOREIGN KEY ([AutorID])
+            REFERENCES [dbo].[Usuarios]([UsuarioID]) ON DELETE NO ACTION
+    );
+
+    -- Índices
+    CREATE INDEX [IX_Menciones_Contenido] ON [dbo].[Menciones] ([TipoContenido], [ContenidoID]);
+    CREATE INDEX [IX_Menciones_UsuarioMencionado] ON [dbo].[Menciones] ([UsuarioMencionadoID]);
+
+    PRINT 'Tabla Menciones creada exitosamente.';
+END
+ELSE
+BEGIN
+    PRINT 'La tabla Menciones ya existe.';
+END
+GO
+
+-- Tabla de preferencias de notificación (sin fila = tipo habilitado)
+IF NOT EXISTS (SELECT * FROM sysobjects WHERE name='PreferenciasNotificacion' AND xtype='U')
+BEGIN
+    CREATE TABLE [dbo].[PreferenciasNotificacion] (
+        [PreferenciaID] INT IDENTITY(1,1) PRIMARY KEY,
+        [UsuarioID] INT NOT NULL,
+        [Tipo] NVARCHAR(50) NOT NULL,
+        [Habilitada] BIT NOT NULL DEFAULT 1,
+        [FechaActualizacion] DATETIME NOT NULL DEFAULT GETDATE(),
+
+        -- Constraints
+        CONSTRAINT [FK_PreferenciasNotificacion_Usuario] FOREIGN KEY ([UsuarioID])
+            REFERENCES [dbo].[Usuarios]([UsuarioID]) ON DELETE CASCADE
+    );
+
+    -- Índices
+    CREATE UNIQUE INDEX [IX_PreferenciasNotificacion_Usuario_Tipo] ON [dbo].[PreferenciasNotificacion] ([UsuarioID], [Tipo]);
+
+    PRINT 'Tabla PreferenciasNotificacion creada exitosamente.';
+END
+ELSE
+BEGIN
+    PRINT 'La tabla PreferenciasNotificacion ya existe.';
+END
+GO
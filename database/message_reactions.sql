@@ -0,0 +1,33 @@
+-- Script para crear la tabla de reacciones a mensajes
+-- SkillSwap - Sistema de Mensajería (reacciones con emoji)
+
+USE [SkillSwapDB];
+GO
+
+-- Tabla de reacciones (una fila por mensaje, usuario y emoji)
+IF NOT EXISTS (SELECT * FROM sysobjects WHERE name='MessageReactions' AND xtype='U')
+BEGIN
+    CREATE TABLE [dbo].[MessageReactions] (
+        [ID] INT IDENTITY(1,1) PRIMARY KEY,
+        [MessageID] INT NOT NULL,
+        [UserID] INT NOT NULL,
+        [Emoji] NVARCHAR(32) NOT NULL,
+        [CreatedAt] DATETIME NOT NULL DEFAULT GETDATE(),
+
+        -- Constraints
+        CONSTRAINT [FK_MessageReactions_Message] FOREIGN KEY ([MessageID])
+            REFERENCES [dbo].[Messages]([ID]) ON DELETE CASCADE,
+        CONSTRAINT [FK_MessageReactions_User] FOREIGN KEY ([UserID])
+            REFERENCES [dbo].[Usuarios]([UsuarioID]) ON DELETE NO ACTION
+    );
+
+    -- Índices
+    CREATE UNIQUE INDEX [IX_MessageReactions_Message_User_Emoji] ON [dbo].[MessageReactions] ([MessageID], [UserID], [Emoji]);
+
+    PRINT 'Tabla MessageReactions creada exitosamente.';
+END
+ELSE
+BEGIN
+    PRINT 'La tabla MessageReactions ya existe.';
+END
+GO
@@ -0,0 +1,65 @@
+-- Script para crear la tabla de confirmaciones de entrega y lectura
+-- SkillSwap - Sistema de Mensajería (acuses por participante)
+
+USE [SkillSwapDB];
+GO
+
+-- Tabla de acuses de mensajes (una fila por mensaje y destinatario)
+IF NOT EXISTS (SELECT * FROM sysobjects WHERE name='MessageReceipts' AND xtype='U')
+BEGIN
+    CREATE TABLE [dbo].[MessageReceipts] (
+        [ID] INT IDENTITY(1,1) PRIMARY KEY,
+        [MessageID] INT NOT NULL,
+        [UserID] INT NOT NULL,
+        [DeliveredAt] DATETIME NULL,
+        [ReadAt] DATETIME NULL,
+        [CreatedAt] DATETIME NOT NULL DEFAULT GETDATE(),
+        [UpdatedAt] DATETIME NOT NULL DEFAULT GETDATE(),
+
+        -- Constraints
+        CONSTRAINT [FK_MessageReceipts_Message] FOREIGN KEY ([MessageID])
+            REFERENCES [dbo].[Messages]([ID]) ON DELETE CASCADE,
+        CONSTRAINT [FK_MessageReceipts_User] FOREIGN KEY ([UserID])
+            REFERENCES [dbo].[Usuarios]([UsuarioID]) ON DELETE NO ACTION
+    );
+
+    -- Índices
+    CREATE UNIQUE INDEX [IX_MessageReceipts_Message_User] ON [dbo].[MessageReceipts] ([MessageID], [UserID]);
+    CREATE INDEX [IX_MessageReceipts_UserID_ReadAt] ON [dbo].[MessageReceipts] ([UserID], [ReadAt]);
+
+    PRINT 'Tabla MessageReceipts creada exitosamente.';
+END
+ELSE
+BEGIN
+    PRINT 'La tabla MessageReceipts ya existe.';
+END
+GO
+
+-- Poblar acuses para los mensajes existentes a partir de IsRead/ReadAt. Los destinatarios son los
+-- participantes de la conversación o, en las conversaciones antiguas sin participantes, User1ID/User2ID
+-- (igual que conversationParticipantIDs en la API)
+INSERT INTO [dbo].[MessageReceipts] ([MessageID], [UserID], [DeliveredAt], [ReadAt])
+SELECT m.[ID], d.[UserID],
+       CASE WHEN m.[IsRead] = 1 THEN COALESCE(m.[ReadAt], m.[CreatedAt]) END,
+       CASE WHEN m.[IsRead] = 1 THEN COALESCE(m.[ReadAt], m.[CreatedAt]) END
+FROM [dbo].[Messages] m
+INNER JOIN [dbo].[Conversations] c ON c.[ID] = m.[ConversationID]
+CROSS APPLY (
+    SELECT cp.[UserID]
+    FROM [dbo].[ConversationParticipants] cp
+    WHERE cp.[ConversationID] = m.[ConversationID] AND cp.[DeletedAt] IS NULL
+    UNION
+    SELECT u.[UserID]
+    FROM (VALUES (c.[User1ID]), (c.[User2ID])) u([UserID])
+    WHERE NOT EXISTS (
+        SELECT 1 FROM [dbo].[ConversationParticipants] cp
+        WHERE cp.[ConversationID] = m.[ConversationID] AND cp.[DeletedAt] IS NULL
+    )
+) d
+WHERE d.[UserID] != m.[SenderID]
+  AND NOT EXISTS (
+      SELECT 1 FROM [dbo].[MessageReceipts] r
+      WHERE r.[MessageID] = m.[ID] AND r.[UserID] = d.[UserID]
+  );
+PRINT 'Acuses de mensajes existentes generados.';
+GO
@@ -0,0 +1,26 @@
+-- Script para registrar los posts eliminados
+-- SkillSwap - El feed Atom usa la fecha de eliminación para su Last-Modified
+
+USE [SkillSwapDB];
+GO
+
+-- Un registro por post eliminado con los datos por los que se filtra el feed. Sin clave foránea:
+-- el post ya no existe.
+IF NOT EXISTS (SELECT * FROM sysobjects WHERE name='PostsEliminados' AND xtype='U')
+BEGIN
+    CREATE TABLE [dbo].[PostsEliminados] (
+        [PostID] INT NOT NULL PRIMARY KEY,
+        [HabilidadID] INT NOT NULL,
+        [TipoPost] NVARCHAR(20) NULL,
+        [FechaEliminacion] DATETIME NOT NULL DEFAULT GETDATE()
+    );
+
+    CREATE INDEX [IX_PostsEliminados_Fecha] ON [dbo].[PostsEliminados] ([FechaEliminacion] DESC);
+
+    PRINT 'Tabla PostsEliminados creada exitosamente.';
+END
+ELSE
+BEGIN
+    PRINT 'La tabla PostsEliminados ya existe.';
+END
+GO
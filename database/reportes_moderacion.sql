@@ -0,0 +1,94 @@
+-- Script para reportes de contenido y cola de moderación
+-- SkillSwap - Moderación de posts, comentarios, mensajes y usuarios
+
+USE [SkillSwapDB];
+GO
+
+-- Reportes de usuarios sobre contenido. Un usuario solo puede reportar una vez el mismo contenido,
+-- por lo que el número de filas por contenido equivale a reportantes distintos.
+IF NOT EXISTS (SELECT * FROM sysobjects WHERE name='Reportes' AND xtype='U')
+BEGIN
+    CREATE TABLE [dbo].[Reportes] (
+        [ReporteID] INT IDENTITY(1,1) PRIMARY KEY,
+        [TipoContenido] NVARCHAR(20) NOT NULL,   -- post, comment, message, user
+        [ContenidoID] INT NOT NULL,
+        [UsuarioReportadoID] INT NULL,           -- Autor del contenido (o el propio usuario reportado)
+        [ReportanteID] INT NOT NULL,
+        [Motivo] NVARCHAR(30) NOT NULL,
+        [Descripcion] NVARCHAR(1000) NULL,
+        [Estado] NVARCHAR(20) NOT NULL DEFAULT 'pending',  -- pending, resolved, dismissed
+        [Accion] NVARCHAR(20) NULL,
+        [NotaResolucion] NVARCHAR(1000) NULL,
+        [ModeradorID] INT NULL,
+        [FechaCreacion] DATETIME NOT NULL DEFAULT GETDATE(),
+        [FechaResolucion] DATETIME NULL,
+
+        -- Constraints
+        CONSTRAINT [FK_Reportes_Reportante] FOREIGN KEY ([ReportanteID])
+            REFERENCES [dbo].[Usuarios]([UsuarioID]) ON DELETE NO ACTION,
+        CONSTRAINT [FK_Reportes_Moderador] FOREIGN KEY ([ModeradorID])
+            REFERENCES [dbo].[Usuarios]([UsuarioID]) ON DELETE NO ACTION,
+        CONSTRAINT [CK_Reportes_TipoContenido] CHECK ([TipoContenido] IN ('post', 'comment', 'message', 'user')),
+        CONSTRAINT [CK_Reportes_Estado] CHECK ([Estado] IN ('pending', 'resolved', 'dismissed'))
+    );
+
+    -- Índices
+    CREATE UNIQUE INDEX [IX_Reportes_Contenido_Reportante] ON [dbo].[Reportes] ([TipoContenido], [ContenidoID], [ReportanteID]);
+    CREATE INDEX [IX_Reportes_Estado_Fecha] ON [dbo].[Reportes] ([Estado], [FechaCreacion] DESC);
+
+    PRINT 'Tabla Reportes creada exitosamente.';
+END
+ELSE
+BEGIN
+    PRINT 'La tabla Reportes ya existe.';
+END
+GO
+
+-- Registro de auditoría de todas las acciones de moderación (incluido el ocultamiento automático)
+IF NOT EXISTS (SELECT * FROM sysobjects WHERE name='AccionesModeracion' AND xtype='U')
+BEGIN
+    CREATE TABLE [dbo].[AccionesModeracion] (
+        [AccionModeracionID] INT IDENTITY(1,1) PRIMARY KEY,
+        [ModeradorID] INT NULL,                  -- NULL cuando la acción es automática
+        [Accion] NVARCHAR(20) NOT NULL,          -- dismiss, hide, warn, suspend, ban, auto_hide
+        [TipoContenido] NVARCHAR(20) NOT NULL,
+        [ContenidoID] INT NOT NULL,
+        [UsuarioAfectadoID] INT NULL,
+        [ReporteID] INT NULL,
+        [Nota] NVARCHAR(1000) NULL,
+        [FechaCreacion] DATETIME NOT NULL DEFAULT GETDATE(),
+
+        -- Constraints
+        CONSTRAINT [FK_AccionesModeracion_Moderador] FOREIGN KEY ([ModeradorID])
+            REFERENCES [dbo].[Usuarios]([UsuarioID]) ON DELETE NO ACTION,
+        CONSTRAINT [FK_AccionesModeracion_Reporte] FOREIGN KEY ([ReporteID])
+            REFERENCES [dbo].[Reportes]([ReporteID]) ON DELETE NO ACTION
+    );
+
+    -- Índices
+    CREATE INDEX [IX_AccionesModeracion_Contenido] ON [dbo].[AccionesModeracion] ([TipoContenido], [ContenidoID]);
+    CREATE INDEX [IX_AccionesModeracion_Fecha] ON [dbo].[AccionesModeracion] ([FechaCreacion] DESC);
+
+    PRINT 'Tabla AccionesModeracion creada exitosamente.';
+END
+ELSE
+BEGIN
+    PRINT 'La tabla AccionesModeracion ya existe.';
+END
+GO
+
+-- Posts ocultos por moderación (los comentarios usan Activo y los mensajes DeletedAt)
+IF COL_LENGTH('dbo.Posts', 'Oculto') IS NULL
+BEGIN
+    ALTER TABLE [dbo].[Posts] ADD [Oculto] BIT NOT NULL CONSTRAINT [DF_Posts_Oculto] DEFAULT 0;
+    PRINT 'Columna Posts.Oculto agregada.';
+END
+GO
+
+-- Suspensiones temporales de cuentas
+IF COL_LENGTH('dbo.Usuarios', 'SuspendidoHasta') IS NULL
+BEGIN
+    ALTER TABLE [dbo].[Usuarios] ADD [SuspendidoHasta] DATETIME NULL;
+    PRINT 'Columna Usuarios.SuspendidoHasta agregada.';
+END
+GO
@@ -0,0 +1,40 @@
+-- Script para los respaldos de habilidades entre usuarios
+-- SkillSwap - Quien completó una sesión con otro usuario puede respaldar las habilidades que ofrece
+
+USE [SkillSwapDB];
+GO
+
+-- Un respaldo por habilidad ofrecida (UsuariosHabilidades) y por usuario que respalda.
+-- EmparejamientoID registra el match con la sesión completada que habilitó el respaldo.
+-- RespaldanteID no elimina en cascada (SQL Server no admite dos rutas de cascada desde
+-- Usuarios): los respaldos dados se eliminan al eliminar al usuario.
+IF NOT EXISTS (SELECT * FROM sysobjects WHERE name='RespaldosHabilidad' AND xtype='U')
+BEGIN
+    CREATE TABLE [dbo].[RespaldosHabilidad] (
+        [RespaldoID] INT IDENTITY(1,1) PRIMARY KEY,
+        [UsuarioHabilidadID] INT NOT NULL,
+        [RespaldanteID] INT NOT NULL,
+        [EmparejamientoID] INT NULL,
+        [Nota] NVARCHAR(280) NULL,
+        [FechaCreacion] DATETIME NOT NULL DEFAULT GETDATE(),
+
+        -- Constraints
+        CONSTRAINT [FK_RespaldosHabilidad_UsuarioHabilidad] FOREIGN KEY ([UsuarioHabilidadID])
+            REFERENCES [dbo].[UsuariosHabilidades]([UsuarioHabilidadID]) ON DELETE CASCADE,
+        CONSTRAINT [FK_RespaldosHabilidad_Respaldante] FOREIGN KEY ([RespaldanteID])
+            REFERENCES [dbo].[Usuarios]([UsuarioID]),
+        CONSTRAINT [FK_RespaldosHabilidad_Emparejamiento] FOREIGN KEY ([EmparejamientoID])
+            REFERENCES [dbo].[Emparejamientos]([EmparejamientoID]) ON DELETE SET NULL
+    );
+
+    -- Índices: un respaldo por usuario y habilidad, y los respaldos dados por cada usuario
+    CREATE UNIQUE INDEX [IX_RespaldosHabilidad_UsuarioHabilidad_Respaldante] ON [dbo].[RespaldosHabilidad] ([UsuarioHabilidadID], [RespaldanteID]);
+    CREATE INDEX [IX_RespaldosHabilidad_Respaldante] ON [dbo].[RespaldosHabilidad] ([RespaldanteID]);
+
+    PRINT 'Tabla RespaldosHabilidad creada exitosamente.';
+END
+ELSE
+BEGIN
+    PRINT 'La tabla RespaldosHabilidad ya existe.';
+END
+GO
@@ -0,0 +1,44 @@
+-- Script para las respuestas a posts que proponen un emparejamiento
+-- SkillSwap - Responder a un post ofreciendo una habilidad a cambio
+
+USE [SkillSwapDB];
+GO
+
+-- Respuestas a posts (cada una crea un emparejamiento pendiente entre el autor y quien responde)
+IF NOT EXISTS (SELECT * FROM sysobjects WHERE name='RespuestasPost' AND xtype='U')
+BEGIN
+    CREATE TABLE [dbo].[RespuestasPost] (
+        [RespuestaID] INT IDENTITY(1,1) PRIMARY KEY,
+        [PostID] INT NOT NULL,
+        [UsuarioID] INT NOT NULL,
+        [HabilidadOfrecidaID] INT NOT NULL,
+        [EmparejamientoID] INT NOT NULL,
+        [Mensaje] NVARCHAR(1000) NULL,
+        [Estado] NVARCHAR(20) NOT NULL DEFAULT 'pending',
+        [FechaCreacion] DATETIME NOT NULL DEFAULT GETDATE(),
+        [FechaRespuesta] DATETIME NULL,
+
+        -- Constraints
+        CONSTRAINT [FK_RespuestasPost_Post] FOREIGN KEY ([PostID])
+            REFERENCES [dbo].[Posts]([PostID]) ON DELETE CASCADE,
+        CONSTRAINT [FK_RespuestasPost_Usuario] FOREIGN KEY ([UsuarioID])
+            REFERENCES [dbo].[Usuarios]([UsuarioID]) ON DELETE NO ACTION,
+        CONSTRAINT [FK_RespuestasPost_Habilidad] FOREIGN KEY ([HabilidadOfrecidaID])
+            REFERENCES [dbo].[Habilidades]([HabilidadID]) ON DELETE NO ACTION,
+        CONSTRAINT [FK_RespuestasPost_Emparejamiento] FOREIGN KEY ([EmparejamientoID])
+            REFERENCES [dbo].[Emparejamientos]([EmparejamientoID]) ON DELETE NO ACTION,
+        CONSTRAINT [CK_RespuestasPost_Estado] CHECK ([Estado] IN ('pending', 'accepted', 'declined'))
+    );
+
+    -- Índices: una sola respuesta pendiente por usuario y post
+    CREATE UNIQUE INDEX [IX_RespuestasPost_Post_Usuario_Pendiente] ON [dbo].[RespuestasPost] ([PostID], [UsuarioID])
+        WHERE [Estado] = 'pending';
+    CREATE INDEX [IX_RespuestasPost_Post_Estado] ON [dbo].[RespuestasPost] ([PostID], [Estado], [FechaCreacion] DESC);
+
+    PRINT 'Tabla RespuestasPost creada exitosamente.';
+END
+ELSE
+BEGIN
+    PRINT 'La tabla RespuestasPost ya existe.';
+END
+GO
@@ -0,0 +1,125 @@
+-- Script para el historial de ediciones de comentarios y posts
+-- SkillSwap - Revisiones y marcas de "editado"
+
+USE [SkillSwapDB];
+GO
+
+-- Versiones anteriores de los comentarios (una fila por edición)
+IF NOT EXISTS (SELECT * FROM sysobjects WHERE name='RevisionesComentario' AND xtype='U')
+BEGIN
+    CREATE TABLE [dbo].[RevisionesComentario] (
+        [RevisionID] INT IDENTITY(1,1) PRIMARY KEY,
+        [ComentarioID] INT NOT NULL,
+        [ContenidoAnterior] NVARCHAR(MAX) NOT NULL,
+        [EditorID] INT NULL,
+        [FechaEdicion] DATETIME NOT NULL DEFAULT GETDATE(),
+
+        -- Constraints
+        CONSTRAINT [FK_RevisionesComentario_Comentario] FOREIGN KEY ([ComentarioID])
+            REFERENCES [dbo].[Comentarios]([ComentarioID]) ON DELETE CASCADE,
+        CONSTRAINT [FK_RevisionesComentario_Editor] FOREIGN KEY ([EditorID])
+            REFERENCES [dbo].[Usuarios]([UsuarioID]) ON DELETE NO ACTION
+    );
+
+    -- Índices
+    CREATE INDEX [IX_RevisionesComentario_Comentario] ON [dbo].[RevisionesComentario] ([ComentarioID], [FechaEdicion] DESC);
+
+    PRINT 'Tabla RevisionesComentario creada exitosamente.';
+END
+ELSE
+BEGIN
+    PRINT 'La tabla RevisionesComentario ya existe.';
+END
+GO
+
+-- Versiones anteriores de los posts (descripción y habilidad)
+IF NOT EXISTS (SELECT * FROM sysobjects WHERE name='RevisionesPost' AND xtype='U')
+BEGIN
+    CREATE TABLE [dbo].[RevisionesPost] (
+        [RevisionID] INT IDENTITY(1,1) PRIMARY KEY,
+        [PostID] INT NOT NULL,
+        [DescripcionAnterior] NVARCHAR(MAX) NOT NULL,
+        [HabilidadIDAnterior] INT NULL,
+        [EditorID] INT NULL,
+        [FechaEdicion] DATETIME NOT NULL DEFAULT GETDATE(),
+
+        -- Constraints
+        CONSTRAINT [FK_RevisionesPost_Post] FOREIGN KEY ([PostID])
+            REFERENCES [dbo].[Posts]([PostID]) ON DELETE CASCADE,
+        CONSTRAINT [FK_RevisionesPost_Editor] FOREIGN KEY ([EditorID])
+            REFERENCES [dbo].[Usuarios]([UsuarioID]) ON DELETE NO ACTION
+    );
+
+    -- Índices
+    CREATE INDEX [IX_RevisionesPost_Post] ON [dbo].[RevisionesPost] ([PostID], [FechaEdicion] DESC);
+
+    PRINT 'Tabla RevisionesPost creada exitosamente.';
+END
+ELSE
+BEGIN
+    PRINT 'La tabla RevisionesPost ya existe.';
+END
+GO
+
+-- Marcas de "editado" en los contenidos
+IF COL_LENGTH('dbo.Comentarios', 'EditadoEn') IS NULL
+BEGIN
+    ALTER TABLE [dbo].[Comentarios] ADD [EditadoEn] DATETIME NULL,
+        [TotalEdiciones] INT NOT NULL CONSTRAINT [DF_Comentarios_TotalEdiciones] DEFAULT 0;
+    PRINT 'Columnas Comentarios.EditadoEn y TotalEdiciones agregadas.';
+END
+GO
+
+IF COL_LENGTH('dbo.Posts', 'EditadoEn') IS NULL
+BEGIN
+    ALTER TABLE [dbo].[Posts] ADD [EditadoEn] DATETIME NULL,
+        [TotalEdiciones] INT NOT NULL CONSTRAINT [DF_Posts_TotalEdiciones] DEFAULT 0;
+    PRINT 'Columnas Posts.EditadoEn y TotalEdiciones agregadas.';
+END
+GO
+
+-- La vista de comentarios expone las marcas de edición
+ALTER VIEW vw_ComentariosCompletos AS
+SELECT
+    c.ComentarioID,
+    c.PostID,
+    c.UsuarioID,
+    c.ComentarioPadreID,
+    c.Contenido,
+    c.ContenidoHTML,
+    c.CreatedAt,
+    c.UpdatedAt,
+    c.EditadoEn,
+    c.TotalEdiciones,
+    c.Activo,
+    u.NombreUsuario,
+    u.PrimerNombre,
+    u.Apellido,
+    u.CorreoElectronico,
+    -- Conteo de likes y dislikes
+    ISNULL(likes.total_likes, 0) AS TotalLikes,
+    ISNULL(dislikes.total_dislikes, 0) AS TotalDislikes,
+    -- Conteo de respuestas
+    ISNULL(respuestas.total_respuestas, 0) AS TotalRespuestas
+FROM Comentarios c
+JOIN Usuarios u ON c.UsuarioID = u.UsuarioID
+LEFT JOIN (
+    SELECT ComentarioID, COUNT(*) as total_likes
+    FROM ComentarioLikes
+    WHERE TipoVoto = 'like'
+    GROUP BY ComentarioID
+) likes ON c.ComentarioID = likes.ComentarioID
+LEFT JOIN (
+    SELECT ComentarioID, COUNT(*) as total_dislikes
+    FROM ComentarioLikes
+    WHERE TipoVoto = 'dislike'
+    GROUP BY ComentarioID
+) dislikes ON c.ComentarioID = dislikes.ComentarioID
+LEFT JOIN (
+    SELECT ComentarioPadreID, COUNT(*) as total_respuestas
+    FROM Comentarios
+    WHERE ComentarioPadreID IS NOT NULL AND Activo = 1 AND Oculto = 0
+    GROUP BY ComentarioPadreID
+) respuestas ON c.ComentarioID = respuestas.ComentarioPadreID
+WHERE c.Activo = 1 AND c.Oculto = 0;
+GO
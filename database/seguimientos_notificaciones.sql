@@ -0,0 +1,70 @@
+-- Script para seguimiento de hilos de posts y agrupación de notificaciones
+-- SkillSwap - Notificaciones de actividad en comentarios
+
+USE [SkillSwapDB];
+GO
+
+-- Seguimiento de hilos: Siguiendo = 0 indica que el usuario dejó de seguir el post.
+-- El autor del post lo sigue por defecto aunque no tenga fila.
+IF NOT EXISTS (SELECT * FROM sysobjects WHERE name='SeguimientosPost' AND xtype='U')
+BEGIN
+    CREATE TABLE [dbo].[SeguimientosPost] (
+        [SeguimientoID] INT IDENTITY(1,1) PRIMARY KEY,
+        [UsuarioID] INT NOT NULL,
+        [PostID] INT NOT NULL,
+        [Siguiendo] BIT NOT NULL DEFAULT 1,
+        [FechaCreacion] DATETIME NOT NULL DEFAULT GETDATE(),
+
+        -- Constraints
+        CONSTRAINT [FK_SeguimientosPost_Usuario] FOREIGN KEY ([UsuarioID])
+            REFERENCES [dbo].[Usuarios]([UsuarioID]) ON DELETE NO ACTION,
+        CONSTRAINT [FK_SeguimientosPost_Post] FOREIGN KEY ([PostID])
+            REFERENCES [dbo].[Posts]([PostID]) ON DELETE CASCADE
+    );
+
+    -- Índices
+    CREATE UNIQUE INDEX [IX_SeguimientosPost_Usuario_Post] ON [dbo].[SeguimientosPost] ([UsuarioID], [PostID]);
+    CREATE INDEX [IX_SeguimientosPost_Post] ON [dbo].[SeguimientosPost] ([PostID], [Siguiendo]);
+
+    PRINT 'Tabla SeguimientosPost creada exitosamente.';
+END
+ELSE
+BEGIN
+    PRINT 'La tabla SeguimientosPost ya existe.';
+END
+GO
+
+-- Usuarios que originaron una notificación agrupada ("5 personas respondieron a tu comentario")
+IF NOT EXISTS (SELECT * FROM sysobjects WHERE name='NotificacionActores' AND xtype='U')
+BEGIN
+    CREATE TABLE [dbo].[NotificacionActores] (
+        [NotificacionActorID] INT IDENTITY(1,1) PRIMARY KEY,
+        [NotificacionID] INT NOT NULL,
+        [UsuarioID] INT NOT NULL,
+        [FechaCreacion] DATETIME NOT NULL DEFAULT GETDATE(),
+
+        -- Constraints
+        CONSTRAINT [FK_NotificacionActores_Notificacion] FOREIGN KEY ([NotificacionID])
+            REFERENCES [dbo].[Notificaciones]([NotificacionID]) ON DELETE CASCADE,
+        CONSTRAINT [FK_NotificacionActores_Usuario] FOREIGN KEY ([UsuarioID])
+            REFERENCES [dbo].[Usuarios]([UsuarioID]) ON DELETE NO ACTION
+    );
+
+    -- Índices
+    CREATE UNIQUE INDEX [IX_NotificacionActores_Notificacion_Usuario] ON [dbo].[NotificacionActores] ([NotificacionID], [UsuarioID]);
+
+    PRINT 'Tabla NotificacionActores creada exitosamente.';
+END
+ELSE
+BEGIN
+    PRINT 'La tabla NotificacionActores ya existe.';
+END
+GO
+
+-- Índice para buscar notificaciones agrupables (no leídas del mismo tipo y referencia)
+IF NOT EXISTS (SELECT * FROM sys.indexes WHERE name = 'IX_Notificaciones_Agrupacion')
+BEGIN
+    CREATE INDEX [IX_Notificaciones_Agrupacion] ON [dbo].[Notificaciones] ([UsuarioID], [Tipo], [ReferenciaID], [Leida]);
+    PRINT 'Índice IX_Notificaciones_Agrupacion creado.';
+END
+GO
@@ -0,0 +1,128 @@
+-- Script para la taxonomía de habilidades
+-- SkillSwap - Árbol de categorías, alias de habilidades canónicas, fusión de duplicados
+-- y cola de aprobación de las habilidades propuestas por usuarios
+
+USE [SkillSwapDB];
+GO
+
+-- Árbol de categorías (PadreID NULL = categoría raíz)
+IF NOT EXISTS (SELECT * FROM sysobjects WHERE name='CategoriasHabilidad' AND xtype='U')
+BEGIN
+    CREATE TABLE [dbo].[CategoriasHabilidad] (
+        [CategoriaID] INT IDENTITY(1,1) PRIMARY KEY,
+        [Nombre] NVARCHAR(100) NOT NULL,
+        [PadreID] INT NULL,
+        [Descripcion] NVARCHAR(500) NULL,
+        [FechaCreacion] DATETIME NOT NULL DEFAULT GETDATE(),
+
+        -- Constraints
+        CONSTRAINT [FK_CategoriasHabilidad_Padre] FOREIGN KEY ([PadreID])
+            REFERENCES [dbo].[CategoriasHabilidad]([CategoriaID]) ON DELETE NO ACTION,
+        CONSTRAINT [CK_CategoriasHabilidad_Padre] CHECK ([PadreID] IS NULL OR [PadreID] <> [CategoriaID])
+    );
+
+    CREATE INDEX [IX_CategoriasHabilidad_Padre] ON [dbo].[CategoriasHabilidad] ([PadreID], [Nombre]);
+
+    PRINT 'Tabla CategoriasHabilidad creada exitosamente.';
+END
+ELSE
+BEGIN
+    PRINT 'La tabla CategoriasHabilidad ya existe.';
+END
+GO
+
+-- Categoría del árbol asignada a cada habilidad. Habilidades.Categoria conserva el nombre
+-- de la categoría para los listados y filtros existentes.
+IF COL_LENGTH('dbo.Habilidades', 'CategoriaID') IS NULL
+BEGIN
+    ALTER TABLE [dbo].[Habilidades] ADD [CategoriaID] INT NULL
+        CONSTRAINT [FK_Habilidades_Categoria] FOREIGN KEY REFERENCES [dbo].[CategoriasHabilidad]([CategoriaID]);
+    PRINT 'Columna Habilidades.CategoriaID agregada.';
+END
+GO
+
+-- El árbol reemplaza la lista fija de categorías: se elimina el CHECK de Habilidades.Categoria
+-- (creado sin nombre en scripts.sql) y la columna admite nombres de categoría de hasta 100 caracteres
+DECLARE @CheckCategoria SYSNAME;
+SELECT @CheckCategoria = cc.name
+FROM sys.check_constraints cc
+INNER JOIN sys.columns c ON c.object_id = cc.parent_object_id AND c.column_id = cc.parent_column_id
+WHERE cc.parent_object_id = OBJECT_ID('dbo.Habilidades') AND c.name = 'Categoria';
+IF @CheckCategoria IS NOT NULL
+BEGIN
+    EXEC('ALTER TABLE [dbo].[Habilidades] DROP CONSTRAINT [' + @CheckCategoria + ']');
+    PRINT 'Restricción de categorías fijas de Habilidades eliminada.';
+END
+IF COL_LENGTH('dbo.Habilidades', 'Categoria') < 200
+BEGIN
+    ALTER TABLE [dbo].[Habilidades] ALTER COLUMN [Categoria] NVARCHAR(100) NULL;
+    PRINT 'Columna Habilidades.Categoria ampliada.';
+END
+GO
+
+-- Alias y sinónimos de las habilidades canónicas ("Golang" -> "Go")
+IF NOT EXISTS (SELECT * FROM sysobjects WHERE name='AliasesHabilidad' AND xtype='U')
+BEGIN
+    CREATE TABLE [dbo].[AliasesHabilidad] (
+        [AliasID] INT IDENTITY(1,1) PRIMARY KEY,
+        [HabilidadID] INT NOT NULL,
+        [Alias] NVARCHAR(100) NOT NULL,
+        [AliasNormalizado] NVARCHAR(100) NOT NULL,
+        [FechaCreacion] DATETIME NOT NULL DEFAULT GETDATE(),
+
+        -- Constraints
+        CONSTRAINT [FK_AliasesHabilidad_Habilidad] FOREIGN KEY ([HabilidadID])
+            REFERENCES [dbo].[Habilidades]([HabilidadID]) ON DELETE CASCADE
+    );
+
+    -- Índices: un alias normalizado apunta a una sola habilidad
+    CREATE UNIQUE INDEX [IX_AliasesHabilidad_Normalizado] ON [dbo].[AliasesHabilidad] ([AliasNormalizado]);
+    CREATE INDEX [IX_AliasesHabilidad_Habilidad] ON [dbo].[AliasesHabilidad] ([HabilidadID]);
+
+    PRINT 'Tabla AliasesHabilidad creada exitosamente.';
+END
+ELSE
+BEGIN
+    PRINT 'La tabla AliasesHabilidad ya existe.';
+END
+GO
+
+-- Habilidades propuestas por usuarios, pendientes de aprobación por un administrador
+IF NOT EXISTS (SELECT * FROM sysobjects WHERE name='PropuestasHabilidad' AND xtype='U')
+BEGIN
+    CREATE TABLE [dbo].[PropuestasHabilidad] (
+        [PropuestaID] INT IDENTITY(1,1) PRIMARY KEY,
+        [UsuarioID] INT NOT NULL,
+        [Nombre] NVARCHAR(100) NOT NULL,
+        [NombreNormalizado] NVARCHAR(100) NOT NULL,
+        [Categoria] NVARCHAR(100) NULL,
+        [Descripcion] NVARCHAR(500) NULL,
+        [Estado] NVARCHAR(20) NOT NULL DEFAULT 'pending',
+        [HabilidadID] INT NULL,                 -- Habilidad creada o existente a la que se asoció
+        [RevisorID] INT NULL,
+        [NotaRevision] NVARCHAR(500) NULL,
+        [FechaCreacion] DATETIME NOT NULL DEFAULT GETDATE(),
+        [FechaRevision] DATETIME NULL,
+
+        -- Constraints
+        CONSTRAINT [FK_PropuestasHabilidad_Usuario] FOREIGN KEY ([UsuarioID])
+            REFERENCES [dbo].[Usuarios]([UsuarioID]) ON DELETE CASCADE,
+        CONSTRAINT [FK_PropuestasHabilidad_Habilidad] FOREIGN KEY ([HabilidadID])
+            REFERENCES [dbo].[Habilidades]([HabilidadID]) ON DELETE SET NULL,
+        CONSTRAINT [FK_PropuestasHabilidad_Revisor] FOREIGN KEY ([RevisorID])
+            REFERENCES [dbo].[Usuarios]([UsuarioID]) ON DELETE NO ACTION,
+        CONSTRAINT [CK_PropuestasHabilidad_Estado] CHECK ([Estado] IN ('pending', 'approved', 'rejected'))
+    );
+
+    -- Índices: una sola propuesta pendiente por nombre normalizado
+    CREATE UNIQUE INDEX [IX_PropuestasHabilidad_Pendiente] ON [dbo].[PropuestasHabilidad] ([NombreNormalizado])
+        WHERE [Estado] = 'pending';
+    CREATE INDEX [IX_PropuestasHabilidad_Estado] ON [dbo].[PropuestasHabilidad] ([Estado], [FechaCreacion]);
+
+    PRINT 'Tabla PropuestasHabilidad creada exitosamente.';
+END
+ELSE
+BEGIN
+    PRINT 'La tabla PropuestasHabilidad ya existe.';
+END
+GO